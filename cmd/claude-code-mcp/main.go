@@ -18,8 +18,10 @@ import (
 
 	"github.com/d-kuro/claude-code-mcp/internal/cmd"
 	"github.com/d-kuro/claude-code-mcp/internal/cmd/google"
+	"github.com/d-kuro/claude-code-mcp/internal/ignore"
 	"github.com/d-kuro/claude-code-mcp/internal/logging"
 	"github.com/d-kuro/claude-code-mcp/internal/server"
+	"github.com/d-kuro/claude-code-mcp/internal/tools"
 	"github.com/d-kuro/claude-code-mcp/internal/version"
 )
 
@@ -40,17 +42,98 @@ Claude Code's built-in tools as MCP tools for external applications.`,
 
 // serverFlags holds the flags for the server command
 type serverFlags struct {
-	httpAddr string
+	httpAddr    string
+	httpToken   string
+	config      string
+	readOnly    bool
+	sanitize    bool
+	backupFiles bool
+	maxDepth    int
+	allowRoot   bool
+	skipDirs    bool
+	skipDirList []string
+	cleanEnv    bool
+	allowedEnv  []string
+	dangerous   []string
+	allowDanger []string
+	binaries    []string
+	sessionEnv  map[string]string
+	noTTY       bool
+	stripANSI   bool
+	compress    bool
+	progress    time.Duration
+	manifest    string
+	history     int
+	maxSessions int
+	maxCommands int
+	cmdQueue    time.Duration
+	maxArgSize  int64
+	maxOutput   int64
+	allowTypes  []string
+	blockTypes  []string
+	proxy       string
+	webCache    string
+	webCacheMax int64
+	redirects   int
+	tempDir     string
+	envelope    bool
+	chunkedRead int64
+	writeRetry  int
+	maxNotebook int64
+	lsTimeout   time.Duration
+	lsMax       int
 }
 
 var serverOpts = &serverFlags{}
 
 func init() {
 	// Add server flags
-	rootCmd.Flags().StringVar(&serverOpts.httpAddr, "http", "", "HTTP server address (e.g., :8080)")
+	rootCmd.Flags().StringVar(&serverOpts.httpAddr, "http", "", "HTTP server address (e.g., :8080 for 127.0.0.1:8080); non-loopback addresses require --http-token")
+	rootCmd.Flags().StringVar(&serverOpts.httpToken, "http-token", os.Getenv("CLAUDE_CODE_MCP_HTTP_TOKEN"), "Bearer token HTTP clients must send (defaults to $CLAUDE_CODE_MCP_HTTP_TOKEN)")
+	rootCmd.Flags().BoolVar(&serverOpts.compress, "http-compress", false, "Gzip-compress large HTTP responses for clients that accept it")
+	rootCmd.Flags().StringVar(&serverOpts.config, "config", "", "YAML config file with path and command rules and disabled tools (reloaded on SIGHUP)")
+	rootCmd.Flags().BoolVar(&serverOpts.sanitize, "sanitize-output", false, "Neutralize prompt control markers (e.g. <system-reminder>) in tool output")
+	rootCmd.Flags().BoolVar(&serverOpts.backupFiles, "backup-files", false, "Keep a .backup copy of files while Edit and MultiEdit write them instead of rolling back in memory")
+	rootCmd.Flags().IntVar(&serverOpts.maxDepth, "max-search-depth", 0, "Maximum directory depth for Glob and Grep searches (0 for unbounded)")
+	rootCmd.Flags().BoolVar(&serverOpts.allowRoot, "allow-root-search", false, "Allow Glob and Grep searches rooted at a filesystem root such as /")
+	rootCmd.Flags().BoolVar(&serverOpts.skipDirs, "default-ignores", false, "Skip common noise directories (.git, node_modules, vendor, __pycache__, .venv) in Glob, Grep and LS")
+	rootCmd.Flags().StringSliceVar(&serverOpts.skipDirList, "default-ignore-dirs", ignore.DefaultDirectories, "Comma-separated directory names skipped by --default-ignores")
+	rootCmd.Flags().BoolVar(&serverOpts.cleanEnv, "clean-env", false, "Run commands with a minimal environment (PATH, HOME, LANG, ...) instead of the server's full environment")
+	rootCmd.Flags().StringSliceVar(&serverOpts.allowedEnv, "allowed-env-vars", nil, "Comma-separated list of the only environment variables passed to Bash sessions")
+	rootCmd.Flags().StringArrayVar(&serverOpts.dangerous, "dangerous-pattern", nil, "Case-insensitive regular expression for Bash and custom tool commands to reject, in addition to the built-in dangerous patterns (repeatable)")
+	rootCmd.Flags().StringArrayVar(&serverOpts.allowDanger, "allow-dangerous-pattern", nil, "Case-insensitive regular expression for commands accepted even though they match a dangerous pattern (repeatable)")
+	rootCmd.Flags().StringSliceVar(&serverOpts.binaries, "allowed-binaries", nil, "Comma-separated list of the only binaries Bash and custom tool commands may run (e.g. git,go,ls,grep)")
+	rootCmd.Flags().BoolVar(&serverOpts.noTTY, "non-interactive", false, "Set TERM=dumb, NO_COLOR=1 and CI=1 for commands run by Bash and custom tools")
+	rootCmd.Flags().BoolVar(&serverOpts.stripANSI, "strip-ansi", false, "Remove ANSI escape sequences from the output of Bash and custom tools")
+	rootCmd.Flags().StringToStringVar(&serverOpts.sessionEnv, "session-env", nil, "Comma-separated KEY=VALUE variables set in every new Bash session (e.g. CI=1,NO_COLOR=1)")
+	rootCmd.Flags().DurationVar(&serverOpts.progress, "progress-interval", 0, "Send Bash progress notifications at this interval while a command runs (0 to disable)")
+	rootCmd.Flags().Int64Var(&serverOpts.maxArgSize, "max-argument-size", 0, "Maximum size in bytes of a tool call's arguments (0 for the default of 16 MiB, -1 to disable)")
+	rootCmd.Flags().Int64Var(&serverOpts.maxOutput, "max-output-size", 0, "Maximum size in bytes of a tool call's text output before it is truncated (0 for the default of 1 MiB, -1 to disable)")
+	rootCmd.Flags().IntVar(&serverOpts.maxSessions, "max-sessions", 0, "Maximum number of live Bash sessions (0 for no limit)")
+	rootCmd.Flags().IntVar(&serverOpts.maxCommands, "max-concurrent-commands", 0, "Maximum number of Bash commands running at once (0 for no limit)")
+	rootCmd.Flags().DurationVar(&serverOpts.cmdQueue, "command-queue-timeout", 0, "How long a Bash command over --max-concurrent-commands waits for a slot before failing")
+	rootCmd.Flags().IntVar(&serverOpts.history, "history-size", 0, "Number of commands kept in each Bash session history (0 for the default of 100, -1 to disable)")
+	rootCmd.Flags().StringSliceVar(&serverOpts.allowTypes, "allowed-content-types", nil, "Only return WebFetch content of these media types (e.g. text/html,text/plain,application/json)")
+	rootCmd.Flags().StringSliceVar(&serverOpts.blockTypes, "blocked-content-types", nil, "Reject WebFetch content of these media types (e.g. application/pdf,image/*)")
+	rootCmd.Flags().StringVar(&serverOpts.proxy, "proxy", "", "HTTP proxy URL for WebFetch and WebSearch (defaults to HTTP_PROXY/HTTPS_PROXY/NO_PROXY)")
+	rootCmd.Flags().StringVar(&serverOpts.webCache, "web-cache-dir", "", "Directory for caching WebFetch results for 15 minutes (disabled when empty)")
+	rootCmd.Flags().Int64Var(&serverOpts.webCacheMax, "web-cache-max-size", 0, "Maximum size in bytes of the WebFetch cache directory (0 for the default of 50 MiB)")
+	rootCmd.Flags().IntVar(&serverOpts.writeRetry, "write-retries", 0, "Times to retry writes failing with a transient filesystem error such as ESTALE (0 for the default of 2, negative to disable)")
+	rootCmd.Flags().Int64Var(&serverOpts.maxNotebook, "max-notebook-size", 0, "Largest notebook file in bytes that NotebookRead and NotebookEdit load (0 for the default of 100 MiB)")
+	rootCmd.Flags().DurationVar(&serverOpts.lsTimeout, "ls-timeout", 0, "Timeout for the ls command run by LS (0 for the default of 10s)")
+	rootCmd.Flags().IntVar(&serverOpts.lsMax, "ls-max-entries", 0, "Entries LS lists per directory before summarizing the rest (0 for the default of 5000)")
+	rootCmd.Flags().IntVar(&serverOpts.redirects, "max-redirects", 0, "Maximum number of redirects WebFetch follows (0 for the default of 5)")
+	rootCmd.Flags().StringVar(&serverOpts.tempDir, "temp-dir", "", "Root directory for TempFile and TempDir scratch entries, removed on shutdown (defaults to claude-code-mcp in the system temp directory)")
+	rootCmd.Flags().Int64Var(&serverOpts.chunkedRead, "chunked-read-threshold", 0, "File size in bytes above which Read reads files in chunks with bounded memory (0 for the default of 50 MiB)")
+	rootCmd.Flags().BoolVar(&serverOpts.envelope, "response-envelope", false, "Wrap tool results in a versioned JSON envelope with the tool name, content and metadata")
+	rootCmd.Flags().StringVar(&serverOpts.manifest, "tools-manifest", "", "YAML manifest of custom command-backed tools to register")
+	rootCmd.Flags().BoolVar(&serverOpts.readOnly, "read-only", false, "Reject tools that modify files or run commands (Write, Edit, MultiEdit, ReplaceInFiles, ApplyPatch, FormatFile, Symlink, NotebookEdit, TempFile, TempDir, Bash)")
 
 	// Add subcommands
 	rootCmd.AddCommand(cmd.NewVersionCmd())
+	rootCmd.AddCommand(cmd.NewDescribeToolCmd())
+	rootCmd.AddCommand(cmd.NewExportToolsCmd())
+	rootCmd.AddCommand(cmd.NewSecurityStatusCmd())
 	rootCmd.AddCommand(google.NewGoogleCmd())
 }
 
@@ -66,7 +149,50 @@ func runServer(cmd *cobra.Command, args []string) error {
 	// Initialize logger with log level
 	logger := logging.NewLogger(logLevel)
 
-	opts := &server.Options{}
+	opts := &server.Options{
+		ConfigFile:               serverOpts.config,
+		HTTPAuthToken:            serverOpts.httpToken,
+		ReadOnly:                 serverOpts.readOnly,
+		SanitizeOutput:           serverOpts.sanitize,
+		BackupFiles:              serverOpts.backupFiles,
+		MaxSearchDepth:           serverOpts.maxDepth,
+		AllowRootSearch:          serverOpts.allowRoot,
+		CleanEnv:                 serverOpts.cleanEnv,
+		AllowedEnvVars:           serverOpts.allowedEnv,
+		DangerousPatterns:        serverOpts.dangerous,
+		AllowedDangerousPatterns: serverOpts.allowDanger,
+		AllowedBinaries:          serverOpts.binaries,
+		SessionEnv:               serverOpts.sessionEnv,
+		StripANSI:                serverOpts.stripANSI,
+		CompressResponses:        serverOpts.compress,
+		ProgressInterval:         serverOpts.progress,
+		ToolManifest:             serverOpts.manifest,
+		HistorySize:              serverOpts.history,
+		MaxSessions:              serverOpts.maxSessions,
+		MaxCommands:              serverOpts.maxCommands,
+		CommandQueueTimeout:      serverOpts.cmdQueue,
+		MaxArgumentSize:          serverOpts.maxArgSize,
+		MaxOutputSize:            serverOpts.maxOutput,
+		AllowedContentTypes:      serverOpts.allowTypes,
+		BlockedContentTypes:      serverOpts.blockTypes,
+		Proxy:                    serverOpts.proxy,
+		WebCacheDir:              serverOpts.webCache,
+		WebCacheMaxSize:          serverOpts.webCacheMax,
+		MaxRedirects:             serverOpts.redirects,
+		TempDir:                  serverOpts.tempDir,
+		ResponseEnvelope:         serverOpts.envelope,
+		ChunkedReadThreshold:     serverOpts.chunkedRead,
+		WriteRetries:             serverOpts.writeRetry,
+		MaxNotebookSize:          serverOpts.maxNotebook,
+		LSTimeout:                serverOpts.lsTimeout,
+		LSMaxEntries:             serverOpts.lsMax,
+	}
+	if serverOpts.noTTY {
+		opts.NonInteractiveEnv = tools.NonInteractiveEnvVars
+	}
+	if serverOpts.skipDirs {
+		opts.DefaultIgnores = serverOpts.skipDirList
+	}
 
 	srv, err := server.New(opts)
 	if err != nil {
@@ -83,32 +209,44 @@ func runServer(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to start server: %w", err)
 	}
 
-	var transport mcp.Transport
+	if serverOpts.config != "" {
+		go reloadOnHangup(ctx, srv)
+	}
+
+	transportName := "stdio"
 	if serverOpts.httpAddr != "" {
-		// TODO: Implement HTTP/SSE transport
-		logger.Warn("HTTP transport not yet implemented, using stdio",
-			slog.String("requested_addr", serverOpts.httpAddr))
-		transport = mcp.NewStdioTransport()
-	} else {
-		transport = mcp.NewStdioTransport()
+		transportName = "http"
 	}
 
 	logger.Info("Claude Code MCP Server starting",
 		slog.String("version", version.GetVersion().Version),
-		slog.String("transport", fmt.Sprintf("%T", transport)),
+		slog.String("transport", transportName),
+		slog.Bool("read_only", serverOpts.readOnly),
 		slog.Int("tools_available", srv.GetRegistry().Count()))
 
-	// Start server in a goroutine so we can handle signals
+	// Start server in a goroutine so we can handle signals. The transport
+	// has its own context, so that it keeps running while Stop drains the
+	// in-flight tool calls and their results can still be sent.
+	serveCtx, stopServing := context.WithCancel(context.Background())
+	defer stopServing()
 	serverDone := make(chan error, 1)
 	go func() {
-		serverDone <- srv.Serve(ctx, transport)
+		if serverOpts.httpAddr != "" {
+			serverDone <- srv.ListenAndServe(serveCtx, serverOpts.httpAddr)
+			return
+		}
+		serverDone <- srv.Serve(serveCtx, mcp.NewStdioTransport())
 	}()
 
 	// Wait for either the server to finish or a signal
+	var serveErr error
+	serving := true
 	select {
 	case err := <-serverDone:
+		serving = false
 		if err != nil && !errors.Is(err, context.Canceled) {
 			logger.Error("Server error", slog.Any("error", err))
+			serveErr = err
 		}
 	case <-ctx.Done():
 		logger.Info("Shutdown signal received")
@@ -122,6 +260,32 @@ func runServer(cmd *cobra.Command, args []string) error {
 		logger.Error("Error stopping server", slog.Any("error", err))
 	}
 
-	logger.Info("Claude Code MCP Server stopped")
-	return nil
+	// Close the transport only once the in-flight calls are drained
+	if serving {
+		stopServing()
+		<-serverDone
+	}
+
+	stats := srv.GetRegistry().Stats()
+	logger.Info("Claude Code MCP Server stopped",
+		slog.Int64("tool_invocations", stats.Invocations),
+		slog.Int64("tool_errors", stats.Errors))
+	return serveErr
+}
+
+// reloadOnHangup reloads the server configuration file on every SIGHUP until
+// ctx is done. Reload logs whether it succeeded.
+func reloadOnHangup(ctx context.Context, srv *server.Server) {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	defer signal.Stop(hangup)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hangup:
+			_ = srv.Reload()
+		}
+	}
 }
@@ -0,0 +1,62 @@
+// Package cmd provides the export-tools command.
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/d-kuro/claude-code-mcp/internal/logging"
+	"github.com/d-kuro/claude-code-mcp/internal/server"
+)
+
+// NewExportToolsCmd creates a new export-tools command
+func NewExportToolsCmd() *cobra.Command {
+	var manifest, config, output string
+
+	cmd := &cobra.Command{
+		Use:   "export-tools",
+		Short: "Print the catalog of all tools and their schemas",
+		Long: `Print a single JSON document describing every enabled tool: its name, category,
+description, JSON Schema of its arguments and example arguments. Use it to
+generate clients or documentation for the server.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			srv, err := server.New(&server.Options{
+				Logger:       logging.NewLogger("error"),
+				ConfigFile:   config,
+				ToolManifest: manifest,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to create server: %w", err)
+			}
+
+			var w io.Writer = os.Stdout
+			if output != "" {
+				file, err := os.Create(output)
+				if err != nil {
+					return fmt.Errorf("failed to create output file: %w", err)
+				}
+				defer func() {
+					_ = file.Close()
+				}()
+				w = file
+			}
+
+			encoder := json.NewEncoder(w)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(srv.ToolCatalog()); err != nil {
+				return fmt.Errorf("error encoding tool catalog: %w", err)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&manifest, "tools-manifest", "", "YAML manifest of custom command-backed tools to include")
+	cmd.Flags().StringVar(&config, "config", "", "YAML config file whose disabled tools are left out")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Write the catalog to this file instead of standard output")
+	return cmd
+}
@@ -0,0 +1,51 @@
+// Package cmd provides the describe-tool command.
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/d-kuro/claude-code-mcp/internal/logging"
+	"github.com/d-kuro/claude-code-mcp/internal/server"
+)
+
+// NewDescribeToolCmd creates a new describe-tool command
+func NewDescribeToolCmd() *cobra.Command {
+	var manifest, config string
+
+	cmd := &cobra.Command{
+		Use:   "describe-tool <name>",
+		Short: "Print a tool's description, schema and examples",
+		Long:  `Print the description, argument schema, category and example arguments of a tool as JSON.`,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			srv, err := server.New(&server.Options{
+				Logger:       logging.NewLogger("error"),
+				ConfigFile:   config,
+				ToolManifest: manifest,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to create server: %w", err)
+			}
+
+			description, err := srv.DescribeTool(args[0])
+			if err != nil {
+				return err
+			}
+
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(description); err != nil {
+				return fmt.Errorf("error encoding tool description: %w", err)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&manifest, "tools-manifest", "", "YAML manifest of custom command-backed tools to describe")
+	cmd.Flags().StringVar(&config, "config", "", "YAML config file whose disabled tools are not described")
+	return cmd
+}
@@ -0,0 +1,54 @@
+// Package cmd provides the security-status command.
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/d-kuro/claude-code-mcp/internal/logging"
+	"github.com/d-kuro/claude-code-mcp/internal/server"
+)
+
+// NewSecurityStatusCmd creates a new security-status command
+func NewSecurityStatusCmd() *cobra.Command {
+	var config string
+	var readOnly bool
+
+	cmd := &cobra.Command{
+		Use:   "security-status",
+		Short: "Print the effective security configuration",
+		Long: `Print the allowed and blocked paths and commands, URL rules, path handling flags,
+read-only mode and disabled tools the server would run with, as JSON. Use it to verify
+that a config file is applied as intended.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			srv, err := server.New(&server.Options{
+				Logger:     logging.NewLogger("error"),
+				ConfigFile: config,
+				ReadOnly:   readOnly,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to create server: %w", err)
+			}
+
+			status, err := srv.SecurityStatus()
+			if err != nil {
+				return err
+			}
+
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(status); err != nil {
+				return fmt.Errorf("error encoding security status: %w", err)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&config, "config", "", "YAML config file with the validator rules and disabled tools")
+	cmd.Flags().BoolVar(&readOnly, "read-only", false, "Report the configuration of a server started with --read-only")
+	return cmd
+}
@@ -0,0 +1,254 @@
+// Package ignore implements project-wide path filtering using .mcpignore files.
+package ignore
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FileName is the name of the project ignore file.
+const FileName = ".mcpignore"
+
+// DefaultDirectories are the directories that Glob, Grep and LS skip when
+// default ignores are enabled: version control metadata, dependency trees
+// and caches, which rarely hold the files being looked for.
+var DefaultDirectories = []string{".git", "node_modules", "vendor", "__pycache__", ".venv"}
+
+// rule is a single compiled ignore pattern.
+type rule struct {
+	regex   *regexp.Regexp
+	negate  bool
+	dirOnly bool
+}
+
+// Matcher matches paths against the gitignore-style patterns of an ignore file.
+// The file is parsed on first use and re-parsed whenever its modification time
+// or size changes. A missing file matches nothing. A nil Matcher is valid and
+// matches nothing.
+//
+// Checking for changes costs a stat per Match, so a search that matches many
+// paths should call Snapshot once and match against the snapshot.
+type Matcher struct {
+	path string
+	root string
+
+	// static is set on snapshots, whose rules never change
+	static bool
+
+	mu      sync.Mutex
+	modTime time.Time
+	size    int64
+	exists  bool
+	rules   []rule
+}
+
+// New creates a matcher for the ignore file at path. Patterns are relative
+// to the directory containing the file.
+func New(path string) *Matcher {
+	cleanPath := filepath.Clean(path)
+	return &Matcher{
+		path: cleanPath,
+		root: filepath.Dir(cleanPath),
+	}
+}
+
+// Path returns the location of the ignore file.
+func (m *Matcher) Path() string {
+	if m == nil {
+		return ""
+	}
+	return m.path
+}
+
+// Snapshot returns a matcher that keeps the rules the ignore file has now
+// and does not check the file again. A nil Matcher returns nil.
+func (m *Matcher) Snapshot() *Matcher {
+	if m == nil {
+		return nil
+	}
+	return &Matcher{
+		path:   m.path,
+		root:   m.root,
+		static: true,
+		rules:  m.load(),
+	}
+}
+
+// Match reports whether the absolute path is excluded by the ignore file.
+// A path is also excluded when any of its parent directories is excluded.
+func (m *Matcher) Match(path string, isDir bool) bool {
+	if m == nil {
+		return false
+	}
+
+	rules := m.load()
+	if len(rules) == 0 {
+		return false
+	}
+
+	rel, err := filepath.Rel(m.root, filepath.Clean(path))
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return false
+	}
+
+	parts := strings.Split(filepath.ToSlash(rel), "/")
+	for i := range parts {
+		candidate := strings.Join(parts[:i+1], "/")
+		candidateIsDir := isDir || i < len(parts)-1
+		if matchRules(rules, candidate, candidateIsDir) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// load returns the current rules, re-parsing the ignore file if it changed.
+func (m *Matcher) load() []rule {
+	if m.static {
+		return m.rules
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stat, err := os.Stat(m.path)
+	if err != nil {
+		m.exists = false
+		m.rules = nil
+		return nil
+	}
+
+	if m.exists && stat.ModTime().Equal(m.modTime) && stat.Size() == m.size {
+		return m.rules
+	}
+
+	data, err := os.ReadFile(m.path)
+	if err != nil {
+		m.exists = false
+		m.rules = nil
+		return nil
+	}
+
+	m.exists = true
+	m.modTime = stat.ModTime()
+	m.size = stat.Size()
+	m.rules = parse(data)
+	return m.rules
+}
+
+// matchRules applies the rules in order; the last matching rule wins.
+func matchRules(rules []rule, relPath string, isDir bool) bool {
+	ignored := false
+	for _, r := range rules {
+		if r.dirOnly && !isDir {
+			continue
+		}
+		if r.regex.MatchString(relPath) {
+			ignored = !r.negate
+		}
+	}
+	return ignored
+}
+
+// parse compiles the patterns of an ignore file.
+func parse(data []byte) []rule {
+	var rules []rule
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var r rule
+		if strings.HasPrefix(line, "!") {
+			r.negate = true
+			line = line[1:]
+		} else if strings.HasPrefix(line, `\!`) || strings.HasPrefix(line, `\#`) {
+			line = line[1:]
+		}
+
+		if strings.HasSuffix(line, "/") {
+			r.dirOnly = true
+			line = strings.TrimSuffix(line, "/")
+		}
+
+		// Patterns containing a slash are anchored to the ignore file directory
+		anchored := strings.Contains(line, "/")
+		line = strings.TrimPrefix(line, "/")
+		if line == "" {
+			continue
+		}
+
+		expr := globToRegexp(line)
+		if anchored {
+			expr = "^" + expr + "$"
+		} else {
+			expr = "^(?:.*/)?" + expr + "$"
+		}
+
+		regex, err := regexp.Compile(expr)
+		if err != nil {
+			continue
+		}
+		r.regex = regex
+		rules = append(rules, r)
+	}
+
+	return rules
+}
+
+// globToRegexp converts a gitignore glob into a regular expression body.
+func globToRegexp(pattern string) string {
+	var builder strings.Builder
+
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch c {
+		case '*':
+			if i+1 < len(pattern) && pattern[i+1] == '*' {
+				i++
+				if i+1 < len(pattern) && pattern[i+1] == '/' {
+					// "**/" matches zero or more directories
+					i++
+					builder.WriteString("(?:.*/)?")
+				} else {
+					builder.WriteString(".*")
+				}
+			} else {
+				builder.WriteString("[^/]*")
+			}
+		case '?':
+			builder.WriteString("[^/]")
+		case '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end < 0 {
+				builder.WriteString(`\[`)
+				continue
+			}
+			class := pattern[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			builder.WriteString("[" + class + "]")
+			i += end + 1
+		case '\\':
+			if i+1 < len(pattern) {
+				i++
+				builder.WriteString(regexp.QuoteMeta(string(pattern[i])))
+			}
+		default:
+			builder.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	return builder.String()
+}
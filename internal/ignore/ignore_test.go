@@ -0,0 +1,121 @@
+package ignore
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestMatch(t *testing.T) {
+	root := t.TempDir()
+	content := `# build output
+build/
+*.log
+!keep.log
+/vendor
+docs/**/*.tmp
+secret?.txt
+`
+	if err := os.WriteFile(filepath.Join(root, FileName), []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write ignore file: %v", err)
+	}
+
+	m := New(filepath.Join(root, FileName))
+
+	tests := []struct {
+		name  string
+		path  string
+		isDir bool
+		want  bool
+	}{
+		{name: "directory pattern", path: "build", isDir: true, want: true},
+		{name: "file inside ignored directory", path: "build/out/app.bin", want: true},
+		{name: "nested directory pattern", path: "src/build", isDir: true, want: true},
+		{name: "directory pattern does not match file", path: "build", isDir: false, want: false},
+		{name: "extension pattern", path: "logs/app.log", want: true},
+		{name: "negated pattern", path: "logs/keep.log", want: false},
+		{name: "anchored pattern at root", path: "vendor/lib.go", want: true},
+		{name: "anchored pattern not nested", path: "src/vendor/lib.go", want: false},
+		{name: "double star", path: "docs/a/b/c.tmp", want: true},
+		{name: "double star zero directories", path: "docs/c.tmp", want: true},
+		{name: "question mark", path: "secret1.txt", want: true},
+		{name: "unmatched file", path: "src/main.go", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := m.Match(filepath.Join(root, filepath.FromSlash(tt.path)), tt.isDir); got != tt.want {
+				t.Errorf("Match(%q) = %v, want %v", tt.path, got, tt.want)
+			}
+		})
+	}
+
+	if m.Match(filepath.Join(filepath.Dir(root), "build", "x"), false) {
+		t.Errorf("Expected paths outside the ignore file directory not to match")
+	}
+}
+
+func TestMatchReloadsOnChange(t *testing.T) {
+	root := t.TempDir()
+	ignorePath := filepath.Join(root, FileName)
+	target := filepath.Join(root, "data", "file.csv")
+
+	m := New(ignorePath)
+	if m.Match(target, false) {
+		t.Errorf("Expected no match without an ignore file")
+	}
+
+	if err := os.WriteFile(ignorePath, []byte("data/\n"), 0644); err != nil {
+		t.Fatalf("Failed to write ignore file: %v", err)
+	}
+	if !m.Match(target, false) {
+		t.Errorf("Expected match after ignore file was created")
+	}
+
+	if err := os.WriteFile(ignorePath, []byte("*.txt\n"), 0644); err != nil {
+		t.Fatalf("Failed to write ignore file: %v", err)
+	}
+	// Ensure the modification time changes even on coarse-grained filesystems
+	future := time.Now().Add(2 * time.Second)
+	if err := os.Chtimes(ignorePath, future, future); err != nil {
+		t.Fatalf("Failed to update modification time: %v", err)
+	}
+	if m.Match(target, false) {
+		t.Errorf("Expected no match after ignore file was changed")
+	}
+
+	if err := os.Remove(ignorePath); err != nil {
+		t.Fatalf("Failed to remove ignore file: %v", err)
+	}
+	if m.Match(filepath.Join(root, "notes.txt"), false) {
+		t.Errorf("Expected no match after ignore file was removed")
+	}
+}
+
+func TestSnapshotKeepsRules(t *testing.T) {
+	root := t.TempDir()
+	ignorePath := filepath.Join(root, FileName)
+	target := filepath.Join(root, "data", "file.csv")
+	if err := os.WriteFile(ignorePath, []byte("data/\n"), 0644); err != nil {
+		t.Fatalf("Failed to write ignore file: %v", err)
+	}
+
+	snapshot := New(ignorePath).Snapshot()
+	if err := os.Remove(ignorePath); err != nil {
+		t.Fatalf("Failed to remove ignore file: %v", err)
+	}
+	if !snapshot.Match(target, false) {
+		t.Errorf("Expected the snapshot to keep matching after the ignore file was removed")
+	}
+}
+
+func TestNilMatcher(t *testing.T) {
+	var m *Matcher
+	if m.Match("/any/path", false) {
+		t.Errorf("Expected nil matcher to match nothing")
+	}
+	if m.Snapshot() != nil {
+		t.Errorf("Expected the snapshot of a nil matcher to be nil")
+	}
+}
@@ -52,6 +52,13 @@ func (l *Logger) WithSession(sessionID string) *Logger {
 	}
 }
 
+// WithRequestID returns a logger with the request id of a tool call.
+func (l *Logger) WithRequestID(requestID string) *Logger {
+	return &Logger{
+		Logger: l.With(slog.String("request_id", requestID)),
+	}
+}
+
 // Debug logs a debug message with optional arguments.
 func (l *Logger) Debug(msg string, args ...any) {
 	l.Logger.Debug(msg, args...)
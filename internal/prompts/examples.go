@@ -0,0 +1,126 @@
+// Package prompts provides sample arguments for the built-in tools.
+package prompts
+
+// ToolExamples holds sample arguments for the built-in tools, as JSON
+// objects, keyed by tool name. They are shown by the describe-tool command.
+var ToolExamples = map[string][]string{
+	"Read": {
+		`{"file_path": "/home/user/project/main.go"}`,
+		`{"file_path": "/home/user/project/server.log", "offset": 1000, "limit": 200}`,
+		`{"file_path": "/home/user/project/main.go", "anchor": "func main", "context_lines": 10}`,
+	},
+	"ReadMany": {
+		`{"paths": ["/home/user/project/go.mod", "/home/user/project/main.go"]}`,
+	},
+	"Write": {
+		`{"file_path": "/home/user/project/notes.txt", "content": "First line\n"}`,
+	},
+	"Edit": {
+		`{"file_path": "/home/user/project/main.go", "old_string": "fmt.Println(\"hello\")", "new_string": "fmt.Println(\"hello, world\")"}`,
+		`{"file_path": "/home/user/project/main.go", "old_string": "oldName", "new_string": "newName", "replace_all": true}`,
+	},
+	"MultiEdit": {
+		`{"file_path": "/home/user/project/main.go", "edits": [{"old_string": "foo", "new_string": "bar"}, {"old_string": "baz", "new_string": "qux", "replace_all": true}]}`,
+	},
+	"LS": {
+		`{"path": "/home/user/project"}`,
+		`{"path": "/home/user/project", "ignore": ["*.log"], "sort_by": "mtime"}`,
+		`{"paths": ["/home/user/project/cmd", "/home/user/project/internal", "/home/user/project/docs"]}`,
+	},
+	"Glob": {
+		`{"pattern": "**/*.go"}`,
+		`{"pattern": "*.md", "path": "/home/user/project/docs"}`,
+	},
+	"Grep": {
+		`{"pattern": "func main", "include": "*.go"}`,
+		`{"pattern": "TODO|FIXME", "path": "/home/user/project/internal"}`,
+		`{"pattern": "a.b.c", "fixed_strings": true, "whole_word": true}`,
+		`{"pattern": "func \\w+Handler", "include": "*.go", "output_format": "ndjson"}`,
+	},
+	"Stat": {
+		`{"path": "/home/user/project/main.go"}`,
+	},
+	"ReadJSON": {
+		`{"path": "/home/user/project/package.json", "query": "scripts.test"}`,
+		`{"path": "/home/user/project/tsconfig.json", "query": "compilerOptions.paths[\"@/*\"][0]"}`,
+	},
+	"Symlink": {
+		`{"target": "config/production.yaml", "link_path": "/home/user/project/config.yaml"}`,
+	},
+	"ReadLink": {
+		`{"path": "/home/user/project/config.yaml"}`,
+	},
+	"GitStatus": {
+		`{}`,
+		`{"path": "/home/user/project/services/api"}`,
+	},
+	"GitDiff": {
+		`{}`,
+		`{"staged": true}`,
+		`{"file_path": "/home/user/project/main.go"}`,
+	},
+	"ValidateFile": {
+		`{"file_path": "/home/user/project/config.yaml"}`,
+	},
+	"FormatFile": {
+		`{"file_path": "/home/user/project/package.json"}`,
+	},
+	"ReplaceInFiles": {
+		`{"pattern": "oldName", "replacement": "newName", "path_glob": "**/*.go", "dry_run": true}`,
+	},
+	"ApplyPatch": {
+		`{"patch": "--- a/main.go\n+++ b/main.go\n@@ -1 +1 @@\n-package old\n+package main\n", "path": "/home/user/project"}`,
+	},
+	"TempFile": {
+		`{}`,
+		`{"prefix": "report-", "suffix": ".json"}`,
+	},
+	"TempDir": {
+		`{"prefix": "build-"}`,
+	},
+	"WatchFile": {
+		`{"path": "/home/user/project/server.log", "timeout_ms": 5000}`,
+	},
+	"Bash": {
+		`{"command": "go test ./...", "description": "Run the tests"}`,
+		`{"command": "make build", "timeout": 300000, "output_format": "json"}`,
+		`{"command": "npm run dev", "run_in_background": true}`,
+	},
+	"BashHistory": {
+		`{"limit": 10}`,
+	},
+	"BashEnv": {
+		`{}`,
+		`{"session": "default"}`,
+	},
+	"BashJobs": {
+		`{}`,
+		`{"clear_finished": true}`,
+	},
+	"WebFetch": {
+		`{"url": "https://go.dev/doc/", "prompt": "List the main sections of this page"}`,
+	},
+	"WebSearch": {
+		`{"query": "Go 1.24 release notes"}`,
+		`{"query": "context cancellation", "allowed_domains": ["go.dev"], "output_format": "json"}`,
+	},
+	"NotebookRead": {
+		`{"notebook_path": "/home/user/project/analysis.ipynb"}`,
+		`{"notebook_path": "/home/user/project/analysis.ipynb", "omit_outputs": true}`,
+	},
+	"NotebookEdit": {
+		`{"notebook_path": "/home/user/project/analysis.ipynb", "cell_id": "cell-1", "new_source": "print(df.head())"}`,
+	},
+	"TodoRead": {
+		`{}`,
+	},
+	"TodoWrite": {
+		`{"todos": [{"id": "1", "content": "Write the tests", "status": "in_progress", "priority": "high"}]}`,
+	},
+	"ListExecutions": {
+		`{}`,
+	},
+	"CancelExecution": {
+		`{"id": "exec-1"}`,
+	},
+}
@@ -0,0 +1,192 @@
+// Package prompts provides the descriptions of the tools that extend the built-in tool set.
+package prompts
+
+// Documentation for tools that extend Claude Code's built-in tool set.
+// These tools have no upstream counterpart, so their descriptions live here
+// rather than in the generated tools/*.md files.
+
+// ListExecutionsToolDoc describes the ListExecutions tool.
+const ListExecutionsToolDoc = `Lists tool calls that are currently running on this server.
+
+Usage:
+- Returns a JSON array with the execution id, tool name, session id, and start time of each in-flight tool call
+- Use the returned id with the CancelExecution tool to stop a long-running call
+- Calls made through ListExecutions and CancelExecution are not tracked`
+
+// CancelExecutionToolDoc describes the CancelExecution tool.
+const CancelExecutionToolDoc = `Cancels an in-flight tool call by its execution id.
+
+Usage:
+- The id parameter must be an execution id returned by ListExecutions
+- Cancellation is delivered through the call's context; tools that run external commands terminate them
+- Returns an error if no running execution has the given id`
+
+// WatchFileToolDoc describes the WatchFile tool.
+const WatchFileToolDoc = `Waits for new lines to be appended to a file, like tail -f, and returns them.
+
+Usage:
+- The path parameter must be an absolute path to an existing file
+- Only lines written after the call starts are returned
+- Returns once max_lines lines have arrived (default 100, maximum 2000) or timeout_ms elapses (default 5000, maximum 60000)
+- An incomplete final line is included when the timeout elapses
+- If the file is truncated or replaced by log rotation, reading restarts from the beginning of the new content and the output notes it
+- Useful for watching logs of a process started with the Bash tool`
+
+// StatToolDoc describes the Stat tool.
+const StatToolDoc = `Returns metadata for a file or directory without reading its content.
+
+Usage:
+- The path parameter must be an absolute path
+- Returns a JSON object with the path, size in bytes, permission mode, modification time, and whether the path is a directory
+- For a symbolic link, the metadata describes the link target and symlink_target holds the link destination; a broken link is reported with the link's own metadata
+- Returns an error if the path does not exist, so it can be used to check for existence and type before reading or writing`
+
+// ReadJSONToolDoc describes the ReadJSON tool.
+const ReadJSONToolDoc = `Reads a single value from a JSON file, so that large files do not have to be read whole.
+
+Usage:
+- The path parameter must be an absolute path to a JSON file
+- The query parameter selects the value with keys separated by dots and array indexes in brackets, e.g. "a.b[0].c"
+- Keys containing dots or brackets are quoted in brackets, e.g. servers["api.internal"].port
+- An empty query, "." or "$" returns the whole document
+- Returns the selected value pretty-printed as JSON, with object keys in file order
+- When the path does not exist, the error says which part of it was found and why the next step failed, e.g. an unknown key or an index out of range`
+
+// SymlinkToolDoc describes the Symlink tool.
+const SymlinkToolDoc = `Creates a symbolic link at link_path pointing to target.
+
+Usage:
+- The link_path parameter must be an absolute path that does not exist yet, in an existing directory
+- The target may be absolute or relative; a relative target is stored as given and resolved from the directory of the link
+- The target must be a path the tools are allowed to write, so a link cannot be used to reach blocked, disallowed or read-only directories
+- The target does not have to exist
+- Use ReadLink to check where an existing link points`
+
+// ReadLinkToolDoc describes the ReadLink tool.
+const ReadLinkToolDoc = `Returns the target of an existing symbolic link, exactly as stored in the link.
+
+Usage:
+- The path parameter must be an absolute path to a symbolic link
+- A relative target is returned as is; it is relative to the directory of the link
+- Returns an error if the path does not exist or is not a symbolic link
+- Use Stat to get the metadata of the file the link points to`
+
+// GitStatusToolDoc describes the GitStatus tool.
+const GitStatusToolDoc = `Reports the branch and the changed files of a git repository without running arbitrary commands.
+
+Usage:
+- The path parameter is optional; it is an absolute path to a directory in the repository and defaults to the server's working directory
+- Returns a JSON object with the repository root, the branch, its upstream with the number of commits ahead and behind, and the changed files
+- Each file has git's one-letter status codes for the staged (index) and unstaged (worktree) change, with "." for no change and "?" for untracked files; renamed files also have orig_path and unmerged files have conflict set
+- Repository hooks, pagers, filter drivers and other configured programs are not run, and the system and global git configuration is ignored
+- Use GitDiff to see the changes themselves`
+
+// GitDiffToolDoc describes the GitDiff tool.
+const GitDiffToolDoc = `Returns the uncommitted changes of a git repository as a unified diff without running arbitrary commands.
+
+Usage:
+- The path parameter is optional; it is an absolute path to a directory in the repository and defaults to the server's working directory
+- By default the diff shows unstaged changes; set staged to true for the changes staged for the next commit
+- Set file_path to an absolute path to limit the diff to one file or directory
+- Untracked files are not included; use GitStatus to list them and Read to view them
+- Diffs larger than 256 KiB are truncated
+- External diff, textconv and filter drivers configured in the repository are not run, and the system and global git configuration is ignored`
+
+// ValidateFileToolDoc describes the ValidateFile tool.
+const ValidateFileToolDoc = `Checks that a JSON, YAML or TOML file is syntactically valid without modifying it.
+
+Usage:
+- The file_path parameter must be an absolute path to an existing file
+- The format is chosen from the extension: .json, .yaml, .yml or .toml
+- Returns a JSON object with the path, the format, and whether the file is valid
+- For an invalid file, error holds the parser message and, when the parser reports them, the line and column of the problem
+- Use this after editing a configuration file to confirm it still parses`
+
+// FormatFileToolDoc describes the FormatFile tool.
+const FormatFileToolDoc = `Rewrites a JSON, YAML or TOML file pretty-printed.
+
+Usage:
+- The file_path parameter must be an absolute path to an existing file
+- The format is chosen from the extension: .json, .yaml, .yml or .toml
+- JSON is indented with two spaces and YAML with two spaces, both keeping their key order; YAML comments are kept
+- TOML is rewritten with sorted keys and loses its comments, so it is refused unless allow_lossy is true; prefer ValidateFile for hand-written TOML
+- An invalid file is not modified; the result reports the syntax error like ValidateFile
+- The file is replaced atomically, and formatted is false when it was already formatted`
+
+// TempFileToolDoc describes the TempFile tool.
+const TempFileToolDoc = `Creates an empty scratch file in the server's temporary workspace and returns its absolute path.
+
+Usage:
+- Use this instead of guessing a location such as /tmp, which may not be writable or allowed
+- The optional prefix and suffix parameters are added around a random part of the file name, e.g. suffix ".json"; they must not contain path separators
+- The returned path can be used with Write, Edit, Read and Bash
+- Files created this way are removed when the server stops, so do not keep results there that the user needs`
+
+// TempDirToolDoc describes the TempDir tool.
+const TempDirToolDoc = `Creates an empty scratch directory in the server's temporary workspace and returns its absolute path.
+
+Usage:
+- Use this instead of guessing a location such as /tmp, which may not be writable or allowed
+- The optional prefix parameter starts the directory name and must not contain path separators
+- The returned path can be used as a working directory for Bash or as a parent for files created with Write
+- Directories created this way are removed with their content when the server stops`
+
+// ReadManyToolDoc describes the ReadMany tool.
+const ReadManyToolDoc = `Reads several files in a single call.
+
+Usage:
+- The paths parameter is a list of absolute file paths (at most 50)
+- Files are read concurrently and returned in the order given, each in a section headed by "==> path <=="
+- Each file is read like the Read tool: line numbers in cat -n format, up to limit lines (default 2000) from the start of the file
+- A path that is invalid, not allowed, or unreadable produces an error in its own section; the other files are still returned
+- Prefer this over several Read calls when you already know which files you need`
+
+// ReplaceInFilesToolDoc describes the ReplaceInFiles tool.
+const ReplaceInFilesToolDoc = `Replaces every match of a regular expression across the files of a directory tree.
+
+Usage:
+- The pattern parameter is a regular expression (Go RE2 syntax); replacement may refer to capture groups as $1 or ${name}
+- The path parameter is the directory to search (defaults to the current working directory); path_glob limits the files, e.g. "*.go" or "*.{ts,tsx}"
+- Files are found like the Grep tool: ignored, hidden-by-default VCS and binary files are skipped
+- Set dry_run to true to see the changed lines of each file without writing anything; review the dry run before applying large renames
+- Each file is written atomically; if any write fails, files already changed are restored
+- Returns the files changed and the number of replacements in each`
+
+// ApplyPatchToolDoc describes the ApplyPatch tool.
+const ApplyPatchToolDoc = `Applies a unified diff, such as the output of "git diff" or "diff -u", to one or more files.
+
+Usage:
+- The patch parameter is the unified diff text; it may change several files
+- Relative paths in the patch are resolved against the path parameter (defaults to the current working directory); git-style a/ and b/ prefixes are removed
+- Files are created when the old path is /dev/null and deleted when the new path is /dev/null; renames are not supported
+- Each hunk must match the file exactly, although it may have moved from the line numbers in its header
+- The patch is applied atomically: if any hunk does not apply, no file is changed, and if a write fails the files already changed are restored
+- Returns the files changed, each marked A (added), M (modified) or D (deleted)`
+
+// BashHistoryToolDoc describes the BashHistory tool.
+const BashHistoryToolDoc = `Lists the most recent commands run by the Bash tool in the current persistent session.
+
+Usage:
+- Returns a JSON array ordered oldest first, with each command's exit code, duration in milliseconds, and start time
+- The optional limit parameter returns only the last limit commands
+- Commands that could not complete, such as timeouts, have exit_code -1 and an error message
+- The history is bounded; the oldest commands are dropped once the configured size is reached`
+
+// BashJobsToolDoc describes the BashJobs tool.
+const BashJobsToolDoc = `Lists the background jobs started by the Bash tool with run_in_background from this client.
+
+Usage:
+- Returns a JSON array ordered by start, with each job's id, command, status (running, completed or failed), start time and runtime in milliseconds
+- Finished jobs also report their exit code and output; a job that timed out has exit_code -1 and an error message
+- Background jobs start in the session's working directory and environment, but their cd and export commands do not change the session
+- Set clear_finished to true to remove the finished jobs after reporting them, releasing their output; running jobs are kept
+- At most 16 jobs run at once, and starting another fails until one finishes; only the 50 most recent finished jobs are kept`
+
+// BashEnvToolDoc describes the BashEnv tool.
+const BashEnvToolDoc = `Shows the working directory and environment variables of a persistent Bash session without running a command.
+
+Usage:
+- Returns a JSON object with the session name, working_directory and an environment map
+- The optional session parameter names the session; it defaults to the session the Bash tool uses
+- The session exists only after the Bash tool has run a command in it
+- Values of variables that look like secrets, such as tokens, passwords and API keys, are replaced with [REDACTED]`
@@ -0,0 +1,48 @@
+// Package security provides typed errors for security validation failures.
+package security
+
+import (
+	stderrors "errors"
+
+	"github.com/d-kuro/claude-code-mcp/internal/errors"
+)
+
+// Sentinel errors returned by DefaultValidator. Callers can match them with
+// errors.Is; the error text is unchanged from the untyped errors.
+var (
+	ErrPathNotAbsolute   = stderrors.New("path must be absolute")
+	ErrPathBlocked       = stderrors.New("path is blocked")
+	ErrPathNotAllowed    = stderrors.New("path not allowed")
+	ErrPathNotWritable   = stderrors.New("path not writable")
+	ErrCommandBlocked    = stderrors.New("command is blocked")
+	ErrCommandNotAllowed = stderrors.New("command not allowed")
+	ErrInvalidURLScheme  = stderrors.New("invalid URL scheme")
+	ErrLocalhostDenied   = stderrors.New("localhost access denied")
+	ErrPrivateAddress    = stderrors.New("private address access denied")
+)
+
+// validationError pairs a formatted error with the sentinel it represents.
+type validationError struct {
+	sentinel error
+	err      error
+}
+
+// Error returns the message of the formatted error.
+func (e *validationError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap exposes the sentinel along with errors.ErrSecurity so both match errors.Is.
+func (e *validationError) Unwrap() []error {
+	return []error{e.sentinel, errors.ErrSecurity}
+}
+
+// securityError builds a security error for sentinel, using the sentinel's text
+// as the message with optional details.
+func securityError(sentinel error, details string) error {
+	err := errors.Security(sentinel.Error())
+	if details != "" {
+		err = errors.SecurityWithDetails(sentinel.Error(), details)
+	}
+	return &validationError{sentinel: sentinel, err: err}
+}
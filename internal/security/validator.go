@@ -2,16 +2,24 @@
 package security
 
 import (
+	"net"
 	"net/url"
+	"os"
 	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
 	"strings"
 
+	"golang.org/x/text/unicode/norm"
+
 	"github.com/d-kuro/claude-code-mcp/internal/errors"
 )
 
 // Validator defines the security validation interface.
 type Validator interface {
 	ValidatePath(path string) error
+	ValidateWritePath(path string) error
 	ValidateCommand(cmd string, args []string) error
 	ValidateURL(urlStr string) error
 	SanitizePath(path string) (string, error)
@@ -21,8 +29,53 @@ type Validator interface {
 type DefaultValidator struct {
 	allowedPaths    []string
 	blockedPaths    []string
+	writablePaths   []string
 	allowedCommands []string
 	blockedCommands []string
+	strict          bool
+
+	blockNetwork    bool
+	networkCommands []string
+
+	allowedURLSchemes []string
+
+	caseInsensitivePaths bool
+	normalizeUnicode     bool
+	expandPaths          bool
+	pathVariables        []string
+}
+
+// DefaultAllowedURLSchemes are the URL schemes accepted by ValidateURL unless
+// set with WithAllowedURLSchemes.
+var DefaultAllowedURLSchemes = []string{"http", "https"}
+
+// DefaultNetworkCommands are the commands treated as network access when
+// network commands are blocked.
+var DefaultNetworkCommands = []string{
+	"curl",
+	"wget",
+	"nc",
+	"ncat",
+	"netcat",
+	"socat",
+	"telnet",
+	"ssh",
+	"scp",
+	"sftp",
+	"ftp",
+	"rsync",
+}
+
+// commandWrappers run the command that follows them, so the wrapped command
+// is checked instead.
+var commandWrappers = map[string]bool{
+	"sudo":    true,
+	"env":     true,
+	"exec":    true,
+	"command": true,
+	"nohup":   true,
+	"time":    true,
+	"xargs":   true,
 }
 
 // NewDefaultValidator creates a new default validator with secure defaults.
@@ -52,9 +105,18 @@ func NewDefaultValidator() *DefaultValidator {
 			"mount",
 			"umount",
 		},
+		allowedURLSchemes:    DefaultAllowedURLSchemes,
+		caseInsensitivePaths: DefaultCaseInsensitivePaths(),
 	}
 }
 
+// DefaultCaseInsensitivePaths reports whether paths are compared without
+// regard to case by default, which is the case on macOS and Windows whose
+// filesystems are case-insensitive by default.
+func DefaultCaseInsensitivePaths() bool {
+	return runtime.GOOS == "darwin" || runtime.GOOS == "windows"
+}
+
 // WithAllowedPaths sets the allowed paths for file operations.
 func (v *DefaultValidator) WithAllowedPaths(paths []string) *DefaultValidator {
 	v.allowedPaths = make([]string, len(paths))
@@ -68,6 +130,14 @@ func (v *DefaultValidator) WithBlockedPaths(paths []string) *DefaultValidator {
 	return v
 }
 
+// WithWritablePaths sets the paths that may be modified by write operations.
+// When empty, writes follow the same rules as reads.
+func (v *DefaultValidator) WithWritablePaths(paths []string) *DefaultValidator {
+	v.writablePaths = make([]string, len(paths))
+	copy(v.writablePaths, paths)
+	return v
+}
+
 // WithAllowedCommands sets the allowed commands for execution.
 func (v *DefaultValidator) WithAllowedCommands(commands []string) *DefaultValidator {
 	v.allowedCommands = make([]string, len(commands))
@@ -81,46 +151,283 @@ func (v *DefaultValidator) WithBlockedCommands(commands []string) *DefaultValida
 	return v
 }
 
+// WithBlockNetworkCommands rejects commands that run a network command
+// anywhere in a pipeline or command list, such as "cat secrets | curl -d @- ...".
+// The commands are DefaultNetworkCommands unless set with WithNetworkCommands.
+func (v *DefaultValidator) WithBlockNetworkCommands(block bool) *DefaultValidator {
+	v.blockNetwork = block
+	return v
+}
+
+// WithAllowedURLSchemes replaces the URL schemes accepted by ValidateURL.
+// Schemes such as "javascript", "data" and "file" stay rejected unless listed.
+func (v *DefaultValidator) WithAllowedURLSchemes(schemes []string) *DefaultValidator {
+	v.allowedURLSchemes = make([]string, len(schemes))
+	for i, scheme := range schemes {
+		v.allowedURLSchemes[i] = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(scheme), ":"))
+	}
+	return v
+}
+
+// WithCaseInsensitivePaths makes path checks compare paths with the allowed,
+// blocked and writable path lists without regard to case, so "/BLOCKED"
+// matches a "/blocked" rule on a case-insensitive filesystem. The default
+// is DefaultCaseInsensitivePaths.
+func (v *DefaultValidator) WithCaseInsensitivePaths(insensitive bool) *DefaultValidator {
+	v.caseInsensitivePaths = insensitive
+	return v
+}
+
+// WithUnicodeNormalization converts paths to Unicode normalization form C
+// (NFC) before they are compared with the allowed, blocked and writable path
+// lists, which are normalized the same way. A blocked path can then not be
+// re-expressed with combining characters, such as "cafe\u0301" for "café".
+// SanitizePath returns the normalized path. Disabled by default.
+func (v *DefaultValidator) WithUnicodeNormalization(enabled bool) *DefaultValidator {
+	v.normalizeUnicode = enabled
+	return v
+}
+
+// WithPathExpansion makes SanitizePath expand a leading "~", $HOME or
+// ${HOME} to the home directory, and a leading reference to a variable listed
+// with WithPathVariables to its value, before the path is validated, so
+// "~/notes.txt" is checked as an absolute path. Other "$" characters are kept
+// as they are. A listed variable that is unset is rejected. Disabled by default.
+func (v *DefaultValidator) WithPathExpansion(enabled bool) *DefaultValidator {
+	v.expandPaths = enabled
+	return v
+}
+
+// WithPathVariables sets the environment variables, besides HOME, that a
+// path may start with when path expansion is enabled. Only these are read,
+// so a path cannot be used to reveal the value of any other variable.
+func (v *DefaultValidator) WithPathVariables(names []string) *DefaultValidator {
+	v.pathVariables = make([]string, len(names))
+	copy(v.pathVariables, names)
+	return v
+}
+
+// WithNetworkCommands replaces the commands blocked by WithBlockNetworkCommands.
+// Entries may be glob patterns like the blocked commands list.
+func (v *DefaultValidator) WithNetworkCommands(commands []string) *DefaultValidator {
+	v.networkCommands = make([]string, len(commands))
+	copy(v.networkCommands, commands)
+	return v
+}
+
+// WithStrictMode switches the validator to deny by default: with strict mode
+// enabled, an empty allowed paths or allowed commands list rejects every path
+// or command instead of permitting all of them. Writes with no writable paths
+// configured follow the read rules and are therefore denied as well.
+// Blocked lists still apply on top of the allow lists.
+func (v *DefaultValidator) WithStrictMode() *DefaultValidator {
+	v.strict = true
+	return v
+}
+
+// AllowedPaths returns a copy of the directories paths must be under.
+func (v *DefaultValidator) AllowedPaths() []string {
+	return cloneList(v.allowedPaths)
+}
+
+// BlockedPaths returns a copy of the directories that are never accessible.
+func (v *DefaultValidator) BlockedPaths() []string {
+	return cloneList(v.blockedPaths)
+}
+
+// WritablePaths returns a copy of the directories writes are limited to.
+func (v *DefaultValidator) WritablePaths() []string {
+	return cloneList(v.writablePaths)
+}
+
+// AllowedCommands returns a copy of the commands Bash is limited to.
+func (v *DefaultValidator) AllowedCommands() []string {
+	return cloneList(v.allowedCommands)
+}
+
+// BlockedCommands returns a copy of the commands Bash rejects.
+func (v *DefaultValidator) BlockedCommands() []string {
+	return cloneList(v.blockedCommands)
+}
+
+// NetworkCommands returns a copy of the commands treated as network access,
+// which defaults to DefaultNetworkCommands.
+func (v *DefaultValidator) NetworkCommands() []string {
+	if v.networkCommands == nil {
+		return cloneList(DefaultNetworkCommands)
+	}
+	return cloneList(v.networkCommands)
+}
+
+// AllowedURLSchemes returns a copy of the URL schemes ValidateURL accepts.
+func (v *DefaultValidator) AllowedURLSchemes() []string {
+	return cloneList(v.allowedURLSchemes)
+}
+
+// Status is a snapshot of the rules a DefaultValidator enforces. Field names
+// follow the server configuration file.
+type Status struct {
+	AllowedPaths         []string `json:"allowed_paths"`
+	BlockedPaths         []string `json:"blocked_paths"`
+	WritablePaths        []string `json:"writable_paths"`
+	AllowedCommands      []string `json:"allowed_commands"`
+	BlockedCommands      []string `json:"blocked_commands"`
+	Strict               bool     `json:"strict"`
+	BlockNetworkCommands bool     `json:"block_network_commands"`
+	NetworkCommands      []string `json:"network_commands"`
+	AllowedURLSchemes    []string `json:"allowed_url_schemes"`
+	CaseInsensitivePaths bool     `json:"case_insensitive_paths"`
+	UnicodeNormalization bool     `json:"unicode_normalization"`
+	ExpandPaths          bool     `json:"expand_paths"`
+	PathVariables        []string `json:"path_variables"`
+}
+
+// Status returns the rules the validator enforces, with defaults applied.
+// The returned lists are copies.
+func (v *DefaultValidator) Status() Status {
+	return Status{
+		AllowedPaths:         v.AllowedPaths(),
+		BlockedPaths:         v.BlockedPaths(),
+		WritablePaths:        v.WritablePaths(),
+		AllowedCommands:      v.AllowedCommands(),
+		BlockedCommands:      v.BlockedCommands(),
+		Strict:               v.strict,
+		BlockNetworkCommands: v.blockNetwork,
+		NetworkCommands:      v.NetworkCommands(),
+		AllowedURLSchemes:    v.AllowedURLSchemes(),
+		CaseInsensitivePaths: v.caseInsensitivePaths,
+		UnicodeNormalization: v.normalizeUnicode,
+		ExpandPaths:          v.expandPaths,
+		PathVariables:        cloneList(v.pathVariables),
+	}
+}
+
+// cloneList copies list, returning an empty list rather than nil so that
+// unset lists are reported as empty.
+func cloneList(list []string) []string {
+	return append([]string{}, list...)
+}
+
 // ValidatePath validates and checks if a file path is allowed.
 func (v *DefaultValidator) ValidatePath(path string) error {
 	if !filepath.IsAbs(path) {
-		return errors.Security("path must be absolute")
+		return securityError(ErrPathNotAbsolute, "")
 	}
 
-	cleanPath := filepath.Clean(path)
-	resolvedPath, err := filepath.EvalSymlinks(cleanPath)
-	if err != nil {
-		resolvedPath = cleanPath
-	}
+	resolvedPath := resolvePath(path)
 
 	for _, blocked := range v.blockedPaths {
-		if strings.HasPrefix(resolvedPath, blocked) {
-			return errors.SecurityWithDetails(
-				"path is blocked",
-				"path accesses restricted system directory",
-			)
+		if v.hasPathPrefix(resolvedPath, blocked) {
+			return securityError(ErrPathBlocked, "path accesses restricted system directory")
 		}
 	}
 
+	if len(v.allowedPaths) == 0 && v.strict {
+		return securityError(ErrPathNotAllowed, "no allowed directories are configured in strict mode")
+	}
+
 	if len(v.allowedPaths) > 0 {
 		allowed := false
 		for _, allowedPath := range v.allowedPaths {
-			if strings.HasPrefix(resolvedPath, allowedPath) {
+			if v.hasPathPrefix(resolvedPath, allowedPath) {
 				allowed = true
 				break
 			}
 		}
 		if !allowed {
-			return errors.SecurityWithDetails(
-				"path not allowed",
-				"path is not in allowed directories",
-			)
+			return securityError(ErrPathNotAllowed, "path is not in allowed directories")
 		}
 	}
 
 	return nil
 }
 
+// maxLinkHops bounds the symbolic links resolvePath follows, like the
+// kernel's limit on nested links.
+const maxLinkHops = 40
+
+// resolvePath returns path with symbolic links resolved. For a path that does
+// not exist yet, the nearest existing parent is resolved and the remaining
+// elements are appended, so a file created through a linked directory or a
+// dangling link is checked where it will actually be written.
+func resolvePath(path string) string {
+	cleanPath := filepath.Clean(path)
+	for hops := 0; hops < maxLinkHops; hops++ {
+		dir, rest := cleanPath, ""
+		for {
+			if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+				return filepath.Join(resolved, rest)
+			}
+			if info, err := os.Lstat(dir); err == nil && info.Mode()&os.ModeSymlink != 0 {
+				// A dangling link: continue from where it points
+				target, err := os.Readlink(dir)
+				if err != nil {
+					return cleanPath
+				}
+				if !filepath.IsAbs(target) {
+					target = filepath.Join(filepath.Dir(dir), target)
+				}
+				cleanPath = filepath.Join(target, rest)
+				break
+			}
+			parent := filepath.Dir(dir)
+			if parent == dir {
+				return cleanPath
+			}
+			rest = filepath.Join(filepath.Base(dir), rest)
+			dir = parent
+		}
+	}
+	return cleanPath
+}
+
+// hasPathPrefix reports whether path is prefix or inside it, comparing
+// whole path elements so that /proj/out does not contain /proj/outside. Case
+// is ignored when case-insensitive paths are enabled, and paths are compared
+// after Unicode normalization when it is enabled.
+func (v *DefaultValidator) hasPathPrefix(path, prefix string) bool {
+	path, prefix = v.normalizePath(path), filepath.Clean(v.normalizePath(prefix))
+	if v.caseInsensitivePaths {
+		path, prefix = strings.ToLower(path), strings.ToLower(prefix)
+	}
+	if strings.HasSuffix(prefix, string(filepath.Separator)) {
+		// The root directory contains every absolute path
+		return strings.HasPrefix(path, prefix)
+	}
+	return path == prefix || strings.HasPrefix(path, prefix+string(filepath.Separator))
+}
+
+// normalizePath applies Unicode normalization to path when it is enabled.
+func (v *DefaultValidator) normalizePath(path string) string {
+	if v.normalizeUnicode {
+		return norm.NFC.String(path)
+	}
+	return path
+}
+
+// ValidateWritePath validates that a file path may be modified.
+// The path must pass ValidatePath and, when writable paths are configured,
+// must also be inside one of them.
+func (v *DefaultValidator) ValidateWritePath(path string) error {
+	if err := v.ValidatePath(path); err != nil {
+		return err
+	}
+
+	if len(v.writablePaths) == 0 {
+		return nil
+	}
+
+	resolvedPath := resolvePath(path)
+
+	for _, writablePath := range v.writablePaths {
+		if v.hasPathPrefix(resolvedPath, writablePath) {
+			return nil
+		}
+	}
+
+	return securityError(ErrPathNotWritable, "path is not in writable directories")
+}
+
 // ValidateCommand validates if a command is allowed to be executed.
 func (v *DefaultValidator) ValidateCommand(cmd string, args []string) error {
 	if cmd == "" {
@@ -136,13 +443,20 @@ func (v *DefaultValidator) ValidateCommand(cmd string, args []string) error {
 
 	for _, blocked := range v.blockedCommands {
 		if matched, _ := filepath.Match(blocked, baseName); matched {
-			return errors.SecurityWithDetails(
-				"command is blocked",
-				"command is in the blocked list for security",
-			)
+			return securityError(ErrCommandBlocked, "command is in the blocked list for security")
 		}
 	}
 
+	if v.blockNetwork {
+		if name := v.findNetworkCommand(cmd); name != "" {
+			return securityError(ErrCommandBlocked, "network command "+name+" is blocked")
+		}
+	}
+
+	if len(v.allowedCommands) == 0 && v.strict {
+		return securityError(ErrCommandNotAllowed, "no allowed commands are configured in strict mode")
+	}
+
 	if len(v.allowedCommands) > 0 {
 		allowed := false
 		for _, allowedCmd := range v.allowedCommands {
@@ -152,16 +466,45 @@ func (v *DefaultValidator) ValidateCommand(cmd string, args []string) error {
 			}
 		}
 		if !allowed {
-			return errors.SecurityWithDetails(
-				"command not allowed",
-				"command is not in the allowed list",
-			)
+			return securityError(ErrCommandNotAllowed, "command is not in the allowed list")
 		}
 	}
 
 	return nil
 }
 
+// findNetworkCommand returns the first network command run by cmd, or "" if
+// there is none. Every command of a pipeline or list is checked, skipping
+// leading variable assignments and wrappers such as sudo and xargs.
+func (v *DefaultValidator) findNetworkCommand(cmd string) string {
+	networkCommands := v.networkCommands
+	if networkCommands == nil {
+		networkCommands = DefaultNetworkCommands
+	}
+
+	segments := strings.FieldsFunc(cmd, func(r rune) bool {
+		return strings.ContainsRune("|;&\n()`{}", r)
+	})
+	for _, segment := range segments {
+		for _, word := range strings.Fields(segment) {
+			if strings.Contains(word, "=") && !strings.HasPrefix(word, "-") {
+				continue
+			}
+			name := filepath.Base(strings.Trim(word, `"'`))
+			if commandWrappers[name] || strings.HasPrefix(word, "-") {
+				continue
+			}
+			for _, network := range networkCommands {
+				if matched, _ := filepath.Match(network, name); matched {
+					return name
+				}
+			}
+			break
+		}
+	}
+	return ""
+}
+
 // ValidateURL validates if a URL is safe to access.
 func (v *DefaultValidator) ValidateURL(urlStr string) error {
 	if urlStr == "" {
@@ -176,31 +519,70 @@ func (v *DefaultValidator) ValidateURL(urlStr string) error {
 		)
 	}
 
-	if parsedURL.Scheme != "http" && parsedURL.Scheme != "https" {
-		return errors.SecurityWithDetails(
-			"invalid URL scheme",
-			"only HTTP and HTTPS are allowed",
-		)
+	scheme := strings.ToLower(parsedURL.Scheme)
+	if !slices.Contains(v.allowedURLSchemes, scheme) {
+		return securityError(ErrInvalidURLScheme, "allowed schemes are "+strings.Join(v.allowedURLSchemes, ", "))
 	}
 
-	if parsedURL.Host == "" {
+	// Other explicitly allowed schemes, such as data or mailto, may have no host
+	if parsedURL.Host == "" && (scheme == "http" || scheme == "https") {
 		return errors.Validation("URL must have a host")
 	}
 
 	if strings.Contains(parsedURL.Host, "localhost") ||
 		strings.Contains(parsedURL.Host, "127.0.0.1") ||
 		strings.Contains(parsedURL.Host, "::1") {
-		return errors.SecurityWithDetails(
-			"localhost access denied",
-			"access to local services is not allowed",
-		)
+		return securityError(ErrLocalhostDenied, "access to local services is not allowed")
 	}
 
+	return checkURLHostAddress(parsedURL.Hostname())
+}
+
+// checkURLHostAddress rejects a URL host that is an IP address of the local
+// machine, a private network or a link-local network, such as the cloud
+// metadata address 169.254.169.254. Numeric hosts that are not a plain IP
+// address, like "2130706433" or "0x7f.1", are rejected too, since resolvers
+// may read them as one. Host names are not resolved here.
+func checkURLHostAddress(host string) error {
+	ip := net.ParseIP(host)
+	if ip == nil {
+		if isNumericHost(host) {
+			return securityError(ErrPrivateAddress, "numeric host "+host+" is not a valid IP address")
+		}
+		return nil
+	}
+	if ip.IsLoopback() || ip.IsUnspecified() {
+		return securityError(ErrLocalhostDenied, "access to local services is not allowed")
+	}
+	if ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() {
+		return securityError(ErrPrivateAddress, "access to private and link-local networks is not allowed")
+	}
 	return nil
 }
 
-// SanitizePath cleans and validates a file path.
+// isNumericHost reports whether every dot-separated part of host is a
+// decimal, octal or hexadecimal number.
+func isNumericHost(host string) bool {
+	for _, part := range strings.Split(host, ".") {
+		if _, err := strconv.ParseUint(part, 0, 64); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// SanitizePath cleans and validates a file path. When path expansion is
+// enabled, the expanded path is validated and returned.
 func (v *DefaultValidator) SanitizePath(path string) (string, error) {
+	if v.expandPaths {
+		expanded, err := v.expandPath(path)
+		if err != nil {
+			return "", err
+		}
+		path = expanded
+	}
+
+	path = v.normalizePath(path)
 	if err := v.ValidatePath(path); err != nil {
 		return "", err
 	}
@@ -208,3 +590,59 @@ func (v *DefaultValidator) SanitizePath(path string) (string, error) {
 	cleanPath := filepath.Clean(path)
 	return cleanPath, nil
 }
+
+// expandPath replaces a leading "~", $HOME or ${HOME} with the home directory
+// and a leading reference to a variable listed in pathVariables with its
+// value. Other references are left unexpanded. An unset listed variable is an
+// error, so that a missing variable cannot turn "$DIR/file" into "/file".
+func (v *DefaultValidator) expandPath(path string) (string, error) {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", errors.ValidationWithDetails("path expansion failed", err.Error())
+		}
+		return home + path[1:], nil
+	}
+
+	name, rest, ok := leadingVariable(path)
+	if !ok || (name != "HOME" && !slices.Contains(v.pathVariables, name)) {
+		return path, nil
+	}
+	if name == "HOME" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", errors.ValidationWithDetails("path expansion failed", err.Error())
+		}
+		return home + rest, nil
+	}
+	value, set := os.LookupEnv(name)
+	if !set || value == "" {
+		return "", errors.ValidationWithDetails("path expansion failed", "environment variable "+name+" is not set")
+	}
+	return value + rest, nil
+}
+
+// leadingVariable splits a path that starts with $NAME or ${NAME}, followed
+// by a separator or nothing, into the variable name and the rest of the path.
+func leadingVariable(path string) (name, rest string, ok bool) {
+	switch {
+	case strings.HasPrefix(path, "${"):
+		end := strings.IndexByte(path, '}')
+		if end < 0 {
+			return "", "", false
+		}
+		name, rest = path[2:end], path[end+1:]
+	case strings.HasPrefix(path, "$"):
+		end := strings.IndexAny(path, "/"+string(filepath.Separator))
+		if end < 0 {
+			end = len(path)
+		}
+		name, rest = path[1:end], path[end:]
+	default:
+		return "", "", false
+	}
+	if name == "" || (rest != "" && !os.IsPathSeparator(rest[0])) {
+		return "", "", false
+	}
+	return name, rest, true
+}
@@ -0,0 +1,97 @@
+// Package server provides capability reporting for MCP clients.
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/d-kuro/claude-code-mcp/internal/version"
+)
+
+// methodInitialize is the MCP method name used for the initialize handshake.
+const methodInitialize = "initialize"
+
+// capabilitiesMetaKey is the initialize result metadata key holding the server capabilities.
+const capabilitiesMetaKey = "server_capabilities"
+
+// Capabilities describes the version, enabled tools, and optional features of the server.
+type Capabilities struct {
+	Version    string          `json:"version"`
+	Categories []string        `json:"categories"`
+	Tools      []string        `json:"tools"`
+	Features   map[string]bool `json:"features"`
+}
+
+// Capabilities returns the current capabilities of the server.
+func (s *Server) Capabilities() Capabilities {
+	toolNames := make([]string, 0, len(s.toolNames))
+	for _, name := range s.toolNames {
+		if !s.isToolDisabled(name) {
+			toolNames = append(toolNames, name)
+		}
+	}
+	sort.Strings(toolNames)
+
+	categorySet := make(map[string]bool)
+	for _, name := range toolNames {
+		categorySet[s.registry.ToolCategory(name)] = true
+	}
+
+	categories := make([]string, 0, len(categorySet))
+	for category := range categorySet {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+
+	return Capabilities{
+		Version:    version.GetVersion().Version,
+		Categories: categories,
+		Tools:      toolNames,
+		Features: map[string]bool{
+			"http_transport":         s.httpEnabled.Load(),
+			"execution_cancellation": true,
+			"concurrency_limits":     len(s.limiter.Limits()) > 0,
+			"read_only":              s.readOnly,
+			"output_sanitization":    s.sanitizer != nil,
+			"http_compression":       s.compress,
+		},
+	}
+}
+
+// capabilitiesMiddleware attaches the server capabilities to the initialize result metadata.
+func (s *Server) capabilitiesMiddleware(next mcp.MethodHandler[*mcp.ServerSession]) mcp.MethodHandler[*mcp.ServerSession] {
+	return func(ctx context.Context, session *mcp.ServerSession, method string, params mcp.Params) (mcp.Result, error) {
+		result, err := next(ctx, session, method, params)
+		if err != nil || method != methodInitialize {
+			return result, err
+		}
+
+		if initResult, ok := result.(*mcp.InitializeResult); ok {
+			if initResult.Meta == nil {
+				initResult.Meta = make(mcp.Meta)
+			}
+			initResult.Meta[capabilitiesMetaKey] = s.Capabilities()
+		}
+
+		return result, nil
+	}
+}
+
+// handleCapabilities serves the server capabilities as JSON over HTTP.
+func (s *Server) handleCapabilities(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(s.Capabilities()); err != nil {
+		s.logger.Error("Failed to encode capabilities", "error", err)
+	}
+}
@@ -0,0 +1,142 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/d-kuro/claude-code-mcp/internal/logging"
+)
+
+func TestCapabilitiesReflectDisabledTools(t *testing.T) {
+	srv, err := New(&Options{
+		Logger:        logging.NewLogger("error"),
+		DisabledTools: []string{"WebFetch", "WebSearch", "Bash"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	caps := srv.Capabilities()
+
+	for _, name := range []string{"WebFetch", "WebSearch", "Bash"} {
+		if slices.Contains(caps.Tools, name) {
+			t.Errorf("Expected disabled tool %s to be absent from capabilities", name)
+		}
+	}
+
+	if !slices.Contains(caps.Tools, "Read") {
+		t.Errorf("Expected Read to be listed in capabilities, got %v", caps.Tools)
+	}
+
+	if slices.Contains(caps.Categories, "web") {
+		t.Errorf("Expected web category to be absent when all web tools are disabled, got %v", caps.Categories)
+	}
+
+	if !slices.Contains(caps.Categories, "system") {
+		t.Errorf("Expected system category to remain for execution tools, got %v", caps.Categories)
+	}
+
+	if caps.Version == "" {
+		t.Errorf("Expected version to be reported")
+	}
+
+	if caps.Features["http_transport"] {
+		t.Errorf("Expected http_transport to be disabled before serving HTTP")
+	}
+}
+
+func TestCapabilitiesInInitializeResult(t *testing.T) {
+	srv, err := New(&Options{
+		Logger:        logging.NewLogger("error"),
+		DisabledTools: []string{"WebFetch"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	ctx := context.Background()
+	clientTransport, serverTransport := mcp.NewInMemoryTransports()
+
+	serverSession, err := srv.mcpServer.Connect(ctx, serverTransport)
+	if err != nil {
+		t.Fatalf("Failed to connect server: %v", err)
+	}
+	defer func() { _ = serverSession.Close() }()
+
+	var initResult *mcp.InitializeResult
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "v0.0.1"}, nil)
+	client.AddSendingMiddleware(func(next mcp.MethodHandler[*mcp.ClientSession]) mcp.MethodHandler[*mcp.ClientSession] {
+		return func(ctx context.Context, session *mcp.ClientSession, method string, params mcp.Params) (mcp.Result, error) {
+			result, err := next(ctx, session, method, params)
+			if r, ok := result.(*mcp.InitializeResult); ok {
+				initResult = r
+			}
+			return result, err
+		}
+	})
+
+	clientSession, err := client.Connect(ctx, clientTransport)
+	if err != nil {
+		t.Fatalf("Failed to connect client: %v", err)
+	}
+	defer func() { _ = clientSession.Close() }()
+
+	if initResult == nil {
+		t.Fatal("Expected to capture the initialize result")
+	}
+
+	data, err := json.Marshal(initResult.Meta[capabilitiesMetaKey])
+	if err != nil {
+		t.Fatalf("Failed to marshal capabilities metadata: %v", err)
+	}
+
+	var caps Capabilities
+	if err := json.Unmarshal(data, &caps); err != nil {
+		t.Fatalf("Failed to parse capabilities metadata: %v", err)
+	}
+
+	if slices.Contains(caps.Tools, "WebFetch") {
+		t.Errorf("Expected disabled WebFetch to be absent from initialize capabilities")
+	}
+	if !slices.Contains(caps.Tools, "WebSearch") {
+		t.Errorf("Expected WebSearch in initialize capabilities, got %v", caps.Tools)
+	}
+}
+
+func TestCapabilitiesHTTPEndpoint(t *testing.T) {
+	srv, err := New(&Options{
+		Logger:        logging.NewLogger("error"),
+		DisabledTools: []string{"Grep"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	httpServer := httptest.NewServer(srv.HTTPHandler())
+	defer httpServer.Close()
+
+	resp, err := http.Get(httpServer.URL + "/capabilities")
+	if err != nil {
+		t.Fatalf("Failed to request capabilities: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+	}
+
+	var caps Capabilities
+	if err := json.NewDecoder(resp.Body).Decode(&caps); err != nil {
+		t.Fatalf("Failed to decode capabilities: %v", err)
+	}
+
+	if slices.Contains(caps.Tools, "Grep") {
+		t.Errorf("Expected disabled Grep to be absent from HTTP capabilities")
+	}
+}
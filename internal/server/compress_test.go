@@ -0,0 +1,136 @@
+package server
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/d-kuro/claude-code-mcp/internal/logging"
+)
+
+// encodingRecorder records the Content-Encoding of the responses to
+// tools/call requests, before the transport decompresses them.
+type encodingRecorder struct {
+	base      http.RoundTripper
+	mu        sync.Mutex
+	encodings []string
+}
+
+func (r *encodingRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	toolCall := false
+	if req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			data, _ := io.ReadAll(body)
+			toolCall = strings.Contains(string(data), `"tools/call"`)
+		}
+	}
+
+	resp, err := r.base.RoundTrip(req)
+	if err == nil && toolCall {
+		encoding := resp.Header.Get("Content-Encoding")
+		if resp.Uncompressed {
+			// The transport removed the header after decompressing
+			encoding = "gzip"
+		}
+		r.mu.Lock()
+		r.encodings = append(r.encodings, encoding)
+		r.mu.Unlock()
+	}
+	return resp, err
+}
+
+// toolCallEncodings returns the Content-Encoding of each tools/call response.
+func (r *encodingRecorder) toolCallEncodings() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.encodings...)
+}
+
+func TestHTTPResponseCompression(t *testing.T) {
+	srv, err := New(&Options{
+		Logger:            logging.NewLogger("error"),
+		CompressResponses: true,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	type echoArgs struct {
+		Size int `json:"size"`
+	}
+	mcp.AddTool(srv.mcpServer, &mcp.Tool{Name: "Echo"}, func(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[echoArgs]) (*mcp.CallToolResultFor[any], error) {
+		return &mcp.CallToolResultFor[any]{
+			Content: []mcp.Content{&mcp.TextContent{Text: strings.Repeat("a", params.Arguments.Size)}},
+		}, nil
+	})
+
+	httpServer := httptest.NewServer(srv.HTTPHandler())
+	defer httpServer.Close()
+
+	tests := []struct {
+		name       string
+		gzip       bool
+		size       int
+		wantEncode string
+	}{
+		{name: "large response with gzip accepted", gzip: true, size: 64 * 1024, wantEncode: "gzip"},
+		{name: "small response with gzip accepted", gzip: true, size: 10, wantEncode: ""},
+		{name: "large response without gzip", gzip: false, size: 64 * 1024, wantEncode: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The transport asks for gzip and decompresses it unless disabled
+			recorder := &encodingRecorder{base: &http.Transport{DisableCompression: !tt.gzip}}
+			transport := mcp.NewStreamableClientTransport(httpServer.URL, &mcp.StreamableClientTransportOptions{
+				HTTPClient: &http.Client{Transport: recorder},
+			})
+
+			ctx := context.Background()
+			client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "v0.0.1"}, nil)
+			session, err := client.Connect(ctx, transport)
+			if err != nil {
+				t.Fatalf("Failed to connect client: %v", err)
+			}
+			defer func() { _ = session.Close() }()
+
+			result, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "Echo", Arguments: map[string]any{"size": tt.size}})
+			if err != nil {
+				t.Fatalf("Echo call failed: %v", err)
+			}
+			if text := resultText(result); text != strings.Repeat("a", tt.size) {
+				t.Fatalf("Expected %d bytes of output, got %d", tt.size, len(text))
+			}
+			if got := recorder.toolCallEncodings(); len(got) != 1 || got[0] != tt.wantEncode {
+				t.Errorf("Expected Content-Encoding %q, got %q", tt.wantEncode, got)
+			}
+		})
+	}
+}
+
+func TestAcceptsGzip(t *testing.T) {
+	tests := []struct {
+		header string
+		want   bool
+	}{
+		{"gzip", true},
+		{"deflate, gzip;q=0.5", true},
+		{"GZIP", true},
+		{"*", true},
+		{"gzip;q=0", false},
+		{"deflate, br", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := acceptsGzip(tt.header); got != tt.want {
+			t.Errorf("acceptsGzip(%q) = %v, want %v", tt.header, got, tt.want)
+		}
+	}
+}
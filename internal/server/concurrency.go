@@ -0,0 +1,122 @@
+// Package server provides per-category concurrency limits for tool calls.
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/d-kuro/claude-code-mcp/internal/tools"
+)
+
+// DefaultConcurrencyQueueSize is the default number of calls that may wait
+// for a slot in a limited category.
+const DefaultConcurrencyQueueSize = 16
+
+// ConcurrencyLimiter bounds the number of concurrent tool calls per category.
+// Calls beyond the limit wait in a bounded queue; once the queue is full,
+// new calls fail immediately.
+type ConcurrencyLimiter struct {
+	mu        sync.Mutex
+	slots     map[string]chan struct{}
+	waiting   map[string]int
+	queueSize int
+}
+
+// NewConcurrencyLimiter creates a limiter with the given per-category limits.
+// Categories with a limit of zero or less are unrestricted. A queue size of
+// zero or less lets no call wait for a slot.
+func NewConcurrencyLimiter(limits map[string]int, queueSize int) *ConcurrencyLimiter {
+	if queueSize < 0 {
+		queueSize = 0
+	}
+
+	l := &ConcurrencyLimiter{
+		slots:     make(map[string]chan struct{}),
+		waiting:   make(map[string]int),
+		queueSize: queueSize,
+	}
+
+	for category, limit := range limits {
+		if limit > 0 {
+			l.slots[category] = make(chan struct{}, limit)
+		}
+	}
+
+	return l
+}
+
+// Acquire reserves a slot for the given category, waiting if necessary.
+// The returned function releases the slot and must be called when the call finishes.
+func (l *ConcurrencyLimiter) Acquire(ctx context.Context, category string) (func(), error) {
+	l.mu.Lock()
+	slots, limited := l.slots[category]
+	if !limited {
+		l.mu.Unlock()
+		return func() {}, nil
+	}
+
+	select {
+	case slots <- struct{}{}:
+		l.mu.Unlock()
+		return func() { <-slots }, nil
+	default:
+	}
+
+	if l.waiting[category] >= l.queueSize {
+		l.mu.Unlock()
+		return nil, fmt.Errorf("too many concurrent %s tool calls (limit %d, queue %d)", category, cap(slots), l.queueSize)
+	}
+	l.waiting[category]++
+	l.mu.Unlock()
+
+	defer func() {
+		l.mu.Lock()
+		l.waiting[category]--
+		l.mu.Unlock()
+	}()
+
+	select {
+	case slots <- struct{}{}:
+		return func() { <-slots }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Limits returns the configured per-category limits.
+func (l *ConcurrencyLimiter) Limits() map[string]int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	limits := make(map[string]int, len(l.slots))
+	for category, slots := range l.slots {
+		limits[category] = cap(slots)
+	}
+	return limits
+}
+
+// concurrencyMiddleware applies the per-category concurrency limits to tool calls.
+func (s *Server) concurrencyMiddleware(next mcp.MethodHandler[*mcp.ServerSession]) mcp.MethodHandler[*mcp.ServerSession] {
+	return func(ctx context.Context, session *mcp.ServerSession, method string, params mcp.Params) (mcp.Result, error) {
+		if method != methodCallTool {
+			return next(ctx, session, method, params)
+		}
+
+		call, ok := params.(*mcp.CallToolParamsFor[json.RawMessage])
+		if !ok {
+			return next(ctx, session, method, params)
+		}
+
+		release, err := s.limiter.Acquire(ctx, s.registry.ToolCategory(call.Name))
+		if err != nil {
+			return tools.ErrorResponsef("%s: %v", call.Name, err), nil
+		}
+		defer release()
+
+		return next(ctx, session, method, params)
+	}
+}
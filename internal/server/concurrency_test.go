@@ -0,0 +1,139 @@
+package server
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/d-kuro/claude-code-mcp/internal/logging"
+)
+
+func TestConcurrencyLimiterQueueFull(t *testing.T) {
+	limiter := NewConcurrencyLimiter(map[string]int{"file": 1}, 1)
+	ctx := context.Background()
+
+	release, err := limiter.Acquire(ctx, "file")
+	if err != nil {
+		t.Fatalf("Expected first acquire to succeed: %v", err)
+	}
+
+	queued := make(chan error, 1)
+	go func() {
+		release, err := limiter.Acquire(ctx, "file")
+		if err == nil {
+			release()
+		}
+		queued <- err
+	}()
+
+	// Wait until the second call is waiting in the queue
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		limiter.mu.Lock()
+		waiting := limiter.waiting["file"]
+		limiter.mu.Unlock()
+		if waiting == 1 {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if _, err := limiter.Acquire(ctx, "file"); err == nil {
+		t.Errorf("Expected acquire to fail fast when the queue is full")
+	}
+
+	release()
+
+	if err := <-queued; err != nil {
+		t.Errorf("Expected queued acquire to proceed after release: %v", err)
+	}
+
+	// Unlimited categories never block
+	releaseWeb, err := limiter.Acquire(ctx, "web")
+	if err != nil {
+		t.Errorf("Expected unlimited category to succeed: %v", err)
+	}
+	releaseWeb()
+}
+
+func TestConcurrencyQueueSizeOption(t *testing.T) {
+	tests := []struct {
+		configured int
+		want       int
+	}{
+		{configured: 0, want: DefaultConcurrencyQueueSize},
+		{configured: 3, want: 3},
+		{configured: -1, want: 0},
+	}
+	for _, tt := range tests {
+		srv, err := New(&Options{
+			Logger:               logging.NewLogger("error"),
+			ConcurrencyQueueSize: tt.configured,
+		})
+		if err != nil {
+			t.Fatalf("Failed to create server: %v", err)
+		}
+		if srv.limiter.queueSize != tt.want {
+			t.Errorf("ConcurrencyQueueSize %d: expected queue size %d, got %d", tt.configured, tt.want, srv.limiter.queueSize)
+		}
+	}
+}
+
+func TestConcurrencyLimitSerializesSearches(t *testing.T) {
+	srv, err := New(&Options{
+		Logger:            logging.NewLogger("error"),
+		ConcurrencyLimits: map[string]int{"file": 1},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	// Replace Grep with a slow handler that records how many calls overlap.
+	var running, maxRunning int32
+	mcp.AddTool(srv.mcpServer, &mcp.Tool{Name: "Grep"}, func(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[map[string]any]) (*mcp.CallToolResultFor[any], error) {
+		current := atomic.AddInt32(&running, 1)
+		for {
+			previous := atomic.LoadInt32(&maxRunning)
+			if current <= previous || atomic.CompareAndSwapInt32(&maxRunning, previous, current) {
+				break
+			}
+		}
+		time.Sleep(50 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		return &mcp.CallToolResultFor[any]{Content: []mcp.Content{&mcp.TextContent{Text: "ok"}}}, nil
+	})
+
+	const callers = 4
+	sessions := make([]*mcp.ClientSession, callers)
+	for i := range sessions {
+		sessions[i] = connectTestClient(t, srv)
+	}
+
+	var wg sync.WaitGroup
+	for _, session := range sessions {
+		wg.Add(1)
+		go func(session *mcp.ClientSession) {
+			defer wg.Done()
+			result, err := session.CallTool(context.Background(), &mcp.CallToolParams{
+				Name:      "Grep",
+				Arguments: map[string]any{"pattern": "x"},
+			})
+			if err != nil {
+				t.Errorf("Grep call failed: %v", err)
+				return
+			}
+			if result.IsError {
+				t.Errorf("Grep call returned error: %s", resultText(result))
+			}
+		}(session)
+	}
+	wg.Wait()
+
+	if maxRunning != 1 {
+		t.Errorf("Expected searches to be serialized, but %d ran concurrently", maxRunning)
+	}
+}
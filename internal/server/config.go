@@ -0,0 +1,279 @@
+// Package server provides a reloadable configuration file for the security
+// rules and enabled tools.
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+	"sync/atomic"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"gopkg.in/yaml.v3"
+
+	"github.com/d-kuro/claude-code-mcp/internal/security"
+	"github.com/d-kuro/claude-code-mcp/internal/tools"
+	"github.com/d-kuro/claude-code-mcp/internal/tools/bash"
+)
+
+// Config is the server configuration file. It can be reloaded while the
+// server runs, replacing the validator rules and the set of disabled tools.
+//
+// Example:
+//
+//	disabled_tools: [WebSearch]
+//	allowed_paths: [/home/user/project]
+//	blocked_commands: [curl, wget]
+type Config struct {
+	// DisabledTools lists tools whose calls are rejected and which are
+	// hidden from the tool list.
+	DisabledTools []string `yaml:"disabled_tools"`
+
+	// The lists below configure security.DefaultValidator. Blocked paths and
+	// commands are added to the default block lists.
+	AllowedPaths    []string `yaml:"allowed_paths"`
+	BlockedPaths    []string `yaml:"blocked_paths"`
+	WritablePaths   []string `yaml:"writable_paths"`
+	AllowedCommands []string `yaml:"allowed_commands"`
+	BlockedCommands []string `yaml:"blocked_commands"`
+	Strict          bool     `yaml:"strict"`
+
+	// BlockNetworkCommands rejects Bash commands that run curl, wget, ssh and
+	// other network commands, or those in NetworkCommands when it is set.
+	BlockNetworkCommands bool     `yaml:"block_network_commands"`
+	NetworkCommands      []string `yaml:"network_commands"`
+
+	// DangerousPatterns are case-insensitive regular expressions for Bash and
+	// custom tool commands that are rejected, on top of the built-in
+	// dangerous patterns and Options.DangerousPatterns. Commands matching
+	// AllowedDangerousPatterns are accepted even so.
+	DangerousPatterns        []string `yaml:"dangerous_patterns"`
+	AllowedDangerousPatterns []string `yaml:"allowed_dangerous_patterns"`
+
+	// AllowedBinaries, when non-empty, lists the only binaries that Bash and
+	// custom tool commands may run, together with Options.AllowedBinaries.
+	// Names may use glob patterns such as "python3*".
+	AllowedBinaries []string `yaml:"allowed_binaries"`
+
+	// AllowedURLSchemes replaces the URL schemes accepted for WebFetch,
+	// which are http and https by default.
+	AllowedURLSchemes []string `yaml:"allowed_url_schemes"`
+
+	// CaseInsensitivePaths compares paths with the path lists without regard
+	// to case. When unset, it is enabled on macOS and Windows.
+	CaseInsensitivePaths *bool `yaml:"case_insensitive_paths"`
+
+	// UnicodeNormalization compares paths with the path lists in Unicode
+	// normalization form C, so combining-character variants match.
+	UnicodeNormalization bool `yaml:"unicode_normalization"`
+
+	// ExpandPaths expands a leading "~" or $HOME, and a leading variable
+	// listed in PathVariables, in paths given to the tools before they are
+	// validated.
+	ExpandPaths bool `yaml:"expand_paths"`
+
+	// PathVariables lists the environment variables, besides HOME, that
+	// ExpandPaths may expand at the start of a path.
+	PathVariables []string `yaml:"path_variables"`
+
+	// AllowRootSearch permits Glob and Grep searches rooted at a filesystem
+	// root, like Options.AllowRootSearch. It is read when the server starts;
+	// a reload does not change it.
+	AllowRootSearch bool `yaml:"allow_root_search"`
+}
+
+// LoadConfig reads and parses a configuration file.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var config Config
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&config); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	}
+
+	return &config, nil
+}
+
+// Validator builds the validator described by the configuration.
+func (c *Config) Validator() *security.DefaultValidator {
+	validator := security.NewDefaultValidator().
+		WithAllowedPaths(c.AllowedPaths).
+		WithBlockedPaths(c.BlockedPaths).
+		WithWritablePaths(c.WritablePaths).
+		WithAllowedCommands(c.AllowedCommands).
+		WithBlockedCommands(c.BlockedCommands)
+	if c.Strict {
+		validator.WithStrictMode()
+	}
+	if c.BlockNetworkCommands {
+		validator.WithBlockNetworkCommands(true)
+		if len(c.NetworkCommands) > 0 {
+			validator.WithNetworkCommands(c.NetworkCommands)
+		}
+	}
+	if len(c.AllowedURLSchemes) > 0 {
+		validator.WithAllowedURLSchemes(c.AllowedURLSchemes)
+	}
+	if c.CaseInsensitivePaths != nil {
+		validator.WithCaseInsensitivePaths(*c.CaseInsensitivePaths)
+	}
+	if c.UnicodeNormalization {
+		validator.WithUnicodeNormalization(true)
+	}
+	if c.ExpandPaths {
+		validator.WithPathExpansion(true).WithPathVariables(c.PathVariables)
+	}
+	return validator
+}
+
+// activeConfig is the part of the configuration that can change at runtime.
+type activeConfig struct {
+	validator     security.Validator
+	commandRules  *bash.ShellExecutor
+	disabledTools map[string]bool
+}
+
+// reloadableValidator delegates to the validator of the active configuration,
+// so a reload takes effect for every tool without recreating them. Each check
+// uses the configuration active when it runs.
+type reloadableValidator struct {
+	config *atomic.Pointer[activeConfig]
+}
+
+func (v reloadableValidator) current() security.Validator {
+	return v.config.Load().validator
+}
+
+func (v reloadableValidator) ValidatePath(path string) error {
+	return v.current().ValidatePath(path)
+}
+
+func (v reloadableValidator) ValidateWritePath(path string) error {
+	return v.current().ValidateWritePath(path)
+}
+
+func (v reloadableValidator) ValidateCommand(cmd string, args []string) error {
+	return v.current().ValidateCommand(cmd, args)
+}
+
+func (v reloadableValidator) ValidateURL(urlStr string) error {
+	return v.current().ValidateURL(urlStr)
+}
+
+func (v reloadableValidator) SanitizePath(path string) (string, error) {
+	return v.current().SanitizePath(path)
+}
+
+// reloadableCommandRules delegates to the command rules of the active
+// configuration, like reloadableValidator.
+type reloadableCommandRules struct {
+	config *atomic.Pointer[activeConfig]
+}
+
+func (r reloadableCommandRules) ValidateCommand(command string) error {
+	return r.config.Load().commandRules.ValidateCommand(command)
+}
+
+// newActiveConfig builds the runtime configuration from a config file.
+func newActiveConfig(config *Config) *activeConfig {
+	disabled := make(map[string]bool, len(config.DisabledTools))
+	for _, name := range config.DisabledTools {
+		disabled[name] = true
+	}
+	return &activeConfig{validator: config.Validator(), disabledTools: disabled}
+}
+
+// newCommandRules builds the command rules from the server options and a
+// config file. It fails if a pattern is not a valid regular expression.
+func (s *Server) newCommandRules(config *Config) (*bash.ShellExecutor, error) {
+	rules := bash.NewShellExecutor().
+		WithDangerousPatterns(slices.Concat(s.dangerousPatterns, config.DangerousPatterns)).
+		WithAllowedDangerousPatterns(slices.Concat(s.allowedDangerous, config.AllowedDangerousPatterns)).
+		WithAllowedBinaries(slices.Concat(s.allowedBinaries, config.AllowedBinaries))
+	if err := rules.Err(); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// isToolDisabled reports whether the active configuration disables the tool.
+func (s *Server) isToolDisabled(name string) bool {
+	return s.config.Load().disabledTools[name]
+}
+
+// Reload re-reads the configuration file and atomically replaces the
+// validator and disabled tools. Calls that are already running finish their
+// current checks with the previous rules; new calls use the new ones. If the
+// file cannot be loaded, the current configuration is kept.
+func (s *Server) Reload() error {
+	if s.configFile == "" {
+		return fmt.Errorf("no config file to reload")
+	}
+
+	config, err := LoadConfig(s.configFile)
+	if err != nil {
+		s.logger.Error("Failed to reload configuration",
+			slog.String("path", s.configFile),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+
+	active := newActiveConfig(config)
+	active.commandRules, err = s.newCommandRules(config)
+	if err != nil {
+		s.logger.Error("Failed to reload configuration",
+			slog.String("path", s.configFile),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+
+	s.config.Store(active)
+	s.logger.Info("Reloaded configuration",
+		slog.String("path", s.configFile),
+		slog.Any("disabled_tools", config.DisabledTools),
+	)
+	return nil
+}
+
+// methodListTools is the MCP method name used to list tools.
+const methodListTools = "tools/list"
+
+// disabledToolsMiddleware hides the tools disabled by the active
+// configuration from the tool list and rejects calls to them.
+func (s *Server) disabledToolsMiddleware(next mcp.MethodHandler[*mcp.ServerSession]) mcp.MethodHandler[*mcp.ServerSession] {
+	return func(ctx context.Context, session *mcp.ServerSession, method string, params mcp.Params) (mcp.Result, error) {
+		switch method {
+		case methodCallTool:
+			if call, ok := params.(*mcp.CallToolParamsFor[json.RawMessage]); ok && s.isToolDisabled(call.Name) {
+				return tools.ErrorResponsef("%s is disabled by the server configuration", call.Name), nil
+			}
+		case methodListTools:
+			result, err := next(ctx, session, method, params)
+			if list, ok := result.(*mcp.ListToolsResult); ok && err == nil {
+				enabled := make([]*mcp.Tool, 0, len(list.Tools))
+				for _, tool := range list.Tools {
+					if !s.isToolDisabled(tool.Name) {
+						enabled = append(enabled, tool)
+					}
+				}
+				list.Tools = enabled
+			}
+			return result, err
+		}
+
+		return next(ctx, session, method, params)
+	}
+}
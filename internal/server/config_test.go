@@ -0,0 +1,202 @@
+package server
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func writeConfig(t *testing.T, path, content string) {
+	t.Helper()
+
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+}
+
+func TestReloadConfig(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	writeConfig(t, configPath, "blocked_commands: [curl]\n")
+
+	srv, err := New(&Options{ConfigFile: configPath})
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	session := connectTestClient(t, srv)
+	ctx := context.Background()
+
+	callBash := func(command string) *mcp.CallToolResult {
+		t.Helper()
+		result, err := session.CallTool(ctx, &mcp.CallToolParams{
+			Name:      "Bash",
+			Arguments: map[string]any{"command": command},
+		})
+		if err != nil {
+			t.Fatalf("Bash call failed: %v", err)
+		}
+		return result
+	}
+
+	if result := callBash("echo before"); result.IsError {
+		t.Fatalf("Expected echo to be allowed before the reload, got: %s", resultText(result))
+	}
+
+	writeConfig(t, configPath, "blocked_commands: [curl, echo]\ndisabled_tools: [WebSearch]\n")
+	if err := srv.Reload(); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+
+	result := callBash("echo after")
+	if !result.IsError || !strings.Contains(resultText(result), "blocked") {
+		t.Errorf("Expected echo to be blocked after the reload, got: %s", resultText(result))
+	}
+
+	result, err = session.CallTool(ctx, &mcp.CallToolParams{
+		Name:      "WebSearch",
+		Arguments: map[string]any{"query": "golang"},
+	})
+	if err != nil {
+		t.Fatalf("WebSearch call failed: %v", err)
+	}
+	if !result.IsError || !strings.Contains(resultText(result), "disabled by the server configuration") {
+		t.Errorf("Expected WebSearch to be disabled, got: %s", resultText(result))
+	}
+
+	list, err := session.ListTools(ctx, nil)
+	if err != nil {
+		t.Fatalf("ListTools failed: %v", err)
+	}
+	for _, tool := range list.Tools {
+		if tool.Name == "WebSearch" {
+			t.Error("Expected WebSearch to be hidden from the tool list")
+		}
+	}
+
+	// A broken file keeps the current configuration
+	writeConfig(t, configPath, "blocked_commands: [\n")
+	if err := srv.Reload(); err == nil {
+		t.Error("Expected reloading an invalid config file to fail")
+	}
+	if result := callBash("echo kept"); !result.IsError {
+		t.Error("Expected the previous configuration to stay active after a failed reload")
+	}
+}
+
+func TestLoadConfigRejectsUnknownFields(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	writeConfig(t, configPath, "blocked_command: [curl]\n")
+
+	if _, err := LoadConfig(configPath); err == nil {
+		t.Error("Expected an unknown field to be rejected")
+	}
+}
+
+func TestDangerousPatternsFromOptionsAndConfig(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	writeConfig(t, configPath, "dangerous_patterns: ['\\breboot\\b']\n")
+
+	srv, err := New(&Options{
+		ConfigFile:               configPath,
+		DangerousPatterns:        []string{`\bshutdown\b`},
+		AllowedDangerousPatterns: []string{`^echo `},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	session := connectTestClient(t, srv)
+
+	callBash := func(command string) *mcp.CallToolResult {
+		t.Helper()
+		result, err := session.CallTool(context.Background(), &mcp.CallToolParams{
+			Name:      "Bash",
+			Arguments: map[string]any{"command": command},
+		})
+		if err != nil {
+			t.Fatalf("Bash call failed: %v", err)
+		}
+		return result
+	}
+
+	for _, command := range []string{"shutdown -h now", "SHUTDOWN now", "reboot"} {
+		if result := callBash(command); !result.IsError || !strings.Contains(resultText(result), "dangerous pattern") {
+			t.Errorf("Expected %q to be rejected, got: %s", command, resultText(result))
+		}
+	}
+	if result := callBash("echo shutdown"); result.IsError {
+		t.Errorf("Expected the allowed pattern to accept the command, got: %s", resultText(result))
+	}
+
+	// The config file patterns are replaced on reload, the options are kept
+	writeConfig(t, configPath, "dangerous_patterns: [poweroff]\n")
+	if err := srv.Reload(); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	if result := callBash("poweroff"); !result.IsError {
+		t.Errorf("Expected the reloaded pattern to reject the command, got: %s", resultText(result))
+	}
+	if result := callBash("shutdown"); !result.IsError {
+		t.Errorf("Expected the option pattern to still reject the command, got: %s", resultText(result))
+	}
+
+	// An invalid pattern keeps the current configuration
+	writeConfig(t, configPath, "dangerous_patterns: ['(']\n")
+	if err := srv.Reload(); err == nil {
+		t.Error("Expected reloading an invalid pattern to fail")
+	}
+	if _, err := New(&Options{DangerousPatterns: []string{"("}}); err == nil {
+		t.Error("Expected an invalid pattern option to be rejected")
+	}
+}
+
+func TestAllowedBinariesFromOptionsAndConfig(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	writeConfig(t, configPath, "allowed_binaries: [pwd]\n")
+
+	srv, err := New(&Options{ConfigFile: configPath, AllowedBinaries: []string{"echo"}})
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	session := connectTestClient(t, srv)
+
+	for command, allowed := range map[string]bool{"echo hi": true, "pwd": true, "echo hi | cat": false, "uname": false} {
+		result, err := session.CallTool(context.Background(), &mcp.CallToolParams{
+			Name:      "Bash",
+			Arguments: map[string]any{"command": command},
+		})
+		if err != nil {
+			t.Fatalf("Bash call failed: %v", err)
+		}
+		if result.IsError == allowed {
+			t.Errorf("Expected %q allowed=%v, got: %s", command, allowed, resultText(result))
+		}
+	}
+}
+
+func TestAllowRootSearchFromConfig(t *testing.T) {
+	for _, allowed := range []bool{false, true} {
+		configPath := filepath.Join(t.TempDir(), "config.yaml")
+		writeConfig(t, configPath, "allow_root_search: "+strconv.FormatBool(allowed)+"\n")
+
+		srv, err := New(&Options{ConfigFile: configPath, MaxSearchDepth: 1})
+		if err != nil {
+			t.Fatalf("Failed to create server: %v", err)
+		}
+		session := connectTestClient(t, srv)
+
+		result, err := session.CallTool(context.Background(), &mcp.CallToolParams{
+			Name:      "Glob",
+			Arguments: map[string]any{"pattern": "claude-code-mcp-no-such-file", "path": "/"},
+		})
+		if err != nil {
+			t.Fatalf("Glob call failed: %v", err)
+		}
+		if rejected := strings.Contains(resultText(result), "refusing to search filesystem root"); rejected == allowed {
+			t.Errorf("allow_root_search: %v: unexpected result: %s", allowed, resultText(result))
+		}
+	}
+}
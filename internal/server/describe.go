@@ -0,0 +1,79 @@
+// Package server provides detailed descriptions of registered tools.
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonschema"
+
+	"github.com/d-kuro/claude-code-mcp/internal/prompts"
+	"github.com/d-kuro/claude-code-mcp/internal/version"
+)
+
+// ToolDescription is the full description of a registered tool, for client
+// tooling and debugging.
+type ToolDescription struct {
+	Name        string             `json:"name"`
+	Category    string             `json:"category"`
+	Description string             `json:"description"`
+	InputSchema *jsonschema.Schema `json:"input_schema"`
+	Examples    []json.RawMessage  `json:"examples,omitempty"`
+}
+
+// ToolCatalog describes every enabled tool of a server in one document, so
+// that other systems can generate clients from the argument schemas.
+type ToolCatalog struct {
+	Server  string             `json:"server"`
+	Version string             `json:"version"`
+	Tools   []*ToolDescription `json:"tools"`
+}
+
+// DescribeTool returns the description, argument schema, category and
+// example arguments of a registered tool.
+func (s *Server) DescribeTool(name string) (*ToolDescription, error) {
+	tool, ok := s.toolSchemas[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown tool %q", name)
+	}
+	if s.isToolDisabled(name) {
+		return nil, fmt.Errorf("tool %q is disabled by the server configuration", name)
+	}
+
+	description := &ToolDescription{
+		Name:        tool.Name,
+		Category:    s.registry.ToolCategory(name),
+		Description: tool.Description,
+		InputSchema: tool.InputSchema,
+	}
+	for _, example := range prompts.ToolExamples[name] {
+		description.Examples = append(description.Examples, json.RawMessage(example))
+	}
+
+	return description, nil
+}
+
+// ToolCatalog returns the descriptions of all registered tools that are not
+// disabled by the server configuration, sorted by name.
+func (s *Server) ToolCatalog() *ToolCatalog {
+	names := make([]string, 0, len(s.toolSchemas))
+	for name := range s.toolSchemas {
+		if !s.isToolDisabled(name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	catalog := &ToolCatalog{
+		Server:  "claude-code-mcp",
+		Version: version.GetVersion().Version,
+		Tools:   make([]*ToolDescription, 0, len(names)),
+	}
+	for _, name := range names {
+		if description, err := s.DescribeTool(name); err == nil {
+			catalog.Tools = append(catalog.Tools, description)
+		}
+	}
+	return catalog
+}
@@ -0,0 +1,133 @@
+package server
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/d-kuro/claude-code-mcp/internal/logging"
+	"github.com/d-kuro/claude-code-mcp/internal/prompts"
+)
+
+func TestDescribeTool(t *testing.T) {
+	srv, err := New(&Options{Logger: logging.NewLogger("error")})
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	description, err := srv.DescribeTool("Read")
+	if err != nil {
+		t.Fatalf("DescribeTool failed: %v", err)
+	}
+	if description.Category != "file" {
+		t.Errorf("Expected category file, got %q", description.Category)
+	}
+	if !strings.Contains(description.Description, "Reads a file") {
+		t.Errorf("Expected the Read tool documentation, got: %.80s", description.Description)
+	}
+	if description.InputSchema == nil || description.InputSchema.Properties["file_path"] == nil {
+		t.Fatalf("Expected the input schema to have a file_path argument, got: %+v", description.InputSchema)
+	}
+	if len(description.Examples) == 0 {
+		t.Error("Expected usage examples for Read")
+	}
+
+	if _, err := srv.DescribeTool("Unknown"); err == nil || !strings.Contains(err.Error(), "unknown tool") {
+		t.Errorf("Expected an unknown tool error, got: %v", err)
+	}
+}
+
+func TestToolExamplesMatchSchemas(t *testing.T) {
+	srv, err := New(&Options{Logger: logging.NewLogger("error")})
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	for name, examples := range prompts.ToolExamples {
+		description, err := srv.DescribeTool(name)
+		if err != nil {
+			t.Errorf("Example for %s: %v", name, err)
+			continue
+		}
+		for _, example := range examples {
+			var args map[string]any
+			if err := json.Unmarshal([]byte(example), &args); err != nil {
+				t.Errorf("Example for %s is not a JSON object: %v", name, err)
+				continue
+			}
+			// Tools that take free-form arguments have no properties to check
+			for key := range args {
+				if len(description.InputSchema.Properties) > 0 && description.InputSchema.Properties[key] == nil {
+					t.Errorf("Example for %s uses unknown argument %q", name, key)
+				}
+			}
+		}
+	}
+}
+
+func TestToolCatalog(t *testing.T) {
+	srv, err := New(&Options{Logger: logging.NewLogger("error")})
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	catalog := srv.ToolCatalog()
+	if catalog.Server != "claude-code-mcp" || catalog.Version == "" {
+		t.Errorf("Expected server name and version, got %q %q", catalog.Server, catalog.Version)
+	}
+	if len(catalog.Tools) != len(srv.toolSchemas) {
+		t.Errorf("Expected %d tools, got %d", len(srv.toolSchemas), len(catalog.Tools))
+	}
+
+	names := make([]string, len(catalog.Tools))
+	for i, tool := range catalog.Tools {
+		names[i] = tool.Name
+		if _, ok := srv.toolSchemas[tool.Name]; !ok {
+			t.Errorf("Catalog lists unregistered tool %q", tool.Name)
+		}
+		if tool.InputSchema == nil || tool.InputSchema.Type != "object" {
+			t.Errorf("Expected an object input schema for %s, got %+v", tool.Name, tool.InputSchema)
+		}
+		if tool.Category == "" || tool.Description == "" {
+			t.Errorf("Expected category and description for %s", tool.Name)
+		}
+	}
+	if !sort.StringsAreSorted(names) {
+		t.Errorf("Expected tools sorted by name, got %v", names)
+	}
+
+	data, err := json.Marshal(catalog)
+	if err != nil {
+		t.Fatalf("Failed to encode catalog: %v", err)
+	}
+	var decoded struct {
+		Tools []struct {
+			Name        string         `json:"name"`
+			InputSchema map[string]any `json:"input_schema"`
+		} `json:"tools"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Failed to decode catalog: %v", err)
+	}
+	for _, tool := range decoded.Tools {
+		if tool.Name == "Read" {
+			if properties, _ := tool.InputSchema["properties"].(map[string]any); properties["file_path"] == nil {
+				t.Errorf("Expected the Read schema to have file_path, got %v", tool.InputSchema)
+			}
+		}
+	}
+
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	writeConfig(t, configPath, "disabled_tools: [WebSearch]\n")
+	srv, err = New(&Options{Logger: logging.NewLogger("error"), ConfigFile: configPath})
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	for _, tool := range srv.ToolCatalog().Tools {
+		if tool.Name == "WebSearch" {
+			t.Error("Expected disabled tool to be left out of the catalog")
+		}
+	}
+}
@@ -0,0 +1,60 @@
+// Package server provides an optional versioned envelope around tool results.
+package server
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/d-kuro/claude-code-mcp/internal/tools"
+)
+
+// ResponseEnvelopeVersion is the version of the ResponseEnvelope format.
+// It changes when fields are removed or change meaning.
+const ResponseEnvelopeVersion = 1
+
+// ResponseEnvelope wraps the result of a tool call when the response envelope
+// is enabled. It is returned as the only text content of the result, so
+// clients can detect the format by its version before parsing the rest.
+type ResponseEnvelope struct {
+	Version int           `json:"version"`
+	Tool    string        `json:"tool"`
+	Result  []mcp.Content `json:"result"`
+	Meta    mcp.Meta      `json:"meta,omitempty"`
+}
+
+// envelopeMiddleware replaces the content of tool results with a
+// ResponseEnvelope holding the original content and metadata. The result
+// keeps its error flag and metadata, so clients that do not parse the
+// envelope still see them.
+func (s *Server) envelopeMiddleware(next mcp.MethodHandler[*mcp.ServerSession]) mcp.MethodHandler[*mcp.ServerSession] {
+	return func(ctx context.Context, session *mcp.ServerSession, method string, params mcp.Params) (mcp.Result, error) {
+		result, err := next(ctx, session, method, params)
+		if err != nil || !s.responseEnvelope || method != methodCallTool {
+			return result, err
+		}
+
+		toolResult, ok := result.(*mcp.CallToolResult)
+		if !ok {
+			return result, nil
+		}
+		call, ok := params.(*mcp.CallToolParamsFor[json.RawMessage])
+		if !ok {
+			return result, nil
+		}
+
+		envelope, err := json.Marshal(&ResponseEnvelope{
+			Version: ResponseEnvelopeVersion,
+			Tool:    call.Name,
+			Result:  toolResult.Content,
+			Meta:    toolResult.Meta,
+		})
+		if err != nil {
+			return tools.ErrorResponsef("failed to encode response envelope: %v", err), nil
+		}
+
+		toolResult.Content = []mcp.Content{&mcp.TextContent{Text: string(envelope)}}
+		return result, nil
+	}
+}
@@ -0,0 +1,71 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/d-kuro/claude-code-mcp/internal/logging"
+	"github.com/d-kuro/claude-code-mcp/internal/tools"
+)
+
+func TestResponseEnvelope(t *testing.T) {
+	callGreet := func(t *testing.T, envelope bool) *mcp.CallToolResult {
+		t.Helper()
+
+		srv, err := New(&Options{
+			Logger:           logging.NewLogger("error"),
+			ResponseEnvelope: envelope,
+		})
+		if err != nil {
+			t.Fatalf("Failed to create server: %v", err)
+		}
+		mcp.AddTool(srv.mcpServer, &mcp.Tool{Name: "Greet"}, func(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[struct{}]) (*mcp.CallToolResultFor[any], error) {
+			return tools.SuccessResponse("hello"), nil
+		})
+
+		result, err := connectTestClient(t, srv).CallTool(context.Background(), &mcp.CallToolParams{Name: "Greet", Arguments: map[string]any{}})
+		if err != nil {
+			t.Fatalf("Greet call failed: %v", err)
+		}
+		return result
+	}
+
+	t.Run("bare by default", func(t *testing.T) {
+		result := callGreet(t, false)
+		if text := resultText(result); text != "hello" {
+			t.Errorf("Expected the bare tool output, got %q", text)
+		}
+	})
+
+	t.Run("envelope", func(t *testing.T) {
+		result := callGreet(t, true)
+		if len(result.Content) != 1 {
+			t.Fatalf("Expected a single envelope content, got %d", len(result.Content))
+		}
+
+		var envelope struct {
+			Version int    `json:"version"`
+			Tool    string `json:"tool"`
+			Result  []struct {
+				Type string `json:"type"`
+				Text string `json:"text"`
+			} `json:"result"`
+			Meta map[string]any `json:"meta"`
+		}
+		if err := json.Unmarshal([]byte(resultText(result)), &envelope); err != nil {
+			t.Fatalf("Expected a JSON envelope, got %q: %v", resultText(result), err)
+		}
+		if envelope.Version != ResponseEnvelopeVersion || envelope.Tool != "Greet" {
+			t.Errorf("Unexpected envelope header: %+v", envelope)
+		}
+		if len(envelope.Result) != 1 || envelope.Result[0].Type != "text" || envelope.Result[0].Text != "hello" {
+			t.Errorf("Expected the original content in the envelope, got %+v", envelope.Result)
+		}
+		if envelope.Meta[tools.RequestIDMetaKey] == nil || envelope.Meta[tools.RequestIDMetaKey] != result.Meta[tools.RequestIDMetaKey] {
+			t.Errorf("Expected the request id in the envelope metadata, got %v", envelope.Meta)
+		}
+	})
+}
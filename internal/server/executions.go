@@ -0,0 +1,262 @@
+// Package server provides tracking and cancellation of in-flight tool calls.
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/d-kuro/claude-code-mcp/internal/prompts"
+	"github.com/d-kuro/claude-code-mcp/internal/tools"
+)
+
+// methodCallTool is the MCP method name used for tool invocations.
+const methodCallTool = "tools/call"
+
+// drainCancelGrace is how long Drain waits for cancelled executions to return.
+const drainCancelGrace = 500 * time.Millisecond
+
+// Execution describes a tool call that is currently running.
+type Execution struct {
+	ID        string    `json:"id"`
+	Tool      string    `json:"tool"`
+	SessionID string    `json:"session_id,omitempty"`
+	StartedAt time.Time `json:"started_at"`
+
+	cancel context.CancelFunc
+}
+
+// ExecutionRegistry tracks in-flight tool calls so they can be listed,
+// cancelled, and drained on shutdown.
+type ExecutionRegistry struct {
+	mu         sync.RWMutex
+	executions map[string]*Execution
+	nextID     uint64
+	running    sync.WaitGroup
+	closed     bool
+}
+
+// NewExecutionRegistry creates an empty execution registry.
+func NewExecutionRegistry() *ExecutionRegistry {
+	return &ExecutionRegistry{
+		executions: make(map[string]*Execution),
+	}
+}
+
+// Start registers a new execution and returns a cancellable context derived
+// from parent, the generated execution id, and a function that must be called
+// when the execution finishes. Once the registry is draining, the returned
+// context is already cancelled.
+func (r *ExecutionRegistry) Start(parent context.Context, toolName, sessionID string) (context.Context, string, func()) {
+	ctx, cancel := context.WithCancel(parent)
+
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		cancel()
+		return ctx, "", func() {}
+	}
+	r.running.Add(1)
+	r.nextID++
+	id := fmt.Sprintf("exec-%d", r.nextID)
+	r.executions[id] = &Execution{
+		ID:        id,
+		Tool:      toolName,
+		SessionID: sessionID,
+		StartedAt: time.Now(),
+		cancel:    cancel,
+	}
+	r.mu.Unlock()
+
+	var once sync.Once
+	done := func() {
+		once.Do(func() {
+			r.mu.Lock()
+			delete(r.executions, id)
+			r.mu.Unlock()
+			cancel()
+			r.running.Done()
+		})
+	}
+
+	return ctx, id, done
+}
+
+// List returns a snapshot of all in-flight executions ordered by start time.
+func (r *ExecutionRegistry) List() []Execution {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	executions := make([]Execution, 0, len(r.executions))
+	for _, exec := range r.executions {
+		executions = append(executions, Execution{
+			ID:        exec.ID,
+			Tool:      exec.Tool,
+			SessionID: exec.SessionID,
+			StartedAt: exec.StartedAt,
+		})
+	}
+
+	sort.Slice(executions, func(i, j int) bool {
+		return executions[i].StartedAt.Before(executions[j].StartedAt)
+	})
+
+	return executions
+}
+
+// Cancel cancels the execution with the given id.
+// It reports whether a running execution was found.
+func (r *ExecutionRegistry) Cancel(id string) bool {
+	r.mu.RLock()
+	exec, exists := r.executions[id]
+	r.mu.RUnlock()
+
+	if !exists {
+		return false
+	}
+
+	exec.cancel()
+	return true
+}
+
+// Count returns the number of in-flight executions.
+func (r *ExecutionRegistry) Count() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.executions)
+}
+
+// Closed reports whether the registry is draining and no longer accepts executions.
+func (r *ExecutionRegistry) Closed() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.closed
+}
+
+// Drain stops accepting executions and waits until the in-flight ones finish
+// or ctx is done. Executions still running then are cancelled and given a
+// short grace period to return. It reports how many executions finished on
+// their own and how many were cancelled.
+func (r *ExecutionRegistry) Drain(ctx context.Context) (drained, cancelled int) {
+	r.mu.Lock()
+	r.closed = true
+	inFlight := len(r.executions)
+	r.mu.Unlock()
+
+	finished := make(chan struct{})
+	go func() {
+		r.running.Wait()
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+		return inFlight, 0
+	case <-ctx.Done():
+	}
+
+	r.mu.RLock()
+	for _, exec := range r.executions {
+		exec.cancel()
+		cancelled++
+	}
+	r.mu.RUnlock()
+
+	select {
+	case <-finished:
+	case <-time.After(drainCancelGrace):
+	}
+	return inFlight - cancelled, cancelled
+}
+
+// executionMiddleware registers every tool call with the execution registry
+// for the duration of its handler.
+func (s *Server) executionMiddleware(next mcp.MethodHandler[*mcp.ServerSession]) mcp.MethodHandler[*mcp.ServerSession] {
+	return func(ctx context.Context, session *mcp.ServerSession, method string, params mcp.Params) (mcp.Result, error) {
+		if method != methodCallTool {
+			return next(ctx, session, method, params)
+		}
+
+		call, ok := params.(*mcp.CallToolParamsFor[json.RawMessage])
+		if !ok || isExecutionTool(call.Name) {
+			return next(ctx, session, method, params)
+		}
+
+		if s.executions.Closed() {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{&mcp.TextContent{Text: "Error: the server is shutting down"}},
+				IsError: true,
+			}, nil
+		}
+
+		execCtx, _, done := s.executions.Start(ctx, call.Name, session.ID())
+		defer done()
+
+		return next(execCtx, session, method, params)
+	}
+}
+
+// isExecutionTool reports whether the tool manages executions itself and
+// should therefore not be tracked.
+func isExecutionTool(name string) bool {
+	return name == "ListExecutions" || name == "CancelExecution"
+}
+
+// ListExecutionsArgs represents the arguments for the ListExecutions tool.
+type ListExecutionsArgs struct{}
+
+// CancelExecutionArgs represents the arguments for the CancelExecution tool.
+type CancelExecutionArgs struct {
+	ID string `json:"id"`
+}
+
+// createExecutionTools creates the tools used to inspect and cancel in-flight tool calls.
+func createExecutionTools(registry *ExecutionRegistry) []*tools.ServerTool {
+	listHandler := func(ctxReq context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[ListExecutionsArgs]) (*mcp.CallToolResultFor[any], error) {
+		return tools.JSONResponse(registry.List()), nil
+	}
+
+	cancelHandler := func(ctxReq context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[CancelExecutionArgs]) (*mcp.CallToolResultFor[any], error) {
+		args := params.Arguments
+
+		if errResp := tools.ValidateNonEmpty(args.ID, "id"); errResp != nil {
+			return errResp, nil
+		}
+
+		if !registry.Cancel(args.ID) {
+			return tools.NotFoundError(fmt.Sprintf("Execution %s", args.ID)), nil
+		}
+
+		return tools.SuccessResponsef("Cancelled execution %s", args.ID), nil
+	}
+
+	listTool := &mcp.Tool{
+		Name:        "ListExecutions",
+		Description: prompts.ListExecutionsToolDoc,
+	}
+
+	cancelTool := &mcp.Tool{
+		Name:        "CancelExecution",
+		Description: prompts.CancelExecutionToolDoc,
+	}
+
+	return []*tools.ServerTool{
+		{
+			Tool: listTool,
+			RegisterFunc: func(server *mcp.Server) {
+				mcp.AddTool(server, listTool, listHandler)
+			},
+		},
+		{
+			Tool: cancelTool,
+			RegisterFunc: func(server *mcp.Server) {
+				mcp.AddTool(server, cancelTool, cancelHandler)
+			},
+		},
+	}
+}
@@ -0,0 +1,229 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/d-kuro/claude-code-mcp/internal/logging"
+)
+
+// connectTestClient connects a new in-memory client session to the server.
+func connectTestClient(t *testing.T, srv *Server) *mcp.ClientSession {
+	t.Helper()
+
+	ctx := context.Background()
+	clientTransport, serverTransport := mcp.NewInMemoryTransports()
+
+	serverSession, err := srv.mcpServer.Connect(ctx, serverTransport)
+	if err != nil {
+		t.Fatalf("Failed to connect server: %v", err)
+	}
+	t.Cleanup(func() { _ = serverSession.Close() })
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "v0.0.1"}, nil)
+	clientSession, err := client.Connect(ctx, clientTransport)
+	if err != nil {
+		t.Fatalf("Failed to connect client: %v", err)
+	}
+	t.Cleanup(func() { _ = clientSession.Close() })
+
+	return clientSession
+}
+
+// resultText returns the concatenated text content of a tool result.
+func resultText(result *mcp.CallToolResult) string {
+	var text strings.Builder
+	for _, content := range result.Content {
+		if tc, ok := content.(*mcp.TextContent); ok {
+			text.WriteString(tc.Text)
+		}
+	}
+	return text.String()
+}
+
+func TestExecutionRegistry(t *testing.T) {
+	registry := NewExecutionRegistry()
+
+	ctx, id, done := registry.Start(context.Background(), "Bash", "session-1")
+
+	executions := registry.List()
+	if len(executions) != 1 {
+		t.Fatalf("Expected 1 execution, got %d", len(executions))
+	}
+	if executions[0].ID != id || executions[0].Tool != "Bash" {
+		t.Errorf("Unexpected execution: %+v", executions[0])
+	}
+
+	if registry.Cancel("unknown") {
+		t.Errorf("Expected Cancel to fail for unknown id")
+	}
+
+	if !registry.Cancel(id) {
+		t.Fatalf("Expected Cancel to succeed for %s", id)
+	}
+
+	select {
+	case <-ctx.Done():
+	default:
+		t.Errorf("Expected execution context to be cancelled")
+	}
+
+	done()
+
+	if registry.Count() != 0 {
+		t.Errorf("Expected registry to be empty after done, got %d", registry.Count())
+	}
+}
+
+func TestCancelInFlightBashExecution(t *testing.T) {
+	srv, err := New(&Options{Logger: logging.NewLogger("error")})
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	// Requests on one session are handled sequentially, so the slow command
+	// and the control calls use separate sessions.
+	worker := connectTestClient(t, srv)
+	control := connectTestClient(t, srv)
+
+	ctx := context.Background()
+	bashDone := make(chan time.Duration, 1)
+	go func() {
+		start := time.Now()
+		_, _ = worker.CallTool(ctx, &mcp.CallToolParams{
+			Name:      "Bash",
+			Arguments: map[string]any{"command": "sleep 30"},
+		})
+		bashDone <- time.Since(start)
+	}()
+
+	var executions []Execution
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		result, err := control.CallTool(ctx, &mcp.CallToolParams{
+			Name:      "ListExecutions",
+			Arguments: map[string]any{},
+		})
+		if err != nil {
+			t.Fatalf("ListExecutions failed: %v", err)
+		}
+
+		if err := json.Unmarshal([]byte(resultText(result)), &executions); err != nil {
+			t.Fatalf("Failed to parse ListExecutions output: %v", err)
+		}
+		if len(executions) > 0 {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	if len(executions) != 1 || executions[0].Tool != "Bash" {
+		t.Fatalf("Expected one in-flight Bash execution, got %+v", executions)
+	}
+
+	result, err := control.CallTool(ctx, &mcp.CallToolParams{
+		Name:      "CancelExecution",
+		Arguments: map[string]any{"id": executions[0].ID},
+	})
+	if err != nil {
+		t.Fatalf("CancelExecution failed: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("CancelExecution returned error: %s", resultText(result))
+	}
+
+	select {
+	case elapsed := <-bashDone:
+		if elapsed >= 30*time.Second {
+			t.Errorf("Expected cancelled command to stop early, took %v", elapsed)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("Bash command did not stop after cancellation")
+	}
+
+	if count := srv.GetExecutions().Count(); count != 0 {
+		t.Errorf("Expected no in-flight executions after cancellation, got %d", count)
+	}
+}
+
+func TestStopDrainsInFlightCalls(t *testing.T) {
+	tests := []struct {
+		name          string
+		work          time.Duration
+		deadline      time.Duration
+		wantCancelled bool
+	}{
+		{"completes before the deadline", 100 * time.Millisecond, 5 * time.Second, false},
+		{"cancelled at the deadline", time.Minute, 100 * time.Millisecond, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, err := New(&Options{Logger: logging.NewLogger("error")})
+			if err != nil {
+				t.Fatalf("Failed to create server: %v", err)
+			}
+
+			started := make(chan struct{})
+			mcp.AddTool(srv.mcpServer, &mcp.Tool{Name: "Slow"}, func(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[struct{}]) (*mcp.CallToolResultFor[any], error) {
+				close(started)
+				select {
+				case <-time.After(tt.work):
+					return &mcp.CallToolResultFor[any]{Content: []mcp.Content{&mcp.TextContent{Text: "finished"}}}, nil
+				case <-ctx.Done():
+					return &mcp.CallToolResultFor[any]{Content: []mcp.Content{&mcp.TextContent{Text: "cancelled"}}, IsError: true}, nil
+				}
+			})
+
+			session := connectTestClient(t, srv)
+			results := make(chan *mcp.CallToolResult, 1)
+			go func() {
+				result, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: "Slow"})
+				if err != nil {
+					t.Errorf("Slow call failed: %v", err)
+				}
+				results <- result
+			}()
+			<-started
+
+			stopCtx, cancel := context.WithTimeout(context.Background(), tt.deadline)
+			defer cancel()
+			start := time.Now()
+			stopErr := srv.Stop(stopCtx)
+			if elapsed := time.Since(start); elapsed > tt.deadline+time.Second {
+				t.Errorf("Expected Stop to return shortly after the %v deadline, took %v", tt.deadline, elapsed)
+			}
+
+			result := <-results
+			if tt.wantCancelled {
+				if !errors.Is(stopErr, context.DeadlineExceeded) {
+					t.Errorf("Expected Stop to report the deadline, got: %v", stopErr)
+				}
+				if result == nil || resultText(result) != "cancelled" {
+					t.Errorf("Expected the call to be cancelled, got: %v", result)
+				}
+			} else {
+				if stopErr != nil {
+					t.Errorf("Expected Stop to succeed, got: %v", stopErr)
+				}
+				if result == nil || resultText(result) != "finished" {
+					t.Errorf("Expected the call to finish, got: %v", result)
+				}
+			}
+
+			rejected, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: "Slow"})
+			if err != nil {
+				t.Fatalf("Call after Stop failed: %v", err)
+			}
+			if !rejected.IsError || !strings.Contains(resultText(rejected), "shutting down") {
+				t.Errorf("Expected calls after Stop to be rejected, got: %s", resultText(rejected))
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+// Package server provides bearer token authentication and the listen address
+// checks for the HTTP transport.
+package server
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// DefaultHTTPHost is the host the HTTP transport listens on when the
+// address has none, as in ":8080".
+const DefaultHTTPHost = "127.0.0.1"
+
+// httpListenAddr returns addr with DefaultHTTPHost filled in when it names
+// only a port. Without an auth token, only loopback addresses are accepted,
+// since every client that can reach the server may run Bash and write files.
+func httpListenAddr(addr string, hasToken bool) (string, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", fmt.Errorf("invalid HTTP address %q: %w", addr, err)
+	}
+	if host == "" {
+		host = DefaultHTTPHost
+	}
+	if !hasToken && !isLoopbackHost(host) {
+		return "", fmt.Errorf("refusing to serve HTTP on non-loopback address %s without an auth token", net.JoinHostPort(host, port))
+	}
+	return net.JoinHostPort(host, port), nil
+}
+
+// isLoopbackHost reports whether host is "localhost" or a loopback IP address.
+func isLoopbackHost(host string) bool {
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// requireBearerToken rejects HTTP requests that do not send the configured
+// token as "Authorization: Bearer <token>". Without a token, requests pass
+// through unchanged.
+func (s *Server) requireBearerToken(next http.Handler) http.Handler {
+	if s.httpToken == "" {
+		return next
+	}
+	expected := []byte("Bearer " + s.httpToken)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), expected) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="claude-code-mcp"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
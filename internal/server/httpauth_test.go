@@ -0,0 +1,82 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/d-kuro/claude-code-mcp/internal/logging"
+)
+
+func TestHTTPListenAddr(t *testing.T) {
+	tests := []struct {
+		addr     string
+		hasToken bool
+		want     string
+		wantErr  bool
+	}{
+		{addr: ":8080", want: "127.0.0.1:8080"},
+		{addr: "localhost:8080", want: "localhost:8080"},
+		{addr: "[::1]:8080", want: "[::1]:8080"},
+		{addr: "0.0.0.0:8080", wantErr: true},
+		{addr: "192.168.1.10:8080", wantErr: true},
+		{addr: "example.com:8080", wantErr: true},
+		{addr: "0.0.0.0:8080", hasToken: true, want: "0.0.0.0:8080"},
+		{addr: "8080", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := httpListenAddr(tt.addr, tt.hasToken)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("httpListenAddr(%q, %v) = %q, %v; want %q, error %v", tt.addr, tt.hasToken, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestListenAndServeRefusesPublicAddressWithoutToken(t *testing.T) {
+	srv, err := New(&Options{Logger: logging.NewLogger("error")})
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	err = srv.ListenAndServe(context.Background(), "0.0.0.0:0")
+	if err == nil || !strings.Contains(err.Error(), "without an auth token") {
+		t.Errorf("Expected the public address to be refused, got %v", err)
+	}
+}
+
+func TestHTTPHandlerRequiresBearerToken(t *testing.T) {
+	srv, err := New(&Options{Logger: logging.NewLogger("error"), HTTPAuthToken: "secret"})
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	httpServer := httptest.NewServer(srv.HTTPHandler())
+	defer httpServer.Close()
+
+	get := func(authorization string) int {
+		t.Helper()
+		req, err := http.NewRequest(http.MethodGet, httpServer.URL+"/capabilities", nil)
+		if err != nil {
+			t.Fatalf("Failed to create request: %v", err)
+		}
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		_ = resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	for _, authorization := range []string{"", "Bearer wrong", "secret"} {
+		if status := get(authorization); status != http.StatusUnauthorized {
+			t.Errorf("Expected 401 for Authorization %q, got %d", authorization, status)
+		}
+	}
+	if status := get("Bearer secret"); status != http.StatusOK {
+		t.Errorf("Expected 200 with the token, got %d", status)
+	}
+}
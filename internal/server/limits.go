@@ -0,0 +1,149 @@
+// Package server provides size limits on tool call arguments and output.
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"unicode/utf8"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// DefaultMaxArgumentSize is the default limit, in bytes, on the encoded
+// arguments of a single tool call.
+const DefaultMaxArgumentSize = 16 << 20
+
+// DefaultMaxOutputSize is the default limit, in bytes, on the text content
+// returned by a single tool call.
+const DefaultMaxOutputSize = 1 << 20
+
+// TruncatedMetaKey is the result metadata key set when the output of a tool
+// call was cut to the output size limit.
+const TruncatedMetaKey = "truncated"
+
+// requestEnvelopeSize is the allowance for the JSON-RPC envelope around the
+// arguments when limiting HTTP request bodies.
+const requestEnvelopeSize = 64 << 10
+
+// argumentSizeMiddleware rejects tool calls whose encoded arguments exceed the
+// configured limit. The call fails with a protocol error before the arguments
+// are decoded for the tool handler.
+//
+// The check runs after the transport has read the whole message, so it
+// protects the tools but not the server's memory. Only the HTTP transport
+// bounds the read itself, through limitRequestBody; the SDK's stdio
+// transport decodes each message from stdin without a size limit.
+func (s *Server) argumentSizeMiddleware(next mcp.MethodHandler[*mcp.ServerSession]) mcp.MethodHandler[*mcp.ServerSession] {
+	return func(ctx context.Context, session *mcp.ServerSession, method string, params mcp.Params) (mcp.Result, error) {
+		if s.maxArgSize <= 0 || method != methodCallTool {
+			return next(ctx, session, method, params)
+		}
+
+		call, ok := params.(*mcp.CallToolParamsFor[json.RawMessage])
+		if ok && int64(len(call.Arguments)) > s.maxArgSize {
+			s.logger.Warn("Rejected oversized tool call",
+				slog.String("tool", call.Name),
+				slog.Int("size", len(call.Arguments)),
+				slog.Int64("limit", s.maxArgSize),
+			)
+			return nil, fmt.Errorf("arguments for %s are %d bytes, which exceeds the limit of %d bytes", call.Name, len(call.Arguments), s.maxArgSize)
+		}
+
+		return next(ctx, session, method, params)
+	}
+}
+
+// outputSizeMiddleware truncates the text content of tool results that exceed
+// the configured output limit, so that no single call can flood the client's
+// context. Tools keep their own limits, such as the number of lines Read
+// returns; this is the policy applied on top of all of them.
+func (s *Server) outputSizeMiddleware(next mcp.MethodHandler[*mcp.ServerSession]) mcp.MethodHandler[*mcp.ServerSession] {
+	return func(ctx context.Context, session *mcp.ServerSession, method string, params mcp.Params) (mcp.Result, error) {
+		result, err := next(ctx, session, method, params)
+		if err != nil || s.maxOutputSize <= 0 || method != methodCallTool {
+			return result, err
+		}
+
+		toolResult, ok := result.(*mcp.CallToolResult)
+		if !ok {
+			return result, nil
+		}
+
+		if size, truncated := truncateContent(toolResult, s.maxOutputSize); truncated {
+			if toolResult.Meta == nil {
+				toolResult.Meta = make(mcp.Meta)
+			}
+			toolResult.Meta[TruncatedMetaKey] = true
+
+			if call, ok := params.(*mcp.CallToolParamsFor[json.RawMessage]); ok {
+				s.logger.Warn("Truncated oversized tool output",
+					slog.String("tool", call.Name),
+					slog.Int64("size", size),
+					slog.Int64("limit", s.maxOutputSize),
+				)
+			}
+		}
+
+		return result, nil
+	}
+}
+
+// truncateContent cuts the text content of result to limit bytes in total,
+// dropping any text content past the limit and ending the last kept text with
+// a footer. It returns the original size of the text and whether it was cut.
+func truncateContent(result *mcp.CallToolResult, limit int64) (int64, bool) {
+	var size int64
+	for _, content := range result.Content {
+		if text, ok := content.(*mcp.TextContent); ok {
+			size += int64(len(text.Text))
+		}
+	}
+	if size <= limit {
+		return size, false
+	}
+
+	footer := fmt.Sprintf("\n... (output truncated: showing %d of %d bytes)", limit, size)
+	remaining := limit
+	kept := result.Content[:0]
+	var last *mcp.TextContent
+	for _, content := range result.Content {
+		text, ok := content.(*mcp.TextContent)
+		if !ok {
+			kept = append(kept, content)
+			continue
+		}
+		if remaining <= 0 {
+			continue
+		}
+		if int64(len(text.Text)) > remaining {
+			cut := int(remaining)
+			for cut > 0 && !utf8.RuneStart(text.Text[cut]) {
+				cut--
+			}
+			text.Text = text.Text[:cut]
+		}
+		remaining -= int64(len(text.Text))
+		kept = append(kept, text)
+		last = text
+	}
+	if last == nil {
+		last = &mcp.TextContent{}
+		kept = append(kept, last)
+	}
+	last.Text += footer
+	result.Content = kept
+
+	return size, true
+}
+
+// limitRequestBody caps the size of HTTP request bodies so that oversized
+// requests are refused while they are read, before they are decoded.
+func (s *Server) limitRequestBody(next http.Handler) http.Handler {
+	if s.maxArgSize <= 0 {
+		return next
+	}
+	return http.MaxBytesHandler(next, s.maxArgSize+requestEnvelopeSize)
+}
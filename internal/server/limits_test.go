@@ -0,0 +1,139 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/d-kuro/claude-code-mcp/internal/logging"
+)
+
+func TestOversizedArgumentsRejected(t *testing.T) {
+	srv, err := New(&Options{
+		Logger:          logging.NewLogger("error"),
+		MaxArgumentSize: 1024,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	session := connectTestClient(t, srv)
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	target := filepath.Join(dir, "large.txt")
+	_, err = session.CallTool(ctx, &mcp.CallToolParams{
+		Name:      "Write",
+		Arguments: map[string]any{"file_path": target, "content": strings.Repeat("x", 4096)},
+	})
+	if err == nil || !strings.Contains(err.Error(), "exceeds the limit of 1024 bytes") {
+		t.Fatalf("Expected a protocol error for oversized arguments, got: %v", err)
+	}
+	if _, err := os.Stat(target); !os.IsNotExist(err) {
+		t.Errorf("Expected Write handler not to run for oversized arguments")
+	}
+
+	small := filepath.Join(dir, "small.txt")
+	result, err := session.CallTool(ctx, &mcp.CallToolParams{
+		Name:      "Write",
+		Arguments: map[string]any{"file_path": small, "content": "data"},
+	})
+	if err != nil {
+		t.Fatalf("Write call failed: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("Expected small Write to succeed, got: %s", resultText(result))
+	}
+	if _, err := os.Stat(small); err != nil {
+		t.Errorf("Expected small Write to create the file: %v", err)
+	}
+}
+
+func TestOversizedHTTPRequestRejected(t *testing.T) {
+	srv, err := New(&Options{
+		Logger:          logging.NewLogger("error"),
+		MaxArgumentSize: 1024,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	httpServer := httptest.NewServer(srv.HTTPHandler())
+	defer httpServer.Close()
+
+	body := `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"Write","arguments":{"content":"` +
+		strings.Repeat("x", 1024+requestEnvelopeSize) + `"}}}`
+	req, err := http.NewRequest(http.MethodPost, httpServer.URL, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an oversized body, got %d", resp.StatusCode)
+	}
+}
+
+func TestOversizedOutputTruncated(t *testing.T) {
+	srv, err := New(&Options{
+		Logger:        logging.NewLogger("error"),
+		MaxOutputSize: 100,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	type echoArgs struct {
+		Size int `json:"size"`
+	}
+	mcp.AddTool(srv.mcpServer, &mcp.Tool{Name: "Echo"}, func(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[echoArgs]) (*mcp.CallToolResultFor[any], error) {
+		return &mcp.CallToolResultFor[any]{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: strings.Repeat("a", params.Arguments.Size)},
+				&mcp.TextContent{Text: strings.Repeat("b", params.Arguments.Size)},
+			},
+		}, nil
+	})
+
+	session := connectTestClient(t, srv)
+	ctx := context.Background()
+
+	result, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "Echo", Arguments: map[string]any{"size": 80}})
+	if err != nil {
+		t.Fatalf("Echo call failed: %v", err)
+	}
+	if len(result.Content) != 2 {
+		t.Fatalf("Expected both text contents to be kept, got %d", len(result.Content))
+	}
+	text := resultText(result)
+	if !strings.HasPrefix(text, strings.Repeat("a", 80)+strings.Repeat("b", 20)+"\n") {
+		t.Errorf("Expected the output to be cut at 100 bytes, got: %q", text)
+	}
+	if !strings.Contains(text, "output truncated: showing 100 of 160 bytes") {
+		t.Errorf("Expected a truncation footer, got: %q", text)
+	}
+	if result.Meta[TruncatedMetaKey] != true {
+		t.Errorf("Expected the truncated meta flag, got: %v", result.Meta)
+	}
+
+	result, err = session.CallTool(ctx, &mcp.CallToolParams{Name: "Echo", Arguments: map[string]any{"size": 10}})
+	if err != nil {
+		t.Fatalf("Echo call failed: %v", err)
+	}
+	if resultText(result) != strings.Repeat("a", 10)+strings.Repeat("b", 10) || result.Meta[TruncatedMetaKey] != nil {
+		t.Errorf("Expected small output to be unchanged, got: %q (meta %v)", resultText(result), result.Meta)
+	}
+}
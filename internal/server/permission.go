@@ -0,0 +1,69 @@
+// Package server provides permission hooks that approve mutating tool calls.
+package server
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/d-kuro/claude-code-mcp/internal/tools"
+)
+
+// PermissionRequest describes a mutating tool call awaiting permission.
+type PermissionRequest struct {
+	Tool      string
+	Category  string
+	Arguments json.RawMessage
+}
+
+// PermissionHook decides whether a mutating tool call may run, for example
+// by asking a human to confirm it. Allow returns false to deny the call,
+// with an optional reason that is shown to the client.
+type PermissionHook interface {
+	Allow(ctx context.Context, request PermissionRequest) (allowed bool, reason string)
+}
+
+// PermissionHookFunc adapts a function to the PermissionHook interface.
+type PermissionHookFunc func(ctx context.Context, request PermissionRequest) (bool, string)
+
+// Allow calls f.
+func (f PermissionHookFunc) Allow(ctx context.Context, request PermissionRequest) (bool, string) {
+	return f(ctx, request)
+}
+
+// permissionMiddleware asks the permission hook of the tool's category before
+// a mutating tool runs. Calls in categories without a hook, and calls of
+// tools that are not mutating, are allowed.
+func (s *Server) permissionMiddleware(next mcp.MethodHandler[*mcp.ServerSession]) mcp.MethodHandler[*mcp.ServerSession] {
+	return func(ctx context.Context, session *mcp.ServerSession, method string, params mcp.Params) (mcp.Result, error) {
+		if len(s.permissionHooks) == 0 || method != methodCallTool {
+			return next(ctx, session, method, params)
+		}
+
+		call, ok := params.(*mcp.CallToolParamsFor[json.RawMessage])
+		if !ok || !(IsMutatingTool(call.Name) || s.customTools[call.Name]) {
+			return next(ctx, session, method, params)
+		}
+
+		category := s.registry.ToolCategory(call.Name)
+		hook, ok := s.permissionHooks[category]
+		if !ok {
+			return next(ctx, session, method, params)
+		}
+
+		allowed, reason := hook.Allow(ctx, PermissionRequest{
+			Tool:      call.Name,
+			Category:  category,
+			Arguments: call.Arguments,
+		})
+		if !allowed {
+			if reason != "" {
+				return tools.ErrorResponsef("%s was denied by policy: %s", call.Name, reason), nil
+			}
+			return tools.ErrorResponsef("%s was denied by policy", call.Name), nil
+		}
+
+		return next(ctx, session, method, params)
+	}
+}
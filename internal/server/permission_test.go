@@ -0,0 +1,70 @@
+package server
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/d-kuro/claude-code-mcp/internal/logging"
+)
+
+func TestPermissionHookDeniesBash(t *testing.T) {
+	var asked atomic.Int32
+	denyBash := PermissionHookFunc(func(ctx context.Context, request PermissionRequest) (bool, string) {
+		asked.Add(1)
+		if request.Tool != "Bash" || request.Category != "system" {
+			t.Errorf("Unexpected permission request: %+v", request)
+		}
+		if !strings.Contains(string(request.Arguments), "echo hello") {
+			t.Errorf("Expected the call arguments in the request, got %s", request.Arguments)
+		}
+		return false, "commands need approval"
+	})
+
+	srv, err := New(&Options{
+		Logger:          logging.NewLogger("error"),
+		PermissionHooks: map[string]PermissionHook{"system": denyBash},
+		TempDir:         t.TempDir(),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	session := connectTestClient(t, srv)
+	ctx := context.Background()
+
+	result, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "Bash", Arguments: map[string]any{"command": "echo hello"}})
+	if err != nil {
+		t.Fatalf("Bash call failed: %v", err)
+	}
+	if !result.IsError || resultText(result) != "Error: Bash was denied by policy: commands need approval" {
+		t.Errorf("Expected Bash to be denied by policy, got: %s", resultText(result))
+	}
+
+	// Non-mutating tools in the same category are not subject to the hook
+	result, err = session.CallTool(ctx, &mcp.CallToolParams{Name: "ListExecutions", Arguments: map[string]any{}})
+	if err != nil {
+		t.Fatalf("ListExecutions call failed: %v", err)
+	}
+	if result.IsError {
+		t.Errorf("Expected ListExecutions to be allowed, got: %s", resultText(result))
+	}
+
+	// Categories without a hook are allowed
+	result, err = session.CallTool(ctx, &mcp.CallToolParams{Name: "TempDir", Arguments: map[string]any{}})
+	if err != nil {
+		t.Fatalf("TempDir call failed: %v", err)
+	}
+	if result.IsError {
+		t.Errorf("Expected TempDir to be allowed, got: %s", resultText(result))
+	}
+	if err := srv.Stop(ctx); err != nil {
+		t.Errorf("Stop failed: %v", err)
+	}
+
+	if got := asked.Load(); got != 1 {
+		t.Errorf("Expected the hook to be asked once, got %d", got)
+	}
+}
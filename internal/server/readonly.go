@@ -0,0 +1,56 @@
+// Package server provides a read-only mode that rejects mutating tool calls.
+package server
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/d-kuro/claude-code-mcp/internal/tools"
+)
+
+// MutatingTools lists the tools that can modify the filesystem or run
+// commands. In read-only mode these tools stay registered, but every call
+// fails with a read-only error. TodoWrite only changes in-memory session
+// state and is not considered mutating. Custom tools defined in a tool
+// manifest run commands and are always treated as mutating.
+var MutatingTools = []string{
+	"Write",
+	"Edit",
+	"MultiEdit",
+	"ReplaceInFiles",
+	"ApplyPatch",
+	"FormatFile",
+	"Symlink",
+	"NotebookEdit",
+	"TempFile",
+	"TempDir",
+	"Bash",
+}
+
+// IsMutatingTool reports whether the named tool is listed in MutatingTools.
+func IsMutatingTool(name string) bool {
+	for _, tool := range MutatingTools {
+		if tool == name {
+			return true
+		}
+	}
+	return false
+}
+
+// readOnlyMiddleware rejects calls to mutating tools when the server is read-only.
+func (s *Server) readOnlyMiddleware(next mcp.MethodHandler[*mcp.ServerSession]) mcp.MethodHandler[*mcp.ServerSession] {
+	return func(ctx context.Context, session *mcp.ServerSession, method string, params mcp.Params) (mcp.Result, error) {
+		if !s.readOnly || method != methodCallTool {
+			return next(ctx, session, method, params)
+		}
+
+		call, ok := params.(*mcp.CallToolParamsFor[json.RawMessage])
+		if ok && (IsMutatingTool(call.Name) || s.customTools[call.Name]) {
+			return tools.ErrorResponsef("%s is not available: server is in read-only mode", call.Name), nil
+		}
+
+		return next(ctx, session, method, params)
+	}
+}
@@ -0,0 +1,150 @@
+package server
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/d-kuro/claude-code-mcp/internal/logging"
+	"github.com/d-kuro/claude-code-mcp/internal/security"
+)
+
+func TestReadOnlyModeRejectsWrites(t *testing.T) {
+	srv, err := New(&Options{
+		Logger:   logging.NewLogger("error"),
+		ReadOnly: true,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	session := connectTestClient(t, srv)
+	ctx := context.Background()
+
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "existing.txt")
+	if err := os.WriteFile(existing, []byte("hello"), 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+
+	target := filepath.Join(dir, "new.txt")
+	result, err := session.CallTool(ctx, &mcp.CallToolParams{
+		Name:      "Write",
+		Arguments: map[string]any{"file_path": target, "content": "data"},
+	})
+	if err != nil {
+		t.Fatalf("Write call failed: %v", err)
+	}
+	if !result.IsError || !strings.Contains(resultText(result), "read-only mode") {
+		t.Errorf("Expected read-only error from Write, got: %s", resultText(result))
+	}
+	if _, err := os.Stat(target); !os.IsNotExist(err) {
+		t.Errorf("Expected Write to leave the filesystem unchanged")
+	}
+
+	result, err = session.CallTool(ctx, &mcp.CallToolParams{
+		Name:      "Read",
+		Arguments: map[string]any{"file_path": existing},
+	})
+	if err != nil {
+		t.Fatalf("Read call failed: %v", err)
+	}
+	if result.IsError || !strings.Contains(resultText(result), "hello") {
+		t.Errorf("Expected Read to succeed in read-only mode, got: %s", resultText(result))
+	}
+
+	if !srv.Capabilities().Features["read_only"] {
+		t.Errorf("Expected read_only feature to be reported")
+	}
+}
+
+func TestWritablePathsRestrictWrites(t *testing.T) {
+	dir := t.TempDir()
+	writableDir := filepath.Join(dir, "out")
+	if err := os.Mkdir(writableDir, 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+
+	readable := filepath.Join(dir, "source.txt")
+	if err := os.WriteFile(readable, []byte("hello"), 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+
+	srv, err := New(&Options{
+		Logger:    logging.NewLogger("error"),
+		Validator: security.NewDefaultValidator().WithWritablePaths([]string{writableDir}),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	session := connectTestClient(t, srv)
+	ctx := context.Background()
+
+	result, err := session.CallTool(ctx, &mcp.CallToolParams{
+		Name:      "Read",
+		Arguments: map[string]any{"file_path": readable},
+	})
+	if err != nil {
+		t.Fatalf("Read call failed: %v", err)
+	}
+	if result.IsError {
+		t.Errorf("Expected Read outside writable paths to succeed, got: %s", resultText(result))
+	}
+
+	result, err = session.CallTool(ctx, &mcp.CallToolParams{
+		Name:      "Edit",
+		Arguments: map[string]any{"file_path": readable, "old_string": "hello", "new_string": "bye"},
+	})
+	if err != nil {
+		t.Fatalf("Edit call failed: %v", err)
+	}
+	if !result.IsError || !strings.Contains(resultText(result), "path not writable") {
+		t.Errorf("Expected Edit outside writable paths to fail, got: %s", resultText(result))
+	}
+
+	result, err = session.CallTool(ctx, &mcp.CallToolParams{
+		Name:      "Write",
+		Arguments: map[string]any{"file_path": filepath.Join(writableDir, "result.txt"), "content": "data"},
+	})
+	if err != nil {
+		t.Fatalf("Write call failed: %v", err)
+	}
+	if result.IsError {
+		t.Errorf("Expected Write inside writable paths to succeed, got: %s", resultText(result))
+	}
+}
+
+func TestReadOnlyModeRejectsCustomTools(t *testing.T) {
+	manifest := filepath.Join(t.TempDir(), "tools.yaml")
+	content := "tools:\n  - name: Hello\n    description: Say hello.\n    command: echo hello\n"
+	if err := os.WriteFile(manifest, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write manifest: %v", err)
+	}
+
+	srv, err := New(&Options{
+		Logger:       logging.NewLogger("error"),
+		ReadOnly:     true,
+		ToolManifest: manifest,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	if category := srv.GetRegistry().ToolCategory("Hello"); category != "custom" {
+		t.Errorf("Expected custom category, got %q", category)
+	}
+
+	session := connectTestClient(t, srv)
+	result, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: "Hello"})
+	if err != nil {
+		t.Fatalf("Hello call failed: %v", err)
+	}
+	if !result.IsError || !strings.Contains(resultText(result), "read-only mode") {
+		t.Errorf("Expected read-only error from custom tool, got: %s", resultText(result))
+	}
+}
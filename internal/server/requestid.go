@@ -0,0 +1,91 @@
+// Package server provides request ids for correlating tool calls in logs.
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/d-kuro/claude-code-mcp/internal/tools"
+)
+
+// newRequestID generates a random id for a tool call.
+func newRequestID() string {
+	bytes := make([]byte, 8)
+	if _, err := rand.Read(bytes); err != nil {
+		return "req-" + time.Now().Format("150405.000000000")
+	}
+	return "req-" + hex.EncodeToString(bytes)
+}
+
+// requestIDMiddleware assigns a request id to every tool call. The id is
+// available to handlers through the context, attached to the log records of
+// the call, and returned in the result metadata. The outcome of each call is
+// also counted in the registry stats.
+func (s *Server) requestIDMiddleware(next mcp.MethodHandler[*mcp.ServerSession]) mcp.MethodHandler[*mcp.ServerSession] {
+	return func(ctx context.Context, session *mcp.ServerSession, method string, params mcp.Params) (mcp.Result, error) {
+		if method != methodCallTool {
+			return next(ctx, session, method, params)
+		}
+
+		call, ok := params.(*mcp.CallToolParamsFor[json.RawMessage])
+		if !ok {
+			return next(ctx, session, method, params)
+		}
+
+		requestID := newRequestID()
+		logger := s.logger.WithTool(call.Name).WithRequestID(requestID)
+		start := time.Now()
+
+		result, err := next(tools.WithRequestID(ctx, requestID), session, method, params)
+		duration := slog.Duration("duration", time.Since(start))
+		if err != nil {
+			s.recordInvocation(call.Name, true)
+			logger.Error("Tool call failed", duration, slog.String("error", err.Error()))
+			return result, err
+		}
+
+		toolResult, _ := result.(*mcp.CallToolResult)
+		s.recordInvocation(call.Name, toolResult != nil && toolResult.IsError)
+
+		if toolResult != nil {
+			if toolResult.Meta == nil {
+				toolResult.Meta = make(mcp.Meta)
+			}
+			toolResult.Meta[tools.RequestIDMetaKey] = requestID
+
+			if toolResult.IsError {
+				logger.Warn("Tool call returned an error", duration, slog.String("error", resultMessage(toolResult)))
+				return result, nil
+			}
+		}
+
+		logger.Debug("Tool call completed", duration)
+		return result, nil
+	}
+}
+
+// recordInvocation counts a tool call in the registry stats. Calls of unknown
+// tools are not counted, so clients cannot grow the stats without bound.
+func (s *Server) recordInvocation(toolName string, failed bool) {
+	if _, ok := s.toolSchemas[toolName]; ok {
+		s.registry.RecordInvocation(toolName, failed)
+	}
+}
+
+// resultMessage returns the text content of a tool result.
+func resultMessage(result *mcp.CallToolResult) string {
+	var text strings.Builder
+	for _, content := range result.Content {
+		if tc, ok := content.(*mcp.TextContent); ok {
+			text.WriteString(tc.Text)
+		}
+	}
+	return text.String()
+}
@@ -0,0 +1,118 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/d-kuro/claude-code-mcp/internal/logging"
+	"github.com/d-kuro/claude-code-mcp/internal/tools"
+)
+
+func TestRequestIDInLogsAndResultMeta(t *testing.T) {
+	var logs bytes.Buffer
+	logger := &logging.Logger{
+		Logger: slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug})),
+	}
+
+	srv, err := New(&Options{Logger: logger})
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	session := connectTestClient(t, srv)
+	ctx := context.Background()
+
+	missing := filepath.Join(t.TempDir(), "missing.txt")
+	first, err := session.CallTool(ctx, &mcp.CallToolParams{
+		Name:      "Read",
+		Arguments: map[string]any{"file_path": missing},
+	})
+	if err != nil {
+		t.Fatalf("Read call failed: %v", err)
+	}
+	if !first.IsError {
+		t.Fatalf("Expected Read of a missing file to fail, got: %s", resultText(first))
+	}
+
+	requestID, _ := first.Meta[tools.RequestIDMetaKey].(string)
+	if !strings.HasPrefix(requestID, "req-") {
+		t.Fatalf("Expected a request id in the result meta, got: %v", first.Meta)
+	}
+
+	var logLine string
+	for _, line := range strings.Split(logs.String(), "\n") {
+		if strings.Contains(line, "request_id="+requestID) {
+			logLine = line
+			break
+		}
+	}
+	if logLine == "" {
+		t.Fatalf("Expected request id %s in the logs, got:\n%s", requestID, logs.String())
+	}
+	if !strings.Contains(logLine, "tool=Read") || !strings.Contains(logLine, "Tool call returned an error") {
+		t.Errorf("Expected the log record to name the tool and the failure, got: %s", logLine)
+	}
+
+	second, err := session.CallTool(ctx, &mcp.CallToolParams{
+		Name:      "Read",
+		Arguments: map[string]any{"file_path": missing},
+	})
+	if err != nil {
+		t.Fatalf("Read call failed: %v", err)
+	}
+	if second.Meta[tools.RequestIDMetaKey] == requestID {
+		t.Errorf("Expected each call to get a new request id, got %s twice", requestID)
+	}
+}
+
+func TestRegistryStatsCountInvocations(t *testing.T) {
+	srv, err := New(&Options{Logger: logging.NewLogger("error")})
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	session := connectTestClient(t, srv)
+	ctx := context.Background()
+
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "notes.txt")
+	if err := os.WriteFile(existing, []byte("hello\n"), 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+
+	calls := []mcp.CallToolParams{
+		{Name: "Read", Arguments: map[string]any{"file_path": existing}},
+		{Name: "Read", Arguments: map[string]any{"file_path": existing}},
+		{Name: "Read", Arguments: map[string]any{"file_path": filepath.Join(dir, "missing.txt")}},
+		{Name: "LS", Arguments: map[string]any{"path": dir}},
+	}
+	for _, call := range calls {
+		if _, err := session.CallTool(ctx, &call); err != nil {
+			t.Fatalf("%s call failed: %v", call.Name, err)
+		}
+	}
+	if _, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "NoSuchTool"}); err == nil {
+		t.Fatal("Expected a call of an unknown tool to fail")
+	}
+
+	stats := srv.GetRegistry().Stats()
+	if stats.Invocations != 4 || stats.Errors != 1 {
+		t.Errorf("Expected 4 invocations and 1 error in total, got %+v", stats)
+	}
+	if got := stats.Tools["Read"]; got != (tools.ToolStats{Invocations: 3, Errors: 1}) {
+		t.Errorf("Unexpected Read stats: %+v", got)
+	}
+	if got := stats.Tools["LS"]; got != (tools.ToolStats{Invocations: 1}) {
+		t.Errorf("Unexpected LS stats: %+v", got)
+	}
+	if _, ok := stats.Tools["NoSuchTool"]; ok {
+		t.Error("Expected calls of unknown tools not to be counted")
+	}
+}
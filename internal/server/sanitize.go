@@ -0,0 +1,81 @@
+// Package server provides sanitization of tool output that could be mistaken for prompt control markers.
+package server
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// DefaultSanitizePatterns are the control markers neutralized when output
+// sanitization is enabled and no custom patterns are configured.
+var DefaultSanitizePatterns = []string{
+	`(?i)</?\s*system-reminder\b[^>]*>`,
+	`(?i)</?\s*(?:function_calls|function_results|tool_result|tool_use)\b[^>]*>`,
+	`(?i)</?\s*(?:human|assistant|user)\s*>`,
+}
+
+// OutputSanitizer neutralizes control markers in tool text output.
+type OutputSanitizer struct {
+	patterns []*regexp.Regexp
+}
+
+// NewOutputSanitizer compiles the given regular expressions. When patterns is
+// empty, DefaultSanitizePatterns are used.
+func NewOutputSanitizer(patterns []string) (*OutputSanitizer, error) {
+	if len(patterns) == 0 {
+		patterns = DefaultSanitizePatterns
+	}
+
+	s := &OutputSanitizer{}
+	for _, pattern := range patterns {
+		regex, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid sanitize pattern %q: %w", pattern, err)
+		}
+		s.patterns = append(s.patterns, regex)
+	}
+
+	return s, nil
+}
+
+// Sanitize returns text with every pattern match neutralized. Angle brackets
+// in a match are escaped so tags are shown literally; matches without angle
+// brackets are replaced with "[filtered]".
+func (s *OutputSanitizer) Sanitize(text string) string {
+	for _, regex := range s.patterns {
+		text = regex.ReplaceAllStringFunc(text, neutralizeMarker)
+	}
+	return text
+}
+
+// neutralizeMarker rewrites a single matched control marker.
+func neutralizeMarker(match string) string {
+	if !strings.ContainsAny(match, "<>") {
+		return "[filtered]"
+	}
+	return strings.NewReplacer("<", "&lt;", ">", "&gt;").Replace(match)
+}
+
+// sanitizeMiddleware applies the output sanitizer to the text content of tool results.
+func (s *Server) sanitizeMiddleware(next mcp.MethodHandler[*mcp.ServerSession]) mcp.MethodHandler[*mcp.ServerSession] {
+	return func(ctx context.Context, session *mcp.ServerSession, method string, params mcp.Params) (mcp.Result, error) {
+		result, err := next(ctx, session, method, params)
+		if err != nil || s.sanitizer == nil || method != methodCallTool {
+			return result, err
+		}
+
+		if toolResult, ok := result.(*mcp.CallToolResult); ok {
+			for _, content := range toolResult.Content {
+				if text, ok := content.(*mcp.TextContent); ok {
+					text.Text = s.sanitizer.Sanitize(text.Text)
+				}
+			}
+		}
+
+		return result, nil
+	}
+}
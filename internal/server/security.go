@@ -0,0 +1,45 @@
+// Package server reports the security configuration the server runs with.
+package server
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/d-kuro/claude-code-mcp/internal/security"
+)
+
+// SecurityStatus is the effective security configuration of the server, for
+// operators auditing that their options and config file were applied.
+type SecurityStatus struct {
+	ConfigFile    string          `json:"config_file,omitempty"`
+	ReadOnly      bool            `json:"read_only"`
+	DisabledTools []string        `json:"disabled_tools"`
+	Validator     security.Status `json:"validator"`
+}
+
+// SecurityStatus returns the active validator rules, read-only mode and
+// disabled tools, reflecting the last configuration reload. It fails when
+// the server runs with a custom validator that cannot report its rules.
+func (s *Server) SecurityStatus() (*SecurityStatus, error) {
+	active := s.config.Load()
+	validator, ok := active.validator.(*security.DefaultValidator)
+	if !ok {
+		return nil, fmt.Errorf("validator %T does not report its configuration", active.validator)
+	}
+
+	disabled := maps.Clone(s.disabledTools)
+	maps.Copy(disabled, active.disabledTools)
+
+	names := slices.Sorted(maps.Keys(disabled))
+	if names == nil {
+		names = []string{}
+	}
+
+	return &SecurityStatus{
+		ConfigFile:    s.configFile,
+		ReadOnly:      s.readOnly,
+		DisabledTools: names,
+		Validator:     validator.Status(),
+	}, nil
+}
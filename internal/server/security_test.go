@@ -0,0 +1,83 @@
+package server
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/d-kuro/claude-code-mcp/internal/logging"
+	"github.com/d-kuro/claude-code-mcp/internal/security"
+)
+
+func TestSecurityStatus(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	writeConfig(t, configPath, "blocked_commands: [terraform]\nallowed_paths: [/srv/project]\ndisabled_tools: [WebSearch]\nblock_network_commands: true\n")
+
+	srv, err := New(&Options{Logger: logging.NewLogger("error"), ConfigFile: configPath, ReadOnly: true, DisabledTools: []string{"Bash"}})
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	status, err := srv.SecurityStatus()
+	if err != nil {
+		t.Fatalf("SecurityStatus failed: %v", err)
+	}
+	if !slices.Contains(status.Validator.BlockedCommands, "terraform") || !slices.Contains(status.Validator.BlockedCommands, "sudo") {
+		t.Errorf("Expected configured and default blocked commands, got %v", status.Validator.BlockedCommands)
+	}
+	if !slices.Equal(status.Validator.AllowedPaths, []string{"/srv/project"}) {
+		t.Errorf("Expected configured allowed paths, got %v", status.Validator.AllowedPaths)
+	}
+	if !status.Validator.BlockNetworkCommands || !slices.Equal(status.Validator.NetworkCommands, security.DefaultNetworkCommands) {
+		t.Errorf("Expected network commands to be blocked with the default list, got %+v", status.Validator)
+	}
+	if !status.ReadOnly || status.ConfigFile != configPath {
+		t.Errorf("Expected read-only mode and the config file, got %+v", status)
+	}
+	if !slices.Equal(status.DisabledTools, []string{"Bash", "WebSearch"}) {
+		t.Errorf("Expected disabled tools from options and config, got %v", status.DisabledTools)
+	}
+
+	data, err := json.Marshal(status)
+	if err != nil {
+		t.Fatalf("Failed to encode status: %v", err)
+	}
+	if !strings.Contains(string(data), `"blocked_commands":[`) || !strings.Contains(string(data), `"terraform"`) {
+		t.Errorf("Expected blocked commands in the JSON output, got %s", data)
+	}
+
+	writeConfig(t, configPath, "blocked_commands: [kubectl]\n")
+	if err := srv.Reload(); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	status, err = srv.SecurityStatus()
+	if err != nil {
+		t.Fatalf("SecurityStatus failed: %v", err)
+	}
+	if !slices.Contains(status.Validator.BlockedCommands, "kubectl") || slices.Contains(status.Validator.BlockedCommands, "terraform") {
+		t.Errorf("Expected the reloaded blocked commands, got %v", status.Validator.BlockedCommands)
+	}
+}
+
+func TestSecurityStatusCustomValidator(t *testing.T) {
+	srv, err := New(&Options{Logger: logging.NewLogger("error"), Validator: allowAllValidator{}})
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	if _, err := srv.SecurityStatus(); err == nil || !strings.Contains(err.Error(), "does not report its configuration") {
+		t.Errorf("Expected an error for a custom validator, got %v", err)
+	}
+}
+
+// allowAllValidator is a custom validator that accepts everything.
+type allowAllValidator struct{}
+
+func (allowAllValidator) ValidatePath(string) error              { return nil }
+func (allowAllValidator) ValidateWritePath(string) error         { return nil }
+func (allowAllValidator) ValidateCommand(string, []string) error { return nil }
+func (allowAllValidator) ValidateURL(string) error               { return nil }
+func (allowAllValidator) SanitizePath(path string) (string, error) {
+	return path, nil
+}
@@ -3,17 +3,26 @@ package server
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"net/http"
+	"net/url"
 	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 
 	"github.com/d-kuro/claude-code-mcp/internal/collections"
+	"github.com/d-kuro/claude-code-mcp/internal/ignore"
 	"github.com/d-kuro/claude-code-mcp/internal/logging"
 	"github.com/d-kuro/claude-code-mcp/internal/security"
+	"github.com/d-kuro/claude-code-mcp/internal/storage"
 	"github.com/d-kuro/claude-code-mcp/internal/tools"
 	"github.com/d-kuro/claude-code-mcp/internal/tools/bash"
+	"github.com/d-kuro/claude-code-mcp/internal/tools/custom"
 	"github.com/d-kuro/claude-code-mcp/internal/tools/file"
 	"github.com/d-kuro/claude-code-mcp/internal/tools/notebook"
 	"github.com/d-kuro/claude-code-mcp/internal/tools/todo"
@@ -37,18 +46,275 @@ func (a *loggerAdapter) WithSession(sessionID string) tools.Logger {
 	return &loggerAdapter{Logger: a.Logger.WithSession(sessionID)}
 }
 
+// WithRequestID attaches a request id to the adapter's log records.
+func (a *loggerAdapter) WithRequestID(requestID string) tools.Logger {
+	return &loggerAdapter{Logger: a.Logger.WithRequestID(requestID)}
+}
+
 // Server represents the Claude Code MCP server.
 type Server struct {
-	mcpServer *mcp.Server
-	registry  *tools.Registry
-	logger    *logging.Logger
-	validator security.Validator
+	mcpServer  *mcp.Server
+	registry   *tools.Registry
+	executions *ExecutionRegistry
+	limiter    *ConcurrencyLimiter
+	sanitizer  *OutputSanitizer
+	logger     *logging.Logger
+	validator  security.Validator
+	config     atomic.Pointer[activeConfig]
+	configFile string
+
+	disabledTools     map[string]bool
+	readOnly          bool
+	responseEnvelope  bool
+	permissionHooks   map[string]PermissionHook
+	ignoreFile        string
+	fileMode          os.FileMode
+	dirMode           os.FileMode
+	backupFiles       bool
+	maxDepth          int
+	allowRoot         bool
+	defaultIgnores    []string
+	cleanEnv          bool
+	allowedEnv        []string
+	dangerousPatterns []string
+	allowedDangerous  []string
+	allowedBinaries   []string
+	sessionEnv        map[string]string
+	nonInteractive    map[string]string
+	stripANSI         bool
+	compress          bool
+	progress          time.Duration
+	historySize       int
+	maxSessions       int
+	maxCommands       int
+	commandQueue      time.Duration
+	allowedTypes      []string
+	blockedTypes      []string
+	proxy             *url.URL
+	webCacheDir       string
+	webCacheSize      int64
+	maxRedirects      int
+	stateStore        storage.StateStore
+	tempWorkspace     *tools.TempWorkspace
+	chunkedRead       int64
+	writeRetries      int
+	maxNotebookSize   int64
+	lsTimeout         time.Duration
+	lsMaxEntries      int
+	maxArgSize        int64
+	maxOutputSize     int64
+	manifest          *custom.Manifest
+	customTools       map[string]bool
+	toolNames         []string
+	toolSchemas       map[string]*mcp.Tool
+	httpEnabled       atomic.Bool
+	httpToken         string
 }
 
 // Options configures the server instance.
 type Options struct {
 	Logger    *logging.Logger
 	Validator security.Validator
+
+	// ConcurrencyLimits caps the number of concurrent tool calls per tool
+	// category (e.g. {"file": 4}). Categories without a limit are unrestricted.
+	ConcurrencyLimits map[string]int
+
+	// ConcurrencyQueueSize bounds how many calls may wait for a slot in a
+	// limited category before new calls fail fast. Zero uses
+	// DefaultConcurrencyQueueSize and a negative value disables the queue, so
+	// calls over the limit fail immediately.
+	ConcurrencyQueueSize int
+
+	// DisabledTools lists tool names that are not registered with the server.
+	DisabledTools []string
+
+	// ConfigFile is a YAML file with validator rules and disabled tools. See
+	// Config for the format. When set, its validator replaces Validator, and
+	// Reload re-reads it while the server runs.
+	ConfigFile string
+
+	// IgnoreFile is the project ignore file consulted by Glob, Grep, and LS.
+	// Defaults to .mcpignore in the working directory.
+	IgnoreFile string
+
+	// FileMode and DirMode set the permissions for files and directories
+	// created by the tools. Zero keeps the defaults (0666 and 0755 before the umask).
+	FileMode os.FileMode
+	DirMode  os.FileMode
+
+	// BackupFiles makes Edit and MultiEdit keep a ".backup" copy of the file
+	// while writing, instead of the default in-memory rollback.
+	BackupFiles bool
+
+	// MaxSearchDepth limits the directory depth of Glob and Grep searches.
+	// Zero leaves the depth unbounded.
+	MaxSearchDepth int
+
+	// AllowRootSearch permits Glob and Grep searches rooted at a filesystem
+	// root. The config file can also enable it.
+	AllowRootSearch bool
+
+	// DefaultIgnores names directories, such as ignore.DefaultDirectories,
+	// that Glob, Grep, and LS skip at any depth unless a call turns them
+	// off. Empty disables them.
+	DefaultIgnores []string
+
+	// CleanEnv runs commands with a minimal allow-listed environment instead of
+	// inheriting the server process environment.
+	CleanEnv bool
+
+	// AllowedEnvVars, when non-empty, lists the only process environment
+	// variables passed to Bash sessions, replacing the CleanEnv allow-list.
+	AllowedEnvVars []string
+
+	// DangerousPatterns are case-insensitive regular expressions for Bash and
+	// custom tool commands that are rejected in addition to the built-in
+	// dangerous patterns, such as "rm -rf /". Commands matching
+	// AllowedDangerousPatterns are accepted even if they match one. The
+	// config file can add patterns to both lists.
+	DangerousPatterns        []string
+	AllowedDangerousPatterns []string
+
+	// AllowedBinaries, when non-empty, lists the only binaries that Bash and
+	// custom tool commands may run. Every command of a pipeline or list is
+	// checked by the base name of its binary, and names may use glob
+	// patterns such as "python3*". The config file can add binaries.
+	AllowedBinaries []string
+
+	// SessionEnv holds variables set in every new Bash session, such as
+	// CI=1 or NO_COLOR=1 for non-interactive output.
+	SessionEnv map[string]string
+
+	// NonInteractiveEnv holds variables set for commands run by Bash and
+	// custom tools to keep them from producing terminal output, typically
+	// tools.NonInteractiveEnvVars. Nil leaves the environment unchanged.
+	NonInteractiveEnv map[string]string
+
+	// StripANSI removes ANSI escape sequences from Bash and custom tool output.
+	StripANSI bool
+
+	// HTTPAuthToken is the bearer token HTTP transport clients must send in
+	// the Authorization header. Without it, ListenAndServe only accepts
+	// loopback addresses. It has no effect on stdio.
+	HTTPAuthToken string
+
+	// CompressResponses gzip-compresses large HTTP transport responses for
+	// clients that send Accept-Encoding: gzip. It has no effect on stdio.
+	CompressResponses bool
+
+	// ProgressInterval makes Bash send a progress notification at this interval
+	// while a command runs. Zero disables the notifications.
+	ProgressInterval time.Duration
+
+	// HistorySize is the number of commands kept in each Bash session history.
+	// Zero keeps bash.DefaultHistorySize and a negative value disables it.
+	HistorySize int
+
+	// MaxSessions limits the number of live Bash sessions. Zero means no limit.
+	MaxSessions int
+
+	// MaxCommands limits how many Bash commands run at once. A command over
+	// the limit waits up to CommandQueueTimeout for another to finish before
+	// failing. Zero means no limit.
+	MaxCommands         int
+	CommandQueueTimeout time.Duration
+
+	// AllowedContentTypes, when non-empty, limits the media types WebFetch
+	// returns. BlockedContentTypes are always rejected. Entries may use a
+	// wildcard subtype such as "image/*".
+	AllowedContentTypes []string
+	BlockedContentTypes []string
+
+	// Proxy is the URL of an HTTP proxy for WebFetch and WebSearch requests.
+	// When empty, HTTP_PROXY, HTTPS_PROXY and NO_PROXY are honored.
+	Proxy string
+
+	// WebCacheDir enables a disk cache of WebFetch results in this directory.
+	// Entries expire after 15 minutes, so the cache can be shared and survives
+	// restarts. WebCacheMaxSize caps the directory size in bytes; zero uses
+	// the default of 50 MiB.
+	WebCacheDir     string
+	WebCacheMaxSize int64
+
+	// MaxRedirects caps the redirects WebFetch follows before fetching a
+	// page. Every redirect target is checked by the validator. Zero uses the
+	// default of 5.
+	MaxRedirects int
+
+	// StateStore keeps tool state such as todo lists, and WebFetch results
+	// when WebCacheDir is empty. When nil, that state is kept in memory and
+	// WebFetch results are not cached.
+	StateStore storage.StateStore
+
+	// ChunkedReadThreshold is the file size in bytes above which Read reads
+	// files in chunks, keeping memory bounded even for very long lines, and
+	// notes this in its output. Zero uses the default of 50 MiB.
+	ChunkedReadThreshold int64
+
+	// WriteRetries is how many times Write and the edit tools retry a write
+	// that fails with a transient filesystem error such as EINTR or ESTALE.
+	// Zero uses the default of 2 and a negative value disables retries.
+	WriteRetries int
+
+	// MaxNotebookSize is the largest notebook file in bytes that NotebookRead
+	// and NotebookEdit load; larger notebooks are rejected. Zero uses the
+	// default of 100 MiB.
+	MaxNotebookSize int64
+
+	// LSTimeout bounds the ls command run by LS. Zero uses the default of
+	// 10 seconds.
+	LSTimeout time.Duration
+
+	// LSMaxEntries is the number of entries LS lists per directory; further
+	// entries are summarized as "... (N more entries)". Zero uses the
+	// default of 5000.
+	LSMaxEntries int
+
+	// TempDir is the root under which TempFile and TempDir create scratch
+	// files and directories. It must be allowed by the validator. Entries
+	// created there are removed by Stop. Defaults to a claude-code-mcp
+	// directory under the system temp directory.
+	TempDir string
+
+	// MaxArgumentSize limits the encoded size, in bytes, of the arguments of a
+	// single tool call. Larger calls are rejected before any tool runs. Over
+	// HTTP, oversized request bodies are also refused while they are read;
+	// over stdio, the whole message is read before it is checked. Zero uses
+	// DefaultMaxArgumentSize and a negative value disables the limit.
+	MaxArgumentSize int64
+
+	// MaxOutputSize limits the size, in bytes, of the text returned by a
+	// single tool call. Longer output is truncated with a footer and the
+	// "truncated" result metadata flag. Zero uses DefaultMaxOutputSize and a
+	// negative value disables the limit.
+	MaxOutputSize int64
+
+	// ToolManifest is a YAML file defining custom command-backed tools to
+	// register alongside the built-in ones. See custom.Manifest for the format.
+	ToolManifest string
+
+	// SanitizeOutput neutralizes control markers such as <system-reminder>
+	// tags in tool text output before it is returned to the client.
+	SanitizeOutput bool
+
+	// ResponseEnvelope wraps the content of every tool result in a versioned
+	// ResponseEnvelope. By default results are returned as the tools produce them.
+	ResponseEnvelope bool
+
+	// SanitizePatterns overrides DefaultSanitizePatterns with custom regular
+	// expressions. Only used when SanitizeOutput is set.
+	SanitizePatterns []string
+
+	// PermissionHooks are consulted before a mutating tool, such as Write,
+	// Edit, Bash or a custom tool, runs. The hook registered for the tool's
+	// category (e.g. "file" or "system") decides whether the call may run.
+	// Categories without a hook are allowed, as are all calls by default.
+	PermissionHooks map[string]PermissionHook
+
+	// ReadOnly rejects calls to the tools listed in MutatingTools so that
+	// the server cannot modify the filesystem or run commands.
+	ReadOnly bool
 }
 
 // New creates a new Claude Code MCP server with the given options.
@@ -61,10 +327,24 @@ func New(opts *Options) (*Server, error) {
 		opts.Logger = logging.NewLogger(logLevel)
 	}
 
+	config := &Config{}
+	if opts.ConfigFile != "" {
+		loaded, err := LoadConfig(opts.ConfigFile)
+		if err != nil {
+			return nil, err
+		}
+		config = loaded
+		opts.Validator = config.Validator()
+	}
+
 	if opts.Validator == nil {
 		opts.Validator = security.NewDefaultValidator()
 	}
 
+	if opts.ConcurrencyQueueSize == 0 {
+		opts.ConcurrencyQueueSize = DefaultConcurrencyQueueSize
+	}
+
 	toolCtx := &tools.Context{
 		Logger:    &loggerAdapter{Logger: opts.Logger},
 		Validator: opts.Validator,
@@ -78,12 +358,122 @@ func New(opts *Options) (*Server, error) {
 	}, nil)
 
 	server := &Server{
-		mcpServer: mcpServer,
-		registry:  registry,
-		logger:    opts.Logger,
-		validator: opts.Validator,
+		mcpServer:  mcpServer,
+		registry:   registry,
+		executions: NewExecutionRegistry(),
+		limiter:    NewConcurrencyLimiter(opts.ConcurrencyLimits, opts.ConcurrencyQueueSize),
+		logger:     opts.Logger,
+		configFile: opts.ConfigFile,
+
+		disabledTools:     make(map[string]bool),
+		readOnly:          opts.ReadOnly,
+		responseEnvelope:  opts.ResponseEnvelope,
+		permissionHooks:   opts.PermissionHooks,
+		ignoreFile:        opts.IgnoreFile,
+		fileMode:          opts.FileMode,
+		dirMode:           opts.DirMode,
+		backupFiles:       opts.BackupFiles,
+		maxDepth:          opts.MaxSearchDepth,
+		allowRoot:         opts.AllowRootSearch || config.AllowRootSearch,
+		defaultIgnores:    opts.DefaultIgnores,
+		cleanEnv:          opts.CleanEnv,
+		allowedEnv:        opts.AllowedEnvVars,
+		dangerousPatterns: opts.DangerousPatterns,
+		allowedDangerous:  opts.AllowedDangerousPatterns,
+		allowedBinaries:   opts.AllowedBinaries,
+		sessionEnv:        opts.SessionEnv,
+		nonInteractive:    opts.NonInteractiveEnv,
+		stripANSI:         opts.StripANSI,
+		compress:          opts.CompressResponses,
+		httpToken:         opts.HTTPAuthToken,
+		progress:          opts.ProgressInterval,
+		historySize:       opts.HistorySize,
+		maxSessions:       opts.MaxSessions,
+		maxCommands:       opts.MaxCommands,
+		commandQueue:      opts.CommandQueueTimeout,
+		allowedTypes:      opts.AllowedContentTypes,
+		blockedTypes:      opts.BlockedContentTypes,
+		webCacheDir:       opts.WebCacheDir,
+		webCacheSize:      opts.WebCacheMaxSize,
+		maxRedirects:      opts.MaxRedirects,
+		stateStore:        opts.StateStore,
+		tempWorkspace:     tools.NewTempWorkspace(opts.TempDir),
+		chunkedRead:       opts.ChunkedReadThreshold,
+		writeRetries:      opts.WriteRetries,
+		maxNotebookSize:   opts.MaxNotebookSize,
+		lsTimeout:         opts.LSTimeout,
+		lsMaxEntries:      opts.LSMaxEntries,
+		maxArgSize:        opts.MaxArgumentSize,
+		maxOutputSize:     opts.MaxOutputSize,
+		customTools:       make(map[string]bool),
+		toolSchemas:       make(map[string]*mcp.Tool),
+	}
+
+	active := newActiveConfig(config)
+	active.validator = opts.Validator
+	commandRules, err := server.newCommandRules(config)
+	if err != nil {
+		return nil, err
+	}
+	active.commandRules = commandRules
+	server.config.Store(active)
+	server.validator = reloadableValidator{config: &server.config}
+
+	if server.maxArgSize == 0 {
+		server.maxArgSize = DefaultMaxArgumentSize
+	}
+	if server.maxOutputSize == 0 {
+		server.maxOutputSize = DefaultMaxOutputSize
+	}
+
+	if opts.Proxy != "" {
+		proxy, err := url.Parse(opts.Proxy)
+		if err != nil || proxy.Scheme == "" || proxy.Host == "" {
+			return nil, fmt.Errorf("invalid proxy URL %q", opts.Proxy)
+		}
+		server.proxy = proxy
+	}
+
+	if opts.ToolManifest != "" {
+		manifest, err := custom.LoadManifest(opts.ToolManifest)
+		if err != nil {
+			return nil, err
+		}
+		server.manifest = manifest
+	}
+
+	if server.ignoreFile == "" {
+		if cwd, err := os.Getwd(); err == nil {
+			server.ignoreFile = filepath.Join(cwd, ignore.FileName)
+		}
 	}
 
+	if opts.SanitizeOutput {
+		sanitizer, err := NewOutputSanitizer(opts.SanitizePatterns)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create output sanitizer: %w", err)
+		}
+		server.sanitizer = sanitizer
+	}
+
+	for _, name := range opts.DisabledTools {
+		server.disabledTools[name] = true
+	}
+
+	mcpServer.AddReceivingMiddleware(
+		server.capabilitiesMiddleware,
+		server.envelopeMiddleware,
+		server.requestIDMiddleware,
+		server.argumentSizeMiddleware,
+		server.outputSizeMiddleware,
+		server.sanitizeMiddleware,
+		server.readOnlyMiddleware,
+		server.disabledToolsMiddleware,
+		server.permissionMiddleware,
+		server.executionMiddleware,
+		server.concurrencyMiddleware,
+	)
+
 	if err := server.registerTools(); err != nil {
 		return nil, fmt.Errorf("failed to register tools: %w", err)
 	}
@@ -105,20 +495,42 @@ func (s *Server) Start(ctx context.Context) error {
 	return nil
 }
 
-// Stop stops the MCP server gracefully.
+// Stop stops the MCP server gracefully. New tool calls are rejected, and
+// in-flight calls are given until ctx is done to finish before they are
+// cancelled. Files and directories created by TempFile and TempDir are
+// removed afterwards. The context given to Serve or ListenAndServe should be
+// cancelled only after Stop returns, so the drained calls can send results.
 func (s *Server) Stop(ctx context.Context) error {
-	s.logger.Info("Stopping Claude Code MCP server")
-
-	// TODO: Add cleanup logic for any running operations
-	// For now, we just log the stop event
+	s.logger.Info("Stopping Claude Code MCP server",
+		slog.Int("in_flight", s.executions.Count()),
+	)
 
-	select {
-	case <-ctx.Done():
-		s.logger.Warn("Server stop timed out")
+	drained, cancelled := s.executions.Drain(ctx)
+	s.cleanupTempWorkspace()
+	if cancelled > 0 {
+		s.logger.Warn("Server stop timed out",
+			slog.Int("drained", drained),
+			slog.Int("cancelled", cancelled),
+		)
 		return ctx.Err()
-	default:
-		s.logger.Info("Server stopped successfully")
-		return nil
+	}
+
+	s.logger.Info("Server stopped successfully", slog.Int("drained", drained))
+	return nil
+}
+
+// cleanupTempWorkspace removes the entries created in the temp workspace.
+func (s *Server) cleanupTempWorkspace() {
+	removed, err := s.tempWorkspace.Cleanup()
+	if err != nil {
+		s.logger.Warn("Failed to remove temporary files",
+			slog.Int("removed", removed),
+			slog.Any("error", err),
+		)
+		return
+	}
+	if removed > 0 {
+		s.logger.Debug("Removed temporary files", slog.Int("removed", removed))
 	}
 }
 
@@ -127,6 +539,11 @@ func (s *Server) GetRegistry() *tools.Registry {
 	return s.registry
 }
 
+// GetExecutions returns the registry of in-flight tool executions.
+func (s *Server) GetExecutions() *ExecutionRegistry {
+	return s.executions
+}
+
 // registerTools registers all Claude Code tools with the server.
 func (s *Server) registerTools() error {
 	s.logger.Debug("Registering tools with MCP server")
@@ -135,6 +552,22 @@ func (s *Server) registerTools() error {
 		Logger:    &loggerAdapter{Logger: s.logger},
 		Validator: s.validator,
 	}
+	if s.ignoreFile != "" {
+		toolCtx.WithIgnoreFile(s.ignoreFile)
+	}
+	toolCtx.WithDefaultFileMode(s.fileMode).WithDefaultDirMode(s.dirMode).WithBackupFiles(s.backupFiles)
+	toolCtx.WithMaxSearchDepth(s.maxDepth).WithRootSearch(s.allowRoot).WithCleanEnv(s.cleanEnv)
+	toolCtx.WithDefaultIgnores(s.defaultIgnores)
+	toolCtx.WithAllowedEnvVars(s.allowedEnv).WithDefaultSessionEnv(s.sessionEnv)
+	toolCtx.WithCommandRules(reloadableCommandRules{config: &s.config})
+	toolCtx.WithNonInteractiveEnv(s.nonInteractive).WithStripANSI(s.stripANSI)
+	toolCtx.WithProgressInterval(s.progress).WithHistorySize(s.historySize)
+	toolCtx.WithBashLimits(s.maxSessions, s.maxCommands, s.commandQueue)
+	toolCtx.WithAllowedContentTypes(s.allowedTypes).WithBlockedContentTypes(s.blockedTypes).WithProxy(s.proxy)
+	toolCtx.WithWebCache(s.webCacheDir, s.webCacheSize).WithStateStore(s.stateStore)
+	toolCtx.WithMaxRedirects(s.maxRedirects).WithTempWorkspace(s.tempWorkspace)
+	toolCtx.WithChunkedReadThreshold(s.chunkedRead).WithWriteRetries(s.writeRetries)
+	toolCtx.WithMaxNotebookSize(s.maxNotebookSize).WithLSLimits(s.lsTimeout, s.lsMaxEntries)
 
 	// Create file operation tools
 	fileTools := file.CreateFileTools(toolCtx)
@@ -151,6 +584,15 @@ func (s *Server) registerTools() error {
 	// Create todo management tools
 	todoTools := todo.CreateTodoTools(toolCtx)
 
+	// Create execution management tools
+	executionTools := createExecutionTools(s.executions)
+
+	// Create user-defined tools from the manifest
+	customTools, err := s.createCustomTools(toolCtx)
+	if err != nil {
+		return err
+	}
+
 	// Combine all tools
 	allTools := collections.Concat(
 		fileTools,
@@ -158,20 +600,30 @@ func (s *Server) registerTools() error {
 		notebookTools,
 		webTools,
 		todoTools,
+		executionTools,
+		customTools,
 	)
 
 	// Register tools with MCP server
 	var toolNames []string
 	for _, tool := range allTools {
+		if s.disabledTools[tool.Tool.Name] {
+			s.logger.Debug("Skipping disabled tool", "name", tool.Tool.Name)
+			continue
+		}
+
 		// Use the RegisterFunc to register the tool with proper type inference
 		tool.RegisterFunc(s.mcpServer)
 		toolNames = append(toolNames, tool.Tool.Name)
+		s.toolSchemas[tool.Tool.Name] = tool.Tool
 
 		s.logger.Debug("Registered tool", "name", tool.Tool.Name)
 	}
 
+	s.toolNames = toolNames
+
 	s.logger.Info("Successfully registered tools",
-		slog.Int("count", len(allTools)),
+		slog.Int("count", len(toolNames)),
 		slog.Any("tools", toolNames),
 	)
 
@@ -180,6 +632,32 @@ func (s *Server) registerTools() error {
 	return nil
 }
 
+// createCustomTools creates the tools defined in the custom tools manifest.
+// Custom tools run commands, so they are treated as mutating tools.
+func (s *Server) createCustomTools(toolCtx *tools.Context) ([]*tools.ServerTool, error) {
+	if s.manifest == nil {
+		return nil, nil
+	}
+
+	for _, spec := range s.manifest.Tools {
+		if category := s.registry.ToolCategory(spec.Name); category != "unknown" {
+			return nil, fmt.Errorf("custom tool %s conflicts with a built-in tool", spec.Name)
+		}
+	}
+
+	customTools, err := custom.CreateCustomTools(toolCtx, s.manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, tool := range customTools {
+		s.registry.SetToolCategory(tool.Tool.Name, custom.Category)
+		s.customTools[tool.Tool.Name] = true
+	}
+
+	return customTools, nil
+}
+
 // Serve runs the MCP server with the specified transport.
 // It connects the MCP server to the transport and waits for either
 // the session to complete or the context to be cancelled.
@@ -216,3 +694,62 @@ func (s *Server) Serve(ctx context.Context, transport mcp.Transport) error {
 		return ctx.Err()
 	}
 }
+
+// HTTPHandler returns an HTTP handler that serves the MCP streamable HTTP
+// transport at the root path and the server capabilities at /capabilities.
+// With CompressResponses, large responses are gzip-compressed for clients
+// that accept it. With HTTPAuthToken, every request must carry the token.
+func (s *Server) HTTPHandler() http.Handler {
+	mcpHandler := mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server {
+		return s.mcpServer
+	}, nil)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/capabilities", s.handleCapabilities)
+	mux.Handle("/", s.limitRequestBody(mcpHandler))
+	return s.requireBearerToken(s.compressResponses(mux))
+}
+
+// ListenAndServe runs the MCP server over HTTP on the given address until
+// the context is cancelled. An address without a host, such as ":8080",
+// listens on DefaultHTTPHost, and a non-loopback address is refused unless
+// HTTPAuthToken is set.
+func (s *Server) ListenAndServe(ctx context.Context, addr string) error {
+	addr, err := httpListenAddr(addr, s.httpToken != "")
+	if err != nil {
+		return err
+	}
+	s.httpEnabled.Store(true)
+
+	httpServer := &http.Server{
+		Addr:              addr,
+		Handler:           s.HTTPHandler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	s.logger.Info("Starting MCP server transport",
+		slog.String("transport", "http"),
+		slog.String("addr", addr),
+	)
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- httpServer.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return fmt.Errorf("HTTP server failed: %w", err)
+	case <-ctx.Done():
+		s.logger.Info("MCP server shutting down due to context cancellation")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := httpServer.Shutdown(shutdownCtx); err != nil {
+			return fmt.Errorf("failed to shut down HTTP server: %w", err)
+		}
+		return ctx.Err()
+	}
+}
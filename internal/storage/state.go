@@ -0,0 +1,252 @@
+// Package storage provides key-value stores for tool state.
+package storage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrStateNotFound is returned when a key is missing or has expired.
+var ErrStateNotFound = errors.New("state not found")
+
+// StateStore stores tool state, such as cached fetches and todo lists, as
+// byte values under string keys. Implementations must be safe for concurrent use.
+type StateStore interface {
+	// Get returns the value stored under key, or ErrStateNotFound when the
+	// key is missing or has expired.
+	Get(key string) ([]byte, error)
+
+	// Set stores value under key. A positive ttl makes the value expire
+	// after that duration; zero or a negative ttl keeps it until deleted.
+	Set(key string, value []byte, ttl time.Duration) error
+
+	// Delete removes key. Deleting a missing key is not an error.
+	Delete(key string) error
+}
+
+// stateEntry is a stored value with its expiry time.
+type stateEntry struct {
+	Key       string    `json:"key"`
+	Value     []byte    `json:"value"`
+	ExpiresAt time.Time `json:"expires_at,omitempty"`
+}
+
+// expired reports whether the entry has expired at now.
+func (e stateEntry) expired(now time.Time) bool {
+	return !e.ExpiresAt.IsZero() && !now.Before(e.ExpiresAt)
+}
+
+// newStateEntry copies value into an entry that expires after ttl.
+func newStateEntry(key string, value []byte, ttl time.Duration, now time.Time) stateEntry {
+	entry := stateEntry{Key: key, Value: append([]byte(nil), value...)}
+	if ttl > 0 {
+		entry.ExpiresAt = now.Add(ttl)
+	}
+	return entry
+}
+
+// MemoryStateStore keeps state in memory for the lifetime of the process.
+type MemoryStateStore struct {
+	mu      sync.Mutex
+	entries map[string]stateEntry
+	now     func() time.Time
+}
+
+// NewMemoryStateStore creates an empty in-memory state store.
+func NewMemoryStateStore() *MemoryStateStore {
+	return &MemoryStateStore{entries: make(map[string]stateEntry), now: time.Now}
+}
+
+// Get returns a copy of the value stored under key.
+func (s *MemoryStateStore) Get(key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[key]
+	if !ok {
+		return nil, ErrStateNotFound
+	}
+	if entry.expired(s.now()) {
+		delete(s.entries, key)
+		return nil, ErrStateNotFound
+	}
+	return append([]byte(nil), entry.Value...), nil
+}
+
+// Set stores a copy of value under key.
+func (s *MemoryStateStore) Set(key string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries[key] = newStateEntry(key, value, ttl, s.now())
+	return nil
+}
+
+// Delete removes key.
+func (s *MemoryStateStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, key)
+	return nil
+}
+
+// stateFileExt is the extension of the files written by FileStateStore.
+const stateFileExt = ".json"
+
+// FileStateStore keeps each key in its own file in a directory, so state
+// survives restarts and can be shared between processes. Expired entries are
+// removed when they are read, and when a size cap is set the oldest files are
+// removed once the directory grows past it.
+type FileStateStore struct {
+	dir     string
+	maxSize int64
+	mu      sync.Mutex
+	now     func() time.Time
+}
+
+// NewFileStateStore creates a state store in dir. The directory is created
+// on the first write.
+func NewFileStateStore(dir string) *FileStateStore {
+	return &FileStateStore{dir: dir, now: time.Now}
+}
+
+// WithMaxSize caps the total size of the store's files in bytes. Zero or a
+// negative size leaves it unbounded.
+func (s *FileStateStore) WithMaxSize(maxSize int64) *FileStateStore {
+	s.maxSize = maxSize
+	return s
+}
+
+// path returns the file that holds key. Keys are hashed so that any string
+// can be used as a key.
+func (s *FileStateStore) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(s.dir, hex.EncodeToString(sum[:])+stateFileExt)
+}
+
+// Get returns the value stored under key, removing the file if it has expired.
+func (s *FileStateStore) Get(key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	path := s.path(key)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrStateNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state: %w", err)
+	}
+
+	var entry stateEntry
+	if err := json.Unmarshal(data, &entry); err != nil || entry.Key != key {
+		_ = os.Remove(path)
+		return nil, ErrStateNotFound
+	}
+	if entry.expired(s.now()) {
+		_ = os.Remove(path)
+		return nil, ErrStateNotFound
+	}
+	return entry.Value, nil
+}
+
+// Set writes value under key and then enforces the size cap.
+func (s *FileStateStore) Set(key string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := json.Marshal(newStateEntry(key, value, ttl, s.now()))
+	if err != nil {
+		return fmt.Errorf("failed to encode state: %w", err)
+	}
+
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	// Write to a temporary file and rename it so that readers, including
+	// other processes sharing the directory, never see a partial entry
+	tmp, err := os.CreateTemp(s.dir, ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to write state: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write state: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write state: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path(key)); err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write state: %w", err)
+	}
+
+	s.prune()
+	return nil
+}
+
+// Delete removes the file that holds key.
+func (s *FileStateStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.Remove(s.path(key)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete state: %w", err)
+	}
+	return nil
+}
+
+// prune removes the least recently written files until the directory fits in maxSize.
+func (s *FileStateStore) prune() {
+	if s.maxSize <= 0 {
+		return
+	}
+
+	dirEntries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return
+	}
+
+	type stateFile struct {
+		path    string
+		size    int64
+		modTime time.Time
+	}
+
+	var files []stateFile
+	var total int64
+	for _, dirEntry := range dirEntries {
+		if dirEntry.IsDir() || !strings.HasSuffix(dirEntry.Name(), stateFileExt) {
+			continue
+		}
+		info, err := dirEntry.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, stateFile{path: filepath.Join(s.dir, dirEntry.Name()), size: info.Size(), modTime: info.ModTime()})
+		total += info.Size()
+	}
+
+	sort.Slice(files, func(i, j int) bool { return files[i].modTime.Before(files[j].modTime) })
+	for _, file := range files {
+		if total <= s.maxSize {
+			break
+		}
+		if os.Remove(file.path) == nil {
+			total -= file.size
+		}
+	}
+}
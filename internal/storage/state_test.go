@@ -0,0 +1,166 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// testClock is a settable time source for expiry tests.
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	return c.now
+}
+
+// testStateStore runs the behavior every StateStore must have against the
+// stores created by newStore, which must read the time from now.
+func testStateStore(t *testing.T, newStore func(t *testing.T, now func() time.Time) StateStore) {
+	t.Run("set and get", func(t *testing.T) {
+		store := newStore(t, time.Now)
+
+		if err := store.Set("a", []byte("one"), 0); err != nil {
+			t.Fatalf("Set failed: %v", err)
+		}
+		assertStateValue(t, store, "a", "one")
+
+		if err := store.Set("a", []byte("two"), 0); err != nil {
+			t.Fatalf("Set failed: %v", err)
+		}
+		assertStateValue(t, store, "a", "two")
+	})
+
+	t.Run("missing key", func(t *testing.T) {
+		store := newStore(t, time.Now)
+
+		if _, err := store.Get("missing"); !errors.Is(err, ErrStateNotFound) {
+			t.Errorf("Expected ErrStateNotFound, got: %v", err)
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		store := newStore(t, time.Now)
+
+		if err := store.Set("a", []byte("one"), 0); err != nil {
+			t.Fatalf("Set failed: %v", err)
+		}
+		if err := store.Delete("a"); err != nil {
+			t.Fatalf("Delete failed: %v", err)
+		}
+		if _, err := store.Get("a"); !errors.Is(err, ErrStateNotFound) {
+			t.Errorf("Expected ErrStateNotFound after delete, got: %v", err)
+		}
+		if err := store.Delete("a"); err != nil {
+			t.Errorf("Expected deleting a missing key to succeed, got: %v", err)
+		}
+	})
+
+	t.Run("values are copied", func(t *testing.T) {
+		store := newStore(t, time.Now)
+
+		value := []byte("one")
+		if err := store.Set("a", value, 0); err != nil {
+			t.Fatalf("Set failed: %v", err)
+		}
+		value[0] = 'X'
+
+		got, err := store.Get("a")
+		if err != nil {
+			t.Fatalf("Get failed: %v", err)
+		}
+		got[0] = 'Y'
+		assertStateValue(t, store, "a", "one")
+	})
+
+	t.Run("ttl", func(t *testing.T) {
+		clock := &testClock{now: time.Now()}
+		store := newStore(t, clock.Now)
+
+		if err := store.Set("short", []byte("one"), time.Minute); err != nil {
+			t.Fatalf("Set failed: %v", err)
+		}
+		if err := store.Set("forever", []byte("two"), 0); err != nil {
+			t.Fatalf("Set failed: %v", err)
+		}
+
+		clock.now = clock.now.Add(59 * time.Second)
+		assertStateValue(t, store, "short", "one")
+
+		clock.now = clock.now.Add(time.Second)
+		if _, err := store.Get("short"); !errors.Is(err, ErrStateNotFound) {
+			t.Errorf("Expected expired key to be missing, got: %v", err)
+		}
+
+		clock.now = clock.now.Add(24 * time.Hour)
+		assertStateValue(t, store, "forever", "two")
+	})
+}
+
+func assertStateValue(t *testing.T, store StateStore, key, want string) {
+	t.Helper()
+
+	got, err := store.Get(key)
+	if err != nil {
+		t.Fatalf("Get(%q) failed: %v", key, err)
+	}
+	if string(got) != want {
+		t.Errorf("Get(%q) = %q, want %q", key, got, want)
+	}
+}
+
+func TestMemoryStateStore(t *testing.T) {
+	testStateStore(t, func(t *testing.T, now func() time.Time) StateStore {
+		store := NewMemoryStateStore()
+		store.now = now
+		return store
+	})
+}
+
+func TestFileStateStore(t *testing.T) {
+	testStateStore(t, func(t *testing.T, now func() time.Time) StateStore {
+		store := NewFileStateStore(filepath.Join(t.TempDir(), "state"))
+		store.now = now
+		return store
+	})
+}
+
+func TestFileStateStoreSurvivesRestart(t *testing.T) {
+	dir := t.TempDir()
+
+	if err := NewFileStateStore(dir).Set("a", []byte("one"), time.Hour); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	assertStateValue(t, NewFileStateStore(dir), "a", "one")
+}
+
+func TestFileStateStoreMaxSize(t *testing.T) {
+	dir := t.TempDir()
+	store := NewFileStateStore(dir)
+
+	if err := store.Set("old", []byte("value"), 0); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	info, err := os.Stat(store.path("old"))
+	if err != nil {
+		t.Fatalf("Failed to stat state file: %v", err)
+	}
+
+	// Age the first file so it is the one pruned, then allow room for one file only
+	past := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(store.path("old"), past, past); err != nil {
+		t.Fatalf("Failed to age state file: %v", err)
+	}
+	store.WithMaxSize(info.Size() * 3 / 2)
+
+	if err := store.Set("new", []byte("value"), 0); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if _, err := store.Get("old"); !errors.Is(err, ErrStateNotFound) {
+		t.Errorf("Expected the oldest entry to be pruned, got: %v", err)
+	}
+	assertStateValue(t, store, "new", "value")
+}
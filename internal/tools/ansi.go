@@ -0,0 +1,14 @@
+// Package tools provides removal of terminal escape sequences from output.
+package tools
+
+import "regexp"
+
+// ansiEscapePattern matches ANSI escape sequences: CSI sequences such as
+// colors and cursor movement, OSC sequences such as hyperlinks and window
+// titles, and the remaining two-byte escapes.
+var ansiEscapePattern = regexp.MustCompile(`\x1b\[[0-?]*[ -/]*[@-~]|\x1b\][^\x07\x1b]*(?:\x07|\x1b\\)|\x1b[@-Z\\-_]`)
+
+// StripANSI removes ANSI escape sequences from s.
+func StripANSI(s string) string {
+	return ansiEscapePattern.ReplaceAllString(s, "")
+}
@@ -4,6 +4,8 @@ package bash
 import (
 	"context"
 	"fmt"
+	"os"
+	"sync/atomic"
 	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
@@ -12,11 +14,43 @@ import (
 	"github.com/d-kuro/claude-code-mcp/internal/tools"
 )
 
+// MaxOutputLength is the number of stdout characters returned before truncation.
+const MaxOutputLength = 30000
+
+// Output formats supported by the Bash tool.
+const (
+	OutputFormatText = "text"
+	OutputFormatJSON = "json"
+)
+
 // BashArgs represents the arguments for the Bash tool.
 type BashArgs struct {
-	Command     string  `json:"command"`
-	Description *string `json:"description,omitempty"`
-	Timeout     *int    `json:"timeout,omitempty"`
+	Command       string  `json:"command"`
+	Description   *string `json:"description,omitempty"`
+	Timeout       *int    `json:"timeout,omitempty"`
+	OutputFormat  *string `json:"output_format,omitempty"`
+	CombineOutput *bool   `json:"combine_output,omitempty"`
+	FailOnNonZero *bool   `json:"fail_on_non_zero,omitempty"`
+	Isolated      *bool   `json:"isolated,omitempty"`
+	// RunInBackground starts the command as a background job and returns
+	// its ID without waiting; see the BashJobs tool.
+	RunInBackground *bool `json:"run_in_background,omitempty"`
+}
+
+// CommandOutput is the structured result returned when output_format is "json".
+type CommandOutput struct {
+	ExitCode         int    `json:"exit_code"`
+	DurationMs       int64  `json:"duration_ms"`
+	Stdout           string `json:"stdout"`
+	Stderr           string `json:"stderr"`
+	WorkingDirectory string `json:"working_directory"`
+	Truncated        bool   `json:"truncated"`
+	// TerminationReason tells whether the command exited or was killed:
+	// "exited", "timeout", "cancelled" or "signalled".
+	TerminationReason TerminationReason `json:"termination_reason"`
+	Signal            string            `json:"signal,omitempty"`
+	// Hint suggests a next step, e.g. when the command was not found.
+	Hint string `json:"hint,omitempty"`
 }
 
 // CreateBashTool creates the Bash tool using MCP SDK patterns.
@@ -32,12 +66,20 @@ func CreateBashTool(ctx *tools.Context) *tools.ServerTool {
 			}, nil
 		}
 
-		// Validate command security
+		// Validate command security, for both session and isolated commands
 		if err := ctx.Validator.ValidateCommand(args.Command, nil); err != nil {
-			return &mcp.CallToolResultFor[any]{
-				Content: []mcp.Content{&mcp.TextContent{Text: "Error: Command validation failed: " + err.Error()}},
-				IsError: true,
-			}, nil
+			return tools.ValidationErrorResult("Command validation failed", err), nil
+		}
+		if err := ValidateCommandRules(ctx, args.Command); err != nil {
+			return tools.ValidationErrorResult("Command validation failed", err), nil
+		}
+
+		outputFormat := OutputFormatText
+		if args.OutputFormat != nil && *args.OutputFormat != "" {
+			outputFormat = *args.OutputFormat
+		}
+		if outputFormat != OutputFormatText && outputFormat != OutputFormatJSON {
+			return tools.InvalidFieldError("output_format", fmt.Sprintf("must be %q or %q", OutputFormatText, OutputFormatJSON)), nil
 		}
 
 		// Determine timeout (default 120s, max 600s)
@@ -55,20 +97,68 @@ func CreateBashTool(ctx *tools.Context) *tools.ServerTool {
 			}
 		}
 
-		// Get or create session manager
-		sessionManager := GetSessionManager()
+		opts := ExecOptions{CleanEnv: ctx.CleanEnv, CombineOutput: args.CombineOutput != nil && *args.CombineOutput}
+		if args.RunInBackground != nil && *args.RunInBackground {
+			if args.Isolated != nil && *args.Isolated {
+				return tools.InvalidFieldError("run_in_background", "cannot be combined with isolated"), nil
+			}
+			jobID, err := GetSessionManager().StartBackgroundJob(jobOwner(session), args.Command, timeout, opts)
+			if err != nil {
+				return tools.ErrorResponse(err.Error()), nil
+			}
+			return tools.SuccessResponsef("Started background job %s. Use the BashJobs tool to check its status and output.", jobID), nil
+		}
+
+		if token := params.GetProgressToken(); token != nil && ctx.ProgressInterval > 0 {
+			opts.Captured = &atomic.Int64{}
+			stop := startProgress(ctxReq, session, token, ctx.ProgressInterval, opts.Captured)
+			defer stop()
+		}
 
-		// Execute command in persistent session
-		result, err := sessionManager.ExecuteCommand(ctxReq, args.Command, timeout)
-		if err != nil {
+		var result *CommandResult
+		var err error
+		if args.Isolated != nil && *args.Isolated {
+			// Run outside the persistent session, in the project root
+			result, err = executeIsolated(ctxReq, ctx, args.Command, timeout, opts)
+		} else {
+			// Execute command in persistent session
+			result, err = GetSessionManager().Execute(ctxReq, args.Command, timeout, opts)
+		}
+		if err != nil && result == nil {
 			return &mcp.CallToolResultFor[any]{
 				Content: []mcp.Content{&mcp.TextContent{Text: "Error: " + err.Error()}},
 				IsError: true,
 			}, nil
 		}
 
+		if ctx.StripANSI {
+			result.Stdout = tools.StripANSI(result.Stdout)
+			result.Stderr = tools.StripANSI(result.Stderr)
+		}
+
+		// A command killed on timeout or cancellation always fails, with its partial output
+		interrupted := err != nil
+		failed := interrupted || args.FailOnNonZero != nil && *args.FailOnNonZero && result.ExitCode != 0
+		hint := commandNotFoundHint(result, ctx.Validator)
+
+		if outputFormat == OutputFormatJSON {
+			output := newCommandOutput(result)
+			output.Hint = hint
+			response := tools.JSONResponse(output)
+			response.StructuredContent = output
+			response.IsError = failed
+			return response, nil
+		}
+
+		if interrupted {
+			return tools.ErrorResponse(formatCommandResult(result, args.Description)), nil
+		}
+		if failed {
+			return tools.ErrorResponse(appendHint(formatCommandFailure(result), hint)), nil
+		}
+
 		// Format output
-		output := formatCommandResult(result, args.Description)
+		output := appendHint(formatCommandResult(result, args.Description), hint)
 
 		return &mcp.CallToolResultFor[any]{
 			Content: []mcp.Content{&mcp.TextContent{Text: output}},
@@ -88,6 +178,67 @@ func CreateBashTool(ctx *tools.Context) *tools.ServerTool {
 	}
 }
 
+// defaultCommandRules checks commands against the default dangerous patterns
+// when the context sets no command rules.
+var defaultCommandRules = NewShellExecutor()
+
+// ValidateCommandRules checks a command line against the command rules of
+// ctx, or the default dangerous patterns when it has none.
+func ValidateCommandRules(ctx *tools.Context, command string) error {
+	if ctx.CommandRules != nil {
+		return ctx.CommandRules.ValidateCommand(command)
+	}
+	return defaultCommandRules.ValidateCommand(command)
+}
+
+// executeIsolated runs a command for the isolated option. It starts in the
+// server's working directory, the project root, from the same environment as
+// new sessions, without any session exports.
+func executeIsolated(ctxReq context.Context, ctx *tools.Context, command string, timeout time.Duration, opts ExecOptions) (*CommandResult, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current working directory: %w", err)
+	}
+
+	executor := NewShellExecutor().WithAllowedEnvVars(ctx.AllowedEnvVars)
+	return GetSessionManager().executeIsolated(ctxReq, executor, command, cwd, sessionSeedEnv(ctx), timeout, opts)
+}
+
+// startProgress sends a progress notification every interval until the
+// returned stop function is called. Each notification reports the elapsed
+// time and the number of output bytes captured so far.
+func startProgress(ctx context.Context, session *mcp.ServerSession, token any, interval time.Duration, captured *atomic.Int64) (stop func()) {
+	done := make(chan struct{})
+	finished := make(chan struct{})
+	start := time.Now()
+
+	go func() {
+		defer close(finished)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				elapsed := time.Since(start)
+				_ = session.NotifyProgress(ctx, &mcp.ProgressNotificationParams{
+					ProgressToken: token,
+					Progress:      elapsed.Seconds(),
+					Message:       fmt.Sprintf("Running for %s, %d bytes of output captured", elapsed.Round(time.Second), captured.Load()),
+				})
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-finished
+	}
+}
+
 // formatCommandResult formats the command execution result into a readable string.
 func formatCommandResult(result *CommandResult, description *string) string {
 	var output string
@@ -98,14 +249,14 @@ func formatCommandResult(result *CommandResult, description *string) string {
 	}
 
 	// Add command execution summary
-	output += fmt.Sprintf("Command executed successfully (exit code: %d, duration: %s)\n\n", result.ExitCode, result.Duration)
+	output += commandSummary(result) + "\n\n"
 
 	// Add stdout if present
 	if result.Stdout != "" {
 		output += "Output:\n"
 		// Truncate output if too long (30000 characters)
-		if len(result.Stdout) > 30000 {
-			output += result.Stdout[:30000] + "\n... (output truncated)\n"
+		if len(result.Stdout) > MaxOutputLength {
+			output += result.Stdout[:MaxOutputLength] + "\n... (output truncated)\n"
 		} else {
 			output += result.Stdout + "\n"
 		}
@@ -123,3 +274,71 @@ func formatCommandResult(result *CommandResult, description *string) string {
 
 	return output
 }
+
+// commandSummary describes how a command ended: whether it exited, with its
+// exit code, or was killed, and why.
+func commandSummary(result *CommandResult) string {
+	switch result.TerminationReason {
+	case TerminationTimeout:
+		return fmt.Sprintf("Command timed out and was killed (duration: %s)", result.Duration)
+	case TerminationCancelled:
+		return fmt.Sprintf("Command was cancelled and killed (duration: %s)", result.Duration)
+	case TerminationSignalled:
+		return fmt.Sprintf("Command was terminated by signal: %s (duration: %s)", result.Signal, result.Duration)
+	default:
+		return fmt.Sprintf("Command executed successfully (exit code: %d, duration: %s)", result.ExitCode, result.Duration)
+	}
+}
+
+// formatCommandFailure formats a command that exited with a non-zero code as
+// an error message with its exit code and error output.
+func formatCommandFailure(result *CommandResult) string {
+	output := fmt.Sprintf("Command failed with exit code %d (duration: %s)", result.ExitCode, result.Duration)
+	if result.TerminationReason == TerminationSignalled {
+		output = commandSummary(result)
+	}
+
+	if result.Stderr != "" {
+		output += "\n\nError output:\n" + result.Stderr
+	}
+
+	if result.Stdout != "" {
+		stdout := result.Stdout
+		if len(stdout) > MaxOutputLength {
+			stdout = stdout[:MaxOutputLength] + "\n... (output truncated)"
+		}
+		output += "\n\nOutput:\n" + stdout
+	}
+
+	return output
+}
+
+// appendHint appends a hint as its own paragraph, if there is one.
+func appendHint(output, hint string) string {
+	if hint == "" {
+		return output
+	}
+	return output + "\n\n" + hint
+}
+
+// newCommandOutput converts a command result into its structured form,
+// truncating stdout to the same length as the text format.
+func newCommandOutput(result *CommandResult) CommandOutput {
+	output := CommandOutput{
+		ExitCode:         result.ExitCode,
+		DurationMs:       result.Duration.Milliseconds(),
+		Stdout:           result.Stdout,
+		Stderr:           result.Stderr,
+		WorkingDirectory: result.WorkingDirectory,
+
+		TerminationReason: result.TerminationReason,
+		Signal:            result.Signal,
+	}
+
+	if len(output.Stdout) > MaxOutputLength {
+		output.Stdout = output.Stdout[:MaxOutputLength]
+		output.Truncated = true
+	}
+
+	return output
+}
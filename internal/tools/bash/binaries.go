@@ -0,0 +1,143 @@
+// Package bash provides the lookup of the binaries a command line runs.
+package bash
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// commandSeparators end one command of a pipeline or list and start another.
+// Parentheses and backquotes also start the commands of subshells and
+// command substitutions.
+const commandSeparators = "|;&\n()`{}"
+
+// fdRedirectPattern matches redirections such as 2>&1, >&2 and &>, whose "&"
+// does not separate commands.
+var fdRedirectPattern = regexp.MustCompile(`[0-9]*[<>]&[0-9-]*|&>>?`)
+
+// binaryWrappers run the command that follows them, which must be allowed too.
+var binaryWrappers = map[string]bool{
+	"sudo":    true,
+	"env":     true,
+	"exec":    true,
+	"command": true,
+	"nohup":   true,
+	"time":    true,
+	"timeout": true,
+	"nice":    true,
+	"xargs":   true,
+}
+
+// shellKeywords start or continue compound commands; the command they
+// introduce is the next word.
+var shellKeywords = map[string]bool{
+	"if": true, "then": true, "else": true, "elif": true, "fi": true,
+	"while": true, "until": true, "do": true, "done": true, "esac": true,
+	"!": true, "{": true, "}": true,
+}
+
+// checkAllowedBinaries rejects a command that runs a binary outside the
+// allowlist set with WithAllowedBinaries.
+func (e *ShellExecutor) checkAllowedBinaries(command string) error {
+	if len(e.allowedBinaries) == 0 {
+		return nil
+	}
+	for _, binary := range commandBinaries(command) {
+		if !matchesAnyBinary(binary, e.allowedBinaries) {
+			return fmt.Errorf("binary %q is not in the allowed binaries", binary)
+		}
+	}
+	return nil
+}
+
+// commandBinaries returns the base names of the binaries a command line runs:
+// the first word of each command of its pipelines, lists, subshells and
+// command substitutions, after leading variable assignments and shell
+// keywords, plus the commands run by wrappers such as sudo.
+func commandBinaries(command string) []string {
+	command = fdRedirectPattern.ReplaceAllString(command, " ")
+
+	var binaries []string
+	for _, segment := range splitCommands(command) {
+		words := strings.Fields(segment)
+		if len(words) > 0 && (words[0] == "for" || words[0] == "case" || words[0] == "select" || words[0] == "function") {
+			// The rest of the header is not a command
+			continue
+		}
+
+		wrapped := false
+		for _, word := range words {
+			switch {
+			case shellKeywords[word]:
+				continue
+			case isAssignment(word) && !wrapped:
+				continue
+			case wrapped && (strings.HasPrefix(word, "-") || isAssignment(word) || isNumeric(word)):
+				// Options and arguments of the wrapper
+				continue
+			}
+
+			name := filepath.Base(strings.Trim(word, `"'`))
+			if name == "$" || name == "" {
+				break
+			}
+			binaries = append(binaries, name)
+			if !binaryWrappers[name] {
+				break
+			}
+			wrapped = true
+		}
+	}
+	return binaries
+}
+
+// splitCommands splits a command line at command separators outside quotes.
+// Inside double quotes, backquotes and parentheses still separate commands,
+// since they run command substitutions.
+func splitCommands(command string) []string {
+	var segments []string
+	var current strings.Builder
+	var quote rune
+	for _, r := range command {
+		switch {
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			}
+		case r == '\'' && quote == 0, r == '"' && quote == 0:
+			quote = r
+		case r == '"' && quote == '"':
+			quote = 0
+		case strings.ContainsRune(commandSeparators, r) && (quote == 0 || strings.ContainsRune("`()", r)):
+			segments = append(segments, current.String())
+			current.Reset()
+			continue
+		}
+		current.WriteRune(r)
+	}
+	return append(segments, current.String())
+}
+
+// isAssignment reports whether a word assigns a variable, as in FOO=bar cmd.
+func isAssignment(word string) bool {
+	name, _, ok := strings.Cut(word, "=")
+	return ok && name != "" && !strings.HasPrefix(word, "-") && !strings.ContainsAny(name, `/"'$`)
+}
+
+// isNumeric reports whether a word is a number or duration such as 10 or 5s,
+// as taken by timeout and nice.
+func isNumeric(word string) bool {
+	return word != "" && strings.Trim(word, "0123456789.smhd") == "" && word[0] >= '0' && word[0] <= '9'
+}
+
+// matchesAnyBinary reports whether a binary name matches one of the patterns.
+func matchesAnyBinary(name string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := filepath.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
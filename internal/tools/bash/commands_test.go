@@ -76,6 +76,38 @@ func TestShellExecutor_ValidateCommand(t *testing.T) {
 			wantErr: true,
 			errMsg:  "dangerous pattern",
 		},
+		{
+			name:    "rm -rf / later in a list",
+			command: "cd /tmp && sudo rm -fr --no-preserve-root /*",
+			wantErr: true,
+			errMsg:  "dangerous pattern",
+		},
+		{
+			name:    "mkfs in a command substitution",
+			command: "echo $(mkfs /dev/sdb1)",
+			wantErr: true,
+			errMsg:  "dangerous pattern",
+		},
+		{
+			name:    "rm -rf of a directory under the root",
+			command: "rm -rf /tmp/build",
+			wantErr: false,
+		},
+		{
+			name:    "mkfs as a grep argument",
+			command: "grep -rn mkfs docs",
+			wantErr: false,
+		},
+		{
+			name:    "fdisk as a man page",
+			command: "man fdisk",
+			wantErr: false,
+		},
+		{
+			name:    "dd from a file",
+			command: "dd if=disk.img of=/tmp/copy.img",
+			wantErr: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -96,6 +128,92 @@ func TestShellExecutor_ValidateCommand(t *testing.T) {
 	}
 }
 
+func TestShellExecutor_ValidateCommandCustomPatterns(t *testing.T) {
+	executor := NewShellExecutor().
+		WithDangerousPatterns([]string{`\bshutdown\b`}).
+		WithAllowedDangerousPatterns([]string{`^dd if=/dev/zero of=/tmp/`})
+
+	tests := []struct {
+		name    string
+		command string
+		wantErr bool
+	}{
+		{"custom dangerous pattern", "shutdown -h now", true},
+		{"custom pattern ignores case", "sudo SHUTDOWN -r now", true},
+		{"custom pattern word boundary", "echo shutdownlog", false},
+		{"allowed dd", "dd if=/dev/zero of=/tmp/disk.img bs=1M count=10", false},
+		{"dd outside the allowed pattern", "dd if=/dev/zero of=/dev/sda", true},
+		{"defaults still apply", "mkfs.ext4 /dev/sdb1", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := executor.ValidateCommand(tt.command)
+			if tt.wantErr && (err == nil || !strings.Contains(err.Error(), "dangerous pattern")) {
+				t.Errorf("ValidateCommand(%q) = %v, want a dangerous pattern error", tt.command, err)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("ValidateCommand(%q) unexpected error = %v", tt.command, err)
+			}
+		})
+	}
+}
+
+func TestShellExecutor_ValidateCommandInvalidPattern(t *testing.T) {
+	executor := NewShellExecutor().WithDangerousPatterns([]string{"("})
+
+	if err := executor.ValidateCommand("echo hello"); err == nil || !strings.Contains(err.Error(), "invalid command pattern") {
+		t.Errorf("Expected an invalid pattern to reject commands, got: %v", err)
+	}
+}
+
+func TestShellExecutor_ValidateCommandAllowedBinaries(t *testing.T) {
+	executor := NewShellExecutor().WithAllowedBinaries([]string{"ls", "grep", "git", "echo", "sudo", "python3*"})
+
+	tests := []struct {
+		name       string
+		command    string
+		wantBinary string
+	}{
+		{name: "allowed binary", command: "ls -la /tmp"},
+		{name: "allowed by path", command: "/usr/bin/git status"},
+		{name: "allowed glob", command: "python3.12 -c 'print(1)'"},
+		{name: "allowed pipeline", command: "ls | grep go 2>&1 | grep -v test"},
+		{name: "assignment before binary", command: "GIT_PAGER=cat git log"},
+		{name: "separators in quotes", command: `echo "a | b; c" 'd && e'`},
+		{name: "disallowed binary", command: "curl https://example.com", wantBinary: "curl"},
+		{name: "disallowed pipeline stage", command: "ls | xargs rm", wantBinary: "xargs"},
+		{name: "disallowed list command", command: "echo hi && rm -f out.txt", wantBinary: "rm"},
+		{name: "disallowed command substitution", command: "echo $(whoami)", wantBinary: "whoami"},
+		{name: "disallowed wrapped binary", command: "sudo -E rm file", wantBinary: "rm"},
+		{name: "dangerous pattern still applies", command: "echo x; mkfs /dev/sdb", wantBinary: "mkfs"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := executor.ValidateCommand(tt.command)
+			if tt.wantBinary == "" {
+				if err != nil {
+					t.Errorf("ValidateCommand(%q) unexpected error = %v", tt.command, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), `binary "`+tt.wantBinary+`" is not in the allowed binaries`) {
+				t.Errorf("ValidateCommand(%q) = %v, want binary %s rejected", tt.command, err, tt.wantBinary)
+			}
+		})
+	}
+
+	// The allowlist composes with the dangerous patterns
+	withPatterns := NewShellExecutor().WithAllowedBinaries([]string{"dd"})
+	if err := withPatterns.ValidateCommand("dd if=/dev/zero of=/dev/sda"); err == nil || !strings.Contains(err.Error(), "dangerous pattern") {
+		t.Errorf("Expected an allowed binary to still be checked for dangerous patterns, got: %v", err)
+	}
+	if err := NewShellExecutor().ValidateCommand("curl https://example.com"); err != nil {
+		t.Errorf("Expected every binary to be allowed without an allowlist, got: %v", err)
+	}
+}
+
 func TestShellExecutor_ExecuteInSession_BasicCommands(t *testing.T) {
 	executor := NewShellExecutor()
 	session := createTestSession()
@@ -222,6 +340,68 @@ func TestShellExecutor_ExecuteInSession_ContextCancellation(t *testing.T) {
 	}
 }
 
+func TestShellExecutor_TerminationReason(t *testing.T) {
+	executor := NewShellExecutor()
+
+	t.Run("exited", func(t *testing.T) {
+		result, err := executor.ExecuteInSession(context.Background(), createTestSession(), "echo done; exit 3", 5*time.Second)
+		if err != nil {
+			t.Fatalf("ExecuteInSession() unexpected error = %v", err)
+		}
+		if result.TerminationReason != TerminationExited || result.ExitCode != 3 {
+			t.Errorf("Expected the command to exit with code 3, got %+v", result)
+		}
+		if summary := formatCommandResult(result, nil); !strings.Contains(summary, "exit code: 3") {
+			t.Errorf("Expected the exit code in the summary, got %q", summary)
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		result, err := executor.ExecuteInSession(context.Background(), createTestSession(), "echo started; sleep 5", 200*time.Millisecond)
+		if err == nil || !strings.Contains(err.Error(), "timed out") {
+			t.Fatalf("ExecuteInSession() error = %v, want timeout error", err)
+		}
+		if result == nil || result.TerminationReason != TerminationTimeout || result.ExitCode != -1 {
+			t.Fatalf("Expected a timed out result, got %+v", result)
+		}
+		if !strings.Contains(result.Stdout, "started") {
+			t.Errorf("Expected the partial output to be kept, got %q", result.Stdout)
+		}
+		if summary := formatCommandResult(result, nil); !strings.Contains(summary, "Command timed out and was killed") {
+			t.Errorf("Expected the timeout in the summary, got %q", summary)
+		}
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(200*time.Millisecond, cancel)
+
+		result, err := executor.ExecuteInSession(ctx, createTestSession(), "sleep 5", 5*time.Second)
+		if err == nil || !strings.Contains(err.Error(), "cancelled") {
+			t.Fatalf("ExecuteInSession() error = %v, want cancellation error", err)
+		}
+		if result == nil || result.TerminationReason != TerminationCancelled || result.ExitCode != -1 {
+			t.Fatalf("Expected a cancelled result, got %+v", result)
+		}
+		if summary := formatCommandResult(result, nil); !strings.Contains(summary, "Command was cancelled") {
+			t.Errorf("Expected the cancellation in the summary, got %q", summary)
+		}
+	})
+
+	t.Run("signalled", func(t *testing.T) {
+		result, err := executor.ExecuteInSession(context.Background(), createTestSession(), "kill -TERM $$", 5*time.Second)
+		if err != nil {
+			t.Fatalf("ExecuteInSession() unexpected error = %v", err)
+		}
+		if result.TerminationReason != TerminationSignalled || result.Signal != "terminated" {
+			t.Errorf("Expected the command to be terminated by SIGTERM, got %+v", result)
+		}
+		if summary := formatCommandResult(result, nil); !strings.Contains(summary, "terminated by signal: terminated") {
+			t.Errorf("Expected the signal in the summary, got %q", summary)
+		}
+	})
+}
+
 func TestShellExecutor_HandleCdCommand(t *testing.T) {
 	executor := NewShellExecutor()
 
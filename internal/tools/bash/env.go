@@ -0,0 +1,101 @@
+// Package bash provides the tool that shows the environment of a session.
+package bash
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/d-kuro/claude-code-mcp/internal/prompts"
+	"github.com/d-kuro/claude-code-mcp/internal/tools"
+)
+
+// RedactedValue replaces the values of secret variables in BashEnv output.
+const RedactedValue = "[REDACTED]"
+
+var (
+	// secretNamePattern matches the names of variables that usually hold
+	// secrets, such as GITHUB_TOKEN or AWS_SECRET_ACCESS_KEY.
+	secretNamePattern = regexp.MustCompile(`(?i)(secret|token|passw(or)?d|api_?key|private_?key|credential|auth)`)
+	// secretValuePattern matches values that look like well-known credentials
+	// whatever the variable is called: GitHub, OpenAI and Anthropic style
+	// keys, AWS access key IDs, Slack tokens and JWTs.
+	secretValuePattern = regexp.MustCompile(`^(gh[pousr]_[A-Za-z0-9]{20,}|github_pat_\w{20,}|sk-[\w-]{20,}|AKIA[0-9A-Z]{16}|xox[abpr]-[\w-]{10,}|eyJ[\w-]+\.[\w-]+\.[\w-]+)$`)
+)
+
+// BashEnvArgs represents the arguments for the BashEnv tool.
+type BashEnvArgs struct {
+	Session *string `json:"session,omitempty"`
+}
+
+// EnvOutput is the result of the BashEnv tool.
+type EnvOutput struct {
+	Session          string            `json:"session"`
+	WorkingDirectory string            `json:"working_directory"`
+	Environment      map[string]string `json:"environment"`
+}
+
+// CreateBashEnvTool creates the BashEnv tool using MCP SDK patterns.
+func CreateBashEnvTool(ctx *tools.Context) *tools.ServerTool {
+	handler := func(ctxReq context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[BashEnvArgs]) (*mcp.CallToolResultFor[any], error) {
+		args := params.Arguments
+
+		sessionID := sessionIDFor(ctx.CleanEnv)
+		if args.Session != nil && *args.Session != "" {
+			sessionID = *args.Session
+		}
+
+		output, ok := GetSessionManager().sessionEnv(sessionID)
+		if !ok {
+			return tools.ErrorResponse(fmt.Sprintf("session %q does not exist; it is created by the first Bash command", sessionID)), nil
+		}
+
+		return tools.JSONResponse(output), nil
+	}
+
+	tool := &mcp.Tool{
+		Name:        "BashEnv",
+		Description: prompts.BashEnvToolDoc,
+	}
+
+	return &tools.ServerTool{
+		Tool: tool,
+		RegisterFunc: func(server *mcp.Server) {
+			mcp.AddTool(server, tool, handler)
+		},
+	}
+}
+
+// sessionEnv returns the working directory and environment of a session,
+// with secret values redacted.
+func (sm *SessionManager) sessionEnv(sessionID string) (EnvOutput, bool) {
+	session, exists := sm.GetSession(sessionID)
+	if !exists {
+		return EnvOutput{}, false
+	}
+
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	env := make(map[string]string, len(session.Environment))
+	for name, value := range session.Environment {
+		env[name] = redactEnvValue(name, value)
+	}
+
+	return EnvOutput{
+		Session:          sessionID,
+		WorkingDirectory: session.WorkingDirectory,
+		Environment:      env,
+	}, true
+}
+
+// redactEnvValue returns value, or RedactedValue when the variable looks
+// like it holds a secret.
+func redactEnvValue(name, value string) string {
+	if value != "" && (secretNamePattern.MatchString(name) || secretValuePattern.MatchString(value)) {
+		return RedactedValue
+	}
+	return value
+}
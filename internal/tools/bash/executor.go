@@ -3,24 +3,139 @@ package bash
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
+	"slices"
 	"strings"
+	"sync/atomic"
+	"syscall"
 	"time"
+
+	"github.com/d-kuro/claude-code-mcp/internal/tools"
 )
 
+// commandPattern is a compiled dangerous or allowed command pattern.
+type commandPattern struct {
+	source string
+	re     *regexp.Regexp
+}
+
+// commandStart matches the start of a command: the start of the line or a
+// list, pipeline, subshell or substitution, optionally followed by sudo. An
+// opening quote also counts, since quoted text may be run by sh -c or eval.
+const commandStart = `(?:^|[;&|(\x60'"])\s*(?:sudo\s+)?`
+
+// commandEnd matches the end of a word in a command.
+const commandEnd = `(?:\s|[;&|)\x60'"]|$)`
+
+// defaultDangerousPatterns are the commands ValidateCommand always rejects.
+// They match only in command position and on whole words, ignoring case, so
+// that "rm -rf /tmp/build" or "grep mkfs docs" are not rejected. The source
+// names the pattern in error messages.
+var defaultDangerousPatterns = []commandPattern{
+	{"rm -rf /", regexp.MustCompile(`(?i)` + commandStart + `rm\s+(?:-\S+\s+)*-\w*(?:r\w*f|f\w*r)\w*\s+(?:-\S+\s+)*/\*?` + commandEnd)},
+	{":(){ :|:& };:", regexp.MustCompile(`:\(\)\s*\{\s*:\s*\|\s*:\s*&\s*\}\s*;\s*:`)},               // Fork bomb
+	{"dd if=/dev/zero", regexp.MustCompile(`(?i)` + commandStart + `dd\s+[^;&|]*\bif=/dev/zero\b`)}, // Dangerous dd usage
+	{"mkfs", regexp.MustCompile(`(?i)` + commandStart + `mkfs(?:\.\w+)?` + commandEnd)},             // Filesystem creation
+	{"fdisk", regexp.MustCompile(`(?i)` + commandStart + `fdisk` + commandEnd)},                     // Disk partitioning
+}
+
 // ShellExecutor handles execution of shell commands with persistent session state.
-type ShellExecutor struct{}
+type ShellExecutor struct {
+	allowedEnvVars    []string
+	dangerousPatterns []commandPattern
+	allowedPatterns   []commandPattern
+	// allowedBinaries, when non-empty, lists the only binaries commands may run.
+	allowedBinaries []string
+	// patternErr records an invalid pattern; ValidateCommand then rejects
+	// every command rather than silently skipping the check.
+	patternErr error
+}
 
 // NewShellExecutor creates a new shell executor.
 func NewShellExecutor() *ShellExecutor {
 	return &ShellExecutor{}
 }
 
+// WithAllowedEnvVars makes commands start with only the named process
+// environment variables, plus the session's own exports. It takes precedence
+// over the session's clean environment setting.
+func (e *ShellExecutor) WithAllowedEnvVars(names []string) *ShellExecutor {
+	e.allowedEnvVars = names
+	return e
+}
+
+// WithDangerousPatterns adds case-insensitive regular expressions to the
+// default dangerous patterns. Commands matching any of them are rejected by
+// ValidateCommand.
+func (e *ShellExecutor) WithDangerousPatterns(patterns []string) *ShellExecutor {
+	e.dangerousPatterns = append(e.dangerousPatterns, e.compilePatterns(patterns)...)
+	return e
+}
+
+// WithAllowedDangerousPatterns sets case-insensitive regular expressions for
+// commands that are allowed even though they match a dangerous pattern.
+func (e *ShellExecutor) WithAllowedDangerousPatterns(patterns []string) *ShellExecutor {
+	e.allowedPatterns = e.compilePatterns(patterns)
+	return e
+}
+
+// WithAllowedBinaries restricts commands to the named binaries. Each command
+// of a pipeline or list must run one of them, matched by the base name of
+// the binary; names may use glob patterns such as "python3*". Commands run
+// through wrappers such as sudo, env or xargs need both the wrapper and the
+// wrapped binary to be allowed. An empty list allows every binary.
+func (e *ShellExecutor) WithAllowedBinaries(binaries []string) *ShellExecutor {
+	e.allowedBinaries = binaries
+	return e
+}
+
+// Err returns the first invalid pattern passed to WithDangerousPatterns or
+// WithAllowedDangerousPatterns, which makes ValidateCommand reject every command.
+func (e *ShellExecutor) Err() error {
+	return e.patternErr
+}
+
+// compilePatterns compiles case-insensitive patterns, recording the first
+// invalid one in patternErr.
+func (e *ShellExecutor) compilePatterns(patterns []string) []commandPattern {
+	compiled := make([]commandPattern, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := regexp.Compile("(?i)" + pattern)
+		if err != nil {
+			if e.patternErr == nil {
+				e.patternErr = fmt.Errorf("invalid command pattern %q: %w", pattern, err)
+			}
+			continue
+		}
+		compiled = append(compiled, commandPattern{source: pattern, re: re})
+	}
+	return compiled
+}
+
+// baseEnv returns the process environment that commands start from.
+func (e *ShellExecutor) baseEnv(cleanEnv bool) []string {
+	if len(e.allowedEnvVars) > 0 {
+		return tools.FilterEnv(e.allowedEnvVars)
+	}
+	return tools.CommandEnv(cleanEnv)
+}
+
 // ExecuteInSession executes a command within a persistent session context.
 func (e *ShellExecutor) ExecuteInSession(ctx context.Context, session *ShellSession, command string, timeout time.Duration) (*CommandResult, error) {
+	return e.executeInSession(ctx, session, command, timeout, ExecOptions{})
+}
+
+// executeInSession is ExecuteInSession with the output options of opts: an
+// optional counter of output bytes captured so far and combined output.
+// A command killed on timeout or cancellation returns its partial result,
+// with the reason in TerminationReason, along with the error.
+func (e *ShellExecutor) executeInSession(ctx context.Context, session *ShellSession, command string, timeout time.Duration, opts ExecOptions) (*CommandResult, error) {
 	start := time.Now()
 
 	// Create context with timeout
@@ -33,18 +148,17 @@ func (e *ShellExecutor) ExecuteInSession(ctx context.Context, session *ShellSess
 	}
 
 	// Execute the command
-	result, err := e.executeCommand(timeoutCtx, session, command)
+	result, err := e.executeCommand(timeoutCtx, session, command, opts)
 	if err != nil {
-		// Check for timeout first, before checking other error types
-		if timeoutCtx.Err() == context.DeadlineExceeded {
-			return nil, fmt.Errorf("command timed out after %v", timeout)
-		}
 		return nil, err
 	}
 
-	// Also check for timeout in case the command completed but the context was cancelled
-	if timeoutCtx.Err() == context.DeadlineExceeded {
-		return nil, fmt.Errorf("command timed out after %v", timeout)
+	// The context is checked even if the command completed, as it may have
+	// been killed just before exiting
+	if timeoutCtx.Err() != nil {
+		result.Duration = time.Since(start)
+		result.WorkingDirectory = session.WorkingDirectory
+		return result, interruptCommand(timeoutCtx, result, timeout)
 	}
 
 	// Update session state based on command execution
@@ -60,6 +174,44 @@ func (e *ShellExecutor) ExecuteInSession(ctx context.Context, session *ShellSess
 	return result, nil
 }
 
+// ExecuteIsolated runs a command once in dir with a fresh environment, plus
+// the variables in env, outside any persistent session: it neither sees nor
+// changes a session's working directory or exports.
+func (e *ShellExecutor) ExecuteIsolated(ctx context.Context, command, dir string, env map[string]string, timeout time.Duration, opts ExecOptions) (*CommandResult, error) {
+	start := time.Now()
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	// A throwaway session carries the directory and environment settings
+	session := &ShellSession{WorkingDirectory: dir, Environment: env, CleanEnv: opts.CleanEnv}
+	result, err := e.executeCommand(timeoutCtx, session, command, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	result.Duration = time.Since(start)
+	result.WorkingDirectory = dir
+
+	if timeoutCtx.Err() != nil {
+		return result, interruptCommand(timeoutCtx, result, timeout)
+	}
+	return result, nil
+}
+
+// interruptCommand marks the result of a command killed because ctx ended,
+// by its timeout or by cancellation, and returns the error reported for it.
+func interruptCommand(ctx context.Context, result *CommandResult, timeout time.Duration) error {
+	result.ExitCode = -1
+	result.Signal = ""
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		result.TerminationReason = TerminationTimeout
+		return fmt.Errorf("command timed out after %v", timeout)
+	}
+	result.TerminationReason = TerminationCancelled
+	return fmt.Errorf("command cancelled: %w", ctx.Err())
+}
+
 // preprocessCommand handles commands that change session state before execution.
 func (e *ShellExecutor) preprocessCommand(session *ShellSession, command string) error {
 	trimmedCmd := strings.TrimSpace(command)
@@ -91,7 +243,7 @@ func (e *ShellExecutor) postprocessCommand(session *ShellSession, command string
 }
 
 // executeCommand executes the actual shell command.
-func (e *ShellExecutor) executeCommand(ctx context.Context, session *ShellSession, command string) (*CommandResult, error) {
+func (e *ShellExecutor) executeCommand(ctx context.Context, session *ShellSession, command string, opts ExecOptions) (*CommandResult, error) {
 	// Use bash as the shell for consistent behavior
 	cmd := exec.CommandContext(ctx, "/bin/bash", "-c", command)
 
@@ -99,44 +251,57 @@ func (e *ShellExecutor) executeCommand(ctx context.Context, session *ShellSessio
 	cmd.Dir = session.WorkingDirectory
 
 	// Set environment variables
-	env := os.Environ()
+	env := e.baseEnv(session.CleanEnv)
 	for key, value := range session.Environment {
 		env = append(env, fmt.Sprintf("%s=%s", key, value))
 	}
 	cmd.Env = env
 
 	// Execute command and capture both stdout and stderr
-	stdout, stderr, err := e.runCommand(cmd)
-	exitCode := 0
+	stdout, stderr, err := e.runCommand(cmd, opts.Captured, opts.CombineOutput)
+	result := &CommandResult{
+		Stdout:            stdout,
+		Stderr:            stderr,
+		TerminationReason: TerminationExited,
+	}
 
-	if err != nil {
-		// Check for context cancellation/timeout first
-		if ctx.Err() == context.DeadlineExceeded {
-			// Command timed out
-			return nil, fmt.Errorf("command timed out")
-		}
-		// Handle different types of errors
-		if exitError, ok := err.(*exec.ExitError); ok {
-			// Command executed but returned non-zero exit code
-			exitCode = exitError.ExitCode()
-		} else {
+	// A command killed because ctx ended is marked by the caller, which knows the timeout
+	if err != nil && ctx.Err() == nil {
+		var exitError *exec.ExitError
+		if !errors.As(err, &exitError) {
 			// Command failed to execute
 			return nil, fmt.Errorf("failed to execute command: %w", err)
 		}
+
+		// Command executed but returned non-zero exit code or was killed
+		result.ExitCode = exitError.ExitCode()
+		if status, ok := exitError.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+			result.TerminationReason = TerminationSignalled
+			result.Signal = status.Signal().String()
+		}
 	}
 
-	return &CommandResult{
-		Stdout:   stdout,
-		Stderr:   stderr,
-		ExitCode: exitCode,
-	}, nil
+	return result, nil
 }
 
 // runCommand runs the command and captures both stdout and stderr separately.
-func (e *ShellExecutor) runCommand(cmd *exec.Cmd) (stdout, stderr string, err error) {
+// When combine is set, stderr is written to the same buffer as stdout, in the
+// order the command produced it, and the returned stderr is empty. When
+// captured is non-nil it tracks the combined output size as it grows.
+func (e *ShellExecutor) runCommand(cmd *exec.Cmd, captured *atomic.Int64, combine bool) (stdout, stderr string, err error) {
 	var stdoutBuf, stderrBuf strings.Builder
-	cmd.Stdout = &stdoutBuf
-	cmd.Stderr = &stderrBuf
+	var stdoutWriter, stderrWriter io.Writer = &stdoutBuf, &stderrBuf
+	if captured != nil {
+		stdoutWriter = &countingWriter{w: &stdoutBuf, n: captured}
+		stderrWriter = &countingWriter{w: &stderrBuf, n: captured}
+	}
+	if combine {
+		// os/exec shares a single pipe when Stdout and Stderr are the same
+		// writer, so the streams stay interleaved as written
+		stderrWriter = stdoutWriter
+	}
+	cmd.Stdout = stdoutWriter
+	cmd.Stderr = stderrWriter
 
 	err = cmd.Run()
 	stdout = stdoutBuf.String()
@@ -144,6 +309,18 @@ func (e *ShellExecutor) runCommand(cmd *exec.Cmd) (stdout, stderr string, err er
 	return
 }
 
+// countingWriter adds the length of every write to a shared counter.
+type countingWriter struct {
+	w io.Writer
+	n *atomic.Int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n.Add(int64(n))
+	return n, err
+}
+
 // handleCdCommand processes cd commands to update session working directory.
 func (e *ShellExecutor) handleCdCommand(session *ShellSession, command string) error {
 	parts := strings.Fields(command)
@@ -252,7 +429,7 @@ func (e *ShellExecutor) updateWorkingDirectoryFromPwd(session *ShellSession) err
 	cmd.Dir = session.WorkingDirectory
 
 	// Set environment
-	env := os.Environ()
+	env := e.baseEnv(session.CleanEnv)
 	for key, value := range session.Environment {
 		env = append(env, fmt.Sprintf("%s=%s", key, value))
 	}
@@ -277,19 +454,25 @@ func (e *ShellExecutor) ValidateCommand(command string) error {
 		return fmt.Errorf("command cannot be empty")
 	}
 
+	if e.patternErr != nil {
+		return e.patternErr
+	}
+
+	// Allowed dangerous patterns do not lift the binary allowlist
+	if err := e.checkAllowedBinaries(command); err != nil {
+		return err
+	}
+
+	for _, pattern := range e.allowedPatterns {
+		if pattern.re.MatchString(command) {
+			return nil
+		}
+	}
+
 	// Check for dangerous patterns
-	dangerousPatterns := []string{
-		"rm -rf /",
-		":(){ :|:& };:",   // Fork bomb
-		"dd if=/dev/zero", // Dangerous dd usage
-		"mkfs",            // Filesystem creation
-		"fdisk",           // Disk partitioning
-	}
-
-	lowerCmd := strings.ToLower(command)
-	for _, pattern := range dangerousPatterns {
-		if strings.Contains(lowerCmd, pattern) {
-			return fmt.Errorf("command contains dangerous pattern: %s", pattern)
+	for _, pattern := range slices.Concat(defaultDangerousPatterns, e.dangerousPatterns) {
+		if pattern.re.MatchString(command) {
+			return fmt.Errorf("command contains dangerous pattern: %s", pattern.source)
 		}
 	}
 
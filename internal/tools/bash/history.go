@@ -0,0 +1,67 @@
+// Package bash provides the command history tool for persistent sessions.
+package bash
+
+import (
+	"context"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/d-kuro/claude-code-mcp/internal/prompts"
+	"github.com/d-kuro/claude-code-mcp/internal/tools"
+)
+
+// BashHistoryArgs represents the arguments for the BashHistory tool.
+type BashHistoryArgs struct {
+	Limit *int `json:"limit,omitempty"`
+}
+
+// HistoryOutput is a single command in the result of the BashHistory tool.
+type HistoryOutput struct {
+	Command    string    `json:"command"`
+	ExitCode   int       `json:"exit_code"`
+	DurationMs int64     `json:"duration_ms"`
+	StartedAt  time.Time `json:"started_at"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// CreateBashHistoryTool creates the BashHistory tool using MCP SDK patterns.
+func CreateBashHistoryTool(ctx *tools.Context) *tools.ServerTool {
+	handler := func(ctxReq context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[BashHistoryArgs]) (*mcp.CallToolResultFor[any], error) {
+		args := params.Arguments
+
+		if args.Limit != nil && *args.Limit < 1 {
+			return tools.InvalidFieldError("limit", "must be at least 1"), nil
+		}
+
+		history := GetSessionManager().GetHistory(sessionIDFor(ctx.CleanEnv))
+		if args.Limit != nil && *args.Limit < len(history) {
+			history = history[len(history)-*args.Limit:]
+		}
+
+		output := make([]HistoryOutput, 0, len(history))
+		for _, entry := range history {
+			output = append(output, HistoryOutput{
+				Command:    entry.Command,
+				ExitCode:   entry.ExitCode,
+				DurationMs: entry.Duration.Milliseconds(),
+				StartedAt:  entry.StartedAt,
+				Error:      entry.Error,
+			})
+		}
+
+		return tools.JSONResponse(output), nil
+	}
+
+	tool := &mcp.Tool{
+		Name:        "BashHistory",
+		Description: prompts.BashHistoryToolDoc,
+	}
+
+	return &tools.ServerTool{
+		Tool: tool,
+		RegisterFunc: func(server *mcp.Server) {
+			mcp.AddTool(server, tool, handler)
+		},
+	}
+}
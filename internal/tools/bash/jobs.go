@@ -0,0 +1,302 @@
+// Package bash provides background jobs run in persistent sessions and the
+// tool that lists them.
+package bash
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/d-kuro/claude-code-mcp/internal/prompts"
+	"github.com/d-kuro/claude-code-mcp/internal/tools"
+)
+
+// JobStatus is the state of a background job.
+type JobStatus string
+
+// Background job states.
+const (
+	JobRunning   JobStatus = "running"
+	JobCompleted JobStatus = "completed"
+	JobFailed    JobStatus = "failed"
+)
+
+// Limits on background jobs, so that a client cannot start commands or keep
+// their output without bound.
+const (
+	// DefaultMaxBackgroundJobs is how many background jobs may run at once.
+	DefaultMaxBackgroundJobs = 16
+	// DefaultJobRetention is how many finished background jobs are kept; the
+	// oldest are removed beyond it.
+	DefaultJobRetention = 50
+)
+
+// ErrTooManyJobs is returned when a background job is started while the
+// maximum number of background jobs are running.
+var ErrTooManyJobs = errors.New("too many background jobs")
+
+// BackgroundJob describes a command started with StartBackgroundJob.
+type BackgroundJob struct {
+	ID string
+	// Owner is the MCP session that started the job; jobs are listed and
+	// cleared per owner.
+	Owner     string
+	SessionID string
+	Command   string
+	Status    JobStatus
+	// ExitCode is set once the job completed, and is -1 if it failed to run
+	// to completion, e.g. on timeout.
+	ExitCode   int
+	StartedAt  time.Time
+	FinishedAt time.Time
+	// Error is set when the job failed.
+	Error string
+	// Result holds the output of a completed job, or the partial output of
+	// a job killed on timeout or when the server shut down.
+	Result *CommandResult
+
+	// seq orders jobs by start
+	seq int
+}
+
+// Runtime returns how long the job ran, or has been running so far.
+func (j BackgroundJob) Runtime() time.Duration {
+	if j.Status == JobRunning {
+		return time.Since(j.StartedAt)
+	}
+	return j.FinishedAt.Sub(j.StartedAt)
+}
+
+// StartBackgroundJob starts a command for owner in the persistent session
+// selected by opts without waiting for it, and returns the job ID. The job
+// runs in the session's working directory and environment as they are when
+// it starts, alongside the other commands of the session, and cannot change
+// them with cd or export. It runs until it finishes, times out or the session
+// manager shuts down. It fails with ErrTooManyJobs when the maximum number of
+// background jobs are running.
+func (sm *SessionManager) StartBackgroundJob(owner, command string, timeout time.Duration, opts ExecOptions) (string, error) {
+	sessionID := sessionIDFor(opts.CleanEnv)
+
+	sm.mu.Lock()
+	if sm.maxJobs > 0 && sm.runningJobs() >= sm.maxJobs {
+		sm.mu.Unlock()
+		return "", fmt.Errorf("%w: the limit of %d running jobs is reached", ErrTooManyJobs, sm.maxJobs)
+	}
+	sm.nextJobID++
+	job := &BackgroundJob{
+		ID:        fmt.Sprintf("job-%d", sm.nextJobID),
+		Owner:     owner,
+		SessionID: sessionID,
+		Command:   command,
+		Status:    JobRunning,
+		StartedAt: time.Now(),
+		seq:       sm.nextJobID,
+	}
+	sm.jobs[job.ID] = job
+	sm.mu.Unlock()
+
+	sm.wg.Add(1)
+	go func() {
+		defer sm.wg.Done()
+
+		result, err := sm.executeInSnapshot(sm.ctx, sessionID, opts, command, timeout)
+
+		sm.mu.Lock()
+		defer sm.mu.Unlock()
+		defer sm.evictFinishedJobs()
+
+		job.FinishedAt = time.Now()
+		if err != nil {
+			job.Status = JobFailed
+			job.ExitCode = -1
+			job.Error = err.Error()
+			job.Result = result
+			return
+		}
+		job.Status = JobCompleted
+		job.ExitCode = result.ExitCode
+		job.Result = result
+	}()
+
+	return job.ID, nil
+}
+
+// runningJobs counts the running background jobs. The caller must hold sm.mu.
+func (sm *SessionManager) runningJobs() int {
+	running := 0
+	for _, job := range sm.jobs {
+		if job.Status == JobRunning {
+			running++
+		}
+	}
+	return running
+}
+
+// evictFinishedJobs removes the oldest finished background jobs beyond the
+// retention count. The caller must hold sm.mu.
+func (sm *SessionManager) evictFinishedJobs() {
+	if sm.jobRetention <= 0 {
+		return
+	}
+
+	var finished []BackgroundJob
+	for _, job := range sm.jobs {
+		if job.Status != JobRunning {
+			finished = append(finished, *job)
+		}
+	}
+	if len(finished) <= sm.jobRetention {
+		return
+	}
+
+	sortJobs(finished)
+	for _, job := range finished[:len(finished)-sm.jobRetention] {
+		delete(sm.jobs, job.ID)
+	}
+}
+
+// ListBackgroundJobs returns copies of the running and finished background
+// jobs of owner, in the order they were started.
+func (sm *SessionManager) ListBackgroundJobs(owner string) []BackgroundJob {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	jobs := make([]BackgroundJob, 0, len(sm.jobs))
+	for _, job := range sm.jobs {
+		if job.Owner == owner {
+			jobs = append(jobs, *job)
+		}
+	}
+
+	sortJobs(jobs)
+	return jobs
+}
+
+// ClearBackgroundJobs removes the finished background jobs of owner,
+// releasing their output, and returns them in the order they were started.
+// Running jobs are kept.
+func (sm *SessionManager) ClearBackgroundJobs(owner string) []BackgroundJob {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	var cleared []BackgroundJob
+	for id, job := range sm.jobs {
+		if job.Owner == owner && job.Status != JobRunning {
+			delete(sm.jobs, id)
+			cleared = append(cleared, *job)
+		}
+	}
+
+	sortJobs(cleared)
+	return cleared
+}
+
+// sortJobs orders jobs by start.
+func sortJobs(jobs []BackgroundJob) {
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].seq < jobs[j].seq
+	})
+}
+
+// BashJobsArgs represents the arguments for the BashJobs tool.
+type BashJobsArgs struct {
+	ClearFinished *bool `json:"clear_finished,omitempty"`
+}
+
+// JobOutput is a single job in the result of the BashJobs tool.
+type JobOutput struct {
+	ID        string    `json:"id"`
+	Command   string    `json:"command"`
+	Status    JobStatus `json:"status"`
+	ExitCode  *int      `json:"exit_code,omitempty"`
+	StartedAt time.Time `json:"started_at"`
+	RuntimeMs int64     `json:"runtime_ms"`
+	Error     string    `json:"error,omitempty"`
+	Stdout    string    `json:"stdout,omitempty"`
+	Stderr    string    `json:"stderr,omitempty"`
+	Truncated bool      `json:"truncated,omitempty"`
+	// Cleared is set for a finished job removed by clear_finished.
+	Cleared bool `json:"cleared,omitempty"`
+}
+
+// CreateBashJobsTool creates the BashJobs tool using MCP SDK patterns.
+func CreateBashJobsTool(ctx *tools.Context) *tools.ServerTool {
+	handler := func(ctxReq context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[BashJobsArgs]) (*mcp.CallToolResultFor[any], error) {
+		args := params.Arguments
+		sm := GetSessionManager()
+		owner := jobOwner(session)
+
+		// Finished jobs are taken out before the rest are listed, so a job
+		// that finishes in between is reported rather than cleared unseen
+		var cleared []BackgroundJob
+		if args.ClearFinished != nil && *args.ClearFinished {
+			cleared = sm.ClearBackgroundJobs(owner)
+		}
+		jobs := append(cleared, sm.ListBackgroundJobs(owner)...)
+		sortJobs(jobs)
+
+		output := make([]JobOutput, 0, len(jobs))
+		for _, job := range jobs {
+			jobOutput := newJobOutput(job, ctx.StripANSI)
+			jobOutput.Cleared = slices.ContainsFunc(cleared, func(c BackgroundJob) bool { return c.ID == job.ID })
+			output = append(output, jobOutput)
+		}
+
+		return tools.JSONResponse(output), nil
+	}
+
+	tool := &mcp.Tool{
+		Name:        "BashJobs",
+		Description: prompts.BashJobsToolDoc,
+	}
+
+	return &tools.ServerTool{
+		Tool: tool,
+		RegisterFunc: func(server *mcp.Server) {
+			mcp.AddTool(server, tool, handler)
+		},
+	}
+}
+
+// jobOwner returns the owner of the background jobs started in an MCP
+// session: its session ID, which is empty over stdio where there is a
+// single client.
+func jobOwner(session *mcp.ServerSession) string {
+	if session == nil {
+		return ""
+	}
+	return session.ID()
+}
+
+// newJobOutput converts a job into its structured form, with the output of a
+// finished job truncated to the same length as the Bash tool's.
+func newJobOutput(job BackgroundJob, stripANSI bool) JobOutput {
+	output := JobOutput{
+		ID:        job.ID,
+		Command:   job.Command,
+		Status:    job.Status,
+		StartedAt: job.StartedAt,
+		RuntimeMs: job.Runtime().Milliseconds(),
+		Error:     job.Error,
+	}
+	if job.Status != JobRunning {
+		exitCode := job.ExitCode
+		output.ExitCode = &exitCode
+	}
+	if job.Result != nil {
+		output.Stdout, output.Stderr = job.Result.Stdout, job.Result.Stderr
+		if stripANSI {
+			output.Stdout, output.Stderr = tools.StripANSI(output.Stdout), tools.StripANSI(output.Stderr)
+		}
+		if len(output.Stdout) > MaxOutputLength {
+			output.Stdout = output.Stdout[:MaxOutputLength]
+			output.Truncated = true
+		}
+	}
+	return output
+}
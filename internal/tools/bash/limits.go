@@ -0,0 +1,99 @@
+// Package bash provides limits on sessions and concurrently running commands.
+package bash
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	// ErrTooManySessions is returned when a command needs a new session but
+	// the maximum number of live sessions is reached.
+	ErrTooManySessions = errors.New("too many sessions")
+	// ErrTooManyCommands is returned when a command could not start within
+	// the queue timeout because the maximum number of concurrent commands
+	// are running.
+	ErrTooManyCommands = errors.New("too many concurrent commands")
+)
+
+// SetMaxSessions limits the number of live sessions. Zero or a negative
+// value removes the limit. Existing sessions are kept.
+func (sm *SessionManager) SetMaxSessions(limit int) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	sm.maxSessions = max(limit, 0)
+}
+
+// SetMaxConcurrentCommands limits how many commands run at once across all
+// sessions. A command started while the limit is reached waits up to
+// queueTimeout for a running one to finish, and fails with
+// ErrTooManyCommands otherwise; a zero queueTimeout fails it immediately.
+// Zero or a negative limit removes the limit. Commands already running are
+// not counted against a new limit.
+func (sm *SessionManager) SetMaxConcurrentCommands(limit int, queueTimeout time.Duration) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	sm.commandSlots = nil
+	if limit > 0 {
+		sm.commandSlots = make(chan struct{}, limit)
+	}
+	sm.commandQueueTimeout = queueTimeout
+}
+
+// acquireCommandSlot waits for a command to be allowed to run and returns
+// the function that releases its slot once the command finished.
+func (sm *SessionManager) acquireCommandSlot(ctx context.Context) (release func(), err error) {
+	sm.mu.RLock()
+	slots := sm.commandSlots
+	queueTimeout := sm.commandQueueTimeout
+	sm.mu.RUnlock()
+
+	if slots != nil {
+		if err := waitForSlot(ctx, slots, queueTimeout, &sm.queuedCommands); err != nil {
+			return nil, err
+		}
+	}
+
+	sm.activeCommands.Add(1)
+	return func() {
+		sm.activeCommands.Add(-1)
+		if slots != nil {
+			<-slots
+		}
+	}, nil
+}
+
+// waitForSlot takes a slot of slots, waiting up to queueTimeout while
+// counting the wait in queued.
+func waitForSlot(ctx context.Context, slots chan struct{}, queueTimeout time.Duration, queued *atomic.Int64) error {
+	select {
+	case slots <- struct{}{}:
+		return nil
+	default:
+	}
+
+	limitErr := fmt.Errorf("%w: the limit of %d is reached", ErrTooManyCommands, cap(slots))
+	if queueTimeout <= 0 {
+		return limitErr
+	}
+
+	queued.Add(1)
+	defer queued.Add(-1)
+
+	timer := time.NewTimer(queueTimeout)
+	defer timer.Stop()
+
+	select {
+	case slots <- struct{}{}:
+		return nil
+	case <-timer.C:
+		return fmt.Errorf("%w after waiting %v", limitErr, queueTimeout)
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,101 @@
+// Package bash provides detection of commands the shell could not find.
+package bash
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/d-kuro/claude-code-mcp/internal/tools"
+)
+
+// exitCodeCommandNotFound is the exit code bash uses for an unknown command.
+const exitCodeCommandNotFound = 127
+
+// commandNotFoundPattern matches bash's error for an unknown command, e.g.
+// "bash: line 1: foo: command not found".
+var commandNotFoundPattern = regexp.MustCompile(`([^\s:]+): command not found`)
+
+// blockedCommandLister is implemented by validators that can list the
+// commands they block, such as security.DefaultValidator.
+type blockedCommandLister interface {
+	BlockedCommands() []string
+}
+
+// missingCommand returns the name of the command bash reported as not found,
+// if the result is a "command not found" failure.
+func missingCommand(result *CommandResult) (string, bool) {
+	if result.ExitCode != exitCodeCommandNotFound {
+		return "", false
+	}
+
+	// Combined output puts the error in Stdout
+	for _, output := range []string{result.Stderr, result.Stdout} {
+		if match := commandNotFoundPattern.FindStringSubmatch(output); match != nil {
+			return match[1], true
+		}
+	}
+	return "", false
+}
+
+// commandNotFoundHint returns a suggestion for the next step when the
+// command was not found, or "" for any other result. When the missing
+// command resembles one the validator blocks, the hint says that the
+// intended command would be rejected anyway.
+func commandNotFoundHint(result *CommandResult, validator tools.Validator) string {
+	name, ok := missingCommand(result)
+	if !ok {
+		return ""
+	}
+
+	hint := fmt.Sprintf("Hint: command %q was not found. Check that it is installed and that its directory is on PATH.", name)
+	if lister, ok := validator.(blockedCommandLister); ok {
+		for _, blocked := range lister.BlockedCommands() {
+			if resemblesCommand(name, blocked) {
+				hint += fmt.Sprintf(" Note that it resembles %q, which the security validator blocks.", blocked)
+				break
+			}
+		}
+	}
+	return hint
+}
+
+// resemblesCommand reports whether name matches the blocked command pattern
+// ignoring case, or is within two edits of a literal blocked command name
+// of at least four characters. Shorter names such as "rm" only match
+// exactly, as nearly any short name is within two edits of them.
+func resemblesCommand(name, blocked string) bool {
+	name = strings.ToLower(name)
+	blocked = strings.ToLower(blocked)
+
+	if matched, _ := filepath.Match(blocked, name); matched {
+		return true
+	}
+	if strings.ContainsAny(blocked, "*?[") || len(blocked) < 4 {
+		return false
+	}
+	return editDistance(name, blocked) <= 2
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
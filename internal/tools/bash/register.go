@@ -2,12 +2,44 @@
 package bash
 
 import (
+	"maps"
+
 	"github.com/d-kuro/claude-code-mcp/internal/tools"
 )
 
 // CreateBashTools creates all bash operation tools using MCP SDK patterns.
 func CreateBashTools(ctx *tools.Context) []*tools.ServerTool {
+	if ctx.HistorySize != 0 {
+		GetSessionManager().SetHistorySize(ctx.HistorySize)
+	}
+	if len(ctx.AllowedEnvVars) > 0 {
+		GetSessionManager().SetAllowedEnvVars(ctx.AllowedEnvVars)
+	}
+	if ctx.MaxSessions > 0 {
+		GetSessionManager().SetMaxSessions(ctx.MaxSessions)
+	}
+	if ctx.MaxConcurrentCommands > 0 {
+		GetSessionManager().SetMaxConcurrentCommands(ctx.MaxConcurrentCommands, ctx.CommandQueueTimeout)
+	}
+	if env := sessionSeedEnv(ctx); len(env) > 0 {
+		GetSessionManager().SetDefaultSessionEnv(env)
+	}
+
 	return []*tools.ServerTool{
 		CreateBashTool(ctx),
+		CreateBashHistoryTool(ctx),
+		CreateBashEnvTool(ctx),
+		CreateBashJobsTool(ctx),
+	}
+}
+
+// sessionSeedEnv returns the variables new sessions start with: the
+// non-interactive variables, overridden by the configured session defaults.
+func sessionSeedEnv(ctx *tools.Context) map[string]string {
+	env := maps.Clone(ctx.NonInteractiveEnv)
+	if env == nil {
+		env = make(map[string]string, len(ctx.DefaultSessionEnv))
 	}
+	maps.Copy(env, ctx.DefaultSessionEnv)
+	return env
 }
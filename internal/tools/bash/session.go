@@ -5,9 +5,13 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"maps"
 	"os"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/d-kuro/claude-code-mcp/internal/tools"
 )
 
 // SessionManager manages persistent shell sessions with TTL-based cleanup.
@@ -16,10 +20,24 @@ type SessionManager struct {
 	sessions       map[string]*ShellSession
 	executor       *ShellExecutor
 	sessionTimeout time.Duration
+	historySize    int
+	defaultEnv     map[string]string
+	jobs           map[string]*BackgroundJob
+	nextJobID      int
+	maxJobs        int
+	jobRetention   int
+	maxSessions    int
 	cleanupTicker  *time.Ticker
 	ctx            context.Context
 	cancel         context.CancelFunc
 	wg             sync.WaitGroup
+
+	// commandSlots bounds concurrent commands when non-nil; see
+	// SetMaxConcurrentCommands.
+	commandSlots        chan struct{}
+	commandQueueTimeout time.Duration
+	activeCommands      atomic.Int64
+	queuedCommands      atomic.Int64
 }
 
 // ShellSession represents a persistent shell session.
@@ -30,8 +48,49 @@ type ShellSession struct {
 	CreatedAt        time.Time
 	LastUsed         time.Time
 	AccessCount      int64
+	// CleanEnv starts commands from the allow-listed environment instead of
+	// the server process environment.
+	CleanEnv bool
+	// History holds the most recent commands run in the session, oldest first.
+	History []HistoryEntry
+
+	// turn is held by the command running in the session. Commands share
+	// the session's working directory and environment, so they run one at
+	// a time.
+	turn chan struct{}
+}
+
+// DefaultHistorySize is the number of commands kept in each session's history.
+const DefaultHistorySize = 100
+
+// HistoryEntry records a command executed in a session.
+type HistoryEntry struct {
+	Command   string
+	ExitCode  int
+	Duration  time.Duration
+	StartedAt time.Time
+	// Error is set when the command could not run to completion, e.g. on timeout.
+	// ExitCode is -1 in that case.
+	Error string
 }
 
+// TerminationReason tells how a command ended.
+type TerminationReason string
+
+// Termination reasons of a command.
+const (
+	// TerminationExited means the command exited on its own, successfully or not.
+	TerminationExited TerminationReason = "exited"
+	// TerminationTimeout means the command was killed when its timeout expired.
+	TerminationTimeout TerminationReason = "timeout"
+	// TerminationCancelled means the command was killed because the request
+	// running it was cancelled.
+	TerminationCancelled TerminationReason = "cancelled"
+	// TerminationSignalled means the command was killed by a signal it did
+	// not get from the server, e.g. a crash or an external kill.
+	TerminationSignalled TerminationReason = "signalled"
+)
+
 // CommandResult represents the result of a command execution.
 type CommandResult struct {
 	Stdout           string
@@ -39,6 +98,11 @@ type CommandResult struct {
 	ExitCode         int
 	Duration         time.Duration
 	WorkingDirectory string
+	// TerminationReason tells whether the command exited or was killed, and
+	// Signal names the signal that killed a signalled command. ExitCode is -1
+	// for a command that did not exit.
+	TerminationReason TerminationReason
+	Signal            string
 }
 
 var (
@@ -75,6 +139,10 @@ func NewSessionManagerWithConfig(sessionTimeout, cleanupInterval time.Duration)
 		sessions:       make(map[string]*ShellSession),
 		executor:       NewShellExecutor(),
 		sessionTimeout: sessionTimeout,
+		historySize:    DefaultHistorySize,
+		jobs:           make(map[string]*BackgroundJob),
+		maxJobs:        DefaultMaxBackgroundJobs,
+		jobRetention:   DefaultJobRetention,
 		cleanupTicker:  time.NewTicker(cleanupInterval),
 		ctx:            ctx,
 		cancel:         cancel,
@@ -86,42 +154,149 @@ func NewSessionManagerWithConfig(sessionTimeout, cleanupInterval time.Duration)
 	return sm
 }
 
+// ExecOptions controls how SessionManager.Execute runs a command.
+type ExecOptions struct {
+	// CleanEnv runs the command in a session whose environment starts from
+	// tools.CleanEnvVars instead of the server process environment.
+	CleanEnv bool
+	// Captured, when non-nil, is advanced by every byte of stdout and stderr
+	// as the command produces it, so callers can report progress.
+	Captured *atomic.Int64
+	// CombineOutput sends stderr to the same stream as stdout so that their
+	// ordering is preserved. The result then has all output in Stdout.
+	CombineOutput bool
+}
+
 // ExecuteCommand executes a command in the default persistent session.
 func (sm *SessionManager) ExecuteCommand(ctx context.Context, command string, timeout time.Duration) (*CommandResult, error) {
-	sessionID := "default"
+	return sm.Execute(ctx, command, timeout, ExecOptions{})
+}
+
+// ExecuteCommandCleanEnv executes a command in a persistent session whose
+// environment starts from tools.CleanEnvVars plus its own exports, rather
+// than the server process environment.
+func (sm *SessionManager) ExecuteCommandCleanEnv(ctx context.Context, command string, timeout time.Duration) (*CommandResult, error) {
+	return sm.Execute(ctx, command, timeout, ExecOptions{CleanEnv: true})
+}
+
+// Execute executes a command in the persistent session selected by opts.
+func (sm *SessionManager) Execute(ctx context.Context, command string, timeout time.Duration, opts ExecOptions) (*CommandResult, error) {
+	return sm.executeInSession(ctx, sessionIDFor(opts.CleanEnv), opts, command, timeout)
+}
+
+// sessionIDFor returns the ID of the persistent session used for commands
+// run with or without a clean environment.
+func sessionIDFor(cleanEnv bool) string {
+	if cleanEnv {
+		return "clean"
+	}
+	return "default"
+}
+
+// executeInSession executes a command in the named session, creating it if
+// needed. A command waits for the one running in the same session to finish,
+// and fails without running when ctx is done before its turn comes.
+func (sm *SessionManager) executeInSession(ctx context.Context, sessionID string, opts ExecOptions, command string, timeout time.Duration) (*CommandResult, error) {
+	session, executor, err := sm.openSession(sessionID, opts.CleanEnv)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := sm.takeTurn(ctx, session); err != nil {
+		return nil, err
+	}
+	defer func() { <-session.turn }()
+
+	// The session is taken first so that a queued command does not hold a
+	// command slot that a command in another session could use
+	release, err := sm.acquireCommandSlot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	// Execute command with session context
+	startedAt := time.Now()
+	result, err := executor.executeInSession(ctx, session, command, timeout, opts)
+	sm.recordCommand(session, command, startedAt, result, err)
 
+	return result, err
+}
+
+// executeInSnapshot runs a background job in a copy of the named session's
+// working directory and environment, taken once the session is free. The job
+// cannot change the session, so it runs alongside the session's other
+// commands instead of holding its turn.
+func (sm *SessionManager) executeInSnapshot(ctx context.Context, sessionID string, opts ExecOptions, command string, timeout time.Duration) (*CommandResult, error) {
+	session, executor, err := sm.openSession(sessionID, opts.CleanEnv)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := sm.takeTurn(ctx, session); err != nil {
+		return nil, err
+	}
+	dir, env := session.WorkingDirectory, maps.Clone(session.Environment)
+	<-session.turn
+
+	startedAt := time.Now()
+	result, err := sm.executeIsolated(ctx, executor, command, dir, env, timeout, opts)
+	sm.recordCommand(session, command, startedAt, result, err)
+
+	return result, err
+}
+
+// executeIsolated runs a command with executor.ExecuteIsolated once the
+// concurrent command limit allows it, so commands outside the sessions count
+// against the limit too.
+func (sm *SessionManager) executeIsolated(ctx context.Context, executor *ShellExecutor, command, dir string, env map[string]string, timeout time.Duration, opts ExecOptions) (*CommandResult, error) {
+	release, err := sm.acquireCommandSlot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	return executor.ExecuteIsolated(ctx, command, dir, env, timeout, opts)
+}
+
+// takeTurn waits until no other command runs in the session and takes its
+// turn, which the caller releases by receiving from session.turn.
+func (sm *SessionManager) takeTurn(ctx context.Context, session *ShellSession) error {
+	select {
+	case session.turn <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("command cancelled while waiting for session %s: %w", session.ID, ctx.Err())
+	}
+}
+
+// openSession returns the named session, creating it if needed, along with
+// the executor to run its commands, and marks the session as used.
+func (sm *SessionManager) openSession(sessionID string, cleanEnv bool) (*ShellSession, *ShellExecutor, error) {
 	sm.mu.Lock()
 	session, exists := sm.sessions[sessionID]
 	if !exists {
+		if sm.maxSessions > 0 && len(sm.sessions) >= sm.maxSessions {
+			sm.mu.Unlock()
+			return nil, nil, fmt.Errorf("%w: the limit of %d is reached", ErrTooManySessions, sm.maxSessions)
+		}
+
 		// Create new session
 		cwd, err := os.Getwd()
 		if err != nil {
 			sm.mu.Unlock()
-			return nil, fmt.Errorf("failed to get current working directory: %w", err)
+			return nil, nil, fmt.Errorf("failed to get current working directory: %w", err)
 		}
 
 		session = &ShellSession{
 			ID:               sessionID,
 			WorkingDirectory: cwd,
-			Environment:      make(map[string]string),
+			Environment:      sm.newSessionEnv(cleanEnv),
+			CleanEnv:         cleanEnv,
 			CreatedAt:        time.Now(),
 			LastUsed:         time.Now(),
 			AccessCount:      0,
-		}
-
-		// Copy current environment
-		for _, env := range os.Environ() {
-			if len(env) > 0 {
-				// Parse key=value format
-				for i := 0; i < len(env); i++ {
-					if env[i] == '=' && i > 0 {
-						key := env[:i]
-						value := env[i+1:]
-						session.Environment[key] = value
-						break
-					}
-				}
-			}
+			turn:             make(chan struct{}, 1),
 		}
 
 		sm.sessions[sessionID] = session
@@ -130,10 +305,94 @@ func (sm *SessionManager) ExecuteCommand(ctx context.Context, command string, ti
 	// Update last used time and access count
 	session.LastUsed = time.Now()
 	session.AccessCount++
+	executor := sm.executor
 	sm.mu.Unlock()
 
-	// Execute command with session context
-	return sm.executor.ExecuteInSession(ctx, session, command, timeout)
+	return session, executor, nil
+}
+
+// recordCommand adds a command that started at startedAt, and its outcome,
+// to the session history.
+func (sm *SessionManager) recordCommand(session *ShellSession, command string, startedAt time.Time, result *CommandResult, err error) {
+	entry := HistoryEntry{Command: command, StartedAt: startedAt, Duration: time.Since(startedAt)}
+	if err != nil {
+		entry.ExitCode = -1
+		entry.Error = err.Error()
+	} else {
+		entry.ExitCode = result.ExitCode
+		entry.Duration = result.Duration
+	}
+	sm.recordHistory(session, entry)
+}
+
+// SetHistorySize sets how many commands each session keeps in its history.
+// Existing histories are trimmed on their next command.
+func (sm *SessionManager) SetHistorySize(size int) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	sm.historySize = size
+}
+
+// SetAllowedEnvVars restricts the process environment variables passed to
+// commands to the named ones. Sessions created before the call keep the
+// environment they started with.
+func (sm *SessionManager) SetAllowedEnvVars(names []string) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	sm.executor = NewShellExecutor().WithAllowedEnvVars(names)
+}
+
+// SetDefaultSessionEnv sets variables that every new session starts with,
+// overriding process environment variables of the same name. Sessions
+// created before the call keep the environment they started with.
+func (sm *SessionManager) SetDefaultSessionEnv(env map[string]string) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	sm.defaultEnv = maps.Clone(env)
+}
+
+// newSessionEnv returns the environment of a new session: the executor's
+// base environment with the default session variables on top. The caller
+// must hold sm.mu.
+func (sm *SessionManager) newSessionEnv(cleanEnv bool) map[string]string {
+	env := tools.EnvMap(sm.executor.baseEnv(cleanEnv))
+	maps.Copy(env, sm.defaultEnv)
+	return env
+}
+
+// recordHistory appends an entry to the session history, dropping the oldest
+// entries beyond the configured size.
+func (sm *SessionManager) recordHistory(session *ShellSession, entry HistoryEntry) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if sm.historySize <= 0 {
+		session.History = nil
+		return
+	}
+
+	session.History = append(session.History, entry)
+	if excess := len(session.History) - sm.historySize; excess > 0 {
+		session.History = append([]HistoryEntry(nil), session.History[excess:]...)
+	}
+}
+
+// GetHistory returns a copy of the command history of a session, oldest first.
+func (sm *SessionManager) GetHistory(sessionID string) []HistoryEntry {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	session, exists := sm.sessions[sessionID]
+	if !exists {
+		return nil
+	}
+
+	history := make([]HistoryEntry, len(session.History))
+	copy(history, session.History)
+	return history
 }
 
 // GetSession returns a session by ID and updates its last used time.
@@ -211,8 +470,9 @@ func (sm *SessionManager) cleanupSessionResources(session *ShellSession) {
 	// - Close file handles
 	// - Clean temporary files
 	// - Reset environment variables
-	// For now, we just clear the environment map
+	// For now, we just clear the environment map and history
 	session.Environment = nil
+	session.History = nil
 }
 
 // Shutdown gracefully shuts down the session manager.
@@ -250,6 +510,10 @@ func (sm *SessionManager) GetSessionStats() map[string]interface{} {
 		"oldest_session":     time.Time{},
 		"newest_session":     time.Time{},
 		"total_access_count": int64(0),
+		"max_sessions":       sm.maxSessions,
+		"active_commands":    sm.activeCommands.Load(),
+		"queued_commands":    sm.queuedCommands.Load(),
+		"max_commands":       cap(sm.commandSlots),
 	}
 
 	if len(sm.sessions) > 0 {
@@ -2,8 +2,11 @@ package bash
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -89,6 +92,33 @@ func TestExecuteCommand_NewSession(t *testing.T) {
 	}
 }
 
+func TestExecute_CombineOutput(t *testing.T) {
+	sm := NewSessionManagerWithConfig(5*time.Minute, 1*time.Minute)
+	defer sm.Shutdown()
+
+	ctx := context.Background()
+	command := "echo out1; echo err1 >&2; echo out2; echo err2 >&2"
+
+	separate, err := sm.Execute(ctx, command, 5*time.Second, ExecOptions{})
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if separate.Stdout != "out1\nout2\n" || separate.Stderr != "err1\nerr2\n" {
+		t.Errorf("Expected separate streams by default, got stdout %q and stderr %q", separate.Stdout, separate.Stderr)
+	}
+
+	combined, err := sm.Execute(ctx, command, 5*time.Second, ExecOptions{CombineOutput: true})
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if combined.Stdout != "out1\nerr1\nout2\nerr2\n" {
+		t.Errorf("Expected interleaved output, got %q", combined.Stdout)
+	}
+	if combined.Stderr != "" {
+		t.Errorf("Expected no separate stderr, got %q", combined.Stderr)
+	}
+}
+
 func TestExecuteCommand_PersistentSession(t *testing.T) {
 	sm := NewSessionManagerWithConfig(5*time.Minute, 1*time.Minute)
 	defer sm.Shutdown()
@@ -117,6 +147,470 @@ func TestExecuteCommand_PersistentSession(t *testing.T) {
 	}
 }
 
+func TestExecuteCommandCleanEnv(t *testing.T) {
+	t.Setenv("CLAUDE_CODE_MCP_TEST_SECRET", "s3cret")
+
+	sm := NewSessionManagerWithConfig(5*time.Minute, 1*time.Minute)
+	defer sm.Shutdown()
+
+	ctx := context.Background()
+	command := "echo \"secret=$CLAUDE_CODE_MCP_TEST_SECRET path=${PATH:+set}\""
+
+	result, err := sm.ExecuteCommandCleanEnv(ctx, command, 5*time.Second)
+	if err != nil {
+		t.Fatalf("Clean env command failed: %v", err)
+	}
+	if result.Stdout != "secret= path=set\n" {
+		t.Errorf("Expected secret to be absent and PATH to be kept, got %q", result.Stdout)
+	}
+
+	// Session exports still apply in the clean environment
+	if _, err := sm.ExecuteCommandCleanEnv(ctx, "export SESSION_VAR=exported", 5*time.Second); err != nil {
+		t.Fatalf("Export command failed: %v", err)
+	}
+	result, err = sm.ExecuteCommandCleanEnv(ctx, "echo $SESSION_VAR", 5*time.Second)
+	if err != nil {
+		t.Fatalf("Echo command failed: %v", err)
+	}
+	if result.Stdout != "exported\n" {
+		t.Errorf("Expected exported variable, got %q", result.Stdout)
+	}
+
+	// The inherited environment is unchanged for the default session
+	result, err = sm.ExecuteCommand(ctx, command, 5*time.Second)
+	if err != nil {
+		t.Fatalf("Inherited env command failed: %v", err)
+	}
+	if result.Stdout != "secret=s3cret path=set\n" {
+		t.Errorf("Expected inherited secret, got %q", result.Stdout)
+	}
+}
+
+func TestSetAllowedEnvVars(t *testing.T) {
+	t.Setenv("CLAUDE_CODE_MCP_TEST_SECRET", "s3cret")
+	t.Setenv("CLAUDE_CODE_MCP_TEST_ALLOWED", "visible")
+
+	sm := NewSessionManagerWithConfig(5*time.Minute, 1*time.Minute)
+	defer sm.Shutdown()
+	sm.SetAllowedEnvVars([]string{"PATH", "CLAUDE_CODE_MCP_TEST_ALLOWED"})
+
+	ctx := context.Background()
+	command := "echo \"allowed=$CLAUDE_CODE_MCP_TEST_ALLOWED secret=$CLAUDE_CODE_MCP_TEST_SECRET home=${HOME:+set}\""
+
+	for _, cleanEnv := range []bool{false, true} {
+		result, err := sm.Execute(ctx, command, 5*time.Second, ExecOptions{CleanEnv: cleanEnv})
+		if err != nil {
+			t.Fatalf("Command failed: %v", err)
+		}
+		if result.Stdout != "allowed=visible secret= home=\n" {
+			t.Errorf("Expected only allowed variables (cleanEnv=%v), got %q", cleanEnv, result.Stdout)
+		}
+	}
+
+	// Session exports still apply alongside the allowed variables
+	if _, err := sm.ExecuteCommand(ctx, "export SESSION_VAR=exported", 5*time.Second); err != nil {
+		t.Fatalf("Export command failed: %v", err)
+	}
+	result, err := sm.ExecuteCommand(ctx, "echo $SESSION_VAR", 5*time.Second)
+	if err != nil {
+		t.Fatalf("Echo command failed: %v", err)
+	}
+	if result.Stdout != "exported\n" {
+		t.Errorf("Expected exported variable, got %q", result.Stdout)
+	}
+}
+
+func TestSetDefaultSessionEnv(t *testing.T) {
+	t.Setenv("TERM", "xterm-256color")
+
+	sm := NewSessionManagerWithConfig(5*time.Minute, 1*time.Minute)
+	defer sm.Shutdown()
+	sm.SetDefaultSessionEnv(map[string]string{"CI": "1", "TERM": "dumb", "NO_COLOR": "1"})
+
+	ctx := context.Background()
+	for _, cleanEnv := range []bool{false, true} {
+		result, err := sm.Execute(ctx, "echo \"$CI $TERM $NO_COLOR\"", 5*time.Second, ExecOptions{CleanEnv: cleanEnv})
+		if err != nil {
+			t.Fatalf("Command failed: %v", err)
+		}
+		if result.Stdout != "1 dumb 1\n" {
+			t.Errorf("Expected the seeded variables in the first command (cleanEnv=%v), got %q", cleanEnv, result.Stdout)
+		}
+	}
+
+	// Session exports override the seeded values
+	if _, err := sm.ExecuteCommand(ctx, "export CI=0", 5*time.Second); err != nil {
+		t.Fatalf("Export command failed: %v", err)
+	}
+	result, err := sm.ExecuteCommand(ctx, "echo $CI", 5*time.Second)
+	if err != nil {
+		t.Fatalf("Echo command failed: %v", err)
+	}
+	if result.Stdout != "0\n" {
+		t.Errorf("Expected the exported value, got %q", result.Stdout)
+	}
+}
+
+func TestMaxConcurrentCommands(t *testing.T) {
+	ctx := context.Background()
+
+	// startBlocking runs n commands in separate sessions that wait until the
+	// returned function is called, after they all hold a command slot.
+	startBlocking := func(t *testing.T, sm *SessionManager, n int) (release func()) {
+		t.Helper()
+		dir := t.TempDir()
+		command := "while [ ! -e " + filepath.Join(dir, "release") + " ]; do sleep 0.01; done"
+
+		var wg sync.WaitGroup
+		for i := range n {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, err := sm.executeInSession(ctx, fmt.Sprintf("blocking-%d", i), ExecOptions{}, command, 10*time.Second); err != nil {
+					t.Errorf("Blocking command failed: %v", err)
+				}
+			}()
+		}
+
+		release = func() {
+			if err := os.WriteFile(filepath.Join(dir, "release"), nil, 0644); err != nil {
+				t.Errorf("Failed to release commands: %v", err)
+			}
+			wg.Wait()
+		}
+		t.Cleanup(release)
+
+		waitForStat(t, sm, "active_commands", int64(n))
+		return release
+	}
+
+	t.Run("over the limit", func(t *testing.T) {
+		sm := NewSessionManagerWithConfig(5*time.Minute, 1*time.Minute)
+		defer sm.Shutdown()
+		sm.SetMaxConcurrentCommands(2, 0)
+
+		release := startBlocking(t, sm, 2)
+		if _, err := sm.ExecuteCommand(ctx, "echo over", 5*time.Second); !errors.Is(err, ErrTooManyCommands) {
+			t.Errorf("Expected ErrTooManyCommands over the limit, got %v", err)
+		}
+		if stats := sm.GetSessionStats(); stats["max_commands"] != 2 {
+			t.Errorf("Expected max_commands 2, got %v", stats["max_commands"])
+		}
+
+		release()
+		if _, err := sm.ExecuteCommand(ctx, "echo after", 5*time.Second); err != nil {
+			t.Errorf("Expected a command to run once slots are free, got %v", err)
+		}
+		if active := sm.GetSessionStats()["active_commands"]; active != int64(0) {
+			t.Errorf("Expected no active commands, got %v", active)
+		}
+	})
+
+	t.Run("queued", func(t *testing.T) {
+		sm := NewSessionManagerWithConfig(5*time.Minute, 1*time.Minute)
+		defer sm.Shutdown()
+		sm.SetMaxConcurrentCommands(2, 5*time.Second)
+
+		release := startBlocking(t, sm, 2)
+		queued := make(chan error, 1)
+		go func() {
+			result, err := sm.ExecuteCommand(ctx, "echo queued", 5*time.Second)
+			if err == nil && result.Stdout != "queued\n" {
+				err = fmt.Errorf("unexpected output %q", result.Stdout)
+			}
+			queued <- err
+		}()
+		waitForStat(t, sm, "queued_commands", int64(1))
+
+		release()
+		if err := <-queued; err != nil {
+			t.Errorf("Expected the queued command to run, got %v", err)
+		}
+	})
+
+	t.Run("queue timeout", func(t *testing.T) {
+		sm := NewSessionManagerWithConfig(5*time.Minute, 1*time.Minute)
+		defer sm.Shutdown()
+		sm.SetMaxConcurrentCommands(1, 50*time.Millisecond)
+
+		startBlocking(t, sm, 1)
+		if _, err := sm.ExecuteCommand(ctx, "echo late", 5*time.Second); !errors.Is(err, ErrTooManyCommands) {
+			t.Errorf("Expected ErrTooManyCommands after the queue timeout, got %v", err)
+		}
+	})
+
+	t.Run("isolated commands", func(t *testing.T) {
+		sm := NewSessionManagerWithConfig(5*time.Minute, 1*time.Minute)
+		defer sm.Shutdown()
+		sm.SetMaxConcurrentCommands(2, 0)
+
+		dir := t.TempDir()
+		command := "while [ ! -e " + filepath.Join(dir, "release") + " ]; do sleep 0.01; done"
+		var wg sync.WaitGroup
+		for range 2 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, err := sm.executeIsolated(ctx, NewShellExecutor(), command, dir, nil, 10*time.Second, ExecOptions{}); err != nil {
+					t.Errorf("Blocking isolated command failed: %v", err)
+				}
+			}()
+		}
+		release := func() {
+			if err := os.WriteFile(filepath.Join(dir, "release"), nil, 0644); err != nil {
+				t.Errorf("Failed to release commands: %v", err)
+			}
+			wg.Wait()
+		}
+		t.Cleanup(release)
+		waitForStat(t, sm, "active_commands", int64(2))
+
+		if _, err := sm.executeIsolated(ctx, NewShellExecutor(), "echo over", dir, nil, 5*time.Second, ExecOptions{}); !errors.Is(err, ErrTooManyCommands) {
+			t.Errorf("Expected ErrTooManyCommands for an isolated command over the limit, got %v", err)
+		}
+		if _, err := sm.ExecuteCommand(ctx, "echo over", 5*time.Second); !errors.Is(err, ErrTooManyCommands) {
+			t.Errorf("Expected isolated commands to count against the limit of session commands, got %v", err)
+		}
+
+		release()
+		if result, err := sm.executeIsolated(ctx, NewShellExecutor(), "echo after", dir, nil, 5*time.Second, ExecOptions{}); err != nil || result.Stdout != "after\n" {
+			t.Errorf("Expected an isolated command to run once slots are free, got %v, %v", result, err)
+		}
+	})
+}
+
+// waitForStat waits until a session statistic has the wanted value.
+func waitForStat(t *testing.T, sm *SessionManager, key string, want any) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for sm.GetSessionStats()[key] != want {
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for %s to be %v, stats: %v", key, want, sm.GetSessionStats())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestExecuteCommand_SessionQueueRespectsContext(t *testing.T) {
+	sm := NewSessionManagerWithConfig(5*time.Minute, 1*time.Minute)
+	defer sm.Shutdown()
+
+	dir := t.TempDir()
+	command := "while [ ! -e " + filepath.Join(dir, "release") + " ]; do sleep 0.01; done; echo first"
+	first := make(chan error, 1)
+	go func() {
+		result, err := sm.ExecuteCommand(context.Background(), command, 10*time.Second)
+		if err == nil && result.Stdout != "first\n" {
+			err = fmt.Errorf("unexpected output %q", result.Stdout)
+		}
+		first <- err
+	}()
+	waitForStat(t, sm, "active_commands", int64(1))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := sm.ExecuteCommand(ctx, "echo second", 10*time.Second)
+	if err == nil || !strings.Contains(err.Error(), "cancelled while waiting for session") {
+		t.Fatalf("Expected the queued command to be cancelled while waiting, got %v", err)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the error to wrap the context error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Expected the queued command to fail promptly, took %v", elapsed)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "release"), nil, 0644); err != nil {
+		t.Fatalf("Failed to release command: %v", err)
+	}
+	if err := <-first; err != nil {
+		t.Errorf("Expected the running command to finish, got %v", err)
+	}
+
+	// The cancelled command never ran, so it is not in the history
+	for _, entry := range sm.GetHistory("default") {
+		if entry.Command == "echo second" {
+			t.Error("Expected the cancelled command to be left out of the history")
+		}
+	}
+	if result, err := sm.ExecuteCommand(context.Background(), "echo third", 5*time.Second); err != nil || result.Stdout != "third\n" {
+		t.Errorf("Expected the session to accept commands again, got %v (err %v)", result, err)
+	}
+}
+
+func TestMaxSessions(t *testing.T) {
+	sm := NewSessionManagerWithConfig(5*time.Minute, 1*time.Minute)
+	defer sm.Shutdown()
+	sm.SetMaxSessions(1)
+
+	ctx := context.Background()
+	if _, err := sm.ExecuteCommand(ctx, "true", 5*time.Second); err != nil {
+		t.Fatalf("ExecuteCommand failed: %v", err)
+	}
+	if _, err := sm.ExecuteCommandCleanEnv(ctx, "true", 5*time.Second); !errors.Is(err, ErrTooManySessions) {
+		t.Errorf("Expected ErrTooManySessions for a second session, got %v", err)
+	}
+	if _, err := sm.ExecuteCommand(ctx, "true", 5*time.Second); err != nil {
+		t.Errorf("Expected the existing session to keep working, got %v", err)
+	}
+	if stats := sm.GetSessionStats(); stats["max_sessions"] != 1 || stats["total_sessions"] != 1 {
+		t.Errorf("Unexpected stats: %v", stats)
+	}
+}
+
+func TestSessionHistory(t *testing.T) {
+	sm := NewSessionManagerWithConfig(5*time.Minute, 1*time.Minute)
+	defer sm.Shutdown()
+
+	ctx := context.Background()
+	commands := []struct {
+		command  string
+		exitCode int
+	}{
+		{"echo one", 0},
+		{"false", 1},
+		{"exit 3", 3},
+	}
+	for _, c := range commands {
+		if _, err := sm.ExecuteCommand(ctx, c.command, 5*time.Second); err != nil {
+			t.Fatalf("ExecuteCommand(%q) failed: %v", c.command, err)
+		}
+	}
+	if _, err := sm.ExecuteCommand(ctx, "sleep 1", 50*time.Millisecond); err == nil {
+		t.Fatal("Expected timeout error")
+	}
+
+	history := sm.GetHistory("default")
+	if len(history) != 4 {
+		t.Fatalf("Expected 4 history entries, got %d", len(history))
+	}
+	for i, c := range commands {
+		if history[i].Command != c.command || history[i].ExitCode != c.exitCode {
+			t.Errorf("Entry %d: expected %q with exit code %d, got %q with exit code %d",
+				i, c.command, c.exitCode, history[i].Command, history[i].ExitCode)
+		}
+		if history[i].Error != "" {
+			t.Errorf("Entry %d: unexpected error %q", i, history[i].Error)
+		}
+		if i > 0 && history[i].StartedAt.Before(history[i-1].StartedAt) {
+			t.Errorf("Entry %d started before entry %d", i, i-1)
+		}
+	}
+	if last := history[3]; last.ExitCode != -1 || last.Error == "" {
+		t.Errorf("Expected timed out command to have exit code -1 and an error, got %+v", last)
+	}
+
+	// The history is capped at the configured size, keeping the newest entries
+	sm.SetHistorySize(2)
+	if _, err := sm.ExecuteCommand(ctx, "echo last", 5*time.Second); err != nil {
+		t.Fatalf("ExecuteCommand failed: %v", err)
+	}
+	history = sm.GetHistory("default")
+	if len(history) != 2 || history[0].Command != "sleep 1" || history[1].Command != "echo last" {
+		t.Errorf("Expected the two newest commands, got %+v", history)
+	}
+
+	if history := sm.GetHistory("missing"); history != nil {
+		t.Errorf("Expected nil history for unknown session, got %+v", history)
+	}
+}
+
+func TestBackgroundJobs(t *testing.T) {
+	sm := NewSessionManagerWithConfig(5*time.Minute, 1*time.Minute)
+	defer sm.Shutdown()
+
+	quick, err := sm.StartBackgroundJob("client-a", "echo done", 5*time.Second, ExecOptions{})
+	if err != nil {
+		t.Fatalf("StartBackgroundJob failed: %v", err)
+	}
+	slow, err := sm.StartBackgroundJob("client-a", "sleep 30", time.Minute, ExecOptions{})
+	if err != nil {
+		t.Fatalf("StartBackgroundJob failed: %v", err)
+	}
+
+	// Wait for the quick job to finish
+	deadline := time.Now().Add(5 * time.Second)
+	var jobs []BackgroundJob
+	for {
+		jobs = sm.ListBackgroundJobs("client-a")
+		if len(jobs) == 2 && jobs[0].Status != JobRunning {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for the quick job, jobs: %+v", jobs)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if jobs[0].ID != quick || jobs[0].Command != "echo done" || jobs[0].Status != JobCompleted || jobs[0].ExitCode != 0 {
+		t.Errorf("Unexpected quick job: %+v", jobs[0])
+	}
+	if jobs[0].Result == nil || strings.TrimSpace(jobs[0].Result.Stdout) != "done" {
+		t.Errorf("Expected the quick job output, got %+v", jobs[0].Result)
+	}
+	if jobs[1].ID != slow || jobs[1].Status != JobRunning || jobs[1].Runtime() <= 0 {
+		t.Errorf("Unexpected slow job: %+v", jobs[1])
+	}
+	if other := sm.ListBackgroundJobs("client-b"); len(other) != 0 {
+		t.Errorf("Expected no jobs for another client, got %+v", other)
+	}
+	if cleared := sm.ClearBackgroundJobs("client-b"); len(cleared) != 0 {
+		t.Errorf("Expected another client not to clear the jobs, got %+v", cleared)
+	}
+
+	if cleared := sm.ClearBackgroundJobs("client-a"); len(cleared) != 1 || cleared[0].ID != quick {
+		t.Errorf("Expected the finished job to be cleared, got %+v", cleared)
+	}
+	jobs = sm.ListBackgroundJobs("client-a")
+	if len(jobs) != 1 || jobs[0].ID != slow {
+		t.Errorf("Expected only the running job to remain, got %+v", jobs)
+	}
+}
+
+func TestBackgroundJobLimits(t *testing.T) {
+	sm := NewSessionManagerWithConfig(5*time.Minute, 1*time.Minute)
+	defer sm.Shutdown()
+	sm.maxJobs = 1
+	sm.jobRetention = 2
+
+	if _, err := sm.StartBackgroundJob("client", "sleep 30", time.Minute, ExecOptions{}); err != nil {
+		t.Fatalf("StartBackgroundJob failed: %v", err)
+	}
+	_, err := sm.StartBackgroundJob("client", "echo rejected", time.Minute, ExecOptions{})
+	if !errors.Is(err, ErrTooManyJobs) || !strings.Contains(err.Error(), "the limit of 1 running jobs is reached") {
+		t.Errorf("Expected a job over the limit to be rejected, got: %v", err)
+	}
+
+	// Finished jobs do not count against the limit, and only the newest
+	// are retained
+	sm.maxJobs = 0
+	var finished []string
+	for i := range 3 {
+		id, err := sm.StartBackgroundJob("client", fmt.Sprintf("echo %d", i), 5*time.Second, ExecOptions{})
+		if err != nil {
+			t.Fatalf("StartBackgroundJob failed: %v", err)
+		}
+		finished = append(finished, id)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	var jobs []BackgroundJob
+	for {
+		jobs = sm.ListBackgroundJobs("client")
+		if len(jobs) == 3 && jobs[1].Status != JobRunning && jobs[2].Status != JobRunning {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for the jobs to finish, jobs: %+v", jobs)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if jobs[0].Status != JobRunning || jobs[1].ID != finished[1] || jobs[2].ID != finished[2] {
+		t.Errorf("Expected the running job and the two newest finished jobs, got %+v", jobs)
+	}
+}
+
 func TestGetSession(t *testing.T) {
 	sm := NewSessionManagerWithConfig(5*time.Minute, 1*time.Minute)
 	defer sm.Shutdown()
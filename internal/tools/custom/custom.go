@@ -0,0 +1,208 @@
+// Package custom provides the creation of tools from manifest specs.
+package custom
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/d-kuro/claude-code-mcp/internal/tools"
+	"github.com/d-kuro/claude-code-mcp/internal/tools/bash"
+	"github.com/d-kuro/claude-code-mcp/internal/tools/file"
+)
+
+// Category is the registry category of custom tools.
+const Category = "custom"
+
+// RegisterManifest adds a tool definition for every tool in the manifest.
+func RegisterManifest(registry *tools.ToolRegistry, manifest *Manifest) error {
+	for _, spec := range manifest.Tools {
+		spec := spec
+		err := registry.RegisterTool(&tools.ToolDefinition{
+			Name:        spec.Name,
+			Description: spec.Description,
+			Category:    Category,
+			Factory: func(ctx *tools.Context) *tools.ServerTool {
+				return CreateCustomTool(ctx, spec)
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to register custom tool %s: %w", spec.Name, err)
+		}
+	}
+	return nil
+}
+
+// CreateCustomTools creates the tools defined in the manifest, sorted by name.
+func CreateCustomTools(ctx *tools.Context, manifest *Manifest) ([]*tools.ServerTool, error) {
+	registry := tools.NewToolRegistry(ctx)
+	if err := RegisterManifest(registry, manifest); err != nil {
+		return nil, err
+	}
+
+	customTools := registry.CreateToolsByCategory(Category)
+	sort.Slice(customTools, func(i, j int) bool {
+		return customTools[i].Tool.Name < customTools[j].Tool.Name
+	})
+	return customTools, nil
+}
+
+// CreateCustomTool creates a tool that renders the spec's command template
+// with the call arguments and runs it through bash.
+func CreateCustomTool(ctx *tools.Context, spec ToolSpec) *tools.ServerTool {
+	tmpl, err := spec.template()
+	if err != nil {
+		// Specs are validated when the manifest is loaded.
+		panic(fmt.Sprintf("custom tool %s: %v", spec.Name, err))
+	}
+
+	handler := func(ctxReq context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[map[string]any]) (*mcp.CallToolResultFor[any], error) {
+		call, err := templateData(spec.Arguments, params.Arguments)
+		if err != nil {
+			return tools.ErrorResponse(err.Error()), nil
+		}
+
+		var command, display strings.Builder
+		if err := tmpl.Execute(&command, call.data); err != nil {
+			return tools.ErrorResponsef("failed to render command: %v", err), nil
+		}
+		if err := tmpl.Execute(&display, call.display); err != nil {
+			return tools.ErrorResponsef("failed to render command: %v", err), nil
+		}
+
+		// Validate command security on the command as it would read with the
+		// argument values in place
+		if err := ctx.Validator.ValidateCommand(display.String(), nil); err != nil {
+			return tools.ValidationErrorResult("Command validation failed", err), nil
+		}
+		if err := bash.ValidateCommandRules(ctx, display.String()); err != nil {
+			return tools.ValidationErrorResult("Command validation failed", err), nil
+		}
+
+		// String arguments are passed as positional parameters, never as shell text
+		bashArgs := append([]string{"-c", command.String(), spec.Name}, call.positional...)
+		executor := file.NewCommandExecutor(spec.Timeout).WithCleanEnv(ctx.CleanEnv).WithEnvOverrides(ctx.NonInteractiveEnv)
+		result, err := executor.Execute(ctxReq, "/bin/bash", bashArgs...)
+		if err != nil {
+			return tools.ErrorResponsef("failed to run %s: %v", spec.Name, err), nil
+		}
+		if ctx.StripANSI {
+			result.Stdout = tools.StripANSI(result.Stdout)
+			result.Stderr = tools.StripANSI(result.Stderr)
+		}
+
+		return tools.SuccessResponse(formatResult(result)), nil
+	}
+
+	return tools.NewToolBuilder[map[string]any](spec.Name, spec.Description, ctx).
+		WithCategory(Category).
+		WithInputSchema(inputSchema(spec)).
+		WithHandler(handler).
+		Build()
+}
+
+// inputSchema builds the JSON schema for a tool's arguments.
+func inputSchema(spec ToolSpec) *jsonschema.Schema {
+	schema := &jsonschema.Schema{
+		Type:                 "object",
+		Properties:           make(map[string]*jsonschema.Schema),
+		AdditionalProperties: &jsonschema.Schema{Not: &jsonschema.Schema{}},
+	}
+	for _, arg := range spec.Arguments {
+		schema.Properties[arg.Name] = &jsonschema.Schema{
+			Type:        arg.Type,
+			Description: arg.Description,
+			Pattern:     arg.Pattern,
+		}
+		if arg.Required {
+			schema.Required = append(schema.Required, arg.Name)
+		}
+	}
+	return schema
+}
+
+// callArguments holds the template values of one call.
+type callArguments struct {
+	// data renders each string argument as a quoted positional parameter,
+	// such as "$1", so that its value never becomes part of the script.
+	data map[string]any
+	// display renders string arguments shell-quoted in place. It is only
+	// used to check the command.
+	display map[string]any
+	// positional holds the values of the string arguments, in the order of
+	// their parameters.
+	positional []string
+}
+
+// templateData converts call arguments into template values. Omitted
+// optional arguments render as nothing and are false in {{if}}.
+func templateData(specs []ArgSpec, args map[string]any) (*callArguments, error) {
+	call := &callArguments{
+		data:    make(map[string]any, len(specs)),
+		display: make(map[string]any, len(specs)),
+	}
+	for _, spec := range specs {
+		value, ok := args[spec.Name]
+		if !ok || value == nil {
+			if spec.Required {
+				return nil, fmt.Errorf("%s is required", spec.Name)
+			}
+			call.data[spec.Name] = ""
+			call.display[spec.Name] = ""
+			continue
+		}
+
+		switch spec.Type {
+		case ArgTypeString:
+			s, ok := value.(string)
+			if !ok {
+				return nil, fmt.Errorf("%s must be a string", spec.Name)
+			}
+			if spec.Pattern != "" && !regexp.MustCompile(spec.Pattern).MatchString(s) {
+				return nil, fmt.Errorf("%s does not match pattern %s", spec.Name, spec.Pattern)
+			}
+			call.positional = append(call.positional, s)
+			call.data[spec.Name] = fmt.Sprintf(`"${%d}"`, len(call.positional))
+			call.display[spec.Name] = shellQuote(s)
+		case ArgTypeInteger:
+			n, ok := value.(float64)
+			if !ok || n != float64(int64(n)) {
+				return nil, fmt.Errorf("%s must be an integer", spec.Name)
+			}
+			call.data[spec.Name] = strconv.FormatInt(int64(n), 10)
+			call.display[spec.Name] = call.data[spec.Name]
+		case ArgTypeBoolean:
+			b, ok := value.(bool)
+			if !ok {
+				return nil, fmt.Errorf("%s must be a boolean", spec.Name)
+			}
+			call.data[spec.Name] = b
+			call.display[spec.Name] = b
+		}
+	}
+	return call, nil
+}
+
+// shellQuote quotes s as a single bash word.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// formatResult formats the output of a custom tool command.
+func formatResult(result *file.CommandResult) string {
+	var output strings.Builder
+	fmt.Fprintf(&output, "Exit code: %d\n", result.ExitCode)
+	if result.Stdout != "" {
+		fmt.Fprintf(&output, "\nSTDOUT:\n%s", result.Stdout)
+	}
+	if result.Stderr != "" {
+		fmt.Fprintf(&output, "\nSTDERR:\n%s", result.Stderr)
+	}
+	return output.String()
+}
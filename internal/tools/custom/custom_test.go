@@ -0,0 +1,319 @@
+package custom
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/d-kuro/claude-code-mcp/internal/tools"
+	"github.com/d-kuro/claude-code-mcp/internal/tools/bash"
+)
+
+const testManifest = `tools:
+  - name: Greet
+    description: Greet someone.
+    command: echo hello {{.name}}{{if .shout}} !!!{{end}} {{.times}}
+    timeout: 10s
+    arguments:
+      - name: name
+        type: string
+        description: Who to greet
+        required: true
+      - name: shout
+        type: boolean
+      - name: times
+        type: integer
+`
+
+// commandValidator rejects commands containing a blocked substring.
+type commandValidator struct {
+	blocked string
+}
+
+func (v *commandValidator) ValidateCommand(command string, args []string) error {
+	if v.blocked != "" && strings.Contains(command, v.blocked) {
+		return fmt.Errorf("command contains %q", v.blocked)
+	}
+	return nil
+}
+
+func (v *commandValidator) ValidatePath(path string) error      { return nil }
+func (v *commandValidator) ValidateWritePath(path string) error { return nil }
+func (v *commandValidator) ValidateURL(url string) error        { return nil }
+func (v *commandValidator) SanitizePath(path string) (string, error) {
+	return path, nil
+}
+
+func connectCustomTools(t *testing.T, validator tools.Validator, manifestYAML string) *mcp.ClientSession {
+	t.Helper()
+	return connectCustomToolsWithContext(t, &tools.Context{Validator: validator}, manifestYAML)
+}
+
+// connectCustomToolsWithContext is connectCustomTools with a custom tool context.
+func connectCustomToolsWithContext(t *testing.T, toolCtx *tools.Context, manifestYAML string) *mcp.ClientSession {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "tools.yaml")
+	if err := os.WriteFile(path, []byte(manifestYAML), 0644); err != nil {
+		t.Fatalf("Failed to write manifest: %v", err)
+	}
+	manifest, err := LoadManifest(path)
+	if err != nil {
+		t.Fatalf("LoadManifest failed: %v", err)
+	}
+
+	customTools, err := CreateCustomTools(toolCtx, manifest)
+	if err != nil {
+		t.Fatalf("CreateCustomTools failed: %v", err)
+	}
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "test-server", Version: "v0.0.1"}, nil)
+	for _, tool := range customTools {
+		tool.RegisterFunc(server)
+	}
+
+	ctx := context.Background()
+	clientTransport, serverTransport := mcp.NewInMemoryTransports()
+	serverSession, err := server.Connect(ctx, serverTransport)
+	if err != nil {
+		t.Fatalf("Failed to connect server: %v", err)
+	}
+	t.Cleanup(func() { _ = serverSession.Close() })
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "v0.0.1"}, nil)
+	clientSession, err := client.Connect(ctx, clientTransport)
+	if err != nil {
+		t.Fatalf("Failed to connect client: %v", err)
+	}
+	t.Cleanup(func() { _ = clientSession.Close() })
+
+	return clientSession
+}
+
+func callText(t *testing.T, session *mcp.ClientSession, name string, args map[string]any) (string, bool) {
+	t.Helper()
+
+	result, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: name, Arguments: args})
+	if err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+	text, ok := result.Content[0].(*mcp.TextContent)
+	if !ok {
+		t.Fatal("Expected TextContent")
+	}
+	return text.Text, result.IsError
+}
+
+func TestCustomToolFromManifest(t *testing.T) {
+	session := connectCustomTools(t, &commandValidator{}, testManifest)
+
+	tests := []struct {
+		name string
+		args map[string]any
+		want string
+	}{
+		{
+			name: "required argument only",
+			args: map[string]any{"name": "world"},
+			want: "hello world\n",
+		},
+		{
+			name: "all arguments",
+			args: map[string]any{"name": "world", "shout": true, "times": 3},
+			want: "hello world !!! 3\n",
+		},
+		{
+			name: "shell metacharacters are quoted",
+			args: map[string]any{"name": "O'Brien; echo injected"},
+			want: "hello O'Brien; echo injected\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, isError := callText(t, session, "Greet", tt.args)
+			if isError {
+				t.Fatalf("Expected success, got error: %s", text)
+			}
+			if !strings.Contains(text, "Exit code: 0") {
+				t.Errorf("Expected exit code 0, got:\n%s", text)
+			}
+			if !strings.HasSuffix(text, "STDOUT:\n"+tt.want) {
+				t.Errorf("Expected stdout %q, got:\n%s", tt.want, text)
+			}
+		})
+	}
+
+	t.Run("missing required argument", func(t *testing.T) {
+		result, err := session.CallTool(context.Background(), &mcp.CallToolParams{
+			Name:      "Greet",
+			Arguments: map[string]any{"shout": true},
+		})
+		if err == nil && !result.IsError {
+			t.Error("Expected an error for a missing required argument")
+		}
+	})
+}
+
+func TestCustomToolArgumentsAreNotShellCode(t *testing.T) {
+	marker := filepath.Join(t.TempDir(), "pwned")
+	manifest := `tools:
+  - name: Say
+    description: Print a message.
+    command: echo "said:" {{.msg}} {{.other}}
+    arguments:
+      - name: msg
+        type: string
+        required: true
+      - name: other
+        type: string
+`
+	session := connectCustomTools(t, &commandValidator{}, manifest)
+
+	msg := "$(touch " + marker + ") `touch " + marker + "` \"; touch " + marker + " #"
+	text, isError := callText(t, session, "Say", map[string]any{"msg": msg, "other": "'$HOME'"})
+	if isError {
+		t.Fatalf("Expected success, got error: %s", text)
+	}
+	if !strings.HasSuffix(text, "STDOUT:\nsaid: "+msg+" '$HOME'\n") {
+		t.Errorf("Expected the arguments to be printed verbatim, got:\n%s", text)
+	}
+	if _, err := os.Stat(marker); err == nil {
+		t.Error("Expected the argument not to run as a command")
+	}
+}
+
+func TestCustomToolCommandValidation(t *testing.T) {
+	session := connectCustomTools(t, &commandValidator{blocked: "forbidden"}, testManifest)
+
+	text, isError := callText(t, session, "Greet", map[string]any{"name": "forbidden"})
+	if !isError {
+		t.Fatalf("Expected validation error, got: %s", text)
+	}
+	if !strings.Contains(text, "Command validation failed") {
+		t.Errorf("Unexpected error message: %s", text)
+	}
+}
+
+func TestCustomToolAllowedBinaries(t *testing.T) {
+	toolCtx := &tools.Context{
+		Validator:    &commandValidator{},
+		CommandRules: bash.NewShellExecutor().WithAllowedBinaries([]string{"printf"}),
+	}
+	session := connectCustomToolsWithContext(t, toolCtx, testManifest)
+
+	text, isError := callText(t, session, "Greet", map[string]any{"name": "world"})
+	if !isError || !strings.Contains(text, `binary "echo" is not in the allowed binaries`) {
+		t.Errorf("Expected echo to be rejected by the binary allowlist, got: %s", text)
+	}
+}
+
+// colorManifest defines a tool that colors its output unless asked not to,
+// and one that always prints escape sequences.
+const colorManifest = `tools:
+  - name: Status
+    description: Print a status.
+    command: if [ -z "$NO_COLOR" ] && [ "$TERM" != dumb ]; then printf '\033[32mok\033[0m\n'; else echo "ok CI=$CI"; fi
+  - name: Bold
+    description: Print bold text.
+    command: printf '\033[1mbold\033[0m \033]8;;https://example.com\007link\033]8;;\007\n'
+`
+
+func TestCustomToolNonInteractiveOutput(t *testing.T) {
+	t.Setenv("TERM", "xterm-256color")
+	t.Setenv("NO_COLOR", "")
+
+	session := connectCustomToolsWithContext(t, &tools.Context{Validator: &commandValidator{}}, colorManifest)
+	if text, _ := callText(t, session, "Status", nil); !strings.Contains(text, "\x1b[32m") {
+		t.Fatalf("Expected colored output by default, got %q", text)
+	}
+
+	session = connectCustomToolsWithContext(t, &tools.Context{
+		Validator:         &commandValidator{},
+		NonInteractiveEnv: tools.NonInteractiveEnvVars,
+		StripANSI:         true,
+	}, colorManifest)
+
+	if text, _ := callText(t, session, "Status", nil); !strings.HasSuffix(text, "STDOUT:\nok CI=1\n") {
+		t.Errorf("Expected plain output in a non-interactive environment, got %q", text)
+	}
+	if text, _ := callText(t, session, "Bold", nil); !strings.HasSuffix(text, "STDOUT:\nbold link\n") {
+		t.Errorf("Expected escape sequences to be stripped, got %q", text)
+	}
+}
+
+func TestParseManifestErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		manifest string
+		wantErr  string
+	}{
+		{
+			name:     "unknown field",
+			manifest: "tools:\n  - name: A\n    description: d\n    command: true\n    shell: zsh\n",
+			wantErr:  "field shell not found",
+		},
+		{
+			name:     "invalid name",
+			manifest: "tools:\n  - name: 'bad name'\n    description: d\n    command: true\n",
+			wantErr:  "name must start with a letter",
+		},
+		{
+			name:     "missing command",
+			manifest: "tools:\n  - name: A\n    description: d\n",
+			wantErr:  "command cannot be empty",
+		},
+		{
+			name:     "undefined argument",
+			manifest: "tools:\n  - name: A\n    description: d\n    command: echo {{.missing}}\n",
+			wantErr:  "undefined argument",
+		},
+		{
+			name:     "unsupported template action",
+			manifest: "tools:\n  - name: A\n    description: d\n    command: echo {{printf \"%s\" .x}}\n    arguments:\n      - name: x\n",
+			wantErr:  "only supports plain argument references",
+		},
+		{
+			name:     "unsupported type",
+			manifest: "tools:\n  - name: A\n    description: d\n    command: echo {{.x}}\n    arguments:\n      - name: x\n        type: list\n",
+			wantErr:  "unsupported type",
+		},
+		{
+			name:     "reference inside double quotes",
+			manifest: "tools:\n  - name: A\n    description: d\n    command: echo \"{{.x}}\"\n    arguments:\n      - name: x\n",
+			wantErr:  "inside quotes",
+		},
+		{
+			name:     "reference inside single quotes",
+			manifest: "tools:\n  - name: A\n    description: d\n    command: echo 'a {{.x}}'\n    arguments:\n      - name: x\n",
+			wantErr:  "inside quotes",
+		},
+		{
+			name:     "quote left open in an if block",
+			manifest: "tools:\n  - name: A\n    description: d\n    command: echo {{if .x}}\"{{end}}done\"\n    arguments:\n      - name: x\n        type: boolean\n",
+			wantErr:  "must be closed in the same block",
+		},
+		{
+			name:     "duplicate tool",
+			manifest: "tools:\n  - name: A\n    description: d\n    command: 'true'\n  - name: A\n    description: d\n    command: 'true'\n",
+			wantErr:  "defined more than once",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseManifest([]byte(tt.manifest))
+			if err == nil {
+				t.Fatal("Expected an error")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
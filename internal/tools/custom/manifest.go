@@ -0,0 +1,291 @@
+// Package custom provides user-defined tools backed by shell command templates.
+package custom
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"regexp"
+	"text/template"
+	"text/template/parse"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Argument types supported in a manifest.
+const (
+	ArgTypeString  = "string"
+	ArgTypeInteger = "integer"
+	ArgTypeBoolean = "boolean"
+)
+
+// DefaultTimeout is used for custom tools that do not set a timeout.
+const DefaultTimeout = 120 * time.Second
+
+// toolNamePattern restricts custom tool names to the characters MCP clients accept.
+var toolNamePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_-]*$`)
+
+// Manifest is the top-level structure of a custom tools manifest file.
+//
+// Example:
+//
+//	tools:
+//	  - name: RunTests
+//	    description: Run the Go tests for a package.
+//	    command: go test {{.package}}
+//	    timeout: 5m
+//	    arguments:
+//	      - name: package
+//	        type: string
+//	        description: Package pattern to test
+//	        required: true
+//	        pattern: '^[./a-zA-Z0-9_-]+$'
+type Manifest struct {
+	Tools []ToolSpec `yaml:"tools"`
+}
+
+// ToolSpec defines a single custom tool.
+type ToolSpec struct {
+	Name        string        `yaml:"name"`
+	Description string        `yaml:"description"`
+	Command     string        `yaml:"command"`
+	Timeout     time.Duration `yaml:"timeout"`
+	Arguments   []ArgSpec     `yaml:"arguments"`
+}
+
+// ArgSpec defines an argument accepted by a custom tool.
+type ArgSpec struct {
+	Name        string `yaml:"name"`
+	Type        string `yaml:"type"`
+	Description string `yaml:"description"`
+	Required    bool   `yaml:"required"`
+	Pattern     string `yaml:"pattern"`
+}
+
+// LoadManifest reads and validates a custom tools manifest.
+func LoadManifest(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tools manifest: %w", err)
+	}
+
+	return ParseManifest(data)
+}
+
+// ParseManifest parses and validates a custom tools manifest.
+func ParseManifest(data []byte) (*Manifest, error) {
+	var manifest Manifest
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse tools manifest: %w", err)
+	}
+
+	if err := manifest.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &manifest, nil
+}
+
+// Validate checks that every tool in the manifest is well formed.
+func (m *Manifest) Validate() error {
+	names := make(map[string]bool)
+	for i := range m.Tools {
+		spec := &m.Tools[i]
+		if err := spec.validate(); err != nil {
+			return fmt.Errorf("invalid custom tool %q: %w", spec.Name, err)
+		}
+		if names[spec.Name] {
+			return fmt.Errorf("custom tool %s is defined more than once", spec.Name)
+		}
+		names[spec.Name] = true
+	}
+	return nil
+}
+
+// validate checks a single tool definition, filling in defaults.
+func (s *ToolSpec) validate() error {
+	if !toolNamePattern.MatchString(s.Name) {
+		return fmt.Errorf("name must start with a letter and contain only letters, digits, '_' or '-'")
+	}
+	if s.Description == "" {
+		return fmt.Errorf("description cannot be empty")
+	}
+	if s.Command == "" {
+		return fmt.Errorf("command cannot be empty")
+	}
+	if s.Timeout < 0 {
+		return fmt.Errorf("timeout cannot be negative")
+	}
+	if s.Timeout == 0 {
+		s.Timeout = DefaultTimeout
+	}
+
+	args := make(map[string]bool)
+	for i := range s.Arguments {
+		arg := &s.Arguments[i]
+		if !toolNamePattern.MatchString(arg.Name) {
+			return fmt.Errorf("argument name %q is invalid", arg.Name)
+		}
+		if args[arg.Name] {
+			return fmt.Errorf("argument %s is defined more than once", arg.Name)
+		}
+		args[arg.Name] = true
+
+		switch arg.Type {
+		case "":
+			arg.Type = ArgTypeString
+		case ArgTypeString, ArgTypeInteger, ArgTypeBoolean:
+		default:
+			return fmt.Errorf("argument %s has unsupported type %q", arg.Name, arg.Type)
+		}
+		if arg.Pattern != "" {
+			if arg.Type != ArgTypeString {
+				return fmt.Errorf("argument %s: pattern is only supported for string arguments", arg.Name)
+			}
+			if _, err := regexp.Compile(arg.Pattern); err != nil {
+				return fmt.Errorf("argument %s has invalid pattern: %w", arg.Name, err)
+			}
+		}
+	}
+
+	tmpl, err := s.template()
+	if err != nil {
+		return err
+	}
+	if err := checkTemplate(tmpl.Root, args); err != nil {
+		return err
+	}
+	_, err = checkQuoting(tmpl.Root, shellUnquoted)
+	return err
+}
+
+// template parses the command template of the tool.
+func (s *ToolSpec) template() (*template.Template, error) {
+	tmpl, err := template.New(s.Name).Option("missingkey=zero").Parse(s.Command)
+	if err != nil {
+		return nil, fmt.Errorf("invalid command template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// checkTemplate ensures a command template only uses field references and
+// if/else blocks, and only references defined arguments.
+func checkTemplate(node parse.Node, args map[string]bool) error {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return nil
+		}
+		for _, child := range n.Nodes {
+			if err := checkTemplate(child, args); err != nil {
+				return err
+			}
+		}
+	case *parse.TextNode:
+	case *parse.ActionNode:
+		return checkTemplate(n.Pipe, args)
+	case *parse.IfNode:
+		for _, child := range []parse.Node{n.Pipe, n.List, n.ElseList} {
+			if err := checkTemplate(child, args); err != nil {
+				return err
+			}
+		}
+	case *parse.PipeNode:
+		if len(n.Decl) > 0 || len(n.Cmds) != 1 || len(n.Cmds[0].Args) != 1 {
+			return fmt.Errorf("command template only supports plain argument references such as {{.name}}")
+		}
+		return checkTemplate(n.Cmds[0].Args[0], args)
+	case *parse.FieldNode:
+		if len(n.Ident) != 1 || !args[n.Ident[0]] {
+			return fmt.Errorf("command references undefined argument %s", n.String())
+		}
+	default:
+		return fmt.Errorf("command template only supports {{.name}} and {{if .name}} blocks")
+	}
+	return nil
+}
+
+// shellQuoteState is the quoting context of a position in a shell command.
+type shellQuoteState int
+
+const (
+	shellUnquoted shellQuoteState = iota
+	shellSingleQuoted
+	shellDoubleQuoted
+	shellANSIQuoted
+)
+
+// checkQuoting rejects argument references inside single, double or $'...'
+// quotes. Every reference already renders as a double-quoted parameter, and
+// inside other quotes it would be read as literal text or left unquoted. It
+// returns the quoting state at the end of node, starting from state.
+func checkQuoting(node parse.Node, state shellQuoteState) (shellQuoteState, error) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return state, nil
+		}
+		for _, child := range n.Nodes {
+			var err error
+			if state, err = checkQuoting(child, state); err != nil {
+				return state, err
+			}
+		}
+	case *parse.TextNode:
+		state = scanShellQuotes(n.Text, state)
+	case *parse.ActionNode:
+		if state != shellUnquoted {
+			return state, fmt.Errorf("command references %s inside quotes; references are quoted automatically", n.Pipe.String())
+		}
+	case *parse.IfNode:
+		end, err := checkQuoting(n.List, state)
+		if err != nil {
+			return state, err
+		}
+		elseEnd, err := checkQuoting(n.ElseList, state)
+		if err != nil {
+			return state, err
+		}
+		if end != elseEnd {
+			return state, fmt.Errorf("quotes opened in an {{if}} block must be closed in the same block")
+		}
+		state = end
+	}
+	return state, nil
+}
+
+// scanShellQuotes returns the quoting state after text, starting from state.
+func scanShellQuotes(text []byte, state shellQuoteState) shellQuoteState {
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch state {
+		case shellUnquoted:
+			switch {
+			case c == '\\':
+				i++
+			case c == '\'':
+				state = shellSingleQuoted
+			case c == '"':
+				state = shellDoubleQuoted
+			case c == '$' && i+1 < len(text) && text[i+1] == '\'':
+				state = shellANSIQuoted
+				i++
+			}
+		case shellSingleQuoted:
+			if c == '\'' {
+				state = shellUnquoted
+			}
+		case shellDoubleQuoted, shellANSIQuoted:
+			switch {
+			case c == '\\':
+				i++
+			case state == shellDoubleQuoted && c == '"', state == shellANSIQuoted && c == '\'':
+				state = shellUnquoted
+			}
+		}
+	}
+	return state
+}
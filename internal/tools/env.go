@@ -0,0 +1,82 @@
+// Package tools provides the environment used for external commands.
+package tools
+
+import (
+	"maps"
+	"os"
+	"slices"
+	"strings"
+)
+
+// CleanEnvVars are the process environment variables passed to commands when
+// a clean environment is requested.
+var CleanEnvVars = []string{
+	"PATH",
+	"HOME",
+	"USER",
+	"LANG",
+	"LC_ALL",
+	"LC_CTYPE",
+	"TERM",
+	"TMPDIR",
+}
+
+// NonInteractiveEnvVars are the variables set for commands when
+// non-interactive output is enforced, so that programs do not emit colors or
+// other output meant for a terminal.
+var NonInteractiveEnvVars = map[string]string{
+	"TERM":     "dumb",
+	"NO_COLOR": "1",
+	"CI":       "1",
+}
+
+// CommandEnv returns the base environment for external commands. By default
+// the full process environment is inherited; when clean is set only the
+// variables in CleanEnvVars are kept, so secrets such as API keys configured
+// for the server are not passed on.
+func CommandEnv(clean bool) []string {
+	if !clean {
+		return os.Environ()
+	}
+
+	return FilterEnv(CleanEnvVars)
+}
+
+// FilterEnv returns the process environment variables with the given names,
+// skipping those that are not set.
+func FilterEnv(names []string) []string {
+	env := make([]string, 0, len(names))
+	for _, name := range names {
+		if value, ok := os.LookupEnv(name); ok {
+			env = append(env, name+"="+value)
+		}
+	}
+	return env
+}
+
+// EnvMap converts a KEY=value environment list to a map.
+func EnvMap(env []string) map[string]string {
+	m := make(map[string]string, len(env))
+	for _, entry := range env {
+		if key, value, ok := strings.Cut(entry, "="); ok && key != "" {
+			m[key] = value
+		}
+	}
+	return m
+}
+
+// OverrideEnv returns env with the variables in vars set, replacing any
+// entries of the same name.
+func OverrideEnv(env []string, vars map[string]string) []string {
+	result := make([]string, 0, len(env)+len(vars))
+	for _, entry := range env {
+		key, _, _ := strings.Cut(entry, "=")
+		if _, set := vars[key]; !set {
+			result = append(result, entry)
+		}
+	}
+	for _, key := range slices.Sorted(maps.Keys(vars)) {
+		result = append(result, key+"="+vars[key])
+	}
+	return result
+}
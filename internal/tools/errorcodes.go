@@ -0,0 +1,63 @@
+// Package tools provides machine-readable error codes for tool results.
+package tools
+
+import (
+	"errors"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/d-kuro/claude-code-mcp/internal/security"
+)
+
+// ErrorCodeMetaKey is the result meta key holding the error code.
+const ErrorCodeMetaKey = "error_code"
+
+// Error codes attached to validation error results.
+const (
+	ErrorCodePathNotAbsolute    = "PATH_NOT_ABSOLUTE"
+	ErrorCodePathBlocked        = "PATH_BLOCKED"
+	ErrorCodePathNotAllowed     = "PATH_NOT_ALLOWED"
+	ErrorCodePathNotWritable    = "PATH_NOT_WRITABLE"
+	ErrorCodeCommandBlocked     = "COMMAND_BLOCKED"
+	ErrorCodeCommandNotAllowed  = "COMMAND_NOT_ALLOWED"
+	ErrorCodeURLSchemeInvalid   = "URL_SCHEME_INVALID"
+	ErrorCodeURLLocalhostDenied = "URL_LOCALHOST_DENIED"
+	ErrorCodeURLPrivateAddress  = "URL_PRIVATE_ADDRESS_DENIED"
+)
+
+// errorCodes maps security sentinel errors to their error codes.
+var errorCodes = []struct {
+	sentinel error
+	code     string
+}{
+	{security.ErrPathNotAbsolute, ErrorCodePathNotAbsolute},
+	{security.ErrPathBlocked, ErrorCodePathBlocked},
+	{security.ErrPathNotAllowed, ErrorCodePathNotAllowed},
+	{security.ErrPathNotWritable, ErrorCodePathNotWritable},
+	{security.ErrCommandBlocked, ErrorCodeCommandBlocked},
+	{security.ErrCommandNotAllowed, ErrorCodeCommandNotAllowed},
+	{security.ErrInvalidURLScheme, ErrorCodeURLSchemeInvalid},
+	{security.ErrLocalhostDenied, ErrorCodeURLLocalhostDenied},
+	{security.ErrPrivateAddress, ErrorCodeURLPrivateAddress},
+}
+
+// ErrorCode returns the error code for err, or "" if it has none.
+func ErrorCode(err error) string {
+	for _, entry := range errorCodes {
+		if errors.Is(err, entry.sentinel) {
+			return entry.code
+		}
+	}
+	return ""
+}
+
+// ValidationErrorResult creates an error result for a failed validation.
+// The text is "Error: <message>: <err>", and the error code of err, if any,
+// is set in the result meta under ErrorCodeMetaKey.
+func ValidationErrorResult(message string, err error) *mcp.CallToolResultFor[any] {
+	var details map[string]any
+	if code := ErrorCode(err); code != "" {
+		details = map[string]any{ErrorCodeMetaKey: code}
+	}
+	return CreateStandardErrorResult(message+": "+err.Error(), details)
+}
@@ -0,0 +1,53 @@
+package tools
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/d-kuro/claude-code-mcp/internal/security"
+)
+
+func TestErrorCode(t *testing.T) {
+	v := security.NewDefaultValidator().WithWritablePaths([]string{"/home/user/project"})
+
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"relative path", v.ValidatePath("relative"), ErrorCodePathNotAbsolute},
+		{"blocked path", v.ValidatePath("/etc/passwd"), ErrorCodePathBlocked},
+		{"not writable", v.ValidateWritePath("/home/user/other"), ErrorCodePathNotWritable},
+		{"blocked command", v.ValidateCommand("sudo", nil), ErrorCodeCommandBlocked},
+		{"invalid scheme", v.ValidateURL("ftp://example.com"), ErrorCodeURLSchemeInvalid},
+		{"localhost", v.ValidateURL("http://localhost"), ErrorCodeURLLocalhostDenied},
+		{"private address", v.ValidateURL("http://169.254.169.254/"), ErrorCodeURLPrivateAddress},
+		{"wrapped", fmt.Errorf("invalid file path: %w", v.ValidatePath("/etc/hosts")), ErrorCodePathBlocked},
+		{"untyped", fmt.Errorf("something else"), ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ErrorCode(tt.err); got != tt.want {
+				t.Errorf("ErrorCode(%v) = %q, want %q", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidationErrorResult(t *testing.T) {
+	err := security.NewDefaultValidator().ValidatePath("/etc/passwd")
+	result := PathValidationError(err)
+
+	if !result.IsError {
+		t.Error("Expected an error result")
+	}
+	if got := result.Meta[ErrorCodeMetaKey]; got != ErrorCodePathBlocked {
+		t.Errorf("Expected %s in meta, got %v", ErrorCodePathBlocked, got)
+	}
+
+	untyped := PathValidationError(fmt.Errorf("boom"))
+	if _, ok := untyped.Meta[ErrorCodeMetaKey]; ok {
+		t.Errorf("Expected no error code for untyped error, got %v", untyped.Meta)
+	}
+}
@@ -2,6 +2,8 @@
 package file
 
 import (
+	"bufio"
+	"bytes"
 	"context"
 	"fmt"
 	"os"
@@ -9,11 +11,14 @@ import (
 	"path/filepath"
 	"strings"
 	"time"
+
+	"github.com/d-kuro/claude-code-mcp/internal/tools"
 )
 
 // CommandExecutor provides secure command execution with validation and timeouts.
 type CommandExecutor struct {
 	timeout time.Duration
+	env     []string
 }
 
 // NewCommandExecutor creates a new command executor with the specified timeout.
@@ -23,6 +28,30 @@ func NewCommandExecutor(timeout time.Duration) *CommandExecutor {
 	}
 }
 
+// WithCleanEnv makes commands start with only the allow-listed variables in
+// tools.CleanEnvVars instead of inheriting the server process environment.
+func (e *CommandExecutor) WithCleanEnv(clean bool) *CommandExecutor {
+	e.env = nil
+	if clean {
+		e.env = tools.CommandEnv(true)
+	}
+	return e
+}
+
+// WithEnvOverrides sets the variables in vars for commands, on top of the
+// environment selected by WithCleanEnv, which must be called first.
+func (e *CommandExecutor) WithEnvOverrides(vars map[string]string) *CommandExecutor {
+	if len(vars) == 0 {
+		return e
+	}
+	env := e.env
+	if env == nil {
+		env = os.Environ()
+	}
+	e.env = tools.OverrideEnv(env, vars)
+	return e
+}
+
 // CommandResult represents the result of a command execution.
 type CommandResult struct {
 	Stdout   string
@@ -41,6 +70,7 @@ func (e *CommandExecutor) Execute(ctx context.Context, name string, args ...stri
 
 	// Create command
 	cmd := exec.CommandContext(timeoutCtx, name, args...)
+	cmd.Env = e.env
 
 	// Set working directory to current directory
 	cwd, err := os.Getwd()
@@ -55,6 +85,11 @@ func (e *CommandExecutor) Execute(ctx context.Context, name string, args ...stri
 	exitCode := 0
 
 	if err != nil {
+		// A cancelled caller is reported as an error rather than a killed exit code
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, fmt.Errorf("command cancelled: %w", ctxErr)
+		}
+
 		// Handle different types of errors
 		if exitError, ok := err.(*exec.ExitError); ok {
 			// Command executed but returned non-zero exit code
@@ -76,6 +111,69 @@ func (e *CommandExecutor) Execute(ctx context.Context, name string, args ...stri
 	}, nil
 }
 
+// ExecuteStream runs a command and passes each line of its standard output,
+// without the trailing newline, to onLine as soon as it is read, so the
+// output is never held in memory as a whole. When onLine returns false the
+// command is killed and stopped is true. The returned result has no Stdout.
+func (e *CommandExecutor) ExecuteStream(ctx context.Context, onLine func(line []byte) bool, name string, args ...string) (result *CommandResult, stopped bool, err error) {
+	start := time.Now()
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, e.timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(timeoutCtx, name, args...)
+	cmd.Env = e.env
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get current working directory: %w", err)
+	}
+	cmd.Dir = cwd
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to execute command: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, false, fmt.Errorf("failed to execute command: %w", err)
+	}
+
+	reader := bufio.NewReader(stdout)
+	for {
+		line, readErr := reader.ReadBytes('\n')
+		if len(line) > 0 && !onLine(bytes.TrimSuffix(line, []byte("\n"))) {
+			stopped = true
+			cancel()
+			break
+		}
+		if readErr != nil {
+			break
+		}
+	}
+
+	exitCode := 0
+	if err := cmd.Wait(); err != nil && !stopped {
+		// A cancelled caller is reported as an error rather than a killed exit code
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, false, fmt.Errorf("command cancelled: %w", ctxErr)
+		}
+
+		exitError, ok := err.(*exec.ExitError)
+		if !ok {
+			return nil, false, fmt.Errorf("failed to execute command: %w", err)
+		}
+		exitCode = exitError.ExitCode()
+	}
+
+	return &CommandResult{
+		Stderr:   stderr.String(),
+		ExitCode: exitCode,
+		Duration: time.Since(start),
+	}, stopped, nil
+}
+
 // ExecuteInDir runs a command in the specified directory.
 func (e *CommandExecutor) ExecuteInDir(ctx context.Context, dir string, name string, args ...string) (*CommandResult, error) {
 	start := time.Now()
@@ -100,6 +198,7 @@ func (e *CommandExecutor) ExecuteInDir(ctx context.Context, dir string, name str
 
 	// Create command
 	cmd := exec.CommandContext(timeoutCtx, name, args...)
+	cmd.Env = e.env
 	cmd.Dir = dir
 
 	// Execute command
@@ -108,6 +207,11 @@ func (e *CommandExecutor) ExecuteInDir(ctx context.Context, dir string, name str
 	exitCode := 0
 
 	if err != nil {
+		// A cancelled caller is reported as an error rather than a killed exit code
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, fmt.Errorf("command cancelled: %w", ctxErr)
+		}
+
 		// Handle different types of errors
 		if exitError, ok := err.(*exec.ExitError); ok {
 			// Command executed but returned non-zero exit code
@@ -176,11 +280,106 @@ func isAllowedCharInArg(arg, char string) bool {
 	return false
 }
 
-// FindBinary searches for a binary in the system PATH.
+// binaryInstallHints suggest how to install the binaries used by the file tools.
+var binaryInstallHints = map[string]string{
+	"rg":   "install ripgrep, e.g. with `brew install ripgrep` or `apt install ripgrep`",
+	"find": "install findutils, e.g. with `brew install findutils` or `apt install findutils`",
+	"ls":   "install coreutils, e.g. with `brew install coreutils` or `apt install coreutils`",
+	"git":  "install git, e.g. with `brew install git` or `apt install git`",
+}
+
+// BinaryNotFoundError is returned by FindBinary when a binary is not in PATH.
+// It reports the PATH that was searched and, for the binaries the file tools
+// use, how to install them.
+type BinaryNotFoundError struct {
+	Name string
+	Path string
+	Hint string
+	Err  error
+}
+
+// Error describes the missing binary, the searched PATH and the install hint.
+func (e *BinaryNotFoundError) Error() string {
+	message := fmt.Sprintf("binary %s not found in PATH %q", e.Name, e.Path)
+	if e.Hint != "" {
+		message += "; " + e.Hint
+	}
+	return message
+}
+
+// Unwrap returns the error from the PATH lookup.
+func (e *BinaryNotFoundError) Unwrap() error {
+	return e.Err
+}
+
+// FindBinary searches for a binary in the system PATH. When it is missing,
+// the error is a *BinaryNotFoundError.
 func FindBinary(name string) (string, error) {
 	path, err := exec.LookPath(name)
 	if err != nil {
-		return "", fmt.Errorf("binary %s not found in PATH: %w", name, err)
+		return "", &BinaryNotFoundError{
+			Name: name,
+			Path: os.Getenv("PATH"),
+			Hint: binaryInstallHints[name],
+			Err:  err,
+		}
 	}
 	return path, nil
 }
+
+const (
+	// vanishedFileRetries is the number of times a search is repeated when
+	// files disappear while the tree is being walked.
+	vanishedFileRetries = 2
+	// vanishedFileBackoff is the initial delay before repeating such a search.
+	vanishedFileBackoff = 50 * time.Millisecond
+	// vanishedFileNote is appended to results that may be incomplete because
+	// files were removed during the walk.
+	vanishedFileNote = "\n(some files were removed during the search; results may be incomplete)"
+)
+
+// ExecuteTolerant runs a directory-walking command such as find or rg. When
+// the command fails only because files vanished during the walk, it is retried
+// with exponential backoff. If the final attempt still reports vanished files,
+// its result is returned with partial set to true.
+func (e *CommandExecutor) ExecuteTolerant(ctx context.Context, name string, args ...string) (result *CommandResult, partial bool, err error) {
+	backoff := vanishedFileBackoff
+	for attempt := 0; ; attempt++ {
+		result, err = e.Execute(ctx, name, args...)
+		if err != nil {
+			return nil, false, err
+		}
+
+		if result.ExitCode == 0 || !onlyVanishedFileWarnings(result.Stderr) {
+			return result, false, nil
+		}
+
+		if attempt >= vanishedFileRetries {
+			return result, true, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, false, fmt.Errorf("command cancelled: %w", ctx.Err())
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// onlyVanishedFileWarnings reports whether stderr consists solely of
+// "No such file or directory" warnings.
+func onlyVanishedFileWarnings(stderr string) bool {
+	found := false
+	for _, line := range strings.Split(stderr, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if !strings.Contains(line, "No such file or directory") {
+			return false
+		}
+		found = true
+	}
+	return found
+}
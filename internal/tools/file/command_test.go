@@ -2,6 +2,13 @@ package file
 
 import (
 	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
 	"testing"
 	"time"
 )
@@ -63,6 +70,158 @@ func TestCommandExecutor(t *testing.T) {
 	}
 }
 
+func TestCommandExecutorCancellation(t *testing.T) {
+	executor := NewCommandExecutor(30 * time.Second)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+	_, err := executor.Execute(ctx, "sleep", "10")
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context cancelled error, got: %v", err)
+	}
+
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("expected cancelled command to stop promptly, took %v", elapsed)
+	}
+}
+
+func TestCommandExecutorCancellationKillsProcess(t *testing.T) {
+	shPath, err := FindBinary("sh")
+	if err != nil {
+		t.Skip("sh not available")
+	}
+
+	pidFile := filepath.Join(t.TempDir(), "pid")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := NewCommandExecutor(30*time.Second).Execute(ctx, shPath, "-c", "echo $$ > "+pidFile+"; exec sleep 30")
+		done <- err
+	}()
+
+	// Wait until the process has started before cancelling it
+	var pid int
+	deadline := time.Now().Add(5 * time.Second)
+	for pid == 0 && time.Now().Before(deadline) {
+		if data, err := os.ReadFile(pidFile); err == nil && strings.HasSuffix(string(data), "\n") {
+			pid, _ = strconv.Atoi(strings.TrimSpace(string(data)))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if pid == 0 {
+		t.Fatal("Process did not start")
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected a cancelled error, got: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Execute did not return after cancellation")
+	}
+
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return
+	}
+	if err := process.Signal(syscall.Signal(0)); err == nil {
+		t.Errorf("Expected process %d to be terminated after cancellation", pid)
+	}
+}
+
+func TestCommandExecutorCleanEnv(t *testing.T) {
+	envPath, err := FindBinary("env")
+	if err != nil {
+		t.Skip("env not available")
+	}
+	t.Setenv("CLAUDE_CODE_MCP_TEST_SECRET", "s3cret")
+
+	inherited, err := NewCommandExecutor(5*time.Second).Execute(context.Background(), envPath)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if !strings.Contains(inherited.Stdout, "CLAUDE_CODE_MCP_TEST_SECRET=s3cret") {
+		t.Errorf("Expected inherited environment to include the variable")
+	}
+
+	clean, err := NewCommandExecutor(5*time.Second).WithCleanEnv(true).Execute(context.Background(), envPath)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if strings.Contains(clean.Stdout, "CLAUDE_CODE_MCP_TEST_SECRET") {
+		t.Errorf("Expected clean environment to omit the variable, got:\n%s", clean.Stdout)
+	}
+	if !strings.Contains(clean.Stdout, "PATH=") {
+		t.Errorf("Expected clean environment to keep PATH, got:\n%s", clean.Stdout)
+	}
+}
+
+func TestCommandExecutorExecuteStream(t *testing.T) {
+	shPath, err := FindBinary("sh")
+	if err != nil {
+		t.Skip("sh not available")
+	}
+	executor := NewCommandExecutor(30 * time.Second)
+
+	var lines []string
+	result, stopped, err := executor.ExecuteStream(context.Background(), func(line []byte) bool {
+		lines = append(lines, string(line))
+		return true
+	}, shPath, "-c", "echo one; echo two; echo err >&2; exit 3")
+	if err != nil {
+		t.Fatalf("ExecuteStream() error = %v", err)
+	}
+	if stopped || result.ExitCode != 3 || strings.TrimSpace(result.Stderr) != "err" {
+		t.Errorf("Unexpected result %+v, stopped %v", result, stopped)
+	}
+	if strings.Join(lines, ",") != "one,two" {
+		t.Errorf("Expected lines one,two, got %q", lines)
+	}
+
+	start := time.Now()
+	lines = nil
+	_, stopped, err = executor.ExecuteStream(context.Background(), func(line []byte) bool {
+		lines = append(lines, string(line))
+		return len(lines) < 2
+	}, shPath, "-c", "echo one; echo two; echo three; exec sleep 30")
+	if err != nil {
+		t.Fatalf("ExecuteStream() error = %v", err)
+	}
+	if !stopped || len(lines) != 2 {
+		t.Errorf("Expected the stream to stop after two lines, got %q, stopped %v", lines, stopped)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("Expected the stopped command to be killed, took %s", elapsed)
+	}
+}
+
+func TestOnlyVanishedFileWarnings(t *testing.T) {
+	tests := []struct {
+		name   string
+		stderr string
+		want   bool
+	}{
+		{name: "empty", stderr: "", want: false},
+		{name: "single warning", stderr: "find: '/tmp/x': No such file or directory\n", want: true},
+		{name: "multiple warnings", stderr: "rg: /a: No such file or directory (os error 2)\nrg: /b: No such file or directory (os error 2)\n", want: true},
+		{name: "mixed errors", stderr: "find: '/a': No such file or directory\nfind: '/b': Permission denied\n", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := onlyVanishedFileWarnings(tt.stderr); got != tt.want {
+				t.Errorf("onlyVanishedFileWarnings() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestCommandValidation(t *testing.T) {
 	executor := NewCommandExecutor(5 * time.Second)
 
@@ -164,3 +323,27 @@ func TestFindBinary(t *testing.T) {
 		})
 	}
 }
+
+func TestFindBinaryNotFound(t *testing.T) {
+	searchPath := t.TempDir()
+	t.Setenv("PATH", searchPath)
+
+	_, err := FindBinary("rg")
+	var notFound *BinaryNotFoundError
+	if !errors.As(err, &notFound) {
+		t.Fatalf("Expected a *BinaryNotFoundError, got %T: %v", err, err)
+	}
+	if !errors.Is(err, exec.ErrNotFound) {
+		t.Errorf("Expected the error to wrap exec.ErrNotFound, got: %v", err)
+	}
+	if !strings.Contains(err.Error(), searchPath) {
+		t.Errorf("Expected the searched PATH in the error, got: %v", err)
+	}
+	if !strings.Contains(err.Error(), "install ripgrep") {
+		t.Errorf("Expected an install hint in the error, got: %v", err)
+	}
+
+	if _, err := FindBinary("nonexistent-binary-xyz"); err == nil || strings.Contains(err.Error(), "install") {
+		t.Errorf("Expected no install hint for an unknown binary, got: %v", err)
+	}
+}
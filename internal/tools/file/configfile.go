@@ -0,0 +1,299 @@
+// Package file provides the tools that validate and format configuration files.
+package file
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"gopkg.in/yaml.v3"
+
+	"github.com/d-kuro/claude-code-mcp/internal/prompts"
+	"github.com/d-kuro/claude-code-mcp/internal/tools"
+)
+
+// Configuration file formats supported by ValidateFile and FormatFile.
+const (
+	ConfigFormatJSON = "json"
+	ConfigFormatYAML = "yaml"
+	ConfigFormatTOML = "toml"
+)
+
+// configFormats maps file extensions to configuration file formats.
+var configFormats = map[string]string{
+	".json": ConfigFormatJSON,
+	".yaml": ConfigFormatYAML,
+	".yml":  ConfigFormatYAML,
+	".toml": ConfigFormatTOML,
+}
+
+// yamlErrorPattern extracts the position from yaml.v3 error messages such as
+// "yaml: line 3: could not find expected ':'".
+var yamlErrorPattern = regexp.MustCompile(`^yaml: (?:unmarshal errors:\s*)?line (\d+)(?:, column (\d+))?: (.*)$`)
+
+// ConfigFileArgs represents the arguments for the ValidateFile tool.
+type ConfigFileArgs struct {
+	FilePath string `json:"file_path"`
+}
+
+// FormatFileArgs represents the arguments for the FormatFile tool.
+type FormatFileArgs struct {
+	FilePath string `json:"file_path"`
+	// AllowLossy allows formats that cannot be rewritten without losing
+	// comments and key order, which is TOML, to be formatted.
+	AllowLossy *bool `json:"allow_lossy,omitempty"`
+}
+
+// ConfigSyntaxError locates a syntax error in a configuration file. Line and
+// Column start at 1 and are zero when the parser does not report them.
+type ConfigSyntaxError struct {
+	Line    int    `json:"line,omitempty"`
+	Column  int    `json:"column,omitempty"`
+	Message string `json:"message"`
+}
+
+// ConfigFileResult is the result of the ValidateFile and FormatFile tools.
+type ConfigFileResult struct {
+	FilePath string             `json:"file_path"`
+	Format   string             `json:"format"`
+	Valid    bool               `json:"valid"`
+	Error    *ConfigSyntaxError `json:"error,omitempty"`
+	// Formatted reports whether FormatFile rewrote the file. It is false
+	// when the file was already formatted.
+	Formatted bool   `json:"formatted,omitempty"`
+	Note      string `json:"note,omitempty"`
+}
+
+// CreateValidateFileTool creates the ValidateFile tool using MCP SDK patterns.
+func CreateValidateFileTool(ctx *tools.Context) *tools.ServerTool {
+	handler := func(ctxReq context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[ConfigFileArgs]) (*mcp.CallToolResultFor[any], error) {
+		args := params.Arguments
+
+		sanitizedPath, err := ctx.Validator.SanitizePath(args.FilePath)
+		if err != nil {
+			return tools.InvalidPathError(err), nil
+		}
+
+		if err := ctx.Validator.ValidatePath(sanitizedPath); err != nil {
+			return tools.PathValidationError(err), nil
+		}
+
+		format, err := configFormat(sanitizedPath)
+		if err != nil {
+			return tools.ErrorResponse(err.Error()), nil
+		}
+
+		content, err := os.ReadFile(sanitizedPath)
+		if err != nil {
+			return tools.ErrorResponsef("failed to read file: %v", err), nil
+		}
+
+		result := ConfigFileResult{FilePath: sanitizedPath, Format: format, Valid: true}
+		if _, syntaxErr := formatConfig(format, content); syntaxErr != nil {
+			result.Valid, result.Error = false, syntaxErr
+		}
+		return tools.JSONResponse(result), nil
+	}
+
+	tool := &mcp.Tool{
+		Name:        "ValidateFile",
+		Description: prompts.ValidateFileToolDoc,
+	}
+
+	return &tools.ServerTool{
+		Tool: tool,
+		RegisterFunc: func(server *mcp.Server) {
+			mcp.AddTool(server, tool, handler)
+		},
+	}
+}
+
+// CreateFormatFileTool creates the FormatFile tool using MCP SDK patterns.
+func CreateFormatFileTool(ctx *tools.Context) *tools.ServerTool {
+	opts := newWriteOptions(ctx)
+
+	handler := func(ctxReq context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[FormatFileArgs]) (*mcp.CallToolResultFor[any], error) {
+		args := params.Arguments
+
+		sanitizedPath, err := ctx.Validator.SanitizePath(args.FilePath)
+		if err != nil {
+			return tools.InvalidPathError(err), nil
+		}
+
+		if err := ctx.Validator.ValidateWritePath(sanitizedPath); err != nil {
+			return tools.PathValidationError(err), nil
+		}
+
+		format, err := configFormat(sanitizedPath)
+		if err != nil {
+			return tools.ErrorResponse(err.Error()), nil
+		}
+
+		// TOML is re-encoded from its decoded value, which drops comments and
+		// reorders keys, so it is only formatted when asked to explicitly
+		if format == ConfigFormatTOML && (args.AllowLossy == nil || !*args.AllowLossy) {
+			return tools.ErrorResponse("formatting TOML drops its comments and sorts its keys; pass allow_lossy: true to format it anyway, or use ValidateFile to check it"), nil
+		}
+
+		info, err := os.Stat(sanitizedPath)
+		if err != nil {
+			return tools.ErrorResponsef("failed to stat file: %v", err), nil
+		}
+		content, err := os.ReadFile(sanitizedPath)
+		if err != nil {
+			return tools.ErrorResponsef("failed to read file: %v", err), nil
+		}
+
+		result := ConfigFileResult{FilePath: sanitizedPath, Format: format, Valid: true}
+		formatted, syntaxErr := formatConfig(format, content)
+		if syntaxErr != nil {
+			// An invalid file is left untouched
+			result.Valid, result.Error = false, syntaxErr
+			return tools.JSONResponse(result), nil
+		}
+
+		if !bytes.Equal(formatted, content) {
+			err := retryTransient(opts, func() error {
+				return replaceFileAtomic(sanitizedPath, content, formatted, info.Mode(), opts)
+			})
+			if err != nil {
+				return tools.ErrorResponse(err.Error()), nil
+			}
+			result.Formatted = true
+		}
+		if format == ConfigFormatTOML {
+			result.Note = "TOML files are rewritten with sorted keys and without comments"
+		}
+		return tools.JSONResponse(result), nil
+	}
+
+	tool := &mcp.Tool{
+		Name:        "FormatFile",
+		Description: prompts.FormatFileToolDoc,
+	}
+
+	return &tools.ServerTool{
+		Tool: tool,
+		RegisterFunc: func(server *mcp.Server) {
+			mcp.AddTool(server, tool, handler)
+		},
+	}
+}
+
+// configFormat returns the configuration format of a file from its extension.
+func configFormat(path string) (string, error) {
+	format, ok := configFormats[strings.ToLower(filepath.Ext(path))]
+	if !ok {
+		return "", fmt.Errorf("unsupported file type %q: expected .json, .yaml, .yml or .toml", filepath.Ext(path))
+	}
+	return format, nil
+}
+
+// formatConfig parses content in the given format and returns it
+// pretty-printed, or the syntax error that makes it invalid. JSON keeps its
+// key order and is indented with two spaces, YAML keeps its key order and
+// comments, and TOML is re-encoded from its decoded value.
+func formatConfig(format string, content []byte) ([]byte, *ConfigSyntaxError) {
+	switch format {
+	case ConfigFormatJSON:
+		return formatJSON(content)
+	case ConfigFormatYAML:
+		return formatYAML(content)
+	case ConfigFormatTOML:
+		return formatTOML(content)
+	}
+	return nil, &ConfigSyntaxError{Message: "unsupported format " + format}
+}
+
+func formatJSON(content []byte) ([]byte, *ConfigSyntaxError) {
+	var raw json.RawMessage
+	if err := json.Unmarshal(content, &raw); err != nil {
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			line, column := offsetPosition(content, syntaxErr.Offset)
+			return nil, &ConfigSyntaxError{Line: line, Column: column, Message: syntaxErr.Error()}
+		}
+		return nil, &ConfigSyntaxError{Message: err.Error()}
+	}
+
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, raw, "", "  "); err != nil {
+		return nil, &ConfigSyntaxError{Message: err.Error()}
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+func formatYAML(content []byte) ([]byte, *ConfigSyntaxError) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	for {
+		var document yaml.Node
+		err := decoder.Decode(&document)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, yamlSyntaxError(err)
+		}
+		if err := encoder.Encode(&document); err != nil {
+			return nil, &ConfigSyntaxError{Message: err.Error()}
+		}
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, &ConfigSyntaxError{Message: err.Error()}
+	}
+	return buf.Bytes(), nil
+}
+
+// yamlSyntaxError converts a yaml.v3 error, which reports positions only in
+// its message.
+func yamlSyntaxError(err error) *ConfigSyntaxError {
+	match := yamlErrorPattern.FindStringSubmatch(err.Error())
+	if match == nil {
+		return &ConfigSyntaxError{Message: err.Error()}
+	}
+	line, _ := strconv.Atoi(match[1])
+	column, _ := strconv.Atoi(match[2])
+	return &ConfigSyntaxError{Line: line, Column: column, Message: match[3]}
+}
+
+func formatTOML(content []byte) ([]byte, *ConfigSyntaxError) {
+	var value map[string]any
+	if _, err := toml.Decode(string(content), &value); err != nil {
+		var parseErr toml.ParseError
+		if errors.As(err, &parseErr) {
+			return nil, &ConfigSyntaxError{Line: parseErr.Position.Line, Column: parseErr.Position.Col, Message: parseErr.Message}
+		}
+		return nil, &ConfigSyntaxError{Message: err.Error()}
+	}
+
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(value); err != nil {
+		return nil, &ConfigSyntaxError{Message: err.Error()}
+	}
+	return buf.Bytes(), nil
+}
+
+// offsetPosition returns the line and column, starting at 1, of the byte
+// before offset, which is where encoding/json reports a syntax error.
+func offsetPosition(content []byte, offset int64) (line, column int) {
+	end := min(max(int(offset)-1, 0), len(content))
+	before := content[:end]
+	line = bytes.Count(before, []byte("\n")) + 1
+	column = end - bytes.LastIndexByte(before, '\n')
+	return line, column
+}
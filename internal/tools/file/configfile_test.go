@@ -0,0 +1,184 @@
+package file
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/d-kuro/claude-code-mcp/internal/tools"
+)
+
+// callConfigFileTool calls ValidateFile or FormatFile and decodes its result.
+func callConfigFileTool(t *testing.T, tool *tools.ServerTool, args map[string]any) ConfigFileResult {
+	t.Helper()
+
+	text, isError := callServerTool(t, tool, args)
+	if isError {
+		t.Fatalf("Expected success, got: %s", text)
+	}
+	var result ConfigFileResult
+	if err := json.Unmarshal([]byte(text), &result); err != nil {
+		t.Fatalf("Failed to decode result %q: %v", text, err)
+	}
+	return result
+}
+
+func TestValidateFile(t *testing.T) {
+	ctx := &tools.Context{Validator: &mockValidator{}}
+	dir := t.TempDir()
+
+	tests := []struct {
+		name       string
+		file       string
+		content    string
+		wantValid  bool
+		wantLine   int
+		wantColumn int
+	}{
+		{name: "valid JSON", file: "config.json", content: "{\n  \"name\": \"app\",\n  \"ports\": [80, 443]\n}\n", wantValid: true},
+		{name: "invalid JSON", file: "broken.json", content: "{\n  \"name\": \"app\",\n  \"ports\": [80 443]\n}\n", wantLine: 3, wantColumn: 16},
+		{name: "trailing comma", file: "comma.json", content: "{\"a\": 1,}", wantLine: 1, wantColumn: 9},
+		{name: "valid YAML", file: "config.yaml", content: "name: app\nports:\n  - 80\n", wantValid: true},
+		{name: "invalid YAML", file: "broken.yml", content: "name: app\nports:\n\t- 80\n", wantLine: 3},
+		{name: "valid TOML", file: "config.toml", content: "name = \"app\"\n\n[server]\nport = 80\n", wantValid: true},
+		{name: "invalid TOML", file: "broken.toml", content: "name = \"app\"\nport = = 80\n", wantLine: 2, wantColumn: 8},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filePath := filepath.Join(dir, tt.file)
+			if err := os.WriteFile(filePath, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to write file: %v", err)
+			}
+
+			result := callConfigFileTool(t, CreateValidateFileTool(ctx), map[string]any{"file_path": filePath})
+			if result.Valid != tt.wantValid {
+				t.Fatalf("Expected valid=%v, got %+v", tt.wantValid, result)
+			}
+			if tt.wantValid {
+				if result.Error != nil {
+					t.Errorf("Expected no error for a valid file, got %+v", result.Error)
+				}
+				return
+			}
+			if result.Error == nil || result.Error.Message == "" {
+				t.Fatalf("Expected a syntax error, got %+v", result)
+			}
+			if result.Error.Line != tt.wantLine {
+				t.Errorf("Expected error on line %d, got %+v", tt.wantLine, result.Error)
+			}
+			if tt.wantColumn != 0 && result.Error.Column != tt.wantColumn {
+				t.Errorf("Expected error in column %d, got %+v", tt.wantColumn, result.Error)
+			}
+
+			content, _ := os.ReadFile(filePath)
+			if string(content) != tt.content {
+				t.Errorf("Expected ValidateFile to leave the file unchanged, got %q", content)
+			}
+		})
+	}
+
+	t.Run("unsupported extension", func(t *testing.T) {
+		text, isError := callServerTool(t, CreateValidateFileTool(ctx), map[string]any{"file_path": filepath.Join(dir, "notes.txt")})
+		if !isError {
+			t.Errorf("Expected an error for an unsupported extension, got: %s", text)
+		}
+	})
+}
+
+func TestFormatFile(t *testing.T) {
+	ctx := &tools.Context{Validator: &mockValidator{}}
+	dir := t.TempDir()
+
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		lossy   bool
+		want    string
+	}{
+		{
+			name:    "JSON",
+			file:    "package.json",
+			content: `{"name":"app","scripts":{"test":"go test"},"tags":["a","b"]}`,
+			want:    "{\n  \"name\": \"app\",\n  \"scripts\": {\n    \"test\": \"go test\"\n  },\n  \"tags\": [\n    \"a\",\n    \"b\"\n  ]\n}\n",
+		},
+		{
+			name:    "YAML",
+			file:    "config.yaml",
+			content: "# settings\nname:    app\nports:\n    - 80\n    - 443\n",
+			want:    "# settings\nname: app\nports:\n  - 80\n  - 443\n",
+		},
+		{
+			name:    "TOML",
+			file:    "config.toml",
+			content: "name=\"app\"\n[server]\nport=80\n",
+			lossy:   true,
+			want:    "name = \"app\"\n\n[server]\n  port = 80\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filePath := filepath.Join(dir, tt.file)
+			if err := os.WriteFile(filePath, []byte(tt.content), 0640); err != nil {
+				t.Fatalf("Failed to write file: %v", err)
+			}
+
+			result := callConfigFileTool(t, CreateFormatFileTool(ctx), map[string]any{"file_path": filePath, "allow_lossy": tt.lossy})
+			if !result.Valid || !result.Formatted {
+				t.Fatalf("Expected the file to be formatted, got %+v", result)
+			}
+			content, _ := os.ReadFile(filePath)
+			if string(content) != tt.want {
+				t.Errorf("Unexpected formatted content:\ngot:  %q\nwant: %q", content, tt.want)
+			}
+			if info, _ := os.Stat(filePath); info.Mode().Perm() != 0640 {
+				t.Errorf("Expected the file mode to be kept, got %v", info.Mode())
+			}
+
+			// Formatting again is a no-op and the result still validates
+			result = callConfigFileTool(t, CreateFormatFileTool(ctx), map[string]any{"file_path": filePath, "allow_lossy": tt.lossy})
+			if !result.Valid || result.Formatted {
+				t.Errorf("Expected an already formatted file to be left alone, got %+v", result)
+			}
+			if result := callConfigFileTool(t, CreateValidateFileTool(ctx), map[string]any{"file_path": filePath}); !result.Valid {
+				t.Errorf("Expected the formatted file to be valid, got %+v", result)
+			}
+		})
+	}
+
+	t.Run("TOML requires allow_lossy", func(t *testing.T) {
+		filePath := filepath.Join(dir, "settings.toml")
+		original := "# listen address\nport=80\n"
+		if err := os.WriteFile(filePath, []byte(original), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+
+		text, isError := callServerTool(t, CreateFormatFileTool(ctx), map[string]any{"file_path": filePath})
+		if !isError || !strings.Contains(text, "allow_lossy") {
+			t.Errorf("Expected TOML formatting to be refused without allow_lossy, got: %s", text)
+		}
+		if content, _ := os.ReadFile(filePath); string(content) != original {
+			t.Errorf("Expected the TOML file to be left unchanged, got %q", content)
+		}
+	})
+
+	t.Run("invalid file is not modified", func(t *testing.T) {
+		filePath := filepath.Join(dir, "broken.json")
+		original := `{"name": "app",}`
+		if err := os.WriteFile(filePath, []byte(original), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+
+		result := callConfigFileTool(t, CreateFormatFileTool(ctx), map[string]any{"file_path": filePath})
+		if result.Valid || result.Formatted || result.Error == nil {
+			t.Fatalf("Expected a syntax error, got %+v", result)
+		}
+		if content, _ := os.ReadFile(filePath); string(content) != original {
+			t.Errorf("Expected the invalid file to be left unchanged, got %q", content)
+		}
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
@@ -19,6 +20,8 @@ type EditArgs struct {
 	OldString  string `json:"old_string"`
 	NewString  string `json:"new_string"`
 	ReplaceAll *bool  `json:"replace_all,omitempty"`
+	// Idempotent treats an edit whose new_string is already present as a no-op.
+	Idempotent *bool `json:"idempotent,omitempty"`
 }
 
 // CreateEditTool creates the Edit tool using MCP SDK patterns.
@@ -28,17 +31,11 @@ func CreateEditTool(ctx *tools.Context) *tools.ServerTool {
 
 		sanitizedPath, err := ctx.Validator.SanitizePath(args.FilePath)
 		if err != nil {
-			return &mcp.CallToolResultFor[any]{
-				Content: []mcp.Content{&mcp.TextContent{Text: "Error: Invalid file path: " + err.Error()}},
-				IsError: true,
-			}, nil
+			return tools.ValidationErrorResult("Invalid file path", err), nil
 		}
 
-		if err := ctx.Validator.ValidatePath(sanitizedPath); err != nil {
-			return &mcp.CallToolResultFor[any]{
-				Content: []mcp.Content{&mcp.TextContent{Text: "Error: Path validation failed: " + err.Error()}},
-				IsError: true,
-			}, nil
+		if err := ctx.Validator.ValidateWritePath(sanitizedPath); err != nil {
+			return tools.ValidationErrorResult("Path validation failed", err), nil
 		}
 
 		if args.OldString == args.NewString {
@@ -49,13 +46,20 @@ func CreateEditTool(ctx *tools.Context) *tools.ServerTool {
 		}
 
 		if args.OldString == "" {
+			// An empty old_string creates a file that does not exist yet
+			result, err := createFileOnEdit(sanitizedPath, args.NewString, ctx.NewFileMode(), ctx.NewDirMode())
+			if err != nil {
+				return &mcp.CallToolResultFor[any]{
+					Content: []mcp.Content{&mcp.TextContent{Text: "Error: " + err.Error()}},
+					IsError: true,
+				}, nil
+			}
 			return &mcp.CallToolResultFor[any]{
-				Content: []mcp.Content{&mcp.TextContent{Text: "Error: old_string cannot be empty"}},
-				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: result}},
 			}, nil
 		}
 
-		result, err := editFileContent(sanitizedPath, args.OldString, args.NewString, args.ReplaceAll)
+		result, err := editFileContent(sanitizedPath, args.OldString, args.NewString, args.ReplaceAll, args.Idempotent, newWriteOptions(ctx))
 		if err != nil {
 			return &mcp.CallToolResultFor[any]{
 				Content: []mcp.Content{&mcp.TextContent{Text: "Error: " + err.Error()}},
@@ -81,8 +85,14 @@ func CreateEditTool(ctx *tools.Context) *tools.ServerTool {
 	}
 }
 
+// alreadyAppliedMessage is returned when an idempotent edit finds its result already in place.
+const alreadyAppliedMessage = "edit appears already applied; no changes made"
+
 // editFileContent performs string replacement on a file.
-func editFileContent(filePath, oldString, newString string, replaceAll *bool) (string, error) {
+// When idempotent is set and old_string is absent but new_string is present,
+// the edit is reported as already applied instead of failing.
+// opts selects the write strategy and the retries on transient errors.
+func editFileContent(filePath, oldString, newString string, replaceAll, idempotent *bool, opts writeOptions) (string, error) {
 	stat, err := os.Stat(filePath)
 	if err != nil {
 		return "", fmt.Errorf("failed to stat file: %w", err)
@@ -100,6 +110,10 @@ func editFileContent(filePath, oldString, newString string, replaceAll *bool) (s
 	originalContent := string(content)
 	shouldReplaceAll := replaceAll != nil && *replaceAll
 
+	if idempotent != nil && *idempotent && isEditAlreadyApplied(originalContent, oldString, newString) {
+		return alreadyAppliedMessage, nil
+	}
+
 	var modifiedContent string
 	var replacementCount int
 
@@ -112,7 +126,7 @@ func editFileContent(filePath, oldString, newString string, replaceAll *bool) (s
 			return "", fmt.Errorf("old_string not found in file")
 		}
 		if occurrenceCount > 1 {
-			return "", fmt.Errorf("old_string appears %d times in file - use replace_all=true or provide more context to make it unique", occurrenceCount)
+			return "", ambiguousMatchError(originalContent, oldString, occurrenceCount)
 		}
 
 		modifiedContent = strings.Replace(originalContent, oldString, newString, 1)
@@ -123,22 +137,50 @@ func editFileContent(filePath, oldString, newString string, replaceAll *bool) (s
 		return "", fmt.Errorf("old_string not found in file")
 	}
 
-	backupPath := filePath + ".backup"
-	if err := os.WriteFile(backupPath, content, stat.Mode()); err != nil {
-		return "", fmt.Errorf("failed to create backup file: %w", err)
+	if err := replaceFileContent(filePath, content, []byte(modifiedContent), stat.Mode(), opts); err != nil {
+		return "", err
 	}
 
-	if err := os.WriteFile(filePath, []byte(modifiedContent), stat.Mode()); err != nil {
-		if restoreErr := os.Rename(backupPath, filePath); restoreErr != nil {
-			return "", fmt.Errorf("failed to write file and failed to restore backup: write error: %w, restore error: %v", err, restoreErr)
-		}
-		return "", fmt.Errorf("failed to write file (backup restored): %w", err)
-	}
-
-	_ = os.Remove(backupPath)
-
 	if shouldReplaceAll {
 		return fmt.Sprintf("Successfully replaced %d occurrences in %s", replacementCount, filePath), nil
 	}
 	return fmt.Sprintf("Successfully replaced 1 occurrence in %s", filePath), nil
 }
+
+// createFileOnEdit creates filePath with content when it does not exist yet.
+// An empty old_string is only accepted for a missing file; for an existing
+// file the edit is rejected.
+func createFileOnEdit(filePath, content string, fileMode, dirMode os.FileMode) (string, error) {
+	if _, err := os.Lstat(filePath); err == nil {
+		return "", fmt.Errorf("old_string cannot be empty when the file exists")
+	} else if !os.IsNotExist(err) {
+		return "", fmt.Errorf("failed to stat file: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(filePath), dirMode); err != nil {
+		return "", fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	// O_EXCL keeps a file created since the check above from being overwritten
+	file, err := os.OpenFile(filePath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, fileMode)
+	if err != nil {
+		return "", fmt.Errorf("failed to create file: %w", err)
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	bytesWritten, err := file.WriteString(content)
+	if err != nil {
+		_ = os.Remove(filePath)
+		return "", fmt.Errorf("failed to write content: %w", err)
+	}
+
+	return fmt.Sprintf("Created %s with new_string as its content (%d bytes)", filePath, bytesWritten), nil
+}
+
+// isEditAlreadyApplied reports whether content no longer contains oldString
+// but already contains the non-empty newString.
+func isEditAlreadyApplied(content, oldString, newString string) bool {
+	return newString != "" && !strings.Contains(content, oldString) && strings.Contains(content, newString)
+}
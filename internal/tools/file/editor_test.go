@@ -1,12 +1,15 @@
 package file
 
 import (
+	"context"
 	"errors"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
 
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
 	"github.com/d-kuro/claude-code-mcp/internal/tools"
 )
 
@@ -88,7 +91,7 @@ func TestEditFileContent(t *testing.T) {
 			stat, _ := os.Stat(testFile)
 			originalMode := stat.Mode()
 
-			result, err := editFileContent(testFile, tt.oldString, tt.newString, tt.replaceAll)
+			result, err := editFileContent(testFile, tt.oldString, tt.newString, tt.replaceAll, nil, writeOptions{})
 
 			if tt.expectError {
 				if err == nil {
@@ -151,6 +154,99 @@ func TestEditFileContent(t *testing.T) {
 	}
 }
 
+func TestEditFileAlreadyApplied(t *testing.T) {
+	tempDir := t.TempDir()
+
+	tests := []struct {
+		name          string
+		content       string
+		oldString     string
+		newString     string
+		replaceAll    *bool
+		idempotent    *bool
+		expectError   bool
+		expectMessage string
+	}{
+		{
+			name:        "default fails when already applied",
+			content:     "Hello Go",
+			oldString:   "world",
+			newString:   "Go",
+			expectError: true,
+		},
+		{
+			name:          "idempotent reports already applied",
+			content:       "Hello Go",
+			oldString:     "world",
+			newString:     "Go",
+			idempotent:    boolPtr(true),
+			expectMessage: "edit appears already applied; no changes made",
+		},
+		{
+			name:          "idempotent with replace_all reports already applied",
+			content:       "qux bar qux",
+			oldString:     "foo",
+			newString:     "qux",
+			replaceAll:    boolPtr(true),
+			idempotent:    boolPtr(true),
+			expectMessage: "edit appears already applied; no changes made",
+		},
+		{
+			name:        "idempotent still fails when neither string is present",
+			content:     "Hello there",
+			oldString:   "world",
+			newString:   "Go",
+			idempotent:  boolPtr(true),
+			expectError: true,
+		},
+		{
+			name:          "idempotent applies edit when old_string is present",
+			content:       "Hello world",
+			oldString:     "world",
+			newString:     "Go",
+			idempotent:    boolPtr(true),
+			expectMessage: "Successfully replaced 1 occurrence",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testFile := filepath.Join(tempDir, strings.ReplaceAll(tt.name, " ", "_")+".txt")
+			if err := os.WriteFile(testFile, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to create test file: %v", err)
+			}
+
+			result, err := editFileContent(testFile, tt.oldString, tt.newString, tt.replaceAll, tt.idempotent, writeOptions{})
+
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("Expected error but got result: %s", result)
+				} else if !strings.Contains(err.Error(), "not found") {
+					t.Errorf("Expected not found error, got: %v", err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if !strings.Contains(result, tt.expectMessage) {
+				t.Errorf("Expected result containing %q, got: %s", tt.expectMessage, result)
+			}
+
+			content, err := os.ReadFile(testFile)
+			if err != nil {
+				t.Fatalf("Failed to read file: %v", err)
+			}
+			expected := strings.ReplaceAll(tt.content, tt.oldString, tt.newString)
+			if string(content) != expected {
+				t.Errorf("Expected content %q, got %q", expected, string(content))
+			}
+		})
+	}
+}
+
 func TestEditFileBackupAndRestore(t *testing.T) {
 	tempDir, err := os.MkdirTemp("", "editor_backup_test_*")
 	if err != nil {
@@ -166,7 +262,7 @@ func TestEditFileBackupAndRestore(t *testing.T) {
 
 	// Test successful backup creation and cleanup
 	t.Run("successful operation cleans up backup", func(t *testing.T) {
-		result, err := editFileContent(testFile, "original", "modified", nil)
+		result, err := editFileContent(testFile, "original", "modified", nil, nil, writeOptions{})
 		if err != nil {
 			t.Errorf("Edit failed: %v", err)
 			return
@@ -196,7 +292,7 @@ func TestEditFileBackupAndRestore(t *testing.T) {
 		}
 
 		// Force an error by trying to edit with empty old_string
-		_, err := editFileContent(testFile, "", "test", nil)
+		_, err := editFileContent(testFile, "", "test", nil, nil, writeOptions{})
 		if err == nil {
 			t.Errorf("Expected error for empty old_string")
 			return
@@ -266,7 +362,7 @@ func TestEditFileErrors(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			testPath := tt.setupFunc()
 
-			_, err := editFileContent(testPath, tt.oldString, tt.newString, nil)
+			_, err := editFileContent(testPath, tt.oldString, tt.newString, nil, nil, writeOptions{})
 
 			if err == nil {
 				t.Errorf("Expected error but got none")
@@ -307,7 +403,7 @@ func TestCreateEditTool(t *testing.T) {
 	}
 
 	// Test successful edit through the core function
-	result, err := editFileContent(testFile, "world", "Go", nil)
+	result, err := editFileContent(testFile, "world", "Go", nil, nil, writeOptions{})
 	if err != nil {
 		t.Errorf("Tool function failed: %v", err)
 	}
@@ -388,7 +484,7 @@ func TestEditFileContentEdgeCases(t *testing.T) {
 				t.Fatalf("Failed to create test file: %v", err)
 			}
 
-			_, err := editFileContent(testFile, tt.oldString, tt.newString, tt.replaceAll)
+			_, err := editFileContent(testFile, tt.oldString, tt.newString, tt.replaceAll, nil, writeOptions{})
 			if err != nil {
 				t.Errorf("Edit failed: %v", err)
 				return
@@ -437,6 +533,10 @@ func (m *mockEditorValidator) ValidatePath(path string) error {
 	return nil
 }
 
+func (m *mockEditorValidator) ValidateWritePath(path string) error {
+	return m.ValidatePath(path)
+}
+
 func (m *mockEditorValidator) ValidateContent(content []byte) error {
 	return nil
 }
@@ -448,3 +548,94 @@ func (m *mockEditorValidator) ValidateCommand(cmd string, args []string) error {
 func (m *mockEditorValidator) ValidateURL(url string) error {
 	return nil
 }
+
+func callEditTool(t *testing.T, args map[string]any) (string, bool) {
+	t.Helper()
+	return callServerTool(t, CreateEditTool(&tools.Context{Validator: &mockValidator{}}), args)
+}
+
+// callServerTool calls a tool through an in-memory MCP session and returns
+// the text of its result and whether it is an error.
+func callServerTool(t *testing.T, tool *tools.ServerTool, args map[string]any) (string, bool) {
+	t.Helper()
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "test-server", Version: "v0.0.1"}, nil)
+	tool.RegisterFunc(server)
+
+	ctx := context.Background()
+	clientTransport, serverTransport := mcp.NewInMemoryTransports()
+	serverSession, err := server.Connect(ctx, serverTransport)
+	if err != nil {
+		t.Fatalf("Failed to connect server: %v", err)
+	}
+	defer func() { _ = serverSession.Close() }()
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "v0.0.1"}, nil)
+	clientSession, err := client.Connect(ctx, clientTransport)
+	if err != nil {
+		t.Fatalf("Failed to connect client: %v", err)
+	}
+	defer func() { _ = clientSession.Close() }()
+
+	result, err := clientSession.CallTool(ctx, &mcp.CallToolParams{Name: tool.Tool.Name, Arguments: args})
+	if err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+	text, ok := result.Content[0].(*mcp.TextContent)
+	if !ok {
+		t.Fatal("Expected TextContent")
+	}
+	return text.Text, result.IsError
+}
+
+func TestEditCreatesMissingFile(t *testing.T) {
+	dir := t.TempDir()
+	newFile := filepath.Join(dir, "nested", "new.txt")
+
+	text, isError := callEditTool(t, map[string]any{
+		"file_path":  newFile,
+		"old_string": "",
+		"new_string": "first line\n",
+	})
+	if isError {
+		t.Fatalf("Expected the file to be created, got error: %s", text)
+	}
+	if !strings.Contains(text, "Created "+newFile) {
+		t.Errorf("Unexpected result message: %s", text)
+	}
+
+	content, err := os.ReadFile(newFile)
+	if err != nil {
+		t.Fatalf("Failed to read created file: %v", err)
+	}
+	if string(content) != "first line\n" {
+		t.Errorf("Expected new_string as the file content, got %q", content)
+	}
+}
+
+func TestEditRejectsEmptyOldStringForExistingFile(t *testing.T) {
+	existing := filepath.Join(t.TempDir(), "existing.txt")
+	if err := os.WriteFile(existing, []byte("keep me"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	text, isError := callEditTool(t, map[string]any{
+		"file_path":  existing,
+		"old_string": "",
+		"new_string": "replaced",
+	})
+	if !isError {
+		t.Fatalf("Expected an error for an empty old_string on an existing file, got: %s", text)
+	}
+	if !strings.Contains(text, "old_string cannot be empty") {
+		t.Errorf("Unexpected error message: %s", text)
+	}
+
+	content, err := os.ReadFile(existing)
+	if err != nil {
+		t.Fatalf("Failed to read file: %v", err)
+	}
+	if string(content) != "keep me" {
+		t.Errorf("Expected the existing file to be unchanged, got %q", content)
+	}
+}
@@ -0,0 +1,46 @@
+package file
+
+import (
+	"context"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/d-kuro/claude-code-mcp/internal/security"
+	"github.com/d-kuro/claude-code-mcp/internal/tools"
+)
+
+func TestBlockedPathErrorCode(t *testing.T) {
+	server := mcp.NewServer(&mcp.Implementation{Name: "test-server", Version: "v0.0.1"}, nil)
+	CreateReadTool(&tools.Context{Validator: security.NewDefaultValidator()}).RegisterFunc(server)
+
+	ctx := context.Background()
+	clientTransport, serverTransport := mcp.NewInMemoryTransports()
+	serverSession, err := server.Connect(ctx, serverTransport)
+	if err != nil {
+		t.Fatalf("Failed to connect server: %v", err)
+	}
+	defer func() { _ = serverSession.Close() }()
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "v0.0.1"}, nil)
+	clientSession, err := client.Connect(ctx, clientTransport)
+	if err != nil {
+		t.Fatalf("Failed to connect client: %v", err)
+	}
+	defer func() { _ = clientSession.Close() }()
+
+	result, err := clientSession.CallTool(ctx, &mcp.CallToolParams{
+		Name:      "Read",
+		Arguments: map[string]any{"file_path": "/etc/passwd"},
+	})
+	if err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+
+	if !result.IsError {
+		t.Fatal("Expected an error result for a blocked path")
+	}
+	if got := result.Meta[tools.ErrorCodeMetaKey]; got != tools.ErrorCodePathBlocked {
+		t.Errorf("Expected %s in result meta, got %v", tools.ErrorCodePathBlocked, result.Meta)
+	}
+}
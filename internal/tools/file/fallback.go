@@ -0,0 +1,227 @@
+// Package file provides pure-Go fallbacks for the find and ripgrep search commands.
+package file
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// ripgrepFallbackWarning ensures the missing ripgrep warning is logged once per process.
+var ripgrepFallbackWarning sync.Once
+
+// warnRipgrepFallback logs that Grep is running without ripgrep, with the
+// lookup error that names the searched PATH and how to install ripgrep.
+func warnRipgrepFallback(opts grepOptions, lookupErr error) {
+	ripgrepFallbackWarning.Do(func() {
+		if opts.Logger != nil {
+			opts.Logger.Warn("ripgrep (rg) not found; Grep is using a slower built-in search. Install ripgrep for better performance",
+				"error", lookupErr)
+		}
+	})
+}
+
+// grepFilesWithWalk searches file contents by walking the tree in Go. It is
+// used when ripgrep is not installed and honors the same options: hidden
+// files, symlinked files, binary files, include pattern, depth, skipped
+// directories, and the ignore matcher. Unlike ripgrep, .gitignore files are not consulted; .git
+// directories are always skipped. It returns the matching files, the number
+// of matching binary files that were skipped, and whether files vanished
+// during the walk.
+func grepFilesWithWalk(ctx context.Context, searchPath, pattern string, opts grepOptions) (paths []string, skippedBinary int, partial bool, err error) {
+	regex, err := compileGrepPattern(pattern, opts)
+	if err != nil {
+		return nil, 0, false, err
+	}
+
+	walkErr := filepath.WalkDir(searchPath, func(path string, d fs.DirEntry, err error) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				partial = true
+			}
+			if d != nil && d.IsDir() && path != searchPath {
+				return fs.SkipDir
+			}
+			return nil
+		}
+
+		if path == searchPath {
+			return nil
+		}
+
+		name := d.Name()
+		if d.IsDir() {
+			atDepthLimit := opts.MaxDepth > 0 && pathDepth(searchPath, path) >= opts.MaxDepth
+			if name == ".git" || (!opts.SearchHidden && strings.HasPrefix(name, ".")) ||
+				atDepthLimit || isSkippedDirectory(name, opts.SkipDirs) || opts.Ignore.Match(path, true) {
+				return fs.SkipDir
+			}
+			return nil
+		}
+
+		if !opts.SearchHidden && strings.HasPrefix(name, ".") {
+			return nil
+		}
+		if opts.Ignore.Match(path, false) {
+			return nil
+		}
+
+		if d.Type()&fs.ModeSymlink != 0 {
+			if !opts.FollowSymlinks {
+				return nil
+			}
+			if info, err := os.Stat(path); err != nil || !info.Mode().IsRegular() {
+				return nil
+			}
+		} else if !d.Type().IsRegular() {
+			return nil
+		}
+
+		if opts.Include != nil && *opts.Include != "" {
+			if matched, err := matchIncludePattern(*opts.Include, name); err != nil || !matched {
+				return nil
+			}
+		}
+
+		matched, binary, err := grepFile(path, regex)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				partial = true
+			}
+			return nil
+		}
+		if !matched {
+			return nil
+		}
+
+		if binary && !opts.SearchBinary {
+			skippedBinary++
+			return nil
+		}
+		paths = append(paths, path)
+		return nil
+	})
+	if walkErr != nil {
+		return nil, 0, false, fmt.Errorf("search cancelled: %w", walkErr)
+	}
+
+	return paths, skippedBinary, partial, nil
+}
+
+// grepFile reports whether the file contains a match for regex and whether
+// it is binary. Text files are searched line by line with searchFileContent;
+// binary files are matched against their raw content.
+func grepFile(path string, regex *regexp.Regexp) (matched, binary bool, err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return false, false, err
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	header := make([]byte, 512)
+	n, err := io.ReadFull(file, header)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return false, false, err
+	}
+
+	if !isBinaryContent(header[:n]) {
+		matched, err := searchFileContent(path, regex)
+		return matched, false, err
+	}
+
+	rest, err := io.ReadAll(file)
+	if err != nil {
+		return false, true, err
+	}
+	return regex.Match(append(header[:n], rest...)), true, nil
+}
+
+// globFilesWithWalk finds files matching a glob pattern by walking the tree
+// in Go. It is used when find is not installed. Patterns without a slash match
+// file names at any depth, as find -name does; other patterns are matched
+// against the slash-separated path relative to searchPath with
+// matchGlobPattern, so "**/*.go" matches recursively on every platform.
+// Directories named in skipDirs are not entered.
+func globFilesWithWalk(ctx context.Context, searchPath, pattern string, skipDirs []string, maxDepth int) (paths []string, partial bool, err error) {
+	pattern = filepath.ToSlash(pattern)
+	nameOnly := !strings.Contains(pattern, "/")
+
+	walkErr := filepath.WalkDir(searchPath, func(path string, d fs.DirEntry, err error) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				partial = true
+			}
+			if d != nil && d.IsDir() && path != searchPath {
+				return fs.SkipDir
+			}
+			return nil
+		}
+
+		if path == searchPath {
+			return nil
+		}
+
+		if d.IsDir() {
+			if (maxDepth > 0 && pathDepth(searchPath, path) >= maxDepth) || isSkippedDirectory(d.Name(), skipDirs) {
+				return fs.SkipDir
+			}
+			return nil
+		}
+
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		var matched bool
+		if nameOnly {
+			matched, err = filepath.Match(pattern, d.Name())
+		} else {
+			rel, relErr := filepath.Rel(searchPath, path)
+			if relErr != nil {
+				return nil
+			}
+			matched, err = matchGlobPattern(pattern, filepath.ToSlash(rel))
+		}
+		if err != nil {
+			return fmt.Errorf("invalid glob pattern: %w", err)
+		}
+		if matched {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if walkErr != nil {
+		if errors.Is(walkErr, context.Canceled) || errors.Is(walkErr, context.DeadlineExceeded) {
+			return nil, false, fmt.Errorf("search cancelled: %w", walkErr)
+		}
+		return nil, false, walkErr
+	}
+
+	return paths, partial, nil
+}
+
+// pathDepth returns how many levels path is below root; direct children are at depth 1.
+func pathDepth(root, path string) int {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return 0
+	}
+	return strings.Count(rel, string(filepath.Separator)) + 1
+}
@@ -0,0 +1,165 @@
+package file
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/d-kuro/claude-code-mcp/internal/ignore"
+)
+
+// hideBinaries points PATH at an empty directory so external search commands cannot be found.
+func hideBinaries(t *testing.T) {
+	t.Helper()
+	t.Setenv("PATH", t.TempDir())
+}
+
+// setupSearchTree creates a small source tree for the fallback searches.
+func setupSearchTree(t *testing.T) string {
+	t.Helper()
+
+	root := t.TempDir()
+	files := map[string]string{
+		"main.go":             "package main // needle\n",
+		"pkg/util.go":         "package pkg\n",
+		"pkg/deep/inner.go":   "package deep // needle\n",
+		"docs/readme.md":      "needle in docs\n",
+		".hidden/secret.go":   "package hidden // needle\n",
+		".git/objects/x.go":   "needle\n",
+		"data/blob.bin":       "needle\x00\x00\x00\x00\x00\x00\x00\x00",
+		"generated/gen.go":    "package generated // needle\n",
+		"pkg/deep/more/x.txt": "nothing here\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create file: %v", err)
+		}
+	}
+	return root
+}
+
+func TestGrepFallbackWithoutRipgrep(t *testing.T) {
+	root := setupSearchTree(t)
+	hideBinaries(t)
+
+	if _, err := FindBinary("rg"); err == nil {
+		t.Fatal("Expected rg to be hidden from PATH")
+	}
+
+	t.Run("defaults", func(t *testing.T) {
+		result, err := grepFilesWithRipgrep(context.Background(), root, "needle", newGrepOptions(GrepArgs{Pattern: "needle"}))
+		if err != nil {
+			t.Fatalf("grepFilesWithRipgrep() error = %v", err)
+		}
+		for _, want := range []string{"main.go", "inner.go", "readme.md", "secret.go", "gen.go"} {
+			if !strings.Contains(result, want) {
+				t.Errorf("Expected %s in results, got:\n%s", want, result)
+			}
+		}
+		if strings.Contains(result, ".git") || strings.Contains(result, "util.go") {
+			t.Errorf("Unexpected files in results:\n%s", result)
+		}
+		if strings.Contains(result, "blob.bin") || !strings.Contains(result, "(1 binary files skipped)") {
+			t.Errorf("Expected binary file to be skipped and counted, got:\n%s", result)
+		}
+	})
+
+	t.Run("options", func(t *testing.T) {
+		include := "*.go"
+		opts := newGrepOptions(GrepArgs{Pattern: "needle", Include: &include, SearchHidden: boolPtr(false)})
+		opts.MaxDepth = 2
+
+		paths, _, _, err := grepFilesWithWalk(context.Background(), root, "needle", opts)
+		if err != nil {
+			t.Fatalf("grepFilesWithWalk() error = %v", err)
+		}
+
+		got := make([]string, len(paths))
+		for i, path := range paths {
+			rel, _ := filepath.Rel(root, path)
+			got[i] = filepath.ToSlash(rel)
+		}
+		want := "generated/gen.go,main.go"
+		if strings.Join(got, ",") != want {
+			t.Errorf("Expected %s, got %v", want, got)
+		}
+	})
+
+	t.Run("binary search", func(t *testing.T) {
+		opts := newGrepOptions(GrepArgs{Pattern: "needle", SearchBinary: boolPtr(true)})
+		paths, skipped, _, err := grepFilesWithWalk(context.Background(), root, "needle", opts)
+		if err != nil {
+			t.Fatalf("grepFilesWithWalk() error = %v", err)
+		}
+		if skipped != 0 || !strings.Contains(strings.Join(paths, "\n"), "blob.bin") {
+			t.Errorf("Expected binary file to be searched, got %v (skipped %d)", paths, skipped)
+		}
+	})
+}
+
+func TestGlobFallbackWithoutFind(t *testing.T) {
+	root := setupSearchTree(t)
+	hideBinaries(t)
+
+	if _, err := FindBinary("find"); err == nil {
+		t.Fatal("Expected find to be hidden from PATH")
+	}
+
+	relPaths := func(result string) []string {
+		var paths []string
+		for _, line := range strings.Split(result, "\n")[1:] {
+			rel, err := filepath.Rel(root, line)
+			if err != nil {
+				t.Fatalf("Unexpected result line %q", line)
+			}
+			paths = append(paths, filepath.ToSlash(rel))
+		}
+		slices.Sort(paths)
+		return paths
+	}
+
+	t.Run("recursive pattern", func(t *testing.T) {
+		result, err := globFilesWithFind(context.Background(), root, "**/*.go", nil, nil, 0, false)
+		if err != nil {
+			t.Fatalf("globFilesWithFind() error = %v", err)
+		}
+		want := []string{".git/objects/x.go", ".hidden/secret.go", "generated/gen.go", "main.go", "pkg/deep/inner.go", "pkg/util.go"}
+		if got := relPaths(result); !slices.Equal(got, want) {
+			t.Errorf("Expected %v, got %v", want, got)
+		}
+	})
+
+	t.Run("prefixed recursive pattern", func(t *testing.T) {
+		result, err := globFilesWithFind(context.Background(), root, "pkg/**/*.go", nil, nil, 0, false)
+		if err != nil {
+			t.Fatalf("globFilesWithFind() error = %v", err)
+		}
+		want := []string{"pkg/deep/inner.go", "pkg/util.go"}
+		if got := relPaths(result); !slices.Equal(got, want) {
+			t.Errorf("Expected %v, got %v", want, got)
+		}
+	})
+
+	t.Run("name pattern with depth limit and ignore file", func(t *testing.T) {
+		if err := os.WriteFile(filepath.Join(root, ".mcpignore"), []byte("generated/\n"), 0644); err != nil {
+			t.Fatalf("Failed to create ignore file: %v", err)
+		}
+		matcher := ignore.New(filepath.Join(root, ".mcpignore"))
+
+		result, err := globFilesWithFind(context.Background(), root, "*.go", matcher, nil, 2, false)
+		if err != nil {
+			t.Fatalf("globFilesWithFind() error = %v", err)
+		}
+		want := []string{".hidden/secret.go", "main.go", "pkg/util.go"}
+		if got := relPaths(result); !slices.Equal(got, want) {
+			t.Errorf("Expected %v, got %v", want, got)
+		}
+	})
+}
@@ -0,0 +1,338 @@
+// Package file provides the tools that report the state of a git repository.
+package file
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/d-kuro/claude-code-mcp/internal/prompts"
+	"github.com/d-kuro/claude-code-mcp/internal/tools"
+)
+
+// MaxGitDiffBytes caps the diff returned by GitDiff.
+const MaxGitDiffBytes = 256 * 1024
+
+// gitTimeout bounds each git command run by GitStatus and GitDiff.
+const gitTimeout = 30 * time.Second
+
+// gitSafeArgs precede every git command. They keep the repository
+// configuration from starting other programs, such as a pager, an
+// fsmonitor or other hook, or an external diff or textconv driver. Filter
+// drivers are named by the repository, so runGit turns them off separately.
+var gitSafeArgs = []string{
+	"--no-pager",
+	"-c", "core.fsmonitor=false",
+	"-c", "core.hooksPath=" + os.DevNull,
+	"-c", "core.pager=cat",
+	"-c", "color.ui=false",
+}
+
+// gitEnv keeps status from taking the index lock, so a concurrent git
+// command run by the user does not fail, and leaves out the system and
+// global configuration, so only the repository's own settings need to be
+// made safe.
+var gitEnv = map[string]string{
+	"GIT_OPTIONAL_LOCKS":  "0",
+	"GIT_CONFIG_NOSYSTEM": "1",
+	"GIT_CONFIG_GLOBAL":   os.DevNull,
+}
+
+// GitStatusArgs represents the arguments for the GitStatus tool.
+type GitStatusArgs struct {
+	Path string `json:"path,omitempty"`
+}
+
+// GitDiffArgs represents the arguments for the GitDiff tool.
+type GitDiffArgs struct {
+	Path     string `json:"path,omitempty"`
+	Staged   *bool  `json:"staged,omitempty"`
+	FilePath string `json:"file_path,omitempty"`
+}
+
+// GitFileStatus is one changed path reported by GitStatus. Index and
+// Worktree hold git's one-letter codes for the staged and unstaged change,
+// with "." for no change and "?" for an untracked file.
+type GitFileStatus struct {
+	Path     string `json:"path"`
+	OrigPath string `json:"orig_path,omitempty"`
+	Index    string `json:"index"`
+	Worktree string `json:"worktree"`
+	Conflict bool   `json:"conflict,omitempty"`
+}
+
+// GitStatusResult is the result of the GitStatus tool.
+type GitStatusResult struct {
+	Root     string          `json:"root"`
+	Branch   string          `json:"branch"`
+	Upstream string          `json:"upstream,omitempty"`
+	Ahead    int             `json:"ahead,omitempty"`
+	Behind   int             `json:"behind,omitempty"`
+	Files    []GitFileStatus `json:"files"`
+}
+
+// CreateGitStatusTool creates the GitStatus tool using MCP SDK patterns.
+func CreateGitStatusTool(ctx *tools.Context) *tools.ServerTool {
+	handler := func(ctxReq context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[GitStatusArgs]) (*mcp.CallToolResultFor[any], error) {
+		args := params.Arguments
+
+		repoPath, errResult := gitRepoPath(ctx, args.Path)
+		if errResult != nil {
+			return errResult, nil
+		}
+
+		executor := NewCommandExecutor(gitTimeout).WithCleanEnv(ctx.CleanEnv).WithEnvOverrides(gitEnv)
+		root, err := runGit(ctxReq, executor, repoPath, "rev-parse", "--show-toplevel")
+		if err != nil {
+			return tools.ErrorResponse(err.Error()), nil
+		}
+
+		output, err := runGit(ctxReq, executor, repoPath, "status", "--porcelain=v2", "--branch", "-z", "--untracked-files=all")
+		if err != nil {
+			return tools.ErrorResponse(err.Error()), nil
+		}
+
+		result, err := parseGitStatus(output)
+		if err != nil {
+			return tools.ErrorResponsef("failed to parse git status: %v", err), nil
+		}
+		result.Root = strings.TrimSpace(root)
+		return tools.JSONResponse(result), nil
+	}
+
+	tool := &mcp.Tool{
+		Name:        "GitStatus",
+		Description: prompts.GitStatusToolDoc,
+	}
+
+	return &tools.ServerTool{
+		Tool: tool,
+		RegisterFunc: func(server *mcp.Server) {
+			mcp.AddTool(server, tool, handler)
+		},
+	}
+}
+
+// CreateGitDiffTool creates the GitDiff tool using MCP SDK patterns.
+func CreateGitDiffTool(ctx *tools.Context) *tools.ServerTool {
+	handler := func(ctxReq context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[GitDiffArgs]) (*mcp.CallToolResultFor[any], error) {
+		args := params.Arguments
+
+		repoPath, errResult := gitRepoPath(ctx, args.Path)
+		if errResult != nil {
+			return errResult, nil
+		}
+
+		gitArgs := []string{"diff", "--no-ext-diff", "--no-textconv", "--no-color"}
+		staged := args.Staged != nil && *args.Staged
+		if staged {
+			gitArgs = append(gitArgs, "--cached")
+		}
+		if args.FilePath != "" {
+			filePath, err := ctx.Validator.SanitizePath(args.FilePath)
+			if err != nil {
+				return tools.InvalidPathError(err), nil
+			}
+			if err := ctx.Validator.ValidatePath(filePath); err != nil {
+				return tools.PathValidationError(err), nil
+			}
+			// After "--" the path can only be read as a path, never as an option
+			gitArgs = append(gitArgs, "--", filePath)
+		}
+
+		executor := NewCommandExecutor(gitTimeout).WithCleanEnv(ctx.CleanEnv).WithEnvOverrides(gitEnv)
+		diff, err := runGit(ctxReq, executor, repoPath, gitArgs...)
+		if err != nil {
+			return tools.ErrorResponse(err.Error()), nil
+		}
+
+		if diff == "" {
+			if staged {
+				return tools.SuccessResponse("No staged changes"), nil
+			}
+			return tools.SuccessResponse("No unstaged changes"), nil
+		}
+		if len(diff) > MaxGitDiffBytes {
+			cut := strings.LastIndexByte(diff[:MaxGitDiffBytes], '\n') + 1
+			return tools.SuccessResponsef("%s\n(diff truncated at %d of %d bytes; pass file_path to see one file)", diff[:cut], cut, len(diff)), nil
+		}
+		return tools.SuccessResponse(diff), nil
+	}
+
+	tool := &mcp.Tool{
+		Name:        "GitDiff",
+		Description: prompts.GitDiffToolDoc,
+	}
+
+	return &tools.ServerTool{
+		Tool: tool,
+		RegisterFunc: func(server *mcp.Server) {
+			mcp.AddTool(server, tool, handler)
+		},
+	}
+}
+
+// gitRepoPath returns the validated directory to run git in, which is the
+// server's working directory when path is empty.
+func gitRepoPath(ctx *tools.Context, path string) (string, *mcp.CallToolResultFor[any]) {
+	if path == "" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return "", tools.ErrorResponsef("failed to get current working directory: %v", err)
+		}
+		path = cwd
+	}
+
+	repoPath, err := ctx.Validator.SanitizePath(path)
+	if err != nil {
+		return "", tools.InvalidPathError(err)
+	}
+	if err := ctx.Validator.ValidatePath(repoPath); err != nil {
+		return "", tools.PathValidationError(err)
+	}
+	return repoPath, nil
+}
+
+// runGit runs git with gitSafeArgs, the overrides of the repository's
+// filter drivers and args in dir and returns its standard output. A non-zero
+// exit is reported with git's error message.
+func runGit(ctx context.Context, executor *CommandExecutor, dir string, args ...string) (string, error) {
+	gitPath, err := FindBinary("git")
+	if err != nil {
+		return "", err
+	}
+
+	filterArgs, err := gitFilterOverrides(ctx, executor, gitPath, dir)
+	if err != nil {
+		return "", err
+	}
+
+	gitArgs := append(append(append([]string{}, gitSafeArgs...), filterArgs...), args...)
+	result, err := executor.ExecuteInDir(ctx, dir, gitPath, gitArgs...)
+	if err != nil {
+		return "", err
+	}
+	if result.ExitCode != 0 {
+		message := strings.TrimSpace(result.Stderr)
+		if message == "" {
+			message = fmt.Sprintf("exit code %d", result.ExitCode)
+		}
+		return "", fmt.Errorf("git %s failed: %s", args[0], message)
+	}
+	return result.Stdout, nil
+}
+
+// gitFilterOverrides returns the -c arguments that turn off every filter
+// driver configured for the repository in dir, since diff and status run a
+// driver's clean command on worktree files. Reading the configuration runs
+// no programs.
+func gitFilterOverrides(ctx context.Context, executor *CommandExecutor, gitPath, dir string) ([]string, error) {
+	configArgs := append(append([]string{}, gitSafeArgs...), "config", "--name-only", "--get-regexp", `^filter\.`)
+	result, err := executor.ExecuteInDir(ctx, dir, gitPath, configArgs...)
+	if err != nil {
+		return nil, err
+	}
+	// Exit code 1 means that no filter is configured
+	if result.ExitCode == 1 {
+		return nil, nil
+	}
+	if result.ExitCode != 0 {
+		return nil, fmt.Errorf("git config failed: %s", strings.TrimSpace(result.Stderr))
+	}
+
+	var args []string
+	seen := make(map[string]bool)
+	for _, key := range strings.Split(strings.TrimSpace(result.Stdout), "\n") {
+		// Driver names may contain dots, so the setting is the last element
+		driver := strings.TrimPrefix(key, "filter.")
+		if i := strings.LastIndexByte(driver, '.'); i >= 0 {
+			driver = driver[:i]
+		}
+		if driver == "" || seen[driver] {
+			continue
+		}
+		seen[driver] = true
+		args = append(args,
+			"-c", "filter."+driver+".clean=",
+			"-c", "filter."+driver+".smudge=",
+			"-c", "filter."+driver+".process=",
+			"-c", "filter."+driver+".required=false",
+		)
+	}
+	return args, nil
+}
+
+// parseGitStatus parses the output of git status --porcelain=v2 --branch -z.
+func parseGitStatus(output string) (*GitStatusResult, error) {
+	result := &GitStatusResult{Files: []GitFileStatus{}}
+	entries := strings.Split(strings.TrimSuffix(output, "\x00"), "\x00")
+	for i := 0; i < len(entries); i++ {
+		entry := entries[i]
+		if entry == "" {
+			continue
+		}
+
+		switch entry[0] {
+		case '#':
+			parseGitBranchHeader(result, entry)
+		case '1':
+			// 1 XY sub mH mI mW hH hI path
+			fields := strings.SplitN(entry, " ", 9)
+			if len(fields) != 9 {
+				return nil, fmt.Errorf("malformed entry %q", entry)
+			}
+			result.Files = append(result.Files, gitFileStatus(fields[1], fields[8]))
+		case '2':
+			// 2 XY sub mH mI mW hH hI Xscore path, then the original path
+			fields := strings.SplitN(entry, " ", 10)
+			if len(fields) != 10 || i+1 >= len(entries) {
+				return nil, fmt.Errorf("malformed entry %q", entry)
+			}
+			file := gitFileStatus(fields[1], fields[9])
+			i++
+			file.OrigPath = entries[i]
+			result.Files = append(result.Files, file)
+		case 'u':
+			// u XY sub m1 m2 m3 mW h1 h2 h3 path
+			fields := strings.SplitN(entry, " ", 11)
+			if len(fields) != 11 {
+				return nil, fmt.Errorf("malformed entry %q", entry)
+			}
+			file := gitFileStatus(fields[1], fields[10])
+			file.Conflict = true
+			result.Files = append(result.Files, file)
+		case '?':
+			result.Files = append(result.Files, GitFileStatus{Path: strings.TrimPrefix(entry, "? "), Index: "?", Worktree: "?"})
+		case '!':
+			// Ignored files are only listed with --ignored, which is never passed
+		default:
+			return nil, fmt.Errorf("unknown entry %q", entry)
+		}
+	}
+	return result, nil
+}
+
+// parseGitBranchHeader records a "# branch." header line in result.
+func parseGitBranchHeader(result *GitStatusResult, header string) {
+	key, value, _ := strings.Cut(strings.TrimPrefix(header, "# "), " ")
+	switch key {
+	case "branch.head":
+		result.Branch = value
+	case "branch.upstream":
+		result.Upstream = value
+	case "branch.ab":
+		ahead, behind, _ := strings.Cut(value, " ")
+		result.Ahead, _ = strconv.Atoi(strings.TrimPrefix(ahead, "+"))
+		result.Behind, _ = strconv.Atoi(strings.TrimPrefix(behind, "-"))
+	}
+}
+
+// gitFileStatus builds the status of a tracked path from its XY code.
+func gitFileStatus(code, path string) GitFileStatus {
+	return GitFileStatus{Path: path, Index: code[:1], Worktree: code[1:2]}
+}
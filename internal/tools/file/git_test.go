@@ -0,0 +1,190 @@
+package file
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/d-kuro/claude-code-mcp/internal/tools"
+)
+
+// newGitTestRepo creates a repository with one commit of a.txt and b.txt,
+// then stages a change to a.txt and a new file c.txt, changes b.txt without
+// staging it, and leaves d.txt untracked.
+func newGitTestRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	dir := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = dir
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+	}
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	git("init", "-q", "-b", "main")
+	write("a.txt", "alpha\n")
+	write("b.txt", "bravo\n")
+	git("add", ".")
+	git("commit", "-q", "-m", "initial")
+
+	write("a.txt", "alpha staged\n")
+	write("c.txt", "charlie\n")
+	git("add", "a.txt", "c.txt")
+	write("b.txt", "bravo unstaged\n")
+	write("d.txt", "delta\n")
+	return dir
+}
+
+func TestGitStatusTool(t *testing.T) {
+	dir := newGitTestRepo(t)
+	ctx := &tools.Context{Validator: &mockValidator{}}
+
+	output, isError := callServerTool(t, CreateGitStatusTool(ctx), map[string]any{"path": dir})
+	if isError {
+		t.Fatalf("GitStatus failed: %s", output)
+	}
+
+	var result GitStatusResult
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		t.Fatalf("Failed to decode result %q: %v", output, err)
+	}
+	if result.Branch != "main" {
+		t.Errorf("Expected branch main, got %q", result.Branch)
+	}
+	if resolved, _ := filepath.EvalSymlinks(dir); result.Root != resolved && result.Root != dir {
+		t.Errorf("Expected root %s, got %q", dir, result.Root)
+	}
+
+	want := map[string]GitFileStatus{
+		"a.txt": {Path: "a.txt", Index: "M", Worktree: "."},
+		"b.txt": {Path: "b.txt", Index: ".", Worktree: "M"},
+		"c.txt": {Path: "c.txt", Index: "A", Worktree: "."},
+		"d.txt": {Path: "d.txt", Index: "?", Worktree: "?"},
+	}
+	if len(result.Files) != len(want) {
+		t.Fatalf("Expected %d files, got %+v", len(want), result.Files)
+	}
+	for _, file := range result.Files {
+		if file != want[file.Path] {
+			t.Errorf("Expected %+v, got %+v", want[file.Path], file)
+		}
+	}
+}
+
+func TestGitDiffTool(t *testing.T) {
+	dir := newGitTestRepo(t)
+	ctx := &tools.Context{Validator: &mockValidator{}}
+
+	t.Run("unstaged", func(t *testing.T) {
+		output, isError := callServerTool(t, CreateGitDiffTool(ctx), map[string]any{"path": dir})
+		if isError {
+			t.Fatalf("GitDiff failed: %s", output)
+		}
+		if !strings.Contains(output, "+bravo unstaged") || strings.Contains(output, "alpha staged") {
+			t.Errorf("Expected only the unstaged change, got:\n%s", output)
+		}
+	})
+
+	t.Run("staged", func(t *testing.T) {
+		output, isError := callServerTool(t, CreateGitDiffTool(ctx), map[string]any{"path": dir, "staged": true})
+		if isError {
+			t.Fatalf("GitDiff failed: %s", output)
+		}
+		if !strings.Contains(output, "+alpha staged") || !strings.Contains(output, "+charlie") || strings.Contains(output, "bravo unstaged") {
+			t.Errorf("Expected only the staged changes, got:\n%s", output)
+		}
+	})
+
+	t.Run("file path", func(t *testing.T) {
+		output, isError := callServerTool(t, CreateGitDiffTool(ctx), map[string]any{"path": dir, "staged": true, "file_path": filepath.Join(dir, "c.txt")})
+		if isError {
+			t.Fatalf("GitDiff failed: %s", output)
+		}
+		if !strings.Contains(output, "+charlie") || strings.Contains(output, "alpha") {
+			t.Errorf("Expected only the diff of c.txt, got:\n%s", output)
+		}
+	})
+
+	t.Run("no changes", func(t *testing.T) {
+		output, isError := callServerTool(t, CreateGitDiffTool(ctx), map[string]any{"path": dir, "file_path": filepath.Join(dir, "a.txt")})
+		if isError || output != "No unstaged changes" {
+			t.Errorf("Expected no unstaged changes for a.txt, got: %s", output)
+		}
+	})
+
+	t.Run("not a repository", func(t *testing.T) {
+		output, isError := callServerTool(t, CreateGitDiffTool(ctx), map[string]any{"path": t.TempDir()})
+		if !isError || !strings.Contains(output, "git diff failed") {
+			t.Errorf("Expected an error outside a repository, got: %s", output)
+		}
+	})
+}
+
+func TestGitDiffSkipsFilterDrivers(t *testing.T) {
+	dir := newGitTestRepo(t)
+	marker := filepath.Join(t.TempDir(), "ran")
+	config := exec.Command("git", "config", "filter.evil.clean", "touch "+marker)
+	config.Dir = dir
+	if output, err := config.CombinedOutput(); err != nil {
+		t.Fatalf("git config failed: %v\n%s", err, output)
+	}
+	config = exec.Command("git", "config", "filter.evil.required", "true")
+	config.Dir = dir
+	if output, err := config.CombinedOutput(); err != nil {
+		t.Fatalf("git config failed: %v\n%s", err, output)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".gitattributes"), []byte("* filter=evil\n"), 0644); err != nil {
+		t.Fatalf("Failed to write .gitattributes: %v", err)
+	}
+
+	ctx := &tools.Context{Validator: &mockValidator{}}
+	output, isError := callServerTool(t, CreateGitDiffTool(ctx), map[string]any{"path": dir})
+	if isError || !strings.Contains(output, "+bravo unstaged") {
+		t.Errorf("Expected the unstaged diff, got: %s", output)
+	}
+	if _, err := os.Stat(marker); err == nil {
+		t.Errorf("Expected the clean filter not to run")
+	}
+}
+
+func TestParseGitStatus(t *testing.T) {
+	output := "# branch.oid abc\x00# branch.head feature\x00# branch.upstream origin/feature\x00# branch.ab +2 -1\x00" +
+		"2 R. N... 100644 100644 100644 abc abc R100 new name.txt\x00old name.txt\x00" +
+		"u UU N... 100644 100644 100644 100644 abc abc abc conflict.txt\x00"
+
+	result, err := parseGitStatus(output)
+	if err != nil {
+		t.Fatalf("parseGitStatus() error = %v", err)
+	}
+	if result.Branch != "feature" || result.Upstream != "origin/feature" || result.Ahead != 2 || result.Behind != 1 {
+		t.Errorf("Unexpected branch information: %+v", result)
+	}
+
+	want := []GitFileStatus{
+		{Path: "new name.txt", OrigPath: "old name.txt", Index: "R", Worktree: "."},
+		{Path: "conflict.txt", Index: "U", Worktree: "U", Conflict: true},
+	}
+	if len(result.Files) != len(want) {
+		t.Fatalf("Expected %d files, got %+v", len(want), result.Files)
+	}
+	for i := range want {
+		if result.Files[i] != want[i] {
+			t.Errorf("Expected %+v, got %+v", want[i], result.Files[i])
+		}
+	}
+}
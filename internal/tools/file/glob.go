@@ -6,12 +6,13 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 
+	"github.com/d-kuro/claude-code-mcp/internal/ignore"
 	"github.com/d-kuro/claude-code-mcp/internal/prompts"
 	"github.com/d-kuro/claude-code-mcp/internal/tools"
 )
@@ -20,6 +21,8 @@ import (
 type GlobArgs struct {
 	Pattern string  `json:"pattern"`
 	Path    *string `json:"path,omitempty"`
+	// DefaultIgnores turns the server's default ignored directories off or on.
+	DefaultIgnores *bool `json:"default_ignores,omitempty"`
 }
 
 // CreateGlobTool creates the Glob tool using MCP SDK patterns.
@@ -27,39 +30,9 @@ func CreateGlobTool(ctx *tools.Context) *tools.ServerTool {
 	handler := func(ctxReq context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[GlobArgs]) (*mcp.CallToolResultFor[any], error) {
 		args := params.Arguments
 
-		searchPath := "."
-		if args.Path != nil && *args.Path != "" {
-			searchPath = *args.Path
-		}
-
-		var absSearchPath string
-		var err error
-		if filepath.IsAbs(searchPath) {
-			absSearchPath = searchPath
-		} else {
-			cwd, err := os.Getwd()
-			if err != nil {
-				return &mcp.CallToolResultFor[any]{
-					Content: []mcp.Content{&mcp.TextContent{Text: "Error: Failed to get current working directory: " + err.Error()}},
-					IsError: true,
-				}, nil
-			}
-			absSearchPath = filepath.Join(cwd, searchPath)
-		}
-
-		sanitizedPath, err := ctx.Validator.SanitizePath(absSearchPath)
-		if err != nil {
-			return &mcp.CallToolResultFor[any]{
-				Content: []mcp.Content{&mcp.TextContent{Text: "Error: Invalid search path: " + err.Error()}},
-				IsError: true,
-			}, nil
-		}
-
-		if err := ctx.Validator.ValidatePath(sanitizedPath); err != nil {
-			return &mcp.CallToolResultFor[any]{
-				Content: []mcp.Content{&mcp.TextContent{Text: "Error: Path validation failed: " + err.Error()}},
-				IsError: true,
-			}, nil
+		sanitizedPath, errResult := resolveSearchRoot(ctx, args.Path)
+		if errResult != nil {
+			return errResult, nil
 		}
 
 		if args.Pattern == "" {
@@ -69,7 +42,7 @@ func CreateGlobTool(ctx *tools.Context) *tools.ServerTool {
 			}, nil
 		}
 
-		content, err := globFilesWithFind(sanitizedPath, args.Pattern)
+		content, err := globFilesWithFind(ctxReq, sanitizedPath, args.Pattern, ctx.Ignore.Snapshot(), skippedDirectories(ctx, args.DefaultIgnores), ctx.MaxSearchDepth, ctx.CleanEnv)
 		if err != nil {
 			return &mcp.CallToolResultFor[any]{
 				Content: []mcp.Content{&mcp.TextContent{Text: "Error: " + err.Error()}},
@@ -96,7 +69,12 @@ func CreateGlobTool(ctx *tools.Context) *tools.ServerTool {
 }
 
 // globFilesWithFind performs glob pattern matching using find command and returns sorted results.
-func globFilesWithFind(searchPath, pattern string) (string, error) {
+// Paths excluded by the ignore matcher are omitted, and directories named in
+// skipDirs are not searched. A positive maxDepth limits the search depth as
+// find -maxdepth does; 1 matches only files directly in searchPath.
+// When find is not available, the tree is walked in Go instead. cleanEnv runs
+// find with the allow-listed environment only.
+func globFilesWithFind(ctx context.Context, searchPath, pattern string, ignoreMatcher *ignore.Matcher, skipDirs []string, maxDepth int, cleanEnv bool) (string, error) {
 	stat, err := os.Stat(searchPath)
 	if err != nil {
 		return "", fmt.Errorf("failed to stat search path: %w", err)
@@ -106,51 +84,29 @@ func globFilesWithFind(searchPath, pattern string) (string, error) {
 		return "", fmt.Errorf("search path is not a directory")
 	}
 
+	var lines []string
+	var partial bool
+
 	findPath, err := FindBinary("find")
 	if err != nil {
-		return "", fmt.Errorf("find command not found: %w", err)
+		lines, partial, err = globFilesWithWalk(ctx, searchPath, pattern, skipDirs, maxDepth)
+	} else {
+		lines, partial, err = runFind(ctx, findPath, searchPath, pattern, skipDirs, maxDepth, cleanEnv)
 	}
-
-	executor := NewCommandExecutor(30 * time.Second)
-	findPattern := convertGlobToFindPattern(pattern)
-
-	args := []string{
-		searchPath,
-		"-type", "f",
-		"-name", findPattern,
-	}
-
-	if strings.Contains(pattern, "**/") {
-		args = []string{
-			searchPath,
-			"-type", "f",
-			"-path", "*/" + strings.TrimPrefix(pattern, "**/"),
-		}
-	}
-
-	if err := executor.ValidateCommand("find", args); err != nil {
-		return "", fmt.Errorf("command validation failed: %w", err)
-	}
-
-	result, err := executor.Execute(context.Background(), findPath, args...)
 	if err != nil {
-		return "", fmt.Errorf("failed to execute find: %w", err)
+		return "", err
 	}
 
-	if result.ExitCode != 0 {
-		return "", fmt.Errorf("find command failed with exit code %d: %s", result.ExitCode, result.Stderr)
+	note := ""
+	if partial {
+		note = vanishedFileNote
 	}
 
-	if strings.TrimSpace(result.Stdout) == "" {
-		return fmt.Sprintf("No files found matching pattern '%s' in directory '%s'", pattern, searchPath), nil
-	}
-
-	lines := strings.Split(strings.TrimSpace(result.Stdout), "\n")
 	matches := make([]FileMatchInfo, 0, len(lines))
 
 	for _, line := range lines {
 		line = strings.TrimSpace(line)
-		if line == "" {
+		if line == "" || ignoreMatcher.Match(line, false) {
 			continue
 		}
 
@@ -167,9 +123,11 @@ func globFilesWithFind(searchPath, pattern string) (string, error) {
 		}
 	}
 
-	sort.Slice(matches, func(i, j int) bool {
-		return matches[i].ModTime.After(matches[j].ModTime)
-	})
+	if len(matches) == 0 {
+		return fmt.Sprintf("No files found matching pattern '%s' in directory '%s'", pattern, searchPath) + note, nil
+	}
+
+	sortByModTime(matches)
 
 	var output strings.Builder
 	output.WriteString(fmt.Sprintf("Found %d file(s) matching pattern '%s' in directory '%s':\n", len(matches), pattern, searchPath))
@@ -178,7 +136,58 @@ func globFilesWithFind(searchPath, pattern string) (string, error) {
 		output.WriteString(match.Path + "\n")
 	}
 
-	return strings.TrimSuffix(output.String(), "\n"), nil
+	return strings.TrimSuffix(output.String(), "\n") + note, nil
+}
+
+// runFind executes find for the glob pattern and returns the matched file paths.
+// partial is true when files vanished during the search and the results may be incomplete.
+func runFind(ctx context.Context, findPath, searchPath, pattern string, skipDirs []string, maxDepth int, cleanEnv bool) (paths []string, partial bool, err error) {
+	executor := NewCommandExecutor(30 * time.Second).WithCleanEnv(cleanEnv)
+	findPattern := convertGlobToFindPattern(pattern)
+
+	tests := []string{
+		"-type", "f",
+		"-name", findPattern,
+	}
+
+	if strings.Contains(pattern, "**/") {
+		tests = []string{
+			"-type", "f",
+			"-path", "*/" + strings.TrimPrefix(pattern, "**/"),
+		}
+	}
+
+	args := []string{searchPath}
+	if maxDepth > 0 {
+		// -maxdepth is a global option and must precede the tests
+		args = append(args, "-maxdepth", strconv.Itoa(maxDepth))
+	}
+
+	if len(skipDirs) > 0 {
+		// Skipped directories are pruned below the search root, so a search
+		// rooted in one of them still lists its files
+		args = append(args, "-mindepth", "1")
+		for _, name := range skipDirs {
+			args = append(args, "-type", "d", "-name", name, "-prune", "-o")
+		}
+		tests = append(tests, "-print")
+	}
+	args = append(args, tests...)
+
+	if err := executor.ValidateCommand("find", args); err != nil {
+		return nil, false, fmt.Errorf("command validation failed: %w", err)
+	}
+
+	result, partial, err := executor.ExecuteTolerant(ctx, findPath, args...)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to execute find: %w", err)
+	}
+
+	if result.ExitCode != 0 && !partial {
+		return nil, false, fmt.Errorf("find command failed with exit code %d: %s", result.ExitCode, result.Stderr)
+	}
+
+	return strings.Split(strings.TrimSpace(result.Stdout), "\n"), partial, nil
 }
 
 // convertGlobToFindPattern converts a glob pattern to a find-compatible pattern.
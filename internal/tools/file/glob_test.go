@@ -1,11 +1,17 @@
 package file
 
 import (
+	"context"
+	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/d-kuro/claude-code-mcp/internal/security"
+	"github.com/d-kuro/claude-code-mcp/internal/tools"
 )
 
 func TestGlobFiles(t *testing.T) {
@@ -72,7 +78,7 @@ func TestGlobFiles(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result, err := globFilesWithFind(tempDir, tt.pattern)
+			result, err := globFilesWithFind(context.Background(), tempDir, tt.pattern, nil, nil, 0, false)
 			if err != nil {
 				t.Fatalf("globFiles() error = %v", err)
 			}
@@ -92,6 +98,243 @@ func TestGlobFiles(t *testing.T) {
 	}
 }
 
+// installFakeFind puts a find wrapper first in PATH that runs the real find
+// and then reports the given stderr line with exit code 1.
+func installFakeFind(t *testing.T, stderrLine string) {
+	t.Helper()
+
+	realFind, err := FindBinary("find")
+	if err != nil {
+		t.Skip("find not available")
+	}
+
+	binDir := t.TempDir()
+	script := fmt.Sprintf("#!/bin/sh\n%s \"$@\"\necho \"%s\" >&2\nexit 1\n", realFind, stderrLine)
+	if err := os.WriteFile(filepath.Join(binDir, "find"), []byte(script), 0755); err != nil {
+		t.Fatalf("Failed to create fake find: %v", err)
+	}
+
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func TestGlobFilesVanishedDuringWalk(t *testing.T) {
+	tempDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tempDir, "kept.go"), []byte("package main"), 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+
+	t.Run("vanished file warnings return partial results", func(t *testing.T) {
+		installFakeFind(t, "find: '"+filepath.Join(tempDir, "gone")+"': No such file or directory")
+
+		result, err := globFilesWithFind(context.Background(), tempDir, "*.go", nil, nil, 0, false)
+		if err != nil {
+			t.Fatalf("globFilesWithFind() error = %v", err)
+		}
+		if !strings.Contains(result, "kept.go") {
+			t.Errorf("Expected files found before the failure, got: %s", result)
+		}
+		if !strings.Contains(result, "results may be incomplete") {
+			t.Errorf("Expected partial result note, got: %s", result)
+		}
+	})
+
+	t.Run("other errors still fail", func(t *testing.T) {
+		installFakeFind(t, "find: '"+filepath.Join(tempDir, "locked")+"': Permission denied")
+
+		if _, err := globFilesWithFind(context.Background(), tempDir, "*.go", nil, nil, 0, false); err == nil {
+			t.Errorf("Expected error for non-vanished-file failure")
+		}
+	})
+}
+
+func TestGlobFilesMaxDepth(t *testing.T) {
+	tempDir := t.TempDir()
+	for _, name := range []string{"top.go", "a/mid.go", "a/b/deep.go"} {
+		path := filepath.Join(tempDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte("package main"), 0644); err != nil {
+			t.Fatalf("Failed to create file: %v", err)
+		}
+	}
+
+	result, err := globFilesWithFind(context.Background(), tempDir, "*.go", nil, nil, 2, false)
+	if err != nil {
+		t.Fatalf("globFilesWithFind() error = %v", err)
+	}
+	if !strings.Contains(result, "top.go") || !strings.Contains(result, "mid.go") {
+		t.Errorf("Expected files within the depth limit, got: %s", result)
+	}
+	if strings.Contains(result, "deep.go") {
+		t.Errorf("Expected files beyond the depth limit to be excluded, got: %s", result)
+	}
+
+	args := buildRipgrepArgs(tempDir, "main", grepOptions{MaxDepth: 2})
+	if i := slices.Index(args, "--max-depth"); i < 0 || args[i+1] != "2" {
+		t.Errorf("Expected --max-depth 2 in ripgrep args, got %v", args)
+	}
+}
+
+func TestGlobAndGrepOrderWithEqualModTimes(t *testing.T) {
+	tempDir := t.TempDir()
+	modTime := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	names := []string{"c.go", "a.go", "sub/b.go", "d.go"}
+	for _, name := range names {
+		path := filepath.Join(tempDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte("package main"), 0644); err != nil {
+			t.Fatalf("Failed to create file: %v", err)
+		}
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatalf("Failed to set modification time: %v", err)
+		}
+	}
+	// A newer file is still listed first
+	newer := filepath.Join(tempDir, "z.go")
+	if err := os.WriteFile(newer, []byte("package main"), 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+
+	want := []string{"z.go", "a.go", "c.go", "d.go", "sub/b.go"}
+	listed := func(result string) []string {
+		var paths []string
+		for _, line := range strings.Split(result, "\n")[1:] {
+			rel, err := filepath.Rel(tempDir, line)
+			if err != nil {
+				t.Fatalf("Unexpected path %q: %v", line, err)
+			}
+			paths = append(paths, filepath.ToSlash(rel))
+		}
+		return paths
+	}
+
+	for run := 0; run < 5; run++ {
+		result, err := globFilesWithFind(context.Background(), tempDir, "*.go", nil, nil, 0, false)
+		if err != nil {
+			t.Fatalf("globFilesWithFind() error = %v", err)
+		}
+		if got := listed(result); !slices.Equal(got, want) {
+			t.Fatalf("Glob run %d listed %v, want %v", run, got, want)
+		}
+
+		result, err = grepFilesWithRipgrep(context.Background(), tempDir, "package", newGrepOptions(GrepArgs{Pattern: "package"}))
+		if err != nil {
+			t.Fatalf("grepFilesWithRipgrep() error = %v", err)
+		}
+		if got := listed(result); !slices.Equal(got, want) {
+			t.Fatalf("Grep run %d listed %v, want %v", run, got, want)
+		}
+	}
+}
+
+func TestCheckSearchRoot(t *testing.T) {
+	root := filepath.VolumeName(t.TempDir()) + string(filepath.Separator)
+
+	if err := checkSearchRoot(root, false); err == nil || !strings.Contains(err.Error(), "refusing to search filesystem root") {
+		t.Errorf("Expected root search to be rejected, got %v", err)
+	}
+	if err := checkSearchRoot(root, true); err != nil {
+		t.Errorf("Expected root search to be allowed when enabled, got %v", err)
+	}
+	if err := checkSearchRoot(t.TempDir(), false); err != nil {
+		t.Errorf("Expected non-root search to be allowed, got %v", err)
+	}
+
+	link := filepath.Join(t.TempDir(), "r")
+	if err := os.Symlink(root, link); err != nil {
+		t.Skipf("Symlinks not supported: %v", err)
+	}
+	if err := checkSearchRoot(link, false); err == nil || !strings.Contains(err.Error(), "refusing to search filesystem root") {
+		t.Errorf("Expected a link to the root to be rejected, got %v", err)
+	}
+	if err := checkSearchRoot(link+string(filepath.Separator)+".", false); err == nil {
+		t.Error("Expected a link to the root with a trailing element to be rejected")
+	}
+	if err := checkSearchRoot(link, true); err != nil {
+		t.Errorf("Expected a link to the root to be allowed when enabled, got %v", err)
+	}
+}
+
+func TestSearchRootValidation(t *testing.T) {
+	base, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to resolve temp dir: %v", err)
+	}
+	project := filepath.Join(base, "project")
+	blocked := filepath.Join(project, "secrets")
+	outside := filepath.Join(base, "outside")
+	for _, dir := range []string{project, blocked, outside} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("Failed to create dir %s: %v", dir, err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "file.go"), []byte("package main\n"), 0644); err != nil {
+			t.Fatalf("Failed to create file: %v", err)
+		}
+	}
+	if err := os.Symlink(blocked, filepath.Join(project, "link")); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+
+	// Replace find and rg with scripts that record being run, so a rejected
+	// root can be shown to stop the search before any command starts.
+	bin := t.TempDir()
+	marker := filepath.Join(bin, "ran")
+	for _, name := range []string{"find", "rg"} {
+		script := "#!/bin/sh\necho " + name + " >> " + marker + "\n"
+		if err := os.WriteFile(filepath.Join(bin, name), []byte(script), 0755); err != nil {
+			t.Fatalf("Failed to create fake %s: %v", name, err)
+		}
+	}
+	t.Setenv("PATH", bin)
+	t.Chdir(project)
+
+	validator := security.NewDefaultValidator().
+		WithAllowedPaths([]string{project}).
+		WithBlockedPaths([]string{blocked})
+
+	tests := []struct {
+		name    string
+		path    string
+		wantErr string
+	}{
+		{name: "outside allowed paths", path: outside, wantErr: "path not allowed"},
+		{name: "relative path outside allowed paths", path: "../outside", wantErr: "path not allowed"},
+		{name: "dot-dot into blocked dir", path: filepath.Join(project, "src", "..", "secrets"), wantErr: "path is blocked"},
+		{name: "relative dot-dot into blocked dir", path: "src/../secrets", wantErr: "path is blocked"},
+		{name: "symlink into blocked dir", path: filepath.Join(project, "link"), wantErr: "path is blocked"},
+	}
+
+	for _, tt := range tests {
+		for _, tool := range []string{"Glob", "Grep"} {
+			t.Run(tool+"/"+tt.name, func(t *testing.T) {
+				ctx := &tools.Context{Validator: validator}
+				serverTool := CreateGlobTool(ctx)
+				if tool == "Grep" {
+					serverTool = CreateGrepTool(ctx)
+				}
+
+				text, isError := callServerTool(t, serverTool, map[string]any{"pattern": "*.go", "path": tt.path})
+				if !isError || !strings.Contains(text, tt.wantErr) {
+					t.Errorf("Expected search root %s to be rejected, got %q", tt.path, text)
+				}
+				if _, err := os.Stat(marker); err == nil {
+					t.Fatalf("Search command ran for rejected root %s", tt.path)
+				}
+			})
+		}
+	}
+
+	t.Run("allowed root runs search", func(t *testing.T) {
+		callServerTool(t, CreateGlobTool(&tools.Context{Validator: validator}), map[string]any{"pattern": "*.go", "path": "."})
+		if _, err := os.Stat(marker); err != nil {
+			t.Errorf("Expected search command to run for allowed root, got %v", err)
+		}
+	})
+}
+
 func TestMatchGlobPattern(t *testing.T) {
 	tests := []struct {
 		pattern string
@@ -8,21 +8,104 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
-	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 
+	"github.com/d-kuro/claude-code-mcp/internal/ignore"
 	"github.com/d-kuro/claude-code-mcp/internal/prompts"
 	"github.com/d-kuro/claude-code-mcp/internal/tools"
 )
 
 // GrepArgs represents the arguments for the Grep tool.
 type GrepArgs struct {
-	Pattern string  `json:"pattern"`
-	Path    *string `json:"path,omitempty"`
-	Include *string `json:"include,omitempty"`
+	Pattern        string  `json:"pattern"`
+	Path           *string `json:"path,omitempty"`
+	Include        *string `json:"include,omitempty"`
+	FollowSymlinks *bool   `json:"follow_symlinks,omitempty"`
+	SearchHidden   *bool   `json:"search_hidden,omitempty"`
+	SearchBinary   *bool   `json:"search_binary,omitempty"`
+	FixedStrings   *bool   `json:"fixed_strings,omitempty"`
+	WholeWord      *bool   `json:"whole_word,omitempty"`
+	OutputFormat   *string `json:"output_format,omitempty"`
+	// DefaultIgnores turns the server's default ignored directories off or on.
+	DefaultIgnores *bool `json:"default_ignores,omitempty"`
+	// CountBinary reports how many binary files matching the pattern were
+	// skipped, which takes a second ripgrep run. By default they are counted
+	// only when no text file matches.
+	CountBinary *bool `json:"count_binary,omitempty"`
+}
+
+// Limits on the brace expansion of Grep include patterns, so that a pattern
+// such as "{a,b}{a,b}{a,b}..." cannot expand to millions of patterns.
+const (
+	// MaxIncludeExpansions is the most patterns an include pattern may expand to.
+	MaxIncludeExpansions = 256
+	// MaxIncludeBraceDepth is how deeply braces may nest in an include pattern.
+	MaxIncludeBraceDepth = 8
+)
+
+// grepOptions holds the optional search settings derived from GrepArgs.
+type grepOptions struct {
+	Include        *string
+	Ignore         *ignore.Matcher
+	FollowSymlinks bool
+	SearchHidden   bool
+	SearchBinary   bool
+	// CountBinary counts the skipped binary files that match the pattern.
+	// Ripgrep needs a second run with --text for it, so when nil they are
+	// counted only if no text file matched; the search without ripgrep
+	// always counts them.
+	CountBinary *bool
+	// FixedStrings treats the pattern as a literal string, like ripgrep -F.
+	FixedStrings bool
+	// WholeWord only matches the pattern surrounded by word boundaries, like ripgrep -w.
+	WholeWord bool
+	// MaxDepth limits the search depth as ripgrep --max-depth does; zero is unbounded.
+	MaxDepth int
+	// SkipDirs names directories that are not searched, at any depth.
+	SkipDirs []string
+	// Logger receives the warning when ripgrep is unavailable. Nil disables it.
+	Logger tools.Logger
+	// CleanEnv runs ripgrep with the allow-listed environment only.
+	CleanEnv bool
+}
+
+// newGrepOptions resolves GrepArgs into search options with defaults applied.
+// Symlinks are not followed by default to avoid cycles and escaping the search
+// tree; hidden files are searched by default and binary files are skipped.
+func newGrepOptions(args GrepArgs) grepOptions {
+	opts := grepOptions{
+		Include:        args.Include,
+		FollowSymlinks: false,
+		SearchHidden:   true,
+	}
+
+	if args.FollowSymlinks != nil {
+		opts.FollowSymlinks = *args.FollowSymlinks
+	}
+
+	if args.SearchHidden != nil {
+		opts.SearchHidden = *args.SearchHidden
+	}
+
+	if args.SearchBinary != nil {
+		opts.SearchBinary = *args.SearchBinary
+	}
+
+	opts.CountBinary = args.CountBinary
+
+	if args.FixedStrings != nil {
+		opts.FixedStrings = *args.FixedStrings
+	}
+
+	if args.WholeWord != nil {
+		opts.WholeWord = *args.WholeWord
+	}
+
+	return opts
 }
 
 // CreateGrepTool creates the Grep tool using MCP SDK patterns.
@@ -30,56 +113,56 @@ func CreateGrepTool(ctx *tools.Context) *tools.ServerTool {
 	handler := func(ctxReq context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[GrepArgs]) (*mcp.CallToolResultFor[any], error) {
 		args := params.Arguments
 
-		searchPath := "."
-		if args.Path != nil && *args.Path != "" {
-			searchPath = *args.Path
+		sanitizedPath, errResult := resolveSearchRoot(ctx, args.Path)
+		if errResult != nil {
+			return errResult, nil
 		}
 
-		var absSearchPath string
-		var err error
-		if filepath.IsAbs(searchPath) {
-			absSearchPath = searchPath
-		} else {
-			cwd, err := os.Getwd()
-			if err != nil {
+		if args.Pattern == "" {
+			return &mcp.CallToolResultFor[any]{
+				Content: []mcp.Content{&mcp.TextContent{Text: "Error: Pattern cannot be empty"}},
+				IsError: true,
+			}, nil
+		}
+
+		// A fixed string is matched literally, so it needs no regex check
+		if args.FixedStrings == nil || !*args.FixedStrings {
+			if _, err := regexp.Compile(args.Pattern); err != nil {
 				return &mcp.CallToolResultFor[any]{
-					Content: []mcp.Content{&mcp.TextContent{Text: "Error: Failed to get current working directory: " + err.Error()}},
+					Content: []mcp.Content{&mcp.TextContent{Text: "Error: Invalid regular expression: " + err.Error()}},
 					IsError: true,
 				}, nil
 			}
-			absSearchPath = filepath.Join(cwd, searchPath)
 		}
 
-		sanitizedPath, err := ctx.Validator.SanitizePath(absSearchPath)
-		if err != nil {
-			return &mcp.CallToolResultFor[any]{
-				Content: []mcp.Content{&mcp.TextContent{Text: "Error: Invalid search path: " + err.Error()}},
-				IsError: true,
-			}, nil
+		if args.Include != nil {
+			if err := validateIncludePattern(*args.Include); err != nil {
+				return tools.InvalidFieldError("include", err.Error()), nil
+			}
 		}
 
-		if err := ctx.Validator.ValidatePath(sanitizedPath); err != nil {
-			return &mcp.CallToolResultFor[any]{
-				Content: []mcp.Content{&mcp.TextContent{Text: "Error: Path validation failed: " + err.Error()}},
-				IsError: true,
-			}, nil
+		outputFormat := GrepFormatText
+		if args.OutputFormat != nil && *args.OutputFormat != "" {
+			outputFormat = *args.OutputFormat
 		}
-
-		if args.Pattern == "" {
-			return &mcp.CallToolResultFor[any]{
-				Content: []mcp.Content{&mcp.TextContent{Text: "Error: Pattern cannot be empty"}},
-				IsError: true,
-			}, nil
+		if outputFormat != GrepFormatText && outputFormat != GrepFormatNDJSON {
+			return tools.InvalidFieldError("output_format", fmt.Sprintf("must be %q or %q", GrepFormatText, GrepFormatNDJSON)), nil
 		}
 
-		if _, err := regexp.Compile(args.Pattern); err != nil {
-			return &mcp.CallToolResultFor[any]{
-				Content: []mcp.Content{&mcp.TextContent{Text: "Error: Invalid regular expression: " + err.Error()}},
-				IsError: true,
-			}, nil
-		}
+		opts := newGrepOptions(args)
+		opts.Ignore = ctx.Ignore.Snapshot()
+		opts.MaxDepth = ctx.MaxSearchDepth
+		opts.SkipDirs = skippedDirectories(ctx, args.DefaultIgnores)
+		opts.Logger = ctx.Logger
+		opts.CleanEnv = ctx.CleanEnv
 
-		content, err := grepFilesWithRipgrep(sanitizedPath, args.Pattern, args.Include)
+		var content string
+		var err error
+		if outputFormat == GrepFormatNDJSON {
+			content, err = grepNDJSON(ctxReq, session, params.GetProgressToken(), sanitizedPath, args.Pattern, opts)
+		} else {
+			content, err = grepFilesWithRipgrep(ctxReq, sanitizedPath, args.Pattern, opts)
+		}
 		if err != nil {
 			return &mcp.CallToolResultFor[any]{
 				Content: []mcp.Content{&mcp.TextContent{Text: "Error: " + err.Error()}},
@@ -106,7 +189,8 @@ func CreateGrepTool(ctx *tools.Context) *tools.ServerTool {
 }
 
 // grepFilesWithRipgrep performs content search using ripgrep command and returns sorted results.
-func grepFilesWithRipgrep(searchPath, pattern string, includePattern *string) (string, error) {
+// When ripgrep is not installed, it falls back to a slower search in Go.
+func grepFilesWithRipgrep(ctx context.Context, searchPath, pattern string, opts grepOptions) (string, error) {
 	stat, err := os.Stat(searchPath)
 	if err != nil {
 		return "", fmt.Errorf("failed to stat search path: %w", err)
@@ -116,56 +200,23 @@ func grepFilesWithRipgrep(searchPath, pattern string, includePattern *string) (s
 		return "", fmt.Errorf("search path is not a directory")
 	}
 
-	rgPath, err := FindBinary("rg")
+	lines, skippedBinary, partial, err := findMatchingFiles(ctx, searchPath, pattern, opts)
 	if err != nil {
-		return "", fmt.Errorf("ripgrep (rg) not found: %w - please install ripgrep for optimal performance", err)
+		return "", err
 	}
 
-	executor := NewCommandExecutor(30 * time.Second)
-
-	args := []string{
-		"--files-with-matches",
-		"--no-heading",
-		"--no-line-number",
-		"--color=never",
-		"--hidden",
-		"--follow",
-		"--case-sensitive",
+	note := ""
+	if partial {
+		note = vanishedFileNote
 	}
 
-	if includePattern != nil && *includePattern != "" {
-		globPattern := convertIncludePatternToGlob(*includePattern)
-		args = append(args, "--glob", globPattern)
+	if len(lines) == 0 {
+		return fmt.Sprintf("No files found containing pattern '%s' in directory '%s'", pattern, searchPath) + binarySkipNote(skippedBinary) + note, nil
 	}
 
-	args = append(args, pattern, searchPath)
-
-	if err := executor.ValidateCommand("rg", args); err != nil {
-		return "", fmt.Errorf("command validation failed: %w", err)
-	}
-
-	result, err := executor.Execute(context.Background(), rgPath, args...)
-	if err != nil {
-		return "", fmt.Errorf("failed to execute ripgrep: %w", err)
-	}
-
-	if result.ExitCode == 2 {
-		return "", fmt.Errorf("ripgrep error: %s", result.Stderr)
-	}
-
-	if result.ExitCode == 1 || strings.TrimSpace(result.Stdout) == "" {
-		return fmt.Sprintf("No files found containing pattern '%s' in directory '%s'", pattern, searchPath), nil
-	}
-
-	lines := strings.Split(strings.TrimSpace(result.Stdout), "\n")
 	matches := make([]FileMatchInfo, 0, len(lines))
 
 	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
-		}
-
 		if stat, err := os.Stat(line); err == nil {
 			matches = append(matches, FileMatchInfo{
 				Path:    line,
@@ -179,9 +230,7 @@ func grepFilesWithRipgrep(searchPath, pattern string, includePattern *string) (s
 		}
 	}
 
-	sort.Slice(matches, func(i, j int) bool {
-		return matches[i].ModTime.After(matches[j].ModTime)
-	})
+	sortByModTime(matches)
 
 	var output strings.Builder
 	output.WriteString(fmt.Sprintf("Found %d file(s) containing pattern '%s' in directory '%s':\n", len(matches), pattern, searchPath))
@@ -190,15 +239,194 @@ func grepFilesWithRipgrep(searchPath, pattern string, includePattern *string) (s
 		output.WriteString(match.Path + "\n")
 	}
 
-	return strings.TrimSuffix(output.String(), "\n"), nil
+	return strings.TrimSuffix(output.String(), "\n") + binarySkipNote(skippedBinary) + note, nil
 }
 
-// convertIncludePatternToGlob converts a Claude Code include pattern to a ripgrep glob pattern.
-func convertIncludePatternToGlob(includePattern string) string {
-	if strings.Contains(includePattern, "{") && strings.Contains(includePattern, "}") {
-		return includePattern
+// findMatchingFiles returns the files under searchPath whose content matches
+// pattern, using ripgrep when available and a search in Go otherwise.
+// skippedBinary counts binary files left out of the results and partial is
+// true when files vanished during the search.
+func findMatchingFiles(ctx context.Context, searchPath, pattern string, opts grepOptions) (lines []string, skippedBinary int, partial bool, err error) {
+	rgPath, err := FindBinary("rg")
+	if err != nil {
+		warnRipgrepFallback(opts, err)
+		lines, skippedBinary, partial, err = grepFilesWithWalk(ctx, searchPath, pattern, opts)
+		if err != nil {
+			return nil, 0, false, err
+		}
+	} else {
+		executor := NewCommandExecutor(30 * time.Second).WithCleanEnv(opts.CleanEnv)
+
+		lines, partial, err = runRipgrep(ctx, executor, rgPath, buildRipgrepArgs(searchPath, pattern, opts))
+		if err != nil {
+			return nil, 0, false, err
+		}
+		lines = filterIgnored(lines, opts.Ignore)
+
+		// Ripgrep silently skips binary files unless --text is given. Repeat
+		// the search with --text to find out how many were skipped when
+		// nothing else matched, or when asked to.
+		countBinary := len(lines) == 0
+		if opts.CountBinary != nil {
+			countBinary = *opts.CountBinary
+		}
+		if countBinary && !opts.SearchBinary {
+			textOpts := opts
+			textOpts.SearchBinary = true
+			if allLines, _, err := runRipgrep(ctx, executor, rgPath, buildRipgrepArgs(searchPath, pattern, textOpts)); err == nil {
+				skippedBinary = countMissing(filterIgnored(allLines, opts.Ignore), lines)
+			}
+		}
 	}
-	return includePattern
+
+	return lines, skippedBinary, partial, nil
+}
+
+// runRipgrep executes ripgrep with the given arguments and returns the matched file paths.
+// partial is true when files vanished during the search and the results may be incomplete.
+func runRipgrep(ctx context.Context, executor *CommandExecutor, rgPath string, args []string) (paths []string, partial bool, err error) {
+	if err := executor.ValidateCommand("rg", args); err != nil {
+		return nil, false, fmt.Errorf("command validation failed: %w", err)
+	}
+
+	result, partial, err := executor.ExecuteTolerant(ctx, rgPath, args...)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to execute ripgrep: %w", err)
+	}
+
+	if result.ExitCode == 2 && !partial {
+		return nil, false, fmt.Errorf("ripgrep error: %s", result.Stderr)
+	}
+
+	if result.ExitCode == 1 || strings.TrimSpace(result.Stdout) == "" {
+		return nil, partial, nil
+	}
+
+	for _, line := range strings.Split(strings.TrimSpace(result.Stdout), "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" {
+			paths = append(paths, line)
+		}
+	}
+
+	return paths, partial, nil
+}
+
+// filterIgnored removes the paths excluded by the ignore matcher.
+func filterIgnored(paths []string, ignoreMatcher *ignore.Matcher) []string {
+	if ignoreMatcher == nil {
+		return paths
+	}
+
+	filtered := paths[:0]
+	for _, path := range paths {
+		if !ignoreMatcher.Match(path, false) {
+			filtered = append(filtered, path)
+		}
+	}
+	return filtered
+}
+
+// countMissing returns how many entries of all are not present in subset.
+func countMissing(all, subset []string) int {
+	present := make(map[string]bool, len(subset))
+	for _, path := range subset {
+		present[path] = true
+	}
+
+	missing := 0
+	for _, path := range all {
+		if !present[path] {
+			missing++
+		}
+	}
+	return missing
+}
+
+// binarySkipNote returns a note about skipped binary files, or an empty string.
+func binarySkipNote(skipped int) string {
+	if skipped == 0 {
+		return ""
+	}
+	return fmt.Sprintf("\n(%d binary files skipped)", skipped)
+}
+
+// buildRipgrepArgs constructs the ripgrep argument list for a search.
+func buildRipgrepArgs(searchPath, pattern string, opts grepOptions) []string {
+	args := []string{
+		"--files-with-matches",
+		"--no-heading",
+		"--no-line-number",
+		"--color=never",
+	}
+	args = append(args, ripgrepFilterArgs(opts)...)
+	return append(args, pattern, searchPath)
+}
+
+// ripgrepFilterArgs returns the ripgrep flags that select which files and
+// lines are searched, shared by every output mode.
+func ripgrepFilterArgs(opts grepOptions) []string {
+	args := []string{"--case-sensitive"}
+
+	if opts.SearchHidden {
+		args = append(args, "--hidden")
+	}
+
+	if opts.FollowSymlinks {
+		args = append(args, "--follow")
+	}
+
+	if opts.SearchBinary {
+		args = append(args, "--text")
+	}
+
+	if opts.FixedStrings {
+		args = append(args, "--fixed-strings")
+	}
+
+	if opts.WholeWord {
+		args = append(args, "--word-regexp")
+	}
+
+	if opts.MaxDepth > 0 {
+		args = append(args, "--max-depth", strconv.Itoa(opts.MaxDepth))
+	}
+
+	if opts.Include != nil && *opts.Include != "" {
+		// ripgrep rejects nested brace groups, so each expansion is passed as
+		// its own glob; a file matching any of them is searched
+		globs, err := expandBraces(*opts.Include)
+		if err != nil {
+			globs = []string{*opts.Include}
+		}
+		for _, glob := range globs {
+			args = append(args, "--glob", glob)
+		}
+	}
+
+	// Exclusions follow the include globs, since later globs take precedence
+	for _, name := range opts.SkipDirs {
+		args = append(args, "--glob", "!"+name+"/")
+	}
+
+	return args
+}
+
+// compileGrepPattern compiles pattern for the searches done in Go, applying
+// the fixed-string and whole-word options as ripgrep does.
+func compileGrepPattern(pattern string, opts grepOptions) (*regexp.Regexp, error) {
+	if opts.FixedStrings {
+		pattern = regexp.QuoteMeta(pattern)
+	}
+	if opts.WholeWord {
+		pattern = `\b(?:` + pattern + `)\b`
+	}
+
+	regex, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regular expression: %w", err)
+	}
+	return regex, nil
 }
 
 // searchFileContent searches for regex pattern in file content.
@@ -272,21 +500,12 @@ func matchIncludePattern(pattern, fileName string) (bool, error) {
 
 // matchBracePattern handles brace expansion patterns like "*.{ts,tsx}".
 func matchBracePattern(pattern, fileName string) (bool, error) {
-	start := strings.Index(pattern, "{")
-	end := strings.Index(pattern, "}")
-
-	if start == -1 || end == -1 || end <= start {
-		return filepath.Match(pattern, fileName)
+	patterns, err := expandBraces(pattern)
+	if err != nil {
+		return false, err
 	}
 
-	prefix := pattern[:start]
-	suffix := pattern[end+1:]
-	braceContent := pattern[start+1 : end]
-
-	alternatives := strings.Split(braceContent, ",")
-
-	for _, alt := range alternatives {
-		testPattern := prefix + strings.TrimSpace(alt) + suffix
+	for _, testPattern := range patterns {
 		matched, err := filepath.Match(testPattern, fileName)
 		if err != nil {
 			continue
@@ -298,3 +517,85 @@ func matchBracePattern(pattern, fileName string) (bool, error) {
 
 	return false, nil
 }
+
+// validateIncludePattern checks that an include pattern's braces expand
+// within the limits of expandBraces.
+func validateIncludePattern(pattern string) error {
+	if !strings.Contains(pattern, "{") {
+		return nil
+	}
+	_, err := expandBraces(pattern)
+	return err
+}
+
+// expandBraces expands the brace groups of an include pattern, which may be
+// repeated or nested: "*.{ts,{c,m}js}" expands to "*.ts", "*.cjs" and
+// "*.mjs". Whitespace around alternatives is ignored, and a "{" without a
+// matching "}" is kept literally. It fails when the pattern would expand to
+// more than MaxIncludeExpansions patterns or nests braces deeper than
+// MaxIncludeBraceDepth.
+func expandBraces(pattern string) ([]string, error) {
+	return expandBraceGroups(pattern, 0)
+}
+
+func expandBraceGroups(pattern string, depth int) ([]string, error) {
+	start, end, alternatives := findBraceGroup(pattern)
+	if start < 0 {
+		return []string{pattern}, nil
+	}
+	if depth >= MaxIncludeBraceDepth {
+		return nil, fmt.Errorf("braces are nested more than %d levels deep", MaxIncludeBraceDepth)
+	}
+
+	suffixes, err := expandBraceGroups(pattern[end+1:], depth)
+	if err != nil {
+		return nil, err
+	}
+
+	var expanded []string
+	for _, alternative := range alternatives {
+		inner, err := expandBraceGroups(strings.TrimSpace(alternative), depth+1)
+		if err != nil {
+			return nil, err
+		}
+		for _, middle := range inner {
+			for _, suffix := range suffixes {
+				if len(expanded) == MaxIncludeExpansions {
+					return nil, fmt.Errorf("braces expand to more than %d patterns", MaxIncludeExpansions)
+				}
+				expanded = append(expanded, pattern[:start]+middle+suffix)
+			}
+		}
+	}
+	return expanded, nil
+}
+
+// findBraceGroup locates the first brace group of pattern and returns the
+// positions of its braces and its comma-separated alternatives, which may
+// contain nested groups. start is -1 when pattern has no complete group.
+func findBraceGroup(pattern string) (start, end int, alternatives []string) {
+	start = strings.IndexByte(pattern, '{')
+	if start < 0 {
+		return -1, -1, nil
+	}
+
+	nesting := 0
+	last := start + 1
+	for i := start; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '{':
+			nesting++
+		case '}':
+			nesting--
+			if nesting == 0 {
+				return start, i, append(alternatives, pattern[last:i])
+			}
+		case ',':
+			if nesting == 1 {
+				alternatives = append(alternatives, pattern[last:i])
+				last = i + 1
+			}
+		}
+	}
+	return -1, -1, nil
+}
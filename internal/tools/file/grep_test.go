@@ -1,9 +1,17 @@
 package file
 
 import (
+	"context"
+	"encoding/json"
 	"os"
+	"path/filepath"
+	"reflect"
 	"regexp"
+	"slices"
+	"strings"
 	"testing"
+
+	"github.com/d-kuro/claude-code-mcp/internal/tools"
 )
 
 func TestMatchIncludePattern(t *testing.T) {
@@ -131,6 +139,50 @@ func TestMatchBracePattern(t *testing.T) {
 	}
 }
 
+func TestExpandBraces(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    []string
+	}{
+		{"*.go", []string{"*.go"}},
+		{"*.{ts,tsx}", []string{"*.ts", "*.tsx"}},
+		{"*.{ts,{c,m}js}", []string{"*.ts", "*.cjs", "*.mjs"}},
+		{"{a,b}.{x,y}", []string{"a.x", "a.y", "b.x", "b.y"}},
+		{"*.{js, ts}", []string{"*.js", "*.ts"}},
+		{"*.{js,ts", []string{"*.{js,ts"}},
+	}
+	for _, tt := range tests {
+		got, err := expandBraces(tt.pattern)
+		if err != nil {
+			t.Errorf("expandBraces(%q) error = %v", tt.pattern, err)
+			continue
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("expandBraces(%q) = %v, want %v", tt.pattern, got, tt.want)
+		}
+	}
+
+	// Nine groups of two alternatives expand to 512 patterns
+	oversized := strings.Repeat("{a,b}", 9)
+	if _, err := expandBraces(oversized); err == nil || !strings.Contains(err.Error(), "more than 256 patterns") {
+		t.Errorf("Expected an oversized expansion to be rejected, got %v", err)
+	}
+	if _, err := matchIncludePattern(oversized, "aaaaaaaaa"); err == nil {
+		t.Error("Expected matchIncludePattern to reject an oversized expansion")
+	}
+
+	deep := strings.Repeat("{a,", 9) + "b" + strings.Repeat("}", 9)
+	if _, err := expandBraces(deep); err == nil || !strings.Contains(err.Error(), "nested more than 8 levels") {
+		t.Errorf("Expected deeply nested braces to be rejected, got %v", err)
+	}
+
+	ctx := &tools.Context{Validator: &mockValidator{}}
+	output, isError := callServerTool(t, CreateGrepTool(ctx), map[string]any{"pattern": "x", "path": t.TempDir(), "include": oversized})
+	if !isError || !strings.Contains(output, "Invalid include") {
+		t.Errorf("Expected Grep to reject the include pattern, got: %s", output)
+	}
+}
+
 func TestIsBinaryContent(t *testing.T) {
 	tests := []struct {
 		name string
@@ -204,7 +256,7 @@ func TestFunction(param string) error {
 }`
 
 	// Write test content to file
-	if _, err := writeFileContent(tempFile, content); err != nil {
+	if _, err := writeFileContent(tempFile, content, tools.DefaultFileMode, tools.DefaultDirMode, writeOptions{}); err != nil {
 		t.Fatalf("Failed to create test file: %v", err)
 	}
 	defer func() {
@@ -282,3 +334,358 @@ func TestFunction(param string) error {
 		})
 	}
 }
+
+func TestBuildRipgrepArgs(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      GrepArgs
+		wantFlags []string
+		denyFlags []string
+	}{
+		{
+			name:      "defaults",
+			args:      GrepArgs{Pattern: "foo"},
+			wantFlags: []string{"--hidden"},
+			denyFlags: []string{"--follow", "--text", "--fixed-strings", "--word-regexp"},
+		},
+		{
+			name:      "follow symlinks enabled",
+			args:      GrepArgs{Pattern: "foo", FollowSymlinks: boolPtr(true)},
+			wantFlags: []string{"--follow", "--hidden"},
+		},
+		{
+			name:      "hidden files disabled",
+			args:      GrepArgs{Pattern: "foo", SearchHidden: boolPtr(false)},
+			denyFlags: []string{"--hidden", "--follow"},
+		},
+		{
+			name:      "binary search enabled",
+			args:      GrepArgs{Pattern: "foo", SearchBinary: boolPtr(true)},
+			wantFlags: []string{"--text"},
+		},
+		{
+			name:      "fixed strings and whole word",
+			args:      GrepArgs{Pattern: "foo", FixedStrings: boolPtr(true), WholeWord: boolPtr(true)},
+			wantFlags: []string{"--fixed-strings", "--word-regexp"},
+		},
+		{
+			name:      "explicit defaults",
+			args:      GrepArgs{Pattern: "foo", FollowSymlinks: boolPtr(false), SearchHidden: boolPtr(true)},
+			wantFlags: []string{"--hidden"},
+			denyFlags: []string{"--follow"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := buildRipgrepArgs("/tmp/search", tt.args.Pattern, newGrepOptions(tt.args))
+
+			for _, flag := range tt.wantFlags {
+				if !slices.Contains(args, flag) {
+					t.Errorf("Expected %s in args %v", flag, args)
+				}
+			}
+			for _, flag := range tt.denyFlags {
+				if slices.Contains(args, flag) {
+					t.Errorf("Did not expect %s in args %v", flag, args)
+				}
+			}
+
+			if n := len(args); n < 2 || args[n-2] != "foo" || args[n-1] != "/tmp/search" {
+				t.Errorf("Expected pattern and path at the end of args, got %v", args)
+			}
+		})
+	}
+}
+
+func TestBinarySkipNote(t *testing.T) {
+	if note := binarySkipNote(0); note != "" {
+		t.Errorf("Expected no note when nothing was skipped, got %q", note)
+	}
+
+	if note := binarySkipNote(2); note != "\n(2 binary files skipped)" {
+		t.Errorf("Unexpected skip note: %q", note)
+	}
+
+	all := []string{"/a.txt", "/b.bin", "/c.bin"}
+	subset := []string{"/a.txt"}
+	if got := countMissing(all, subset); got != 2 {
+		t.Errorf("countMissing() = %d, want 2", got)
+	}
+}
+
+func TestGrepBinaryFiles(t *testing.T) {
+	if _, err := FindBinary("rg"); err != nil {
+		t.Skip("ripgrep not available")
+	}
+
+	tempDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tempDir, "text.txt"), []byte("needle in text\n"), 0644); err != nil {
+		t.Fatalf("Failed to create text file: %v", err)
+	}
+	binary := append([]byte("needle"), 0, 0, 0, 1, 2, 3)
+	if err := os.WriteFile(filepath.Join(tempDir, "data.bin"), binary, 0644); err != nil {
+		t.Fatalf("Failed to create binary file: %v", err)
+	}
+
+	result, err := grepFilesWithRipgrep(context.Background(), tempDir, "needle", newGrepOptions(GrepArgs{Pattern: "needle"}))
+	if err != nil {
+		t.Fatalf("grepFilesWithRipgrep() error = %v", err)
+	}
+	if strings.Contains(result, "data.bin") {
+		t.Errorf("Expected binary file to be skipped, got: %s", result)
+	}
+	if strings.Contains(result, "binary files skipped") {
+		t.Errorf("Did not expect skipped binary files to be counted when a text file matched, got: %s", result)
+	}
+
+	result, err = grepFilesWithRipgrep(context.Background(), tempDir, "needle", newGrepOptions(GrepArgs{Pattern: "needle", CountBinary: boolPtr(true)}))
+	if err != nil {
+		t.Fatalf("grepFilesWithRipgrep() error = %v", err)
+	}
+	if strings.Contains(result, "data.bin") || !strings.Contains(result, "(1 binary files skipped)") {
+		t.Errorf("Expected binary file to be skipped and counted with count_binary, got: %s", result)
+	}
+
+	if err := os.Remove(filepath.Join(tempDir, "text.txt")); err != nil {
+		t.Fatalf("Failed to remove text file: %v", err)
+	}
+
+	result, err = grepFilesWithRipgrep(context.Background(), tempDir, "needle", newGrepOptions(GrepArgs{Pattern: "needle"}))
+	if err != nil {
+		t.Fatalf("grepFilesWithRipgrep() error = %v", err)
+	}
+	if !strings.Contains(result, "No files found") || !strings.Contains(result, "(1 binary files skipped)") {
+		t.Errorf("Expected skipped binary files to be counted by default when nothing matched, got: %s", result)
+	}
+
+	result, err = grepFilesWithRipgrep(context.Background(), tempDir, "needle", newGrepOptions(GrepArgs{Pattern: "needle", CountBinary: boolPtr(false)}))
+	if err != nil {
+		t.Fatalf("grepFilesWithRipgrep() error = %v", err)
+	}
+	if strings.Contains(result, "binary files skipped") {
+		t.Errorf("Did not expect skipped binary files to be counted with count_binary false, got: %s", result)
+	}
+
+	result, err = grepFilesWithRipgrep(context.Background(), tempDir, "needle", newGrepOptions(GrepArgs{Pattern: "needle", SearchBinary: boolPtr(true), CountBinary: boolPtr(true)}))
+	if err != nil {
+		t.Fatalf("grepFilesWithRipgrep() error = %v", err)
+	}
+	if !strings.Contains(result, "data.bin") {
+		t.Errorf("Expected binary file to be searched with search_binary, got: %s", result)
+	}
+	if strings.Contains(result, "binary files skipped") {
+		t.Errorf("Did not expect skip note with search_binary, got: %s", result)
+	}
+}
+
+func TestGrepNestedBraceInclude(t *testing.T) {
+	if _, err := FindBinary("rg"); err != nil {
+		t.Skip("ripgrep not available")
+	}
+
+	tempDir := t.TempDir()
+	for _, name := range []string{"a.ts", "b.cjs", "c.mjs", "d.js"} {
+		if err := os.WriteFile(filepath.Join(tempDir, name), []byte("needle\n"), 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+
+	include := "*.{ts,{c,m}js}"
+	args := buildRipgrepArgs(tempDir, "needle", newGrepOptions(GrepArgs{Pattern: "needle", Include: &include}))
+	for _, glob := range []string{"*.ts", "*.cjs", "*.mjs"} {
+		if !slices.Contains(args, glob) {
+			t.Errorf("Expected glob %s in args %v", glob, args)
+		}
+	}
+
+	result, err := grepFilesWithRipgrep(context.Background(), tempDir, "needle", newGrepOptions(GrepArgs{Pattern: "needle", Include: &include}))
+	if err != nil {
+		t.Fatalf("grepFilesWithRipgrep() error = %v", err)
+	}
+	for _, name := range []string{"a.ts", "b.cjs", "c.mjs"} {
+		if !strings.Contains(result, name) {
+			t.Errorf("Expected %s in results, got: %s", name, result)
+		}
+	}
+	if strings.Contains(result, "d.js") {
+		t.Errorf("Did not expect d.js in results, got: %s", result)
+	}
+}
+
+func TestParseRipgrepEvent(t *testing.T) {
+	events := []string{
+		`{"type":"begin","data":{"path":{"text":"src/main.go"}}}`,
+		`{"type":"match","data":{"path":{"text":"src/main.go"},"lines":{"text":"func main() { run() }\n"},"line_number":3,"absolute_offset":14,"submatches":[{"match":{"text":"main"},"start":5,"end":9},{"match":{"text":"run"},"start":14,"end":17}]}}`,
+		`{"type":"match","data":{"path":{"bytes":"c3JjL2xhdGluMf8uZ28="},"lines":{"text":"run()\r\n"},"line_number":7,"absolute_offset":40,"submatches":[{"match":{"text":"run"},"start":0,"end":3}]}}`,
+		`{"type":"end","data":{"path":{"text":"src/main.go"},"binary_offset":null,"stats":{"matches":2}}}`,
+		`{"type":"summary","data":{"elapsed_total":{"secs":0,"nanos":1000,"human":"0.000001s"},"stats":{"matches":3}}}`,
+	}
+
+	var matches []GrepMatch
+	for _, event := range events {
+		match, ok, err := parseRipgrepEvent([]byte(event))
+		if err != nil {
+			t.Fatalf("parseRipgrepEvent(%s) error = %v", event, err)
+		}
+		if ok {
+			matches = append(matches, match)
+		}
+	}
+
+	want := []GrepMatch{
+		{
+			Type: "match",
+			Path: "src/main.go",
+			Line: 3,
+			Text: "func main() { run() }",
+			Submatches: []GrepSubmatch{
+				{Text: "main", Start: 5, End: 9},
+				{Text: "run", Start: 14, End: 17},
+			},
+		},
+		{
+			Type:       "match",
+			Path:       "src/latin1\xff.go",
+			Line:       7,
+			Text:       "run()",
+			Submatches: []GrepSubmatch{{Text: "run", Start: 0, End: 3}},
+		},
+	}
+	if !reflect.DeepEqual(matches, want) {
+		t.Errorf("parseRipgrepEvent() matches = %+v, want %+v", matches, want)
+	}
+
+	if _, _, err := parseRipgrepEvent([]byte("not json")); err == nil {
+		t.Error("Expected an error for malformed output")
+	}
+}
+
+func TestGrepNDJSON(t *testing.T) {
+	tempDir := t.TempDir()
+	for _, name := range []string{"a.txt", "b.txt"} {
+		if err := os.WriteFile(filepath.Join(tempDir, name), []byte("needle one\nhay\nneedle two\n"), 0644); err != nil {
+			t.Fatalf("Failed to create file: %v", err)
+		}
+	}
+
+	decode := func(t *testing.T, output string) ([]GrepMatch, GrepSummary) {
+		t.Helper()
+		lines := strings.Split(output, "\n")
+		var matches []GrepMatch
+		for _, line := range lines[:len(lines)-1] {
+			var match GrepMatch
+			if err := json.Unmarshal([]byte(line), &match); err != nil {
+				t.Fatalf("Invalid match line %q: %v", line, err)
+			}
+			matches = append(matches, match)
+		}
+		var summary GrepSummary
+		if err := json.Unmarshal([]byte(lines[len(lines)-1]), &summary); err != nil || summary.Type != "summary" {
+			t.Fatalf("Invalid summary line %q: %v", lines[len(lines)-1], err)
+		}
+		return matches, summary
+	}
+
+	output, err := grepNDJSON(context.Background(), nil, nil, tempDir, "needle", newGrepOptions(GrepArgs{Pattern: "needle"}))
+	if err != nil {
+		t.Fatalf("grepNDJSON() error = %v", err)
+	}
+	matches, summary := decode(t, output)
+	if len(matches) != 4 || summary.Matches != 4 || summary.Truncated {
+		t.Fatalf("Expected 4 matches without truncation, got %d matches and summary %+v", len(matches), summary)
+	}
+	for _, match := range matches {
+		if match.Type != "match" || !strings.HasPrefix(match.Text, "needle") || (match.Line != 1 && match.Line != 3) {
+			t.Errorf("Unexpected match %+v", match)
+		}
+	}
+
+	var emitted int
+	count, truncated, err := streamGrepMatches(context.Background(), tempDir, "needle", newGrepOptions(GrepArgs{Pattern: "needle"}), 3, func(GrepMatch) { emitted++ })
+	if err != nil {
+		t.Fatalf("streamGrepMatches() error = %v", err)
+	}
+	if count != 3 || emitted != 3 || !truncated {
+		t.Errorf("Expected 3 emitted matches and truncation, got count %d, emitted %d, truncated %v", count, emitted, truncated)
+	}
+}
+
+func TestGrepFixedStringsAndWholeWord(t *testing.T) {
+	tempDir := t.TempDir()
+	files := map[string]string{
+		"version.txt": "release a.b.c\n",
+		"regex.txt":   "release aXbYc\n",
+		"word.txt":    "the cat sat\n",
+		"prefix.txt":  "concatenate\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tempDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+
+	grep := func(args map[string]any) (string, bool) {
+		args["path"] = tempDir
+		return callServerTool(t, CreateGrepTool(&tools.Context{Validator: &mockValidator{}}), args)
+	}
+
+	tests := []struct {
+		name     string
+		args     map[string]any
+		want     []string
+		dontWant []string
+	}{
+		{
+			name:     "regex metacharacters match any character by default",
+			args:     map[string]any{"pattern": "a.b.c"},
+			want:     []string{"version.txt", "regex.txt"},
+			dontWant: []string{"word.txt"},
+		},
+		{
+			name:     "fixed strings match literally",
+			args:     map[string]any{"pattern": "a.b.c", "fixed_strings": true},
+			want:     []string{"version.txt"},
+			dontWant: []string{"regex.txt"},
+		},
+		{
+			name: "fixed strings skip regex validation",
+			args: map[string]any{"pattern": "a.b.c(", "fixed_strings": true},
+		},
+		{
+			name:     "whole word",
+			args:     map[string]any{"pattern": "cat", "whole_word": true},
+			want:     []string{"word.txt"},
+			dontWant: []string{"prefix.txt"},
+		},
+		{
+			name:     "substring without whole word",
+			args:     map[string]any{"pattern": "cat"},
+			want:     []string{"word.txt", "prefix.txt"},
+			dontWant: []string{"version.txt"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, isError := grep(tt.args)
+			if isError {
+				t.Fatalf("Grep failed: %s", text)
+			}
+			for _, name := range tt.want {
+				if !strings.Contains(text, name) {
+					t.Errorf("Expected %s in results, got: %s", name, text)
+				}
+			}
+			for _, name := range tt.dontWant {
+				if strings.Contains(text, name) {
+					t.Errorf("Did not expect %s in results, got: %s", name, text)
+				}
+			}
+		})
+	}
+
+	if text, isError := grep(map[string]any{"pattern": "a.b.c("}); !isError || !strings.Contains(text, "Invalid regular expression") {
+		t.Errorf("Expected an invalid regex without fixed_strings to be rejected, got: %s", text)
+	}
+}
@@ -0,0 +1,295 @@
+// Package file provides the NDJSON output mode of the Grep tool.
+package file
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Grep output formats.
+const (
+	GrepFormatText   = "text"
+	GrepFormatNDJSON = "ndjson"
+)
+
+// MaxGrepEvents caps the number of match events in Grep NDJSON output.
+// The search is stopped once the cap is reached.
+const MaxGrepEvents = 1000
+
+// GrepMatch is a "match" event of Grep NDJSON output: one line matching the
+// pattern.
+type GrepMatch struct {
+	Type       string         `json:"type"`
+	Path       string         `json:"path"`
+	Line       int            `json:"line"`
+	Text       string         `json:"text"`
+	Submatches []GrepSubmatch `json:"submatches,omitempty"`
+}
+
+// GrepSubmatch is one occurrence of the pattern in a matching line. Start
+// and End are byte offsets into the line text.
+type GrepSubmatch struct {
+	Text  string `json:"text"`
+	Start int    `json:"start"`
+	End   int    `json:"end"`
+}
+
+// GrepSummary is the "summary" event that ends Grep NDJSON output.
+type GrepSummary struct {
+	Type      string `json:"type"`
+	Matches   int    `json:"matches"`
+	Truncated bool   `json:"truncated"`
+}
+
+// ripgrepEvent is one line of `rg --json` output. Only "match" events are
+// decoded further; "begin", "end", "context" and "summary" are skipped.
+type ripgrepEvent struct {
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+// ripgrepMatch is the data of a ripgrep "match" event.
+type ripgrepMatch struct {
+	Path       ripgrepData `json:"path"`
+	Lines      ripgrepData `json:"lines"`
+	LineNumber int         `json:"line_number"`
+	Submatches []struct {
+		Match ripgrepData `json:"match"`
+		Start int         `json:"start"`
+		End   int         `json:"end"`
+	} `json:"submatches"`
+}
+
+// ripgrepData is how ripgrep encodes a string that may not be valid UTF-8:
+// either as text or as base64-encoded bytes.
+type ripgrepData struct {
+	Text  *string `json:"text"`
+	Bytes string  `json:"bytes"`
+}
+
+// String returns the decoded value.
+func (d ripgrepData) String() string {
+	if d.Text != nil {
+		return *d.Text
+	}
+	decoded, err := base64.StdEncoding.DecodeString(d.Bytes)
+	if err != nil {
+		return ""
+	}
+	return string(decoded)
+}
+
+// parseRipgrepEvent decodes one line of `rg --json` output. ok is false for
+// events other than matches.
+func parseRipgrepEvent(line []byte) (match GrepMatch, ok bool, err error) {
+	var event ripgrepEvent
+	if err := json.Unmarshal(line, &event); err != nil {
+		return GrepMatch{}, false, err
+	}
+	if event.Type != "match" {
+		return GrepMatch{}, false, nil
+	}
+
+	var data ripgrepMatch
+	if err := json.Unmarshal(event.Data, &data); err != nil {
+		return GrepMatch{}, false, err
+	}
+
+	match = GrepMatch{
+		Type: "match",
+		Path: data.Path.String(),
+		Line: data.LineNumber,
+		Text: strings.TrimRight(data.Lines.String(), "\r\n"),
+	}
+	for _, submatch := range data.Submatches {
+		match.Submatches = append(match.Submatches, GrepSubmatch{
+			Text:  submatch.Match.String(),
+			Start: submatch.Start,
+			End:   submatch.End,
+		})
+	}
+	return match, true, nil
+}
+
+// grepNDJSON searches searchPath and returns one JSON "match" event per
+// matching line followed by a "summary" event, one per line. When the client
+// asked for progress, each match event is also sent as a progress
+// notification as soon as it is found.
+func grepNDJSON(ctx context.Context, session *mcp.ServerSession, token any, searchPath, pattern string, opts grepOptions) (string, error) {
+	stat, err := os.Stat(searchPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to stat search path: %w", err)
+	}
+
+	if !stat.IsDir() {
+		return "", fmt.Errorf("search path is not a directory")
+	}
+
+	var output strings.Builder
+	sent := 0
+	count, truncated, err := streamGrepMatches(ctx, searchPath, pattern, opts, MaxGrepEvents, func(match GrepMatch) {
+		line, err := json.Marshal(match)
+		if err != nil {
+			return
+		}
+		output.Write(line)
+		output.WriteByte('\n')
+		sent++
+
+		if token != nil && session != nil {
+			_ = session.NotifyProgress(ctx, &mcp.ProgressNotificationParams{
+				ProgressToken: token,
+				Progress:      float64(sent),
+				Message:       string(line),
+			})
+		}
+	})
+	if err != nil {
+		return "", err
+	}
+
+	summary, err := json.Marshal(GrepSummary{Type: "summary", Matches: count, Truncated: truncated})
+	if err != nil {
+		return "", err
+	}
+	output.Write(summary)
+
+	return output.String(), nil
+}
+
+// streamGrepMatches passes each line under searchPath matching pattern to
+// emit as it is found, using `rg --json` when available and a search in Go
+// otherwise. It stops after maxEvents matches and reports truncated when
+// more matches exist.
+func streamGrepMatches(ctx context.Context, searchPath, pattern string, opts grepOptions, maxEvents int, emit func(GrepMatch)) (count int, truncated bool, err error) {
+	rgPath, err := FindBinary("rg")
+	if err != nil {
+		warnRipgrepFallback(opts, err)
+		return streamGrepMatchesWithWalk(ctx, searchPath, pattern, opts, maxEvents, emit)
+	}
+
+	args := append([]string{"--json"}, ripgrepFilterArgs(opts)...)
+	args = append(args, pattern, searchPath)
+
+	executor := NewCommandExecutor(30 * time.Second).WithCleanEnv(opts.CleanEnv)
+	if err := executor.ValidateCommand("rg", args); err != nil {
+		return 0, false, fmt.Errorf("command validation failed: %w", err)
+	}
+
+	var parseErr error
+	result, stopped, err := executor.ExecuteStream(ctx, func(line []byte) bool {
+		match, ok, err := parseRipgrepEvent(line)
+		if err != nil {
+			parseErr = err
+			return false
+		}
+		if !ok || opts.Ignore.Match(match.Path, false) {
+			return true
+		}
+		if count >= maxEvents {
+			truncated = true
+			return false
+		}
+		count++
+		emit(match)
+		return true
+	}, rgPath, args...)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to execute ripgrep: %w", err)
+	}
+	if parseErr != nil {
+		return 0, false, fmt.Errorf("failed to parse ripgrep output: %w", parseErr)
+	}
+	if !stopped && result.ExitCode == 2 && !onlyVanishedFileWarnings(result.Stderr) {
+		return 0, false, fmt.Errorf("ripgrep error: %s", result.Stderr)
+	}
+
+	return count, truncated, nil
+}
+
+// streamGrepMatchesWithWalk is the fallback of streamGrepMatches when
+// ripgrep is not installed. It selects files as grepFilesWithWalk does.
+func streamGrepMatchesWithWalk(ctx context.Context, searchPath, pattern string, opts grepOptions, maxEvents int, emit func(GrepMatch)) (count int, truncated bool, err error) {
+	paths, _, _, err := grepFilesWithWalk(ctx, searchPath, pattern, opts)
+	if err != nil {
+		return 0, false, err
+	}
+	regex, err := compileGrepPattern(pattern, opts)
+	if err != nil {
+		return 0, false, err
+	}
+
+	for _, path := range paths {
+		if err := ctx.Err(); err != nil {
+			return 0, false, err
+		}
+
+		// One match past the cap tells whether the output is truncated
+		matches, err := matchingLines(path, regex, maxEvents-count+1)
+		if err != nil {
+			// The file vanished or became unreadable since the walk
+			continue
+		}
+		for _, match := range matches {
+			if count >= maxEvents {
+				return count, true, nil
+			}
+			count++
+			emit(match)
+		}
+	}
+
+	return count, false, nil
+}
+
+// matchingLines returns up to limit lines of the file at path that match regex.
+func matchingLines(path string, regex *regexp.Regexp, limit int) ([]GrepMatch, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	var matches []GrepMatch
+	reader := bufio.NewReader(file)
+	for lineNumber := 1; len(matches) < limit; lineNumber++ {
+		line, err := reader.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+		if line == "" {
+			break
+		}
+		text := strings.TrimRight(line, "\r\n")
+
+		if locations := regex.FindAllStringIndex(text, -1); locations != nil {
+			match := GrepMatch{Type: "match", Path: path, Line: lineNumber, Text: text}
+			for _, location := range locations {
+				match.Submatches = append(match.Submatches, GrepSubmatch{
+					Text:  text[location[0]:location[1]],
+					Start: location[0],
+					End:   location[1],
+				})
+			}
+			matches = append(matches, match)
+		}
+
+		if err != nil {
+			break
+		}
+	}
+	return matches, nil
+}
@@ -0,0 +1,209 @@
+package file
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/d-kuro/claude-code-mcp/internal/ignore"
+	"github.com/d-kuro/claude-code-mcp/internal/tools"
+)
+
+// setupIgnoredProject creates a project whose .mcpignore excludes the generated directory.
+func setupIgnoredProject(t *testing.T) (string, *ignore.Matcher) {
+	t.Helper()
+
+	root := t.TempDir()
+	files := map[string]string{
+		ignore.FileName:         "generated/\n",
+		"src/main.go":           "package main // needle\n",
+		"generated/out.go":      "package generated // needle\n",
+		"generated/deep/x.go":   "package deep // needle\n",
+		"src/generated_test.go": "package main // needle\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create file: %v", err)
+		}
+	}
+
+	return root, ignore.New(filepath.Join(root, ignore.FileName))
+}
+
+func TestIgnoreFileFiltersFileTools(t *testing.T) {
+	root, matcher := setupIgnoredProject(t)
+
+	t.Run("Glob", func(t *testing.T) {
+		result, err := globFilesWithFind(context.Background(), root, "*.go", matcher, nil, 0, false)
+		if err != nil {
+			t.Fatalf("globFilesWithFind() error = %v", err)
+		}
+		if strings.Contains(result, filepath.Join(root, "generated")+string(filepath.Separator)) {
+			t.Errorf("Expected ignored directory to be excluded, got:\n%s", result)
+		}
+		if !strings.Contains(result, "main.go") || !strings.Contains(result, "generated_test.go") {
+			t.Errorf("Expected non-ignored files to be listed, got:\n%s", result)
+		}
+	})
+
+	t.Run("Glob with only ignored matches", func(t *testing.T) {
+		result, err := globFilesWithFind(context.Background(), root, "out.go", matcher, nil, 0, false)
+		if err != nil {
+			t.Fatalf("globFilesWithFind() error = %v", err)
+		}
+		if !strings.HasPrefix(result, "No files found") {
+			t.Errorf("Expected no files, got:\n%s", result)
+		}
+	})
+
+	t.Run("LS", func(t *testing.T) {
+		result, err := listDirectoryWithLS(context.Background(), root, lsOptions{Matcher: matcher})
+		if err != nil {
+			t.Fatalf("listDirectoryWithLS() error = %v", err)
+		}
+		if strings.Contains(result, "- generated/") {
+			t.Errorf("Expected ignored directory to be excluded, got:\n%s", result)
+		}
+		if !strings.Contains(result, "- src/") {
+			t.Errorf("Expected src directory to be listed, got:\n%s", result)
+		}
+	})
+
+	t.Run("Grep", func(t *testing.T) {
+		if _, err := FindBinary("rg"); err != nil {
+			t.Skip("ripgrep not available")
+		}
+
+		opts := newGrepOptions(GrepArgs{Pattern: "needle"})
+		opts.Ignore = matcher
+
+		result, err := grepFilesWithRipgrep(context.Background(), root, "needle", opts)
+		if err != nil {
+			t.Fatalf("grepFilesWithRipgrep() error = %v", err)
+		}
+		if strings.Contains(result, filepath.Join(root, "generated")+string(filepath.Separator)) {
+			t.Errorf("Expected ignored directory to be excluded, got:\n%s", result)
+		}
+		if !strings.Contains(result, "main.go") {
+			t.Errorf("Expected non-ignored files to be listed, got:\n%s", result)
+		}
+	})
+
+	t.Run("Grep filtering", func(t *testing.T) {
+		paths := []string{
+			filepath.Join(root, "src", "main.go"),
+			filepath.Join(root, "generated", "out.go"),
+			filepath.Join(root, "generated", "deep", "x.go"),
+		}
+		filtered := filterIgnored(paths, matcher)
+		if len(filtered) != 1 || filtered[0] != paths[0] {
+			t.Errorf("Expected only %s, got %v", paths[0], filtered)
+		}
+	})
+}
+
+func TestDefaultIgnoresFilterFileTools(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{
+		"main.go",
+		"node_modules/pkg/index.go",
+		"src/vendor/lib.go",
+		".venv/lib/hook.go",
+		"src/app.go",
+	} {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte("package main // needle\n"), 0644); err != nil {
+			t.Fatalf("Failed to create file: %v", err)
+		}
+	}
+
+	configured := &tools.Context{Validator: &mockValidator{}, DefaultIgnores: ignore.DefaultDirectories}
+	unconfigured := &tools.Context{Validator: &mockValidator{}}
+
+	skipped := []string{"index.go", "lib.go", "hook.go"}
+	tests := []struct {
+		name     string
+		create   func(*tools.Context) *tools.ServerTool
+		args     map[string]any
+		wantSkip bool
+	}{
+		{"Glob", CreateGlobTool, map[string]any{"pattern": "*.go", "path": root}, true},
+		{"Grep", CreateGrepTool, map[string]any{"pattern": "needle", "path": root}, true},
+		{"Glob turned off", CreateGlobTool, map[string]any{"pattern": "*.go", "path": root, "default_ignores": false}, false},
+		{"Grep turned off", CreateGrepTool, map[string]any{"pattern": "needle", "path": root, "default_ignores": false}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, isError := callServerTool(t, tt.create(configured), tt.args)
+			if isError {
+				t.Fatalf("Unexpected error: %s", output)
+			}
+			if !strings.Contains(output, "main.go") || !strings.Contains(output, "app.go") {
+				t.Errorf("Expected files outside skipped directories, got:\n%s", output)
+			}
+			for _, name := range skipped {
+				if strings.Contains(output, name) == tt.wantSkip {
+					t.Errorf("Expected %s to be listed = %v, got:\n%s", name, !tt.wantSkip, output)
+				}
+			}
+		})
+	}
+
+	t.Run("Grep turned on per call", func(t *testing.T) {
+		args := map[string]any{"pattern": "needle", "path": root}
+		output, _ := callServerTool(t, CreateGrepTool(unconfigured), args)
+		if !strings.Contains(output, "index.go") {
+			t.Errorf("Expected no default ignores without configuration, got:\n%s", output)
+		}
+
+		args["default_ignores"] = true
+		output, _ = callServerTool(t, CreateGrepTool(unconfigured), args)
+		if strings.Contains(output, "index.go") || !strings.Contains(output, "main.go") {
+			t.Errorf("Expected default ignores to apply when turned on, got:\n%s", output)
+		}
+	})
+
+	t.Run("LS", func(t *testing.T) {
+		output, isError := callServerTool(t, CreateLSTool(configured), map[string]any{"path": root})
+		if isError {
+			t.Fatalf("Unexpected error: %s", output)
+		}
+		if strings.Contains(output, "node_modules") || strings.Contains(output, ".venv/") {
+			t.Errorf("Expected skipped directories to be excluded, got:\n%s", output)
+		}
+		if !strings.Contains(output, "- src/") || !strings.Contains(output, "main.go") {
+			t.Errorf("Expected other entries to be listed, got:\n%s", output)
+		}
+
+		output, _ = callServerTool(t, CreateLSTool(configured), map[string]any{"path": root, "sort_by": LSSortSize})
+		if strings.Contains(output, "node_modules") {
+			t.Errorf("Expected skipped directories to be excluded when sorted, got:\n%s", output)
+		}
+
+		output, _ = callServerTool(t, CreateLSTool(configured), map[string]any{"path": root, "default_ignores": false})
+		if !strings.Contains(output, "- node_modules/") {
+			t.Errorf("Expected skipped directories when turned off, got:\n%s", output)
+		}
+	})
+
+	t.Run("search rooted in a skipped directory", func(t *testing.T) {
+		dir := filepath.Join(root, "node_modules")
+		result, err := globFilesWithFind(context.Background(), dir, "*.go", nil, ignore.DefaultDirectories, 0, false)
+		if err != nil {
+			t.Fatalf("globFilesWithFind() error = %v", err)
+		}
+		if !strings.Contains(result, "index.go") {
+			t.Errorf("Expected files under the search root to be listed, got:\n%s", result)
+		}
+	})
+}
@@ -1,6 +1,7 @@
 package file
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -65,7 +66,7 @@ This is a test project for integration testing.
 		for filename := range files {
 			filePath := filepath.Join(projectDir, filename)
 
-			content, err := readFileContent(filePath, nil, nil)
+			content, err := readFileContent(context.Background(), filePath, nil, nil, nil)
 			if err != nil {
 				t.Errorf("Failed to read %s: %v", filename, err)
 				continue
@@ -87,7 +88,7 @@ This is a test project for integration testing.
 		mainFile := filepath.Join(projectDir, "main.go")
 
 		// Edit the greeting message
-		result, err := editFileContent(mainFile, "Hello, World!", "Hello, Go!", nil)
+		result, err := editFileContent(mainFile, "Hello, World!", "Hello, Go!", nil, nil, writeOptions{})
 		if err != nil {
 			t.Errorf("Failed to edit main.go: %v", err)
 			return
@@ -98,7 +99,7 @@ This is a test project for integration testing.
 		}
 
 		// Verify the change
-		content, err := readFileContent(mainFile, nil, nil)
+		content, err := readFileContent(context.Background(), mainFile, nil, nil, nil)
 		if err != nil {
 			t.Errorf("Failed to read modified main.go: %v", err)
 			return
@@ -119,7 +120,7 @@ This is a test project for integration testing.
 			{OldString: "true", NewString: "false"},
 		}
 
-		result, err := performMultiEdit(configFile, edits)
+		result, err := performMultiEdit(configFile, edits, writeOptions{})
 		if err != nil {
 			t.Errorf("Failed to perform multi-edit on config.json: %v", err)
 			return
@@ -153,7 +154,7 @@ This is a test project for integration testing.
 		readmeFile := filepath.Join(projectDir, "README.md")
 
 		// Step 1: Add a new section
-		_, err := editFileContent(readmeFile, "## Features", "## Installation\n\n```bash\ngo install\n```\n\n## Features", nil)
+		_, err := editFileContent(readmeFile, "## Features", "## Installation\n\n```bash\ngo install\n```\n\n## Features", nil, nil, writeOptions{})
 		if err != nil {
 			t.Errorf("Failed to add installation section: %v", err)
 			return
@@ -165,21 +166,21 @@ This is a test project for integration testing.
 			{OldString: "Feature 2", NewString: "API endpoints"},
 		}
 
-		_, err = performMultiEdit(readmeFile, edits)
+		_, err = performMultiEdit(readmeFile, edits, writeOptions{})
 		if err != nil {
 			t.Errorf("Failed to update features: %v", err)
 			return
 		}
 
 		// Step 3: Add more content
-		_, err = editFileContent(readmeFile, "- API endpoints", "- API endpoints\n- Database integration\n- Unit testing", nil)
+		_, err = editFileContent(readmeFile, "- API endpoints", "- API endpoints\n- Database integration\n- Unit testing", nil, nil, writeOptions{})
 		if err != nil {
 			t.Errorf("Failed to add more features: %v", err)
 			return
 		}
 
 		// Verify final content
-		content, err := readFileContent(readmeFile, nil, nil)
+		content, err := readFileContent(context.Background(), readmeFile, nil, nil, nil)
 		if err != nil {
 			t.Errorf("Failed to read final README: %v", err)
 			return
@@ -214,7 +215,7 @@ This is a test project for integration testing.
 			{OldString: "nonexistent", NewString: "fail"}, // This will fail
 		}
 
-		_, err := performMultiEdit(testFile, edits)
+		_, err := performMultiEdit(testFile, edits, writeOptions{})
 		if err == nil {
 			t.Error("Expected error for nonexistent string")
 			return
@@ -256,7 +257,7 @@ func TestConcurrentFileOperations(t *testing.T) {
 			go func() {
 				defer func() { done <- true }()
 
-				_, err := readFileContent(testFile, nil, nil)
+				_, err := readFileContent(context.Background(), testFile, nil, nil, nil)
 				if err != nil {
 					errors <- err
 					return
@@ -292,7 +293,7 @@ func TestConcurrentFileOperations(t *testing.T) {
 			{OldString: "line3", NewString: "third"},
 		}
 
-		_, err := performMultiEdit(testFile, edits)
+		_, err := performMultiEdit(testFile, edits, writeOptions{})
 		if err != nil {
 			t.Errorf("Multi-edit failed: %v", err)
 			return
@@ -350,7 +351,7 @@ func TestLargeFileOperations(t *testing.T) {
 
 	t.Run("read_large_file_with_limit", func(t *testing.T) {
 		start := time.Now()
-		content, err := readFileContent(largeFile, nil, intPtrIntegration(100))
+		content, err := readFileContent(context.Background(), largeFile, nil, intPtrIntegration(100), nil)
 		duration := time.Since(start)
 
 		if err != nil {
@@ -379,7 +380,7 @@ func TestLargeFileOperations(t *testing.T) {
 		start := time.Now()
 
 		// Edit a marker that should exist
-		result, err := editFileContent(largeFile, "MARKER_0:", "EDITED_MARKER_0:", nil)
+		result, err := editFileContent(largeFile, "MARKER_0:", "EDITED_MARKER_0:", nil, nil, writeOptions{})
 		duration := time.Since(start)
 
 		if err != nil {
@@ -394,7 +395,7 @@ func TestLargeFileOperations(t *testing.T) {
 		t.Logf("Edited large file in %v", duration)
 
 		// Verify the edit
-		content, err := readFileContent(largeFile, nil, intPtrIntegration(10))
+		content, err := readFileContent(context.Background(), largeFile, nil, intPtrIntegration(10), nil)
 		if err != nil {
 			t.Errorf("Failed to read edited large file: %v", err)
 			return
@@ -446,7 +447,7 @@ func TestFileOperationsWithDifferentEncodings(t *testing.T) {
 			}
 
 			// Test reading
-			content, err := readFileContent(testFile, nil, nil)
+			content, err := readFileContent(context.Background(), testFile, nil, nil, nil)
 			if err != nil {
 				t.Errorf("Failed to read %s: %v", tt.name, err)
 				return
@@ -459,7 +460,7 @@ func TestFileOperationsWithDifferentEncodings(t *testing.T) {
 
 			// Test editing
 			if strings.Contains(tt.content, "test") {
-				_, err := editFileContent(testFile, "test", "edited", nil)
+				_, err := editFileContent(testFile, "test", "edited", nil, nil, writeOptions{})
 				if err != nil {
 					t.Errorf("Failed to edit %s: %v", tt.name, err)
 					return
@@ -509,7 +510,7 @@ func TestFileOperationsErrorRecovery(t *testing.T) {
 		defer func() { _ = os.Chmod(testFile, 0644) }() // Restore for cleanup
 
 		// Try to edit (should fail gracefully)
-		_, err := editFileContent(testFile, "original", "modified", nil)
+		_, err := editFileContent(testFile, "original", "modified", nil, nil, writeOptions{})
 		if err == nil {
 			t.Error("Expected permission error")
 			return
@@ -540,7 +541,7 @@ func TestFileOperationsErrorRecovery(t *testing.T) {
 		largeContent := strings.Repeat("x", 100*1024*1024) // 100MB
 
 		// This might fail due to memory or disk constraints, but should handle gracefully
-		_, err := editFileContent(testFile, "small content", largeContent, nil)
+		_, err := editFileContent(testFile, "small content", largeContent, nil, nil, writeOptions{})
 
 		// Whether it succeeds or fails, the file should be in a valid state
 		content, readErr := os.ReadFile(testFile)
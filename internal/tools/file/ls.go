@@ -6,19 +6,91 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 
+	"github.com/d-kuro/claude-code-mcp/internal/ignore"
 	"github.com/d-kuro/claude-code-mcp/internal/prompts"
 	"github.com/d-kuro/claude-code-mcp/internal/tools"
 )
 
 // LSArgs represents the arguments for the LS tool.
 type LSArgs struct {
-	Path   string   `json:"path"`
+	Path string `json:"path,omitempty"`
+	// Paths lists several directories in one call, each under its own header.
+	// It replaces Path and may hold at most MaxLSPaths entries.
+	Paths  []string `json:"paths,omitempty"`
 	Ignore []string `json:"ignore,omitempty"`
+	// SortBy orders entries by "name" (default), "size" (largest first) or "mtime" (newest first).
+	SortBy  *string `json:"sort_by,omitempty"`
+	Reverse *bool   `json:"reverse,omitempty"`
+	// ShowHidden lists dotfiles and dot-directories. It defaults to true.
+	ShowHidden *bool `json:"show_hidden,omitempty"`
+	// DefaultIgnores turns the server's default ignored directories off or on.
+	DefaultIgnores *bool `json:"default_ignores,omitempty"`
+}
+
+// MaxLSPaths is the maximum number of directories a single LS call may list.
+const MaxLSPaths = 20
+
+const (
+	// DefaultLSTimeout bounds the ls command when no other timeout is configured.
+	DefaultLSTimeout = 10 * time.Second
+	// DefaultLSMaxEntries is the number of entries listed per directory when
+	// no other cap is configured. Further entries are only counted.
+	DefaultLSMaxEntries = 5000
+)
+
+// Sort keys accepted by the LS tool.
+const (
+	LSSortName  = "name"
+	LSSortSize  = "size"
+	LSSortMTime = "mtime"
+)
+
+// lsOptions holds the settings of a directory listing.
+type lsOptions struct {
+	// Ignore holds the glob patterns of the ignore argument.
+	Ignore []string
+	// Matcher applies the server ignore file. Nil disables it.
+	Matcher *ignore.Matcher
+	// SkipDirs names directories that are left out of the listing.
+	SkipDirs []string
+	// Reverse flips the sort order.
+	Reverse bool
+	// HideHidden leaves out entries whose name starts with a dot.
+	HideHidden bool
+	// CleanEnv runs ls with the allow-listed environment only.
+	CleanEnv bool
+	// Timeout bounds the ls command. Zero selects DefaultLSTimeout.
+	Timeout time.Duration
+	// MaxEntries caps the entries listed. Zero selects DefaultLSMaxEntries.
+	MaxEntries int
+}
+
+// timeout returns the ls timeout with the default applied.
+func (o lsOptions) timeout() time.Duration {
+	if o.Timeout <= 0 {
+		return DefaultLSTimeout
+	}
+	return o.Timeout
+}
+
+// maxEntries returns the entry cap with the default applied.
+func (o lsOptions) maxEntries() int {
+	if o.MaxEntries <= 0 {
+		return DefaultLSMaxEntries
+	}
+	return o.MaxEntries
+}
+
+// lsEntry is a single directory entry listed by the LS tool.
+type lsEntry struct {
+	name  string
+	isDir bool
 }
 
 // CreateLSTool creates the LS tool using MCP SDK patterns.
@@ -26,31 +98,93 @@ func CreateLSTool(ctx *tools.Context) *tools.ServerTool {
 	handler := func(ctxReq context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[LSArgs]) (*mcp.CallToolResultFor[any], error) {
 		args := params.Arguments
 
-		sanitizedPath, err := ctx.Validator.SanitizePath(args.Path)
-		if err != nil {
-			return &mcp.CallToolResultFor[any]{
-				Content: []mcp.Content{&mcp.TextContent{Text: "Error: Invalid path: " + err.Error()}},
-				IsError: true,
-			}, nil
+		sortBy := LSSortName
+		if args.SortBy != nil && *args.SortBy != "" {
+			sortBy = *args.SortBy
 		}
+		if sortBy != LSSortName && sortBy != LSSortSize && sortBy != LSSortMTime {
+			return tools.InvalidFieldError("sort_by", fmt.Sprintf("must be one of %q, %q or %q", LSSortName, LSSortSize, LSSortMTime)), nil
+		}
+		reverse := args.Reverse != nil && *args.Reverse
 
-		if err := ctx.Validator.ValidatePath(sanitizedPath); err != nil {
-			return &mcp.CallToolResultFor[any]{
-				Content: []mcp.Content{&mcp.TextContent{Text: "Error: Path validation failed: " + err.Error()}},
-				IsError: true,
-			}, nil
+		if len(args.Paths) > 0 {
+			if args.Path != "" {
+				return tools.InvalidFieldError("paths", "cannot be combined with path"), nil
+			}
+			if len(args.Paths) > MaxLSPaths {
+				return tools.InvalidFieldError("paths", fmt.Sprintf("must contain at most %d entries", MaxLSPaths)), nil
+			}
+		} else if args.Path == "" {
+			return tools.EmptyFieldError("path"), nil
 		}
 
-		content, err := listDirectoryWithLS(sanitizedPath, args.Ignore)
-		if err != nil {
+		paths := args.Paths
+		if len(paths) == 0 {
+			paths = []string{args.Path}
+		}
+
+		// Validate every directory before listing any of them
+		sanitizedPaths := make([]string, len(paths))
+		for i, path := range paths {
+			sanitizedPath, err := ctx.Validator.SanitizePath(path)
+			if err != nil {
+				return tools.ValidationErrorResult("Invalid path", err), nil
+			}
+
+			if err := ctx.Validator.ValidatePath(sanitizedPath); err != nil {
+				return tools.ValidationErrorResult("Path validation failed", err), nil
+			}
+			sanitizedPaths[i] = sanitizedPath
+		}
+
+		opts := lsOptions{
+			Ignore:     args.Ignore,
+			Matcher:    ctx.Ignore.Snapshot(),
+			SkipDirs:   skippedDirectories(ctx, args.DefaultIgnores),
+			Reverse:    reverse,
+			HideHidden: args.ShowHidden != nil && !*args.ShowHidden,
+			CleanEnv:   ctx.CleanEnv,
+			Timeout:    ctx.LSTimeout,
+			MaxEntries: ctx.LSMaxEntries,
+		}
+		list := func(dirPath string) (string, error) {
+			if sortBy == LSSortName {
+				return listDirectoryWithLS(ctxReq, dirPath, opts)
+			}
+			return listDirectorySorted(dirPath, sortBy, opts)
+		}
+
+		if len(args.Paths) == 0 {
+			content, err := list(sanitizedPaths[0])
+			if err != nil {
+				return &mcp.CallToolResultFor[any]{
+					Content: []mcp.Content{&mcp.TextContent{Text: "Error: " + err.Error()}},
+					IsError: true,
+				}, nil
+			}
+
 			return &mcp.CallToolResultFor[any]{
-				Content: []mcp.Content{&mcp.TextContent{Text: "Error: " + err.Error()}},
-				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: content}},
 			}, nil
 		}
 
+		// Each listing starts with its directory as a header; a directory
+		// that cannot be listed reports its error under the same header.
+		listings := make([]string, len(sanitizedPaths))
+		for i, dirPath := range sanitizedPaths {
+			if err := ctxReq.Err(); err != nil {
+				return tools.ErrorResponsef("listing cancelled: %v", err), nil
+			}
+
+			content, err := list(dirPath)
+			if err != nil {
+				content = fmt.Sprintf("- %s/\n  Error: %v", dirPath, err)
+			}
+			listings[i] = content
+		}
+
 		return &mcp.CallToolResultFor[any]{
-			Content: []mcp.Content{&mcp.TextContent{Text: content}},
+			Content: []mcp.Content{&mcp.TextContent{Text: strings.Join(listings, "\n\n")}},
 		}, nil
 	}
 
@@ -67,8 +201,12 @@ func CreateLSTool(ctx *tools.Context) *tools.ServerTool {
 	}
 }
 
-// listDirectoryWithLS lists directory contents using the ls command.
-func listDirectoryWithLS(dirPath string, ignorePatterns []string) (string, error) {
+// listDirectoryWithLS lists directory contents by name using the ls command.
+// Entries matching the ignore patterns, skipped directories and entries
+// excluded by the ignore matcher are omitted. The output of ls is read line by line and only the first
+// opts.MaxEntries entries are kept; the rest are counted. The ls process is
+// killed when ctx is cancelled or opts.Timeout expires.
+func listDirectoryWithLS(ctx context.Context, dirPath string, opts lsOptions) (string, error) {
 	stat, err := os.Stat(dirPath)
 	if err != nil {
 		return "", fmt.Errorf("failed to stat path: %w", err)
@@ -80,23 +218,67 @@ func listDirectoryWithLS(dirPath string, ignorePatterns []string) (string, error
 
 	lsPath, err := FindBinary("ls")
 	if err != nil {
-		return "", fmt.Errorf("ls command not found: %w", err)
+		// Without ls (e.g. on Windows), list the directory in Go sorted by name
+		return listDirectorySorted(dirPath, LSSortName, opts)
 	}
 
-	executor := NewCommandExecutor(10 * time.Second)
+	executor := NewCommandExecutor(opts.timeout()).WithCleanEnv(opts.CleanEnv)
 
 	args := []string{
 		"-1", // One entry per line
-		"-A", // Show hidden files but not . and ..
 		"-F", // Add indicators to show file types
-		dirPath,
 	}
+	if !opts.HideHidden {
+		args = append(args, "-A") // Show hidden files but not . and ..
+	}
+	if opts.Reverse {
+		args = append(args, "-r")
+	}
+	args = append(args, dirPath)
 
 	if err := executor.ValidateCommand("ls", args); err != nil {
 		return "", fmt.Errorf("command validation failed: %w", err)
 	}
 
-	result, err := executor.Execute(context.Background(), lsPath, args...)
+	var entries []lsEntry
+	listed, more := 0, 0
+	maxEntries := opts.maxEntries()
+
+	result, _, err := executor.ExecuteStream(ctx, func(raw []byte) bool {
+		line := strings.TrimSpace(string(raw))
+		if line == "" {
+			return true
+		}
+		listed++
+
+		name := line
+		isDir := strings.HasSuffix(line, "/")
+		if isDir {
+			name = strings.TrimSuffix(line, "/")
+		}
+
+		if shouldIgnoreFile(name, opts.Ignore) {
+			return true
+		}
+
+		if !isDir {
+			name = strings.TrimSuffix(name, "*") // Executable
+			name = strings.TrimSuffix(name, "@") // Symlink
+			name = strings.TrimSuffix(name, "|") // FIFO
+			name = strings.TrimSuffix(name, "=") // Socket
+		}
+
+		if (isDir && isSkippedDirectory(name, opts.SkipDirs)) || opts.Matcher.Match(filepath.Join(dirPath, name), isDir) {
+			return true
+		}
+
+		if len(entries) >= maxEntries {
+			more++
+			return true
+		}
+		entries = append(entries, lsEntry{name: name, isDir: isDir})
+		return true
+	}, lsPath, args...)
 	if err != nil {
 		return "", fmt.Errorf("failed to execute ls: %w", err)
 	}
@@ -105,42 +287,115 @@ func listDirectoryWithLS(dirPath string, ignorePatterns []string) (string, error
 		return "", fmt.Errorf("ls command failed with exit code %d: %s", result.ExitCode, result.Stderr)
 	}
 
-	if strings.TrimSpace(result.Stdout) == "" {
+	if listed == 0 {
 		return fmt.Sprintf("- %s/\n  (empty directory)", dirPath), nil
 	}
 
-	lines := strings.Split(strings.TrimSpace(result.Stdout), "\n")
-	var output strings.Builder
-	output.WriteString(fmt.Sprintf("- %s/\n", dirPath))
+	return formatLSEntries(dirPath, entries, more), nil
+}
 
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" {
+// listDirectorySorted lists directory contents ordered by name, size or
+// modification time, reading the metadata with os.ReadDir. Sizes are listed
+// largest first and times newest first; reverse flips the order. Ties are
+// broken by name. It also serves as the fallback when ls is not installed.
+func listDirectorySorted(dirPath string, sortBy string, opts lsOptions) (string, error) {
+	stat, err := os.Stat(dirPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to stat path: %w", err)
+	}
+
+	if !stat.IsDir() {
+		return "", fmt.Errorf("path is not a directory")
+	}
+
+	dirEntries, err := os.ReadDir(dirPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read directory: %w", err)
+	}
+
+	if len(dirEntries) == 0 {
+		return fmt.Sprintf("- %s/\n  (empty directory)", dirPath), nil
+	}
+
+	type sortableEntry struct {
+		lsEntry
+		info os.FileInfo
+	}
+
+	var sortable []sortableEntry
+	for _, dirEntry := range dirEntries {
+		name := dirEntry.Name()
+		if shouldIgnoreFile(name, opts.Ignore) || (opts.HideHidden && strings.HasPrefix(name, ".")) {
 			continue
 		}
 
-		name := line
-		isDir := strings.HasSuffix(line, "/")
-		if isDir {
-			name = strings.TrimSuffix(line, "/")
+		// Symlinks are not followed, matching the ls -F classification
+		info, err := dirEntry.Info()
+		if err != nil {
+			// Entry vanished while listing
+			continue
 		}
 
-		if shouldIgnoreFile(name, ignorePatterns) {
+		if (info.IsDir() && isSkippedDirectory(name, opts.SkipDirs)) || opts.Matcher.Match(filepath.Join(dirPath, name), info.IsDir()) {
 			continue
 		}
 
-		if isDir {
-			output.WriteString(fmt.Sprintf("  - %s/\n", name))
+		sortable = append(sortable, sortableEntry{
+			lsEntry: lsEntry{name: name, isDir: info.IsDir()},
+			info:    info,
+		})
+	}
+
+	sort.SliceStable(sortable, func(i, j int) bool {
+		a, b := sortable[i], sortable[j]
+		if opts.Reverse {
+			a, b = b, a
+		}
+		switch sortBy {
+		case LSSortSize:
+			if a.info.Size() != b.info.Size() {
+				return a.info.Size() > b.info.Size()
+			}
+		case LSSortMTime:
+			if !a.info.ModTime().Equal(b.info.ModTime()) {
+				return a.info.ModTime().After(b.info.ModTime())
+			}
+		}
+		return a.name < b.name
+	})
+
+	more := 0
+	if maxEntries := opts.maxEntries(); len(sortable) > maxEntries {
+		more = len(sortable) - maxEntries
+		sortable = sortable[:maxEntries]
+	}
+
+	entries := make([]lsEntry, len(sortable))
+	for i, entry := range sortable {
+		entries[i] = entry.lsEntry
+	}
+
+	return formatLSEntries(dirPath, entries, more), nil
+}
+
+// formatLSEntries renders directory entries as the LS tool tree output,
+// followed by a note when more entries were left out.
+func formatLSEntries(dirPath string, entries []lsEntry, more int) string {
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("- %s/\n", dirPath))
+
+	for _, entry := range entries {
+		if entry.isDir {
+			output.WriteString(fmt.Sprintf("  - %s/\n", entry.name))
 		} else {
-			name = strings.TrimSuffix(name, "*") // Executable
-			name = strings.TrimSuffix(name, "@") // Symlink
-			name = strings.TrimSuffix(name, "|") // FIFO
-			name = strings.TrimSuffix(name, "=") // Socket
-			output.WriteString(fmt.Sprintf("  - %s\n", name))
+			output.WriteString(fmt.Sprintf("  - %s\n", entry.name))
 		}
 	}
+	if more > 0 {
+		output.WriteString(fmt.Sprintf("  ... (%d more entries)\n", more))
+	}
 
-	return strings.TrimSuffix(output.String(), "\n"), nil
+	return strings.TrimSuffix(output.String(), "\n")
 }
 
 // shouldIgnoreFile checks if a filename matches any of the ignore patterns.
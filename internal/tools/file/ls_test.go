@@ -0,0 +1,344 @@
+package file
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/d-kuro/claude-code-mcp/internal/tools"
+)
+
+// setupSortableDirectory creates files whose name, size and mtime orders all differ.
+func setupSortableDirectory(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	now := time.Now()
+	files := []struct {
+		name string
+		size int
+		age  time.Duration
+	}{
+		{"a.txt", 20, 1 * time.Hour},
+		{"b.txt", 30, 3 * time.Hour},
+		{"c.txt", 10, 2 * time.Hour},
+	}
+	for _, f := range files {
+		path := filepath.Join(dir, f.name)
+		if err := os.WriteFile(path, []byte(strings.Repeat("x", f.size)), 0644); err != nil {
+			t.Fatalf("Failed to create file: %v", err)
+		}
+		modTime := now.Add(-f.age)
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatalf("Failed to set file times: %v", err)
+		}
+	}
+
+	return dir
+}
+
+// listedNames extracts the entry names from LS tool output.
+func listedNames(output string) []string {
+	var names []string
+	for _, line := range strings.Split(output, "\n")[1:] {
+		names = append(names, strings.TrimPrefix(strings.TrimSpace(line), "- "))
+	}
+	return names
+}
+
+func TestListDirectorySortOrder(t *testing.T) {
+	dir := setupSortableDirectory(t)
+
+	tests := []struct {
+		name    string
+		sortBy  string
+		reverse bool
+		want    []string
+	}{
+		{"name", LSSortName, false, []string{"a.txt", "b.txt", "c.txt"}},
+		{"name reversed", LSSortName, true, []string{"c.txt", "b.txt", "a.txt"}},
+		{"size", LSSortSize, false, []string{"b.txt", "a.txt", "c.txt"}},
+		{"size reversed", LSSortSize, true, []string{"c.txt", "a.txt", "b.txt"}},
+		{"mtime", LSSortMTime, false, []string{"a.txt", "c.txt", "b.txt"}},
+		{"mtime reversed", LSSortMTime, true, []string{"b.txt", "c.txt", "a.txt"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var output string
+			var err error
+			if tt.sortBy == LSSortName {
+				output, err = listDirectoryWithLS(context.Background(), dir, lsOptions{Reverse: tt.reverse})
+			} else {
+				output, err = listDirectorySorted(dir, tt.sortBy, lsOptions{Reverse: tt.reverse})
+			}
+			if err != nil {
+				t.Fatalf("listing failed: %v", err)
+			}
+
+			got := listedNames(output)
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("Expected order %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestListDirectoryHiddenFiles(t *testing.T) {
+	dir := setupSortableDirectory(t)
+	if err := os.WriteFile(filepath.Join(dir, ".env"), []byte("SECRET=1\n"), 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	if err := os.Mkdir(filepath.Join(dir, ".git"), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	for i, name := range []string{".env", ".git"} {
+		modTime := time.Now().Add(-time.Duration(4+i) * time.Hour)
+		if err := os.Chtimes(filepath.Join(dir, name), modTime, modTime); err != nil {
+			t.Fatalf("Failed to set file times: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		sortBy string
+		hide   bool
+		want   []string
+	}{
+		{"ls shows hidden", LSSortName, false, []string{".env", ".git/", "a.txt", "b.txt", "c.txt"}},
+		{"ls hides hidden", LSSortName, true, []string{"a.txt", "b.txt", "c.txt"}},
+		{"sorted shows hidden", LSSortMTime, false, []string{"a.txt", "c.txt", "b.txt", ".env", ".git/"}},
+		{"sorted hides hidden", LSSortMTime, true, []string{"a.txt", "c.txt", "b.txt"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := lsOptions{HideHidden: tt.hide}
+			var output string
+			var err error
+			if tt.sortBy == LSSortName {
+				output, err = listDirectoryWithLS(context.Background(), dir, opts)
+			} else {
+				output, err = listDirectorySorted(dir, tt.sortBy, opts)
+			}
+			if err != nil {
+				t.Fatalf("listing failed: %v", err)
+			}
+
+			got := listedNames(output)
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestListDirectorySortedFiltering(t *testing.T) {
+	root, matcher := setupIgnoredProject(t)
+
+	output, err := listDirectorySorted(root, LSSortSize, lsOptions{Ignore: []string{"src"}, Matcher: matcher})
+	if err != nil {
+		t.Fatalf("listDirectorySorted() error = %v", err)
+	}
+	if strings.Contains(output, "- generated/") || strings.Contains(output, "- src/") {
+		t.Errorf("Expected ignored entries to be excluded, got:\n%s", output)
+	}
+	if !strings.Contains(output, ".mcpignore") {
+		t.Errorf("Expected remaining entries to be listed, got:\n%s", output)
+	}
+}
+
+func TestListDirectoryFallbackWithoutLS(t *testing.T) {
+	dir := setupSortableDirectory(t)
+	if err := os.Mkdir(filepath.Join(dir, "subdir"), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".env"), []byte("SECRET=1\n"), 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+
+	hideBinaries(t)
+	if _, err := FindBinary("ls"); err == nil {
+		t.Fatal("Expected ls to be hidden from PATH")
+	}
+
+	output, err := listDirectoryWithLS(context.Background(), dir, lsOptions{Ignore: []string{"c.*"}})
+	if err != nil {
+		t.Fatalf("listDirectoryWithLS() fallback error = %v", err)
+	}
+
+	want := "- " + dir + "/\n  - .env\n  - a.txt\n  - b.txt\n  - subdir/"
+	if output != want {
+		t.Errorf("Expected fallback output:\n%s\ngot:\n%s", want, output)
+	}
+
+	empty := t.TempDir()
+	if output, err := listDirectoryWithLS(context.Background(), empty, lsOptions{}); err != nil || !strings.Contains(output, "(empty directory)") {
+		t.Errorf("Expected empty directory marker, got %q (err %v)", output, err)
+	}
+}
+
+func TestListDirectoryWithLSCancelled(t *testing.T) {
+	if _, err := FindBinary("ls"); err != nil {
+		t.Skip("ls not available")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := listDirectoryWithLS(ctx, t.TempDir(), lsOptions{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected a cancelled request to stop ls, got: %v", err)
+	}
+}
+
+func TestListDirectoryMaxEntries(t *testing.T) {
+	dir := t.TempDir()
+	for i := range 25 {
+		if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("file%02d.txt", i)), []byte("x"), 0644); err != nil {
+			t.Fatalf("Failed to create file: %v", err)
+		}
+	}
+
+	check := func(t *testing.T, output string) {
+		t.Helper()
+		if got := strings.Count(output, "\n  - "); got != 10 {
+			t.Errorf("Expected 10 listed entries, got %d:\n%s", got, output)
+		}
+		if !strings.HasSuffix(output, "\n  ... (15 more entries)") {
+			t.Errorf("Expected a note about 15 more entries, got:\n%s", output)
+		}
+	}
+
+	opts := lsOptions{MaxEntries: 10}
+	t.Run("ls", func(t *testing.T) {
+		if _, err := FindBinary("ls"); err != nil {
+			t.Skip("ls not available")
+		}
+		output, err := listDirectoryWithLS(context.Background(), dir, opts)
+		if err != nil {
+			t.Fatalf("listDirectoryWithLS() error = %v", err)
+		}
+		check(t, output)
+		if !strings.Contains(output, "file00.txt") || strings.Contains(output, "file10.txt") {
+			t.Errorf("Expected the first entries by name, got:\n%s", output)
+		}
+	})
+
+	t.Run("sorted", func(t *testing.T) {
+		output, err := listDirectorySorted(dir, LSSortName, opts)
+		if err != nil {
+			t.Fatalf("listDirectorySorted() error = %v", err)
+		}
+		check(t, output)
+	})
+
+	t.Run("under cap", func(t *testing.T) {
+		output, err := listDirectorySorted(dir, LSSortName, lsOptions{MaxEntries: 25})
+		if err != nil {
+			t.Fatalf("listDirectorySorted() error = %v", err)
+		}
+		if strings.Contains(output, "more entries") {
+			t.Errorf("Did not expect a truncation note, got:\n%s", output)
+		}
+	})
+}
+
+func TestListDirectoryWithLSTimeout(t *testing.T) {
+	bin := t.TempDir()
+	if err := os.WriteFile(filepath.Join(bin, "ls"), []byte("#!/bin/sh\nexec sleep 30\n"), 0755); err != nil {
+		t.Fatalf("Failed to create fake ls: %v", err)
+	}
+	sleepDir := filepath.Dir(mustFindBinary(t, "sleep"))
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+sleepDir)
+
+	start := time.Now()
+	_, err := listDirectoryWithLS(context.Background(), t.TempDir(), lsOptions{Timeout: 100 * time.Millisecond})
+	if err == nil {
+		t.Fatal("Expected ls to time out")
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("Expected the timeout to stop ls, took %s", elapsed)
+	}
+}
+
+// mustFindBinary returns the path of a binary or skips the test.
+func mustFindBinary(t *testing.T, name string) string {
+	t.Helper()
+	path, err := FindBinary(name)
+	if err != nil {
+		t.Skipf("%s not available", name)
+	}
+	return path
+}
+
+func TestLSMultiplePaths(t *testing.T) {
+	root := t.TempDir()
+	dirs := []string{filepath.Join(root, "cmd"), filepath.Join(root, "internal"), filepath.Join(root, "docs")}
+	for i, dir := range dirs {
+		if err := os.Mkdir(dir, 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("file%d.txt", i)), []byte("x"), 0644); err != nil {
+			t.Fatalf("Failed to create file: %v", err)
+		}
+	}
+	callLS := func(t *testing.T, args map[string]any) (string, bool) {
+		t.Helper()
+		return callServerTool(t, CreateLSTool(&tools.Context{Validator: &mockValidator{}}), args)
+	}
+
+	text, isError := callLS(t, map[string]any{"paths": dirs})
+	if isError {
+		t.Fatalf("LS failed: %s", text)
+	}
+	sections := strings.Split(text, "\n\n")
+	if len(sections) != 3 {
+		t.Fatalf("Expected 3 listings, got %d:\n%s", len(sections), text)
+	}
+	for i, dir := range dirs {
+		if !strings.HasPrefix(sections[i], "- "+dir+"/\n") || !strings.Contains(sections[i], fmt.Sprintf("file%d.txt", i)) {
+			t.Errorf("Expected listing %d to be %s with its file, got:\n%s", i, dir, sections[i])
+		}
+	}
+
+	t.Run("missing directory", func(t *testing.T) {
+		missing := filepath.Join(root, "missing")
+		text, isError := callLS(t, map[string]any{"paths": []string{dirs[0], missing}})
+		if isError {
+			t.Fatalf("Expected the other directories to be listed, got error: %s", text)
+		}
+		if !strings.Contains(text, "- "+missing+"/\n  Error:") || !strings.Contains(text, "file0.txt") {
+			t.Errorf("Expected an error under the missing directory header, got:\n%s", text)
+		}
+	})
+
+	t.Run("forbidden path", func(t *testing.T) {
+		text, isError := callLS(t, map[string]any{"paths": []string{dirs[0], filepath.Join(root, "forbidden")}})
+		if !isError || !strings.Contains(text, "forbidden path") {
+			t.Errorf("Expected every path to be validated, got: %s", text)
+		}
+	})
+
+	t.Run("too many paths", func(t *testing.T) {
+		paths := make([]string, MaxLSPaths+1)
+		for i := range paths {
+			paths[i] = dirs[0]
+		}
+		text, isError := callLS(t, map[string]any{"paths": paths})
+		if !isError || !strings.Contains(text, fmt.Sprintf("at most %d entries", MaxLSPaths)) {
+			t.Errorf("Expected the number of paths to be limited, got: %s", text)
+		}
+	})
+
+	t.Run("path and paths", func(t *testing.T) {
+		text, isError := callLS(t, map[string]any{"path": dirs[0], "paths": dirs[1:]})
+		if !isError || !strings.Contains(text, "cannot be combined with path") {
+			t.Errorf("Expected path and paths to be exclusive, got: %s", text)
+		}
+	})
+}
@@ -33,17 +33,11 @@ func CreateMultiEditTool(ctx *tools.Context) *tools.ServerTool {
 
 		sanitizedPath, err := ctx.Validator.SanitizePath(args.FilePath)
 		if err != nil {
-			return &mcp.CallToolResultFor[any]{
-				Content: []mcp.Content{&mcp.TextContent{Text: "Error: Invalid file path: " + err.Error()}},
-				IsError: true,
-			}, nil
+			return tools.ValidationErrorResult("Invalid file path", err), nil
 		}
 
-		if err := ctx.Validator.ValidatePath(sanitizedPath); err != nil {
-			return &mcp.CallToolResultFor[any]{
-				Content: []mcp.Content{&mcp.TextContent{Text: "Error: Path validation failed: " + err.Error()}},
-				IsError: true,
-			}, nil
+		if err := ctx.Validator.ValidateWritePath(sanitizedPath); err != nil {
+			return tools.ValidationErrorResult("Path validation failed", err), nil
 		}
 
 		if len(args.Edits) == 0 {
@@ -69,7 +63,7 @@ func CreateMultiEditTool(ctx *tools.Context) *tools.ServerTool {
 			}
 		}
 
-		result, err := performMultiEdit(sanitizedPath, args.Edits)
+		result, err := performMultiEdit(sanitizedPath, args.Edits, newWriteOptions(ctx))
 		if err != nil {
 			return &mcp.CallToolResultFor[any]{
 				Content: []mcp.Content{&mcp.TextContent{Text: "Error: " + err.Error()}},
@@ -96,7 +90,8 @@ func CreateMultiEditTool(ctx *tools.Context) *tools.ServerTool {
 }
 
 // performMultiEdit performs multiple edits atomically on a file.
-func performMultiEdit(filePath string, edits []MultiEditOperation) (string, error) {
+// opts selects the write strategy and the retries on transient errors.
+func performMultiEdit(filePath string, edits []MultiEditOperation, opts writeOptions) (string, error) {
 	stat, err := os.Stat(filePath)
 	if err != nil {
 		return "", fmt.Errorf("failed to stat file: %w", err)
@@ -111,11 +106,6 @@ func performMultiEdit(filePath string, edits []MultiEditOperation) (string, erro
 		return "", fmt.Errorf("failed to read file: %w", err)
 	}
 
-	backupPath := filePath + ".backup"
-	if err := os.WriteFile(backupPath, originalContent, stat.Mode()); err != nil {
-		return "", fmt.Errorf("failed to create backup file: %w", err)
-	}
-
 	currentContent := string(originalContent)
 	totalReplacements := 0
 
@@ -131,12 +121,10 @@ func performMultiEdit(filePath string, edits []MultiEditOperation) (string, erro
 		} else {
 			occurrenceCount := strings.Count(currentContent, edit.OldString)
 			if occurrenceCount == 0 {
-				_ = os.Rename(backupPath, filePath)
 				return "", fmt.Errorf("edit %d: old_string not found in file", i+1)
 			}
 			if occurrenceCount > 1 {
-				_ = os.Rename(backupPath, filePath)
-				return "", fmt.Errorf("edit %d: old_string appears %d times in file - use replace_all=true or provide more context to make it unique", i+1, occurrenceCount)
+				return "", fmt.Errorf("edit %d: %w", i+1, ambiguousMatchError(currentContent, edit.OldString, occurrenceCount))
 			}
 
 			modifiedContent = strings.Replace(currentContent, edit.OldString, edit.NewString, 1)
@@ -144,7 +132,6 @@ func performMultiEdit(filePath string, edits []MultiEditOperation) (string, erro
 		}
 
 		if replacementCount == 0 {
-			_ = os.Rename(backupPath, filePath)
 			return "", fmt.Errorf("edit %d: old_string not found in file", i+1)
 		}
 
@@ -152,14 +139,9 @@ func performMultiEdit(filePath string, edits []MultiEditOperation) (string, erro
 		totalReplacements += replacementCount
 	}
 
-	if err := os.WriteFile(filePath, []byte(currentContent), stat.Mode()); err != nil {
-		if restoreErr := os.Rename(backupPath, filePath); restoreErr != nil {
-			return "", fmt.Errorf("failed to write file and failed to restore backup: write error: %w, restore error: %v", err, restoreErr)
-		}
-		return "", fmt.Errorf("failed to write file (backup restored): %w", err)
+	if err := replaceFileContent(filePath, originalContent, []byte(currentContent), stat.Mode(), opts); err != nil {
+		return "", err
 	}
 
-	_ = os.Remove(backupPath)
-
 	return fmt.Sprintf("Successfully applied %d edits with %d total replacements in %s", len(edits), totalReplacements, filePath), nil
 }
@@ -84,6 +84,22 @@ func TestPerformMultiEdit(t *testing.T) {
 			expectError:   true,
 			errorContains: "edit 2: old_string appears 2 times",
 		},
+		{
+			name:            "ambiguous edit lists occurrence lines",
+			originalContent: "package main\n\nvar x = 1\n\nfunc f() {\n\t// set\n\tvar x = 1\n}\n",
+			edits: []MultiEditOperation{
+				{
+					OldString: "package main",
+					NewString: "package app",
+				},
+				{
+					OldString: "var x = 1",
+					NewString: "var x = 2",
+				},
+			},
+			expectError:   true,
+			errorContains: "edit 2: old_string appears 2 times in file (at lines 3, 7)",
+		},
 		{
 			name:            "edit with string not found",
 			originalContent: "Hello world",
@@ -137,7 +153,7 @@ func TestPerformMultiEdit(t *testing.T) {
 			stat, _ := os.Stat(testFile)
 			originalMode := stat.Mode()
 
-			result, err := performMultiEdit(testFile, tt.edits)
+			result, err := performMultiEdit(testFile, tt.edits, writeOptions{})
 
 			if tt.expectError {
 				if err == nil {
@@ -227,7 +243,7 @@ func TestMultiEditBackupAndRestore(t *testing.T) {
 			},
 		}
 
-		_, err := performMultiEdit(testFile, edits)
+		_, err := performMultiEdit(testFile, edits, writeOptions{})
 		if err == nil {
 			t.Error("Expected error for missing string")
 			return
@@ -303,7 +319,7 @@ func TestMultiEditErrors(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			testPath := tt.setupFunc()
 
-			_, err := performMultiEdit(testPath, tt.edits)
+			_, err := performMultiEdit(testPath, tt.edits, writeOptions{})
 
 			if tt.expectError == "" {
 				// Special case for empty edits - performMultiEdit might accept it
@@ -354,7 +370,7 @@ func TestCreateMultiEditTool(t *testing.T) {
 		{OldString: "test", NewString: "example"},
 	}
 
-	result, err := performMultiEdit(testFile, edits)
+	result, err := performMultiEdit(testFile, edits, writeOptions{})
 	if err != nil {
 		t.Errorf("Tool function failed: %v", err)
 	}
@@ -395,7 +411,7 @@ func TestMultiEditAtomicity(t *testing.T) {
 			{OldString: "line3", NewString: "third"},
 		}
 
-		result, err := performMultiEdit(testFile, edits)
+		result, err := performMultiEdit(testFile, edits, writeOptions{})
 		if err != nil {
 			t.Errorf("Multi-edit failed: %v", err)
 			return
@@ -430,7 +446,7 @@ func TestMultiEditAtomicity(t *testing.T) {
 			{OldString: "nonexistent", NewString: "fail"}, // This will fail
 		}
 
-		_, err := performMultiEdit(testFile, edits)
+		_, err := performMultiEdit(testFile, edits, writeOptions{})
 		if err == nil {
 			t.Error("Expected error for nonexistent string")
 			return
@@ -513,7 +529,7 @@ func TestMultiEditEdgeCases(t *testing.T) {
 				t.Fatalf("Failed to create test file: %v", err)
 			}
 
-			_, err := performMultiEdit(testFile, tt.edits)
+			_, err := performMultiEdit(testFile, tt.edits, writeOptions{})
 
 			// Special case for "multiple edits on same line" which should fail
 			if tt.name == "multiple edits on same line" {
@@ -566,6 +582,10 @@ func (m *mockMultiEditValidator) ValidatePath(path string) error {
 	return nil
 }
 
+func (m *mockMultiEditValidator) ValidateWritePath(path string) error {
+	return m.ValidatePath(path)
+}
+
 func (m *mockMultiEditValidator) ValidateContent(content []byte) error {
 	return nil
 }
@@ -577,3 +597,31 @@ func (m *mockMultiEditValidator) ValidateCommand(cmd string, args []string) erro
 func (m *mockMultiEditValidator) ValidateURL(url string) error {
 	return nil
 }
+
+func TestOccurrenceLines(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		substr  string
+		want    []int
+	}{
+		{"single line matches", "a\nfoo\nb\nfoo foo\n", "foo", []int{2, 4, 4}},
+		{"multi-line substring", "x\ny\nx\ny\n", "x\ny", []int{1, 3}},
+		{"no match", "abc", "z", nil},
+		{"empty substring", "abc", "", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := occurrenceLines(tt.content, tt.substr)
+			if len(got) != len(tt.want) {
+				t.Fatalf("occurrenceLines() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("occurrenceLines() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
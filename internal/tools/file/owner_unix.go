@@ -0,0 +1,18 @@
+//go:build unix
+
+// Package file provides file ownership lookups on Unix systems.
+package file
+
+import (
+	"os"
+	"syscall"
+)
+
+// ownerOf returns the owner and hard link count of a file.
+func ownerOf(info os.FileInfo) (fileOwner, bool) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return fileOwner{}, false
+	}
+	return fileOwner{uid: int(stat.Uid), gid: int(stat.Gid), links: uint64(stat.Nlink)}, true
+}
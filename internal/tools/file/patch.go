@@ -0,0 +1,229 @@
+// Package file provides file operation tools using the MCP SDK patterns.
+package file
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/d-kuro/claude-code-mcp/internal/prompts"
+	"github.com/d-kuro/claude-code-mcp/internal/tools"
+)
+
+// ApplyPatchArgs represents the arguments for the ApplyPatch tool.
+type ApplyPatchArgs struct {
+	Patch string  `json:"patch"`
+	Path  *string `json:"path,omitempty"`
+}
+
+// patchAction is the kind of change a patch makes to a file.
+type patchAction byte
+
+const (
+	patchModify patchAction = 'M'
+	patchCreate patchAction = 'A'
+	patchDelete patchAction = 'D'
+)
+
+// patchChange holds the planned change to a single file.
+type patchChange struct {
+	Path     string
+	Action   patchAction
+	Mode     os.FileMode
+	Original []byte
+	Modified []byte
+}
+
+// errPatchConflict is returned when two sections of a patch change the same file.
+var errPatchConflict = errors.New("the patch changes the file more than once")
+
+// CreateApplyPatchTool creates the ApplyPatch tool using MCP SDK patterns.
+func CreateApplyPatchTool(ctx *tools.Context) *tools.ServerTool {
+	handler := func(ctxReq context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[ApplyPatchArgs]) (*mcp.CallToolResultFor[any], error) {
+		args := params.Arguments
+
+		if strings.TrimSpace(args.Patch) == "" {
+			return tools.EmptyFieldError("patch"), nil
+		}
+
+		baseDir := ""
+		if args.Path != nil && *args.Path != "" {
+			baseDir = *args.Path
+		}
+		if !filepath.IsAbs(baseDir) {
+			cwd, err := os.Getwd()
+			if err != nil {
+				return tools.ErrorResponsef("Failed to get current working directory: %v", err), nil
+			}
+			baseDir = filepath.Join(cwd, baseDir)
+		}
+
+		patches, err := parsePatch(args.Patch)
+		if err != nil {
+			return tools.InvalidFieldError("patch", err.Error()), nil
+		}
+
+		var changes []patchChange
+		seen := make(map[string]bool, len(patches))
+		for _, fp := range patches {
+			path, err := patchTargetPath(baseDir, fp)
+			if err != nil {
+				return tools.ErrorResponse(err.Error()), nil
+			}
+
+			sanitizedPath, err := ctx.Validator.SanitizePath(path)
+			if err != nil {
+				return tools.ValidationErrorResult("Invalid file path", err), nil
+			}
+
+			if err := ctx.Validator.ValidateWritePath(sanitizedPath); err != nil {
+				return tools.ValidationErrorResult(fmt.Sprintf("Path validation failed for %s", sanitizedPath), err), nil
+			}
+
+			if seen[sanitizedPath] {
+				return tools.ErrorResponsef("%s: %v", sanitizedPath, errPatchConflict), nil
+			}
+			seen[sanitizedPath] = true
+
+			change, err := planPatch(sanitizedPath, fp, ctx.NewFileMode())
+			if err != nil {
+				return tools.ErrorResponsef("%s: %v (no files were changed)", sanitizedPath, err), nil
+			}
+			changes = append(changes, change)
+		}
+
+		if err := applyPatchChanges(changes, newWriteOptions(ctx), ctx.NewDirMode()); err != nil {
+			return tools.ErrorResponse(err.Error()), nil
+		}
+
+		return tools.SuccessResponse(formatPatchSummary(changes)), nil
+	}
+
+	tool := &mcp.Tool{
+		Name:        "ApplyPatch",
+		Description: prompts.ApplyPatchToolDoc,
+	}
+
+	return &tools.ServerTool{
+		Tool: tool,
+		RegisterFunc: func(server *mcp.Server) {
+			mcp.AddTool(server, tool, handler)
+		},
+	}
+}
+
+// patchTargetPath resolves the file a patch applies to against the base directory.
+func patchTargetPath(baseDir string, fp filePatch) (string, error) {
+	if fp.OldPath == devNull && fp.NewPath == devNull {
+		return "", fmt.Errorf("patch has no file path")
+	}
+	if fp.OldPath != devNull && fp.NewPath != devNull && fp.OldPath != fp.NewPath {
+		return "", fmt.Errorf("renaming %s to %s is not supported", fp.OldPath, fp.NewPath)
+	}
+
+	path := fp.displayPath()
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(baseDir, path)
+	}
+	return path, nil
+}
+
+// planPatch computes the new content of the file without writing it.
+func planPatch(path string, fp filePatch, fileMode os.FileMode) (patchChange, error) {
+	change := patchChange{Path: path, Action: patchModify, Mode: fileMode}
+
+	switch {
+	case fp.OldPath == devNull:
+		change.Action = patchCreate
+		if _, err := os.Lstat(path); err == nil {
+			return change, fmt.Errorf("cannot create file: it already exists")
+		}
+	case fp.NewPath == devNull:
+		change.Action = patchDelete
+	}
+
+	if change.Action != patchCreate {
+		stat, err := os.Stat(path)
+		if err != nil {
+			return change, fmt.Errorf("failed to stat file: %w", err)
+		}
+		if stat.IsDir() {
+			return change, fmt.Errorf("path is a directory")
+		}
+		change.Mode = stat.Mode().Perm()
+
+		change.Original, err = os.ReadFile(path)
+		if err != nil {
+			return change, fmt.Errorf("failed to read file: %w", err)
+		}
+	}
+
+	modified, err := applyHunks(string(change.Original), fp.Hunks)
+	if err != nil {
+		return change, err
+	}
+	if change.Action == patchDelete && modified != "" {
+		return change, fmt.Errorf("cannot delete file: the patch does not remove all of its content")
+	}
+	change.Modified = []byte(modified)
+
+	return change, nil
+}
+
+// applyPatchChanges writes every planned change. If a change fails, the
+// changes already made are undone.
+func applyPatchChanges(changes []patchChange, opts writeOptions, dirMode os.FileMode) error {
+	for i, change := range changes {
+		if err := applyPatchChange(change, opts, dirMode); err != nil {
+			reverted := 0
+			for _, done := range changes[:i] {
+				if revertPatchChange(done) == nil {
+					reverted++
+				}
+			}
+			return fmt.Errorf("failed to update %s: %w (reverted %d of %d file(s) already changed)", change.Path, err, reverted, i)
+		}
+	}
+	return nil
+}
+
+// applyPatchChange writes a single planned change.
+func applyPatchChange(change patchChange, opts writeOptions, dirMode os.FileMode) error {
+	switch change.Action {
+	case patchCreate:
+		_, err := writeFileContent(change.Path, string(change.Modified), change.Mode, dirMode, opts)
+		return err
+	case patchDelete:
+		return os.Remove(change.Path)
+	default:
+		return replaceFileContent(change.Path, change.Original, change.Modified, change.Mode, opts)
+	}
+}
+
+// revertPatchChange undoes a change made by applyPatchChange.
+func revertPatchChange(change patchChange) error {
+	switch change.Action {
+	case patchCreate:
+		return os.Remove(change.Path)
+	case patchDelete:
+		return os.WriteFile(change.Path, change.Original, change.Mode)
+	default:
+		return replaceFileContent(change.Path, change.Modified, change.Original, change.Mode, writeOptions{})
+	}
+}
+
+// formatPatchSummary lists the files changed by a patch, each prefixed with
+// A (added), M (modified) or D (deleted).
+func formatPatchSummary(changes []patchChange) string {
+	var output strings.Builder
+	fmt.Fprintf(&output, "Applied patch to %d file(s):", len(changes))
+	for _, change := range changes {
+		fmt.Fprintf(&output, "\n%c %s", change.Action, change.Path)
+	}
+	return output.String()
+}
@@ -0,0 +1,232 @@
+package file
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/d-kuro/claude-code-mcp/internal/tools"
+)
+
+func writeTestFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create file: %v", err)
+		}
+	}
+}
+
+func assertFileContent(t *testing.T, path, want string) {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", path, err)
+	}
+	if string(data) != want {
+		t.Errorf("Unexpected content of %s:\n%q\nwant:\n%q", path, data, want)
+	}
+}
+
+func TestApplyPatchClean(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"main.go": "package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Println(\"hello\")\n}\n",
+	})
+
+	patch := `diff --git a/main.go b/main.go
+index 1111111..2222222 100644
+--- a/main.go
++++ b/main.go
+@@ -3,5 +3,6 @@ package main
+ import "fmt"
+
+ func main() {
+-	fmt.Println("hello")
++	fmt.Println("hello, world")
++	fmt.Println("bye")
+ }
+`
+
+	text, isError := callServerTool(t, CreateApplyPatchTool(&tools.Context{Validator: &mockValidator{}}), map[string]any{"patch": patch, "path": dir})
+	if isError {
+		t.Fatalf("Expected success, got error: %s", text)
+	}
+
+	mainPath := filepath.Join(dir, "main.go")
+	if text != "Applied patch to 1 file(s):\nM "+mainPath {
+		t.Errorf("Unexpected summary: %s", text)
+	}
+	assertFileContent(t, mainPath, "package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Println(\"hello, world\")\n\tfmt.Println(\"bye\")\n}\n")
+}
+
+func TestApplyPatchContextMismatchRollsBack(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"a.txt": "one\ntwo\nthree\n",
+		"b.txt": "alpha\nbeta\ngamma\n",
+	}
+	writeTestFiles(t, dir, files)
+
+	// The first file applies cleanly, the second does not match
+	patch := `--- a.txt
++++ a.txt
+@@ -1,3 +1,3 @@
+ one
+-two
++TWO
+ three
+--- b.txt
++++ b.txt
+@@ -1,3 +1,3 @@
+ alpha
+-delta
++DELTA
+ gamma
+`
+
+	text, isError := callServerTool(t, CreateApplyPatchTool(&tools.Context{Validator: &mockValidator{}}), map[string]any{"patch": patch, "path": dir})
+	if !isError {
+		t.Fatalf("Expected a context mismatch error, got: %s", text)
+	}
+	for _, want := range []string{"b.txt", "hunk 1 (@@ -1,3 +1,3 @@) does not apply", "no files were changed"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected error to contain %q, got: %s", want, text)
+		}
+	}
+
+	for name, content := range files {
+		assertFileContent(t, filepath.Join(dir, name), content)
+	}
+}
+
+func TestApplyPatchMultiFile(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"pkg/util.go":  "package pkg\n\nfunc A() {}\n\nfunc B() {}\n\nfunc C() {}\n",
+		"obsolete.txt": "remove me\n",
+	})
+
+	patch := `--- a/pkg/util.go
++++ b/pkg/util.go
+@@ -1,3 +1,3 @@
+-package pkg
++package util
+
+ func A() {}
+@@ -5,3 +5,5 @@
+ func B() {}
+
+ func C() {}
++
++func D() {}
+--- a/obsolete.txt
++++ /dev/null
+@@ -1 +0,0 @@
+-remove me
+--- /dev/null
++++ b/docs/NEW.md
+@@ -0,0 +1,2 @@
++# New
++No trailing newline
+\ No newline at end of file
+`
+
+	text, isError := callServerTool(t, CreateApplyPatchTool(&tools.Context{Validator: &mockValidator{}}), map[string]any{"patch": patch, "path": dir})
+	if isError {
+		t.Fatalf("Expected success, got error: %s", text)
+	}
+
+	expectedSummary := "Applied patch to 3 file(s):\n" +
+		"M " + filepath.Join(dir, "pkg/util.go") + "\n" +
+		"D " + filepath.Join(dir, "obsolete.txt") + "\n" +
+		"A " + filepath.Join(dir, "docs/NEW.md")
+	if text != expectedSummary {
+		t.Errorf("Expected summary:\n%s\ngot:\n%s", expectedSummary, text)
+	}
+
+	assertFileContent(t, filepath.Join(dir, "pkg/util.go"), "package util\n\nfunc A() {}\n\nfunc B() {}\n\nfunc C() {}\n\nfunc D() {}\n")
+	assertFileContent(t, filepath.Join(dir, "docs/NEW.md"), "# New\nNo trailing newline")
+	if _, err := os.Stat(filepath.Join(dir, "obsolete.txt")); !os.IsNotExist(err) {
+		t.Errorf("Expected obsolete.txt to be deleted")
+	}
+}
+
+func TestApplyPatchErrors(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"a.txt": "one\n"})
+
+	tests := []struct {
+		name    string
+		patch   string
+		wantErr string
+	}{
+		{"empty patch", "", "patch"},
+		{"not a diff", "just some text\n", "no file changes found"},
+		{"truncated hunk", "--- a.txt\n+++ a.txt\n@@ -1,3 +1,3 @@\n one\n", "truncated"},
+		{"rename", "--- a.txt\n+++ b.txt\n@@ -1 +1 @@\n-one\n+two\n", "not supported"},
+		{"create existing file", "--- /dev/null\n+++ a.txt\n@@ -0,0 +1 @@\n+new\n", "already exists"},
+		{"forbidden path", "--- forbidden/x.txt\n+++ forbidden/x.txt\n@@ -1 +1 @@\n-a\n+b\n", "Path validation failed"},
+		{"same file twice", "--- a.txt\n+++ a.txt\n@@ -1 +1 @@\n-one\n+two\n--- a.txt\n+++ a.txt\n@@ -1 +1 @@\n-two\n+three\n", "more than once"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, isError := callServerTool(t, CreateApplyPatchTool(&tools.Context{Validator: &mockValidator{}}), map[string]any{"patch": tt.patch, "path": dir})
+			if !isError {
+				t.Fatalf("Expected error, got: %s", text)
+			}
+			if !strings.Contains(text, tt.wantErr) {
+				t.Errorf("Expected error containing %q, got: %s", tt.wantErr, text)
+			}
+		})
+	}
+
+	assertFileContent(t, filepath.Join(dir, "a.txt"), "one\n")
+}
+
+func TestApplyHunksOffset(t *testing.T) {
+	content := "header\nextra\nline 1\nline 2\nline 3\n"
+	patches, err := parsePatch("--- f\n+++ f\n@@ -1,2 +1,3 @@\n line 1\n+inserted\n line 2\n")
+	if err != nil {
+		t.Fatalf("parsePatch failed: %v", err)
+	}
+
+	got, err := applyHunks(content, patches[0].Hunks)
+	if err != nil {
+		t.Fatalf("applyHunks failed: %v", err)
+	}
+	if want := "header\nextra\nline 1\ninserted\nline 2\nline 3\n"; got != want {
+		t.Errorf("Expected hunk to apply at its shifted position:\n%q\ngot:\n%q", want, got)
+	}
+}
+
+func TestApplyPatchChangesRevertsOnWriteFailure(t *testing.T) {
+	dir := t.TempDir()
+	modified := filepath.Join(dir, "modified.txt")
+	created := filepath.Join(dir, "created.txt")
+	writeTestFiles(t, dir, map[string]string{"modified.txt": "old\n"})
+
+	changes := []patchChange{
+		{Path: modified, Action: patchModify, Mode: 0644, Original: []byte("old\n"), Modified: []byte("new\n")},
+		{Path: created, Action: patchCreate, Mode: 0644, Modified: []byte("created\n")},
+		{Path: filepath.Join(dir, "missing.txt"), Action: patchDelete, Mode: 0644, Original: []byte("gone\n")},
+	}
+
+	err := applyPatchChanges(changes, writeOptions{}, 0755)
+	if err == nil || !strings.Contains(err.Error(), "reverted 2 of 2 file(s)") {
+		t.Fatalf("Expected a write failure with both changes reverted, got: %v", err)
+	}
+
+	assertFileContent(t, modified, "old\n")
+	if _, err := os.Stat(created); !os.IsNotExist(err) {
+		t.Errorf("Expected created file to be removed on rollback")
+	}
+}
@@ -3,12 +3,16 @@ package file
 
 import (
 	"bufio"
+	"bytes"
+	"compress/gzip"
 	"context"
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 
@@ -27,15 +31,45 @@ const (
 	DefaultMaxLines = 2000
 	// Maximum line length before truncation
 	MaxLineLength = 2000
+	// Upper bound for a caller-provided line limit
+	MaxReadLines = 100000
+	// Upper bound for a caller-provided maximum line length
+	MaxReadLineLength = 100000
+	// Number of lines read between context cancellation checks
+	contextCheckInterval = 1024
+	// Maximum decompressed size of a gzip file before reading is aborted (100MB)
+	MaxDecompressedSize = 100 * 1024 * 1024
+	// Default number of lines shown on each side of an anchor
+	DefaultAnchorContextLines = 20
+	// Files larger than this are read in chunks with bounded memory (50MB)
+	DefaultChunkedReadThreshold = 50 * 1024 * 1024
 )
 
+// gzipMagic is the header that identifies gzip-compressed data.
+var gzipMagic = []byte{0x1f, 0x8b}
+
 // ReadArgs represents the arguments for the Read tool.
 type ReadArgs struct {
 	FilePath string `json:"file_path"`
 	Offset   *int   `json:"offset,omitempty"`
 	Limit    *int   `json:"limit,omitempty"`
+	// MaxLineLength overrides the length at which long lines are truncated.
+	MaxLineLength *int `json:"max_line_length,omitempty"`
+	// Strict fails the read when the file is not valid UTF-8, instead of
+	// replacing the invalid bytes.
+	Strict *bool `json:"strict,omitempty"`
+	// Anchor reads the lines around the first line containing this text,
+	// ContextLines on each side, instead of using offset and limit.
+	Anchor       *string `json:"anchor,omitempty"`
+	ContextLines *int    `json:"context_lines,omitempty"`
 }
 
+// invalidUTF8Warning is appended to Read output in which invalid UTF-8 was replaced.
+const invalidUTF8Warning = "\n\n<system-reminder>\nWARNING: This file contains invalid UTF-8 byte sequences; they were replaced with the Unicode replacement character (U+FFFD).\n</system-reminder>"
+
+// chunkedReadNote is appended to Read output of files read in chunks.
+const chunkedReadNote = "\n\n<system-reminder>\nNOTE: This file is %d bytes, over the large-file threshold of %d bytes, so it was read in chunks and lines were truncated while reading. Use offset and limit to read other parts of it.\n</system-reminder>"
+
 // CreateReadTool creates the Read tool using MCP SDK patterns.
 func CreateReadTool(ctx *tools.Context) *tools.ServerTool {
 	handler := func(ctxReq context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[ReadArgs]) (*mcp.CallToolResultFor[any], error) {
@@ -43,20 +77,28 @@ func CreateReadTool(ctx *tools.Context) *tools.ServerTool {
 
 		sanitizedPath, err := ctx.Validator.SanitizePath(args.FilePath)
 		if err != nil {
-			return &mcp.CallToolResultFor[any]{
-				Content: []mcp.Content{&mcp.TextContent{Text: "Error: Invalid file path: " + err.Error()}},
-				IsError: true,
-			}, nil
+			return tools.ValidationErrorResult("Invalid file path", err), nil
 		}
 
 		if err := ctx.Validator.ValidatePath(sanitizedPath); err != nil {
-			return &mcp.CallToolResultFor[any]{
-				Content: []mcp.Content{&mcp.TextContent{Text: "Error: Path validation failed: " + err.Error()}},
-				IsError: true,
-			}, nil
+			return tools.ValidationErrorResult("Path validation failed", err), nil
+		}
+
+		offset, limit := args.Offset, args.Limit
+		if args.Anchor != nil {
+			if offset != nil || limit != nil {
+				return tools.InvalidFieldError("anchor", "cannot be combined with offset or limit"), nil
+			}
+			offset, limit, err = anchorWindow(ctxReq, sanitizedPath, *args.Anchor, args.ContextLines)
+			if err != nil {
+				return tools.ErrorResponse(err.Error()), nil
+			}
 		}
 
-		content, err := readFileContent(sanitizedPath, args.Offset, args.Limit)
+		content, err := readFileContentWithThreshold(ctxReq, sanitizedPath, offset, limit, args.MaxLineLength, ctx.ChunkedReadThreshold)
+		if err == nil {
+			content, err = ensureValidUTF8(content, args.Strict != nil && *args.Strict)
+		}
 		if err != nil {
 			return &mcp.CallToolResultFor[any]{
 				Content: []mcp.Content{&mcp.TextContent{Text: "Error: " + err.Error()}},
@@ -82,9 +124,25 @@ func CreateReadTool(ctx *tools.Context) *tools.ServerTool {
 	}
 }
 
-// readFileContent reads file content with support for offset and limit.
+// readFileContent reads file content with support for offset, limit, and line length.
 // Uses optimized strategies based on file size for better performance.
-func readFileContent(filePath string, offset *int, limit *int) (string, error) {
+// Reading stops with the context's error when ctx is cancelled.
+func readFileContent(ctx context.Context, filePath string, offset *int, limit *int, maxLineLength *int) (string, error) {
+	return readFileContentWithThreshold(ctx, filePath, offset, limit, maxLineLength, DefaultChunkedReadThreshold)
+}
+
+// readFileContentWithThreshold is readFileContent with files larger than
+// chunkThreshold bytes read in chunks with bounded memory, followed by a
+// note. A zero chunkThreshold uses DefaultChunkedReadThreshold.
+func readFileContentWithThreshold(ctx context.Context, filePath string, offset *int, limit *int, maxLineLength *int, chunkThreshold int64) (string, error) {
+	if limit != nil && (*limit < 1 || *limit > MaxReadLines) {
+		return "", fmt.Errorf("limit must be between 1 and %d", MaxReadLines)
+	}
+
+	if maxLineLength != nil && (*maxLineLength < 1 || *maxLineLength > MaxReadLineLength) {
+		return "", fmt.Errorf("max_line_length must be between 1 and %d", MaxReadLineLength)
+	}
+
 	file, err := os.Open(filePath)
 	if err != nil {
 		return "", fmt.Errorf("failed to open file: %w", err)
@@ -119,18 +177,159 @@ func readFileContent(filePath string, offset *int, limit *int) (string, error) {
 		maxLines = *limit
 	}
 
+	lineLength := MaxLineLength
+	if maxLineLength != nil {
+		lineLength = *maxLineLength
+	}
+
+	// Gzip files are decompressed transparently; their size is unknown, so stream them
+	reader, compressed, err := decompressReader(file, MaxDecompressedSize)
+	if err != nil {
+		return "", err
+	}
+	if compressed {
+		return readLargeFile(ctx, reader, startOffset, maxLines, lineLength)
+	}
+
+	if chunkThreshold <= 0 {
+		chunkThreshold = DefaultChunkedReadThreshold
+	}
+	if fileSize > chunkThreshold {
+		content, err := readChunkedFile(ctx, file, startOffset, maxLines, lineLength)
+		if err != nil {
+			return "", err
+		}
+		return content + fmt.Sprintf(chunkedReadNote, fileSize, chunkThreshold), nil
+	}
+
 	// Choose strategy based on file size and memory constraints
-	if fileSize > LargeFileThreshold || (int64(maxLines)*MaxLineLength) > MaxMemoryUsage {
-		return readLargeFile(file, startOffset, maxLines)
+	if fileSize > LargeFileThreshold || int64(maxLines)*int64(lineLength) > MaxMemoryUsage {
+		return readLargeFile(ctx, file, startOffset, maxLines, lineLength)
+	}
+
+	return readSmallFile(ctx, file, startOffset, maxLines, lineLength)
+}
+
+// anchorWindow returns the offset and limit of the lines around the first line
+// of the file that contains anchor, with contextLines lines on each side.
+func anchorWindow(ctx context.Context, filePath, anchor string, contextLines *int) (*int, *int, error) {
+	if anchor == "" {
+		return nil, nil, fmt.Errorf("anchor cannot be empty")
+	}
+
+	lines := DefaultAnchorContextLines
+	if contextLines != nil {
+		lines = *contextLines
+	}
+	if lines < 0 || 2*lines+1 > MaxReadLines {
+		return nil, nil, fmt.Errorf("context_lines must be between 0 and %d", (MaxReadLines-1)/2)
+	}
+
+	anchorLine, err := findAnchorLine(ctx, filePath, anchor)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	offset := max(anchorLine-lines, 0)
+	limit := anchorLine - offset + lines + 1
+	return &offset, &limit, nil
+}
+
+// findAnchorLine returns the zero-based index of the first line of the file
+// that contains anchor.
+func findAnchorLine(ctx context.Context, filePath, anchor string) (int, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	if stat, err := file.Stat(); err == nil && stat.IsDir() {
+		return 0, fmt.Errorf("path is a directory, not a file")
+	}
+
+	source, _, err := decompressReader(file, MaxDecompressedSize)
+	if err != nil {
+		return 0, err
+	}
+	reader := bufio.NewReaderSize(source, DefaultBufferSize)
+
+	for index := 0; ; index++ {
+		if index%contextCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return 0, fmt.Errorf("read cancelled: %w", err)
+			}
+		}
+
+		line, err := reader.ReadString('\n')
+		if strings.Contains(line, anchor) {
+			return index, nil
+		}
+		if err == io.EOF {
+			return 0, fmt.Errorf("anchor not found: no line contains %q", anchor)
+		}
+		if err != nil {
+			return 0, fmt.Errorf("error reading file: %w", err)
+		}
+	}
+}
+
+// decompressReader returns a gzip reader for files with a .gz extension whose
+// content starts with the gzip magic bytes. Decompressed output is capped at
+// maxSize bytes. Other files are returned unchanged with compressed set to false.
+func decompressReader(file *os.File, maxSize int64) (reader io.Reader, compressed bool, err error) {
+	if !strings.EqualFold(filepath.Ext(file.Name()), ".gz") {
+		return file, false, nil
+	}
+
+	header := make([]byte, len(gzipMagic))
+	n, err := io.ReadFull(file, header)
+	if _, seekErr := file.Seek(0, io.SeekStart); seekErr != nil {
+		return nil, false, fmt.Errorf("failed to rewind file: %w", seekErr)
+	}
+	if err != nil || n != len(gzipMagic) || !bytes.Equal(header, gzipMagic) {
+		return file, false, nil
 	}
 
-	return readSmallFile(file, startOffset, maxLines)
+	gzipReader, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to open gzip file: %w", err)
+	}
+
+	return &cappedReader{r: gzipReader, limit: maxSize}, true, nil
+}
+
+// cappedReader fails once more than limit bytes have been read, guarding
+// against decompression bombs.
+type cappedReader struct {
+	r     io.Reader
+	limit int64
+	read  int64
+}
+
+func (c *cappedReader) Read(p []byte) (int, error) {
+	if c.read > c.limit {
+		return 0, fmt.Errorf("decompressed content exceeds %d bytes", c.limit)
+	}
+	// Allow one byte past the limit so that hitting it exactly is not an error
+	if remaining := c.limit - c.read + 1; int64(len(p)) > remaining {
+		p = p[:remaining]
+	}
+	n, err := c.r.Read(p)
+	c.read += int64(n)
+	if c.read > c.limit {
+		return 0, fmt.Errorf("decompressed content exceeds %d bytes", c.limit)
+	}
+	return n, err
 }
 
 // readSmallFile optimally reads smaller files into memory using strings.Builder
-func readSmallFile(file *os.File, startOffset, maxLines int) (string, error) {
+func readSmallFile(ctx context.Context, file io.Reader, startOffset, maxLines, maxLineLength int) (string, error) {
 	scanner := bufio.NewScanner(file)
-	scanner.Buffer(make([]byte, DefaultBufferSize), DefaultBufferSize)
+	// Small files are at most LargeFileThreshold bytes, so any line fits in the buffer
+	scanner.Buffer(make([]byte, DefaultBufferSize), LargeFileThreshold+1)
 
 	var builder strings.Builder
 	lineNumber := 1
@@ -141,11 +340,15 @@ func readSmallFile(file *os.File, startOffset, maxLines int) (string, error) {
 	builder.Grow(maxLines * 100) // Estimate 100 chars per line
 
 	for scanner.Scan() && linesRead < maxLines {
+		if currentOffset%contextCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return "", fmt.Errorf("read cancelled: %w", err)
+			}
+		}
+
 		if currentOffset >= startOffset {
 			line := scanner.Text()
-			if len(line) > MaxLineLength {
-				line = line[:MaxLineLength] + "... (truncated)"
-			}
+			line = truncateLine(line, maxLineLength)
 
 			if linesRead > 0 {
 				builder.WriteByte('\n')
@@ -167,7 +370,7 @@ func readSmallFile(file *os.File, startOffset, maxLines int) (string, error) {
 }
 
 // readLargeFile uses streaming approach for large files with controlled memory usage
-func readLargeFile(file *os.File, startOffset, maxLines int) (string, error) {
+func readLargeFile(ctx context.Context, file io.Reader, startOffset, maxLines, maxLineLength int) (string, error) {
 	reader := bufio.NewReaderSize(file, DefaultBufferSize)
 	var builder strings.Builder
 
@@ -179,14 +382,18 @@ func readLargeFile(file *os.File, startOffset, maxLines int) (string, error) {
 	builder.Grow(maxLines * 80)
 
 	for linesRead < maxLines {
+		if currentOffset%contextCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return "", fmt.Errorf("read cancelled: %w", err)
+			}
+		}
+
 		line, err := reader.ReadString('\n')
 		if err != nil {
 			if err == io.EOF {
 				// Handle last line without newline
 				if len(line) > 0 && currentOffset >= startOffset {
-					if len(line) > MaxLineLength {
-						line = line[:MaxLineLength] + "... (truncated)"
-					}
+					line = truncateLine(line, maxLineLength)
 
 					if linesRead > 0 {
 						builder.WriteByte('\n')
@@ -204,9 +411,7 @@ func readLargeFile(file *os.File, startOffset, maxLines int) (string, error) {
 		}
 
 		if currentOffset >= startOffset {
-			if len(line) > MaxLineLength {
-				line = line[:MaxLineLength] + "... (truncated)"
-			}
+			line = truncateLine(line, maxLineLength)
 
 			if linesRead > 0 {
 				builder.WriteByte('\n')
@@ -223,6 +428,93 @@ func readLargeFile(file *os.File, startOffset, maxLines int) (string, error) {
 	return builder.String(), nil
 }
 
+// readChunkedFile reads the requested lines of a very large file in chunks of
+// DefaultBufferSize bytes. Unlike readLargeFile it never holds a whole line in
+// memory: lines before startOffset are discarded as they are read and only
+// the start of each returned line is kept, so memory use is bounded by the
+// buffer and the output regardless of the file size and line lengths.
+func readChunkedFile(ctx context.Context, file io.Reader, startOffset, maxLines, maxLineLength int) (string, error) {
+	reader := bufio.NewReaderSize(file, DefaultBufferSize)
+	var builder strings.Builder
+
+	// Keep a few bytes past the limit so truncateLine can tell the line was
+	// longer and cut it at a character boundary
+	keep := maxLineLength + utf8.UTFMax
+	line := make([]byte, 0, min(keep, DefaultBufferSize))
+	lineSize := 0
+	index := 0
+	linesRead := 0
+
+	for linesRead < maxLines {
+		if lineSize == 0 && index%contextCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return "", fmt.Errorf("read cancelled: %w", err)
+			}
+		}
+
+		chunk, err := reader.ReadSlice('\n')
+		if err != nil && err != bufio.ErrBufferFull && err != io.EOF {
+			return "", fmt.Errorf("error reading file: %w", err)
+		}
+		lineSize += len(chunk)
+		if index >= startOffset && len(line) < keep {
+			line = append(line, chunk[:min(len(chunk), keep-len(line))]...)
+		}
+
+		if err == bufio.ErrBufferFull {
+			// The line continues in the next chunk
+			continue
+		}
+		if err == io.EOF && lineSize == 0 {
+			break
+		}
+
+		if index >= startOffset {
+			if linesRead > 0 {
+				builder.WriteByte('\n')
+			}
+			text := strings.TrimSuffix(string(line), "\n")
+			writeFormattedLine(&builder, index+1, truncateLine(text, maxLineLength))
+			linesRead++
+		}
+
+		if err == io.EOF {
+			break
+		}
+		line = line[:0]
+		lineSize = 0
+		index++
+	}
+
+	return builder.String(), nil
+}
+
+// truncateLine shortens lines longer than maxLineLength bytes, without
+// splitting a multi-byte character.
+func truncateLine(line string, maxLineLength int) string {
+	if len(line) > maxLineLength {
+		cut := maxLineLength
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		return line[:cut] + "... (truncated)"
+	}
+	return line
+}
+
+// ensureValidUTF8 replaces invalid UTF-8 byte sequences in content with
+// U+FFFD and appends a warning, so that the output can be encoded as JSON
+// text. In strict mode invalid content is an error instead.
+func ensureValidUTF8(content string, strict bool) (string, error) {
+	if utf8.ValidString(content) {
+		return content, nil
+	}
+	if strict {
+		return "", fmt.Errorf("file contains invalid UTF-8 byte sequences")
+	}
+	return strings.ToValidUTF8(content, string(utf8.RuneError)) + invalidUTF8Warning, nil
+}
+
 // writeFormattedLine efficiently writes a formatted line to the builder
 // Optimized to avoid fmt.Sprintf allocations in tight loops
 func writeFormattedLine(builder *strings.Builder, lineNumber int, line string) {
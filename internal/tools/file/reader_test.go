@@ -1,11 +1,18 @@
 package file
 
 import (
+	"bytes"
+	"compress/gzip"
+	"context"
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
 	"testing"
+	"time"
+	"unicode/utf8"
 
 	"github.com/d-kuro/claude-code-mcp/internal/tools"
 )
@@ -83,7 +90,7 @@ func TestReadFileContent(t *testing.T) {
 				t.Fatalf("Failed to create test file: %v", err)
 			}
 
-			result, err := readFileContent(testFile, tt.offset, tt.limit)
+			result, err := readFileContent(context.Background(), testFile, tt.offset, tt.limit, nil)
 
 			if tt.expectError {
 				if err == nil {
@@ -160,7 +167,7 @@ func TestReadLargeFile(t *testing.T) {
 	}
 
 	// Test reading with limits
-	result, err := readFileContent(testFile, nil, intPtrReader(10))
+	result, err := readFileContent(context.Background(), testFile, nil, intPtrReader(10), nil)
 	if err != nil {
 		t.Errorf("Failed to read large file: %v", err)
 		return
@@ -210,7 +217,7 @@ func TestReadFileErrors(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			testPath := tt.setupFunc()
 
-			_, err := readFileContent(testPath, nil, nil)
+			_, err := readFileContent(context.Background(), testPath, nil, nil, nil)
 
 			if err == nil {
 				t.Errorf("Expected error but got none")
@@ -250,7 +257,7 @@ func TestReadStrategySwitching(t *testing.T) {
 	}
 
 	// Both should work and produce formatted output
-	smallResult, err := readFileContent(smallFile, nil, nil)
+	smallResult, err := readFileContent(context.Background(), smallFile, nil, nil, nil)
 	if err != nil {
 		t.Errorf("Failed to read small file: %v", err)
 	}
@@ -259,7 +266,7 @@ func TestReadStrategySwitching(t *testing.T) {
 		t.Errorf("Expected formatted output from small file")
 	}
 
-	largeResult, err := readFileContent(largeFile, nil, intPtrReader(5))
+	largeResult, err := readFileContent(context.Background(), largeFile, nil, intPtrReader(5), nil)
 	if err != nil {
 		t.Errorf("Failed to read large file: %v", err)
 	}
@@ -269,6 +276,144 @@ func TestReadStrategySwitching(t *testing.T) {
 	}
 }
 
+func TestReadMaxLineLength(t *testing.T) {
+	tempDir := t.TempDir()
+
+	longLine := strings.Repeat("x", 70000)
+	testFile := filepath.Join(tempDir, "minified.js")
+	if err := os.WriteFile(testFile, []byte(longLine+"\nshort"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	t.Run("default truncates", func(t *testing.T) {
+		result, err := readFileContent(context.Background(), testFile, nil, nil, nil)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !strings.Contains(result, strings.Repeat("x", MaxLineLength)+"... (truncated)") {
+			t.Errorf("Expected line truncated at %d characters", MaxLineLength)
+		}
+	})
+
+	t.Run("raised limit keeps full line", func(t *testing.T) {
+		result, err := readFileContent(context.Background(), testFile, nil, nil, intPtrReader(len(longLine)))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if strings.Contains(result, "... (truncated)") {
+			t.Errorf("Expected no truncation with raised limit")
+		}
+		if !strings.Contains(result, longLine) {
+			t.Errorf("Expected full long line in output")
+		}
+	})
+
+	t.Run("lowered limit", func(t *testing.T) {
+		result, err := readFileContent(context.Background(), testFile, nil, nil, intPtrReader(10))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !strings.Contains(result, "→xxxxxxxxxx... (truncated)") {
+			t.Errorf("Expected line truncated at 10 characters, got: %.100s", result)
+		}
+	})
+
+	t.Run("limit above upper bound", func(t *testing.T) {
+		if _, err := readFileContent(context.Background(), testFile, nil, nil, intPtrReader(MaxReadLineLength+1)); err == nil {
+			t.Errorf("Expected error for max_line_length above %d", MaxReadLineLength)
+		}
+	})
+
+	t.Run("line limit above default", func(t *testing.T) {
+		var content strings.Builder
+		for i := 0; i < DefaultMaxLines+500; i++ {
+			content.WriteString("line\n")
+		}
+		manyLines := filepath.Join(tempDir, "many.txt")
+		if err := os.WriteFile(manyLines, []byte(content.String()), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+
+		result, err := readFileContent(context.Background(), manyLines, nil, intPtrReader(DefaultMaxLines+500), nil)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if lines := strings.Count(result, "\n") + 1; lines != DefaultMaxLines+500 {
+			t.Errorf("Expected %d lines, got %d", DefaultMaxLines+500, lines)
+		}
+
+		if _, err := readFileContent(context.Background(), manyLines, nil, intPtrReader(MaxReadLines+1), nil); err == nil {
+			t.Errorf("Expected error for limit above %d", MaxReadLines)
+		}
+	})
+}
+
+// cancelAfterContext reports cancellation once Err has been checked a set number of times.
+type cancelAfterContext struct {
+	context.Context
+	checks int
+}
+
+func (c *cancelAfterContext) Err() error {
+	if c.checks <= 0 {
+		return context.Canceled
+	}
+	c.checks--
+	return nil
+}
+
+func TestReadFileContentCancellation(t *testing.T) {
+	tempDir := t.TempDir()
+
+	var content strings.Builder
+	for i := 0; i < 50000; i++ {
+		content.WriteString("This is a test line that will help us exceed the large file threshold.\n")
+	}
+
+	testFile := filepath.Join(tempDir, "large.txt")
+	if err := os.WriteFile(testFile, []byte(content.String()), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	smallFile := filepath.Join(tempDir, "small.txt")
+	if err := os.WriteFile(smallFile, []byte(strings.Repeat("line\n", 10000)), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		path string
+	}{
+		{name: "large file strategy", path: testFile},
+		{name: "small file strategy", path: smallFile},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Cancel after a few periodic checks, part way through the file
+			ctx := &cancelAfterContext{Context: context.Background(), checks: 3}
+
+			start := time.Now()
+			_, err := readFileContent(ctx, tt.path, nil, intPtrReader(MaxReadLines), nil)
+			if !errors.Is(err, context.Canceled) {
+				t.Fatalf("Expected context cancelled error, got: %v", err)
+			}
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Errorf("Expected cancelled read to stop promptly, took %v", elapsed)
+			}
+		})
+	}
+
+	t.Run("already cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		if _, err := readFileContent(ctx, testFile, nil, nil, nil); !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected context cancelled error, got: %v", err)
+		}
+	})
+}
+
 func TestCreateReadTool(t *testing.T) {
 	tempDir, err := os.MkdirTemp("", "read_tool_test_*")
 	if err != nil {
@@ -296,7 +441,7 @@ func TestCreateReadTool(t *testing.T) {
 	}
 
 	// Test the core functionality directly (MCP integration would require more setup)
-	result, err := readFileContent(testFile, nil, intPtrReader(2))
+	result, err := readFileContent(context.Background(), testFile, nil, intPtrReader(2), nil)
 	if err != nil {
 		t.Errorf("Tool function failed: %v", err)
 	}
@@ -393,6 +538,10 @@ func (m *mockValidator) ValidatePath(path string) error {
 	return nil
 }
 
+func (m *mockValidator) ValidateWritePath(path string) error {
+	return m.ValidatePath(path)
+}
+
 func (m *mockValidator) ValidateContent(content []byte) error {
 	return nil
 }
@@ -404,3 +553,256 @@ func (m *mockValidator) ValidateCommand(cmd string, args []string) error {
 func (m *mockValidator) ValidateURL(url string) error {
 	return nil
 }
+
+func writeGzipFile(t *testing.T, path, content string) {
+	t.Helper()
+
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write([]byte(content)); err != nil {
+		t.Fatalf("Failed to compress content: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Failed to close gzip writer: %v", err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatalf("Failed to write gzip file: %v", err)
+	}
+}
+
+func TestReadGzipFile(t *testing.T) {
+	tempDir := t.TempDir()
+
+	gzFile := filepath.Join(tempDir, "app.log.gz")
+	writeGzipFile(t, gzFile, "first\nsecond\nthird\nfourth\n")
+
+	result, err := readFileContent(context.Background(), gzFile, intPtrReader(1), intPtrReader(2), nil)
+	if err != nil {
+		t.Fatalf("Failed to read gzip file: %v", err)
+	}
+	expected := "    2→second\n    3→third"
+	if result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+
+	// A .gz file without the gzip header is read as plain text
+	plainFile := filepath.Join(tempDir, "plain.gz")
+	if err := os.WriteFile(plainFile, []byte("not compressed\n"), 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	result, err = readFileContent(context.Background(), plainFile, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to read plain .gz file: %v", err)
+	}
+	if result != "    1→not compressed" {
+		t.Errorf("Expected plain content, got %q", result)
+	}
+}
+
+func TestDecompressReaderSizeCap(t *testing.T) {
+	gzFile := filepath.Join(t.TempDir(), "bomb.gz")
+	writeGzipFile(t, gzFile, strings.Repeat("0", 4096))
+
+	file, err := os.Open(gzFile)
+	if err != nil {
+		t.Fatalf("Failed to open file: %v", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	reader, compressed, err := decompressReader(file, 1024)
+	if err != nil || !compressed {
+		t.Fatalf("Expected gzip reader, got compressed=%v err=%v", compressed, err)
+	}
+
+	_, err = readLargeFile(context.Background(), reader, 0, 10, MaxLineLength)
+	if err == nil || !strings.Contains(err.Error(), "decompressed content exceeds 1024 bytes") {
+		t.Errorf("Expected size cap error, got: %v", err)
+	}
+}
+
+func TestReadInvalidUTF8(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "latin1.txt")
+	if err := os.WriteFile(path, []byte("caf\xe9\nok\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	ctx := &tools.Context{Validator: &mockValidator{}}
+
+	text, isError := callServerTool(t, CreateReadTool(ctx), map[string]any{"file_path": path})
+	if isError {
+		t.Fatalf("Expected success, got error: %s", text)
+	}
+	if !utf8.ValidString(text) {
+		t.Errorf("Expected valid UTF-8 output, got %q", text)
+	}
+	if !strings.Contains(text, "caf\uFFFD") {
+		t.Errorf("Expected the invalid byte to be replaced, got %q", text)
+	}
+	if !strings.Contains(text, "invalid UTF-8 byte sequences") {
+		t.Errorf("Expected a note about invalid bytes, got %q", text)
+	}
+
+	text, isError = callServerTool(t, CreateReadTool(ctx), map[string]any{"file_path": path, "strict": true})
+	if !isError || !strings.Contains(text, "invalid UTF-8") {
+		t.Errorf("Expected strict mode to fail, got: %s", text)
+	}
+}
+
+func TestTruncateLineKeepsCharactersWhole(t *testing.T) {
+	got := truncateLine("héllo", 2)
+	if got != "h... (truncated)" {
+		t.Errorf("truncateLine() = %q, want %q", got, "h... (truncated)")
+	}
+}
+
+func TestReadAnchor(t *testing.T) {
+	var content strings.Builder
+	for i := 1; i <= 100; i++ {
+		fmt.Fprintf(&content, "line %d\n", i)
+	}
+	path := filepath.Join(t.TempDir(), "anchor.txt")
+	if err := os.WriteFile(path, []byte(content.String()), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	ctx := &tools.Context{Validator: &mockValidator{}}
+
+	t.Run("middle of the file", func(t *testing.T) {
+		text, isError := callServerTool(t, CreateReadTool(ctx), map[string]any{"file_path": path, "anchor": "line 50", "context_lines": 2})
+		if isError {
+			t.Fatalf("Expected success, got error: %s", text)
+		}
+		want := "   48→line 48\n   49→line 49\n   50→line 50\n   51→line 51\n   52→line 52"
+		if text != want {
+			t.Errorf("Expected the lines around the anchor:\n%s\ngot:\n%s", want, text)
+		}
+	})
+
+	t.Run("near the start", func(t *testing.T) {
+		text, isError := callServerTool(t, CreateReadTool(ctx), map[string]any{"file_path": path, "anchor": "line 2\n", "context_lines": 3})
+		if isError {
+			t.Fatalf("Expected success, got error: %s", text)
+		}
+		if !strings.HasPrefix(text, "    1→line 1\n") || !strings.HasSuffix(text, "    5→line 5") {
+			t.Errorf("Expected lines 1 to 5, got:\n%s", text)
+		}
+	})
+
+	t.Run("missing anchor", func(t *testing.T) {
+		text, isError := callServerTool(t, CreateReadTool(ctx), map[string]any{"file_path": path, "anchor": "line 500"})
+		if !isError || !strings.Contains(text, "anchor not found") {
+			t.Errorf("Expected an anchor not found error, got: %s", text)
+		}
+	})
+
+	t.Run("combined with offset", func(t *testing.T) {
+		text, isError := callServerTool(t, CreateReadTool(ctx), map[string]any{"file_path": path, "anchor": "line 50", "offset": 10})
+		if !isError || !strings.Contains(text, "anchor") {
+			t.Errorf("Expected anchor and offset to be rejected together, got: %s", text)
+		}
+	})
+}
+
+func TestReadChunkedFile(t *testing.T) {
+	dir := t.TempDir()
+
+	t.Run("matches the streaming reader", func(t *testing.T) {
+		var content strings.Builder
+		for i := 1; i <= 5000; i++ {
+			fmt.Fprintf(&content, "line %d %s\n", i, strings.Repeat("x", i%300))
+		}
+		path := filepath.Join(dir, "lines.txt")
+		if err := os.WriteFile(path, []byte(content.String()), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+
+		for _, window := range []struct{ offset, limit, lineLength int }{{0, 10, MaxLineLength}, {4990, 100, MaxLineLength}, {100, 50, 20}} {
+			want, err := readLargeFile(context.Background(), strings.NewReader(content.String()), window.offset, window.limit, window.lineLength)
+			if err != nil {
+				t.Fatalf("readLargeFile() error = %v", err)
+			}
+			got, err := readChunkedFile(context.Background(), strings.NewReader(content.String()), window.offset, window.limit, window.lineLength)
+			if err != nil {
+				t.Fatalf("readChunkedFile() error = %v", err)
+			}
+			if got != want {
+				t.Errorf("Window %+v differs from the streaming reader:\n%s\nwant:\n%s", window, got, want)
+			}
+		}
+	})
+
+	t.Run("note over threshold", func(t *testing.T) {
+		path := filepath.Join(dir, "small.txt")
+		if err := os.WriteFile(path, []byte("one\ntwo\nthree\n"), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+
+		result, err := readFileContentWithThreshold(context.Background(), path, intPtrReader(1), intPtrReader(1), nil, 4)
+		if err != nil {
+			t.Fatalf("readFileContentWithThreshold() error = %v", err)
+		}
+		if !strings.HasPrefix(result, "    2→two\n") || !strings.Contains(result, "over the large-file threshold of 4 bytes") {
+			t.Errorf("Expected line 2 and a large-file note, got %q", result)
+		}
+
+		result, err = readFileContentWithThreshold(context.Background(), path, nil, nil, nil, 1024)
+		if err != nil {
+			t.Fatalf("readFileContentWithThreshold() error = %v", err)
+		}
+		if strings.Contains(result, "large-file threshold") {
+			t.Errorf("Expected no note below the threshold, got %q", result)
+		}
+	})
+
+	t.Run("bounded memory for a huge line", func(t *testing.T) {
+		const lineSize = 32 * 1024 * 1024
+		path := filepath.Join(dir, "huge.txt")
+		file, err := os.Create(path)
+		if err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+		chunk := bytes.Repeat([]byte("a"), 1024*1024)
+		for written := 0; written < lineSize; written += len(chunk) {
+			if _, err := file.Write(chunk); err != nil {
+				t.Fatalf("Failed to write test file: %v", err)
+			}
+		}
+		if _, err := file.WriteString("\nlast line\n"); err != nil {
+			t.Fatalf("Failed to write test file: %v", err)
+		}
+		if err := file.Close(); err != nil {
+			t.Fatalf("Failed to close test file: %v", err)
+		}
+
+		var before, after runtime.MemStats
+		runtime.GC()
+		runtime.ReadMemStats(&before)
+
+		result, err := readFileContentWithThreshold(context.Background(), path, nil, nil, nil, 1024)
+		if err != nil {
+			t.Fatalf("readFileContentWithThreshold() error = %v", err)
+		}
+
+		runtime.ReadMemStats(&after)
+		if allocated := after.TotalAlloc - before.TotalAlloc; allocated > 4*1024*1024 {
+			t.Errorf("Expected bounded memory use, allocated %d bytes for a %d byte line", allocated, lineSize)
+		}
+		if !strings.Contains(result, "... (truncated)") || !strings.Contains(result, "    2→last line") {
+			t.Errorf("Expected a truncated first line followed by line 2, got %.200q", result)
+		}
+	})
+}
+
+func BenchmarkReadChunkedFile(b *testing.B) {
+	var content strings.Builder
+	for i := 0; i < 100000; i++ {
+		fmt.Fprintf(&content, "line %d of the benchmark fixture with some padding text\n", i)
+	}
+	data := content.String()
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(data)))
+	for b.Loop() {
+		if _, err := readChunkedFile(context.Background(), strings.NewReader(data), 90000, DefaultMaxLines, MaxLineLength); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
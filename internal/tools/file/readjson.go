@@ -0,0 +1,213 @@
+// Package file provides the tool that reads a value from a JSON file.
+package file
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/d-kuro/claude-code-mcp/internal/prompts"
+	"github.com/d-kuro/claude-code-mcp/internal/tools"
+)
+
+// ReadJSONArgs represents the arguments for the ReadJSON tool.
+type ReadJSONArgs struct {
+	Path  string `json:"path"`
+	Query string `json:"query"`
+}
+
+// jsonPathStep is one step of a ReadJSON query: an object key or an array index.
+type jsonPathStep struct {
+	key     string
+	index   int
+	isIndex bool
+}
+
+// String formats the step as it appears in a query.
+func (s jsonPathStep) String() string {
+	if s.isIndex {
+		return "[" + strconv.Itoa(s.index) + "]"
+	}
+	if isPlainJSONKey(s.key) {
+		return "." + s.key
+	}
+	return "[" + strconv.Quote(s.key) + "]"
+}
+
+// CreateReadJSONTool creates the ReadJSON tool using MCP SDK patterns.
+func CreateReadJSONTool(ctx *tools.Context) *tools.ServerTool {
+	handler := func(ctxReq context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[ReadJSONArgs]) (*mcp.CallToolResultFor[any], error) {
+		args := params.Arguments
+
+		sanitizedPath, err := ctx.Validator.SanitizePath(args.Path)
+		if err != nil {
+			return tools.InvalidPathError(err), nil
+		}
+
+		if err := ctx.Validator.ValidatePath(sanitizedPath); err != nil {
+			return tools.PathValidationError(err), nil
+		}
+
+		steps, err := parseJSONQuery(args.Query)
+		if err != nil {
+			return tools.InvalidFieldError("query", err.Error()), nil
+		}
+
+		content, err := os.ReadFile(sanitizedPath)
+		if err != nil {
+			return tools.ErrorResponsef("failed to read file: %v", err), nil
+		}
+		if !json.Valid(content) {
+			return tools.ErrorResponsef("%s is not valid JSON; use ValidateFile to locate the syntax error", sanitizedPath), nil
+		}
+
+		value, err := lookupJSONPath(content, steps)
+		if err != nil {
+			return tools.ErrorResponse(err.Error()), nil
+		}
+
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, value, "", "  "); err != nil {
+			return tools.ErrorResponsef("failed to format value: %v", err), nil
+		}
+		return tools.SuccessResponse(buf.String()), nil
+	}
+
+	tool := &mcp.Tool{
+		Name:        "ReadJSON",
+		Description: prompts.ReadJSONToolDoc,
+	}
+
+	return &tools.ServerTool{
+		Tool: tool,
+		RegisterFunc: func(server *mcp.Server) {
+			mcp.AddTool(server, tool, handler)
+		},
+	}
+}
+
+// parseJSONQuery parses a dotted and bracketed path such as a.b[0].c or
+// servers["api.internal"].port. An empty query, ".", or "$" selects the
+// whole document, and a leading "." or "$" is optional.
+func parseJSONQuery(query string) ([]jsonPathStep, error) {
+	query = strings.TrimSpace(query)
+	query = strings.TrimPrefix(query, "$")
+
+	var steps []jsonPathStep
+	for i := 0; i < len(query); {
+		switch query[i] {
+		case '.':
+			i++
+			if i == len(query) {
+				if len(steps) == 0 {
+					return nil, nil
+				}
+				return nil, fmt.Errorf("query ends with '.'")
+			}
+			end := i
+			for end < len(query) && !strings.ContainsRune(".[]", rune(query[end])) {
+				end++
+			}
+			if end == i {
+				return nil, fmt.Errorf("empty key at offset %d", i)
+			}
+			steps = append(steps, jsonPathStep{key: query[i:end]})
+			i = end
+		case '[':
+			end := strings.IndexByte(query[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unclosed '[' at offset %d", i)
+			}
+			// A quoted key may itself contain ']'
+			if quote := query[i+1]; quote == '"' || quote == '\'' {
+				closing := strings.IndexByte(query[i+2:], quote)
+				if closing < 0 || i+2+closing+1 >= len(query) || query[i+2+closing+1] != ']' {
+					return nil, fmt.Errorf("unterminated quoted key at offset %d", i)
+				}
+				steps = append(steps, jsonPathStep{key: query[i+2 : i+2+closing]})
+				i += 2 + closing + 2
+				continue
+			}
+			inner := strings.TrimSpace(query[i+1 : i+end])
+			index, err := strconv.Atoi(inner)
+			if err != nil || index < 0 {
+				return nil, fmt.Errorf("invalid array index %q at offset %d", inner, i)
+			}
+			steps = append(steps, jsonPathStep{index: index, isIndex: true})
+			i += end + 1
+		default:
+			if i != 0 {
+				return nil, fmt.Errorf("unexpected %q at offset %d", query[i], i)
+			}
+			// The first key needs no leading dot
+			query = "." + query
+		}
+	}
+	return steps, nil
+}
+
+// lookupJSONPath returns the raw JSON value that the steps select in
+// document. Objects are decoded one level at a time, so the selected value
+// keeps its key order. When a step does not match, the error names the part
+// of the path that was found and why the next step failed.
+func lookupJSONPath(document []byte, steps []jsonPathStep) (json.RawMessage, error) {
+	current := json.RawMessage(document)
+	found := "$"
+	for _, step := range steps {
+		if step.isIndex {
+			var array []json.RawMessage
+			if err := json.Unmarshal(current, &array); err != nil || array == nil {
+				return nil, fmt.Errorf("no value at %s%s: %s is %s, not an array", found, step, found, jsonKind(current))
+			}
+			if step.index >= len(array) {
+				return nil, fmt.Errorf("no value at %s%s: index %d is out of range, %s has %d elements", found, step, step.index, found, len(array))
+			}
+			current = array[step.index]
+		} else {
+			var object map[string]json.RawMessage
+			if err := json.Unmarshal(current, &object); err != nil || object == nil {
+				return nil, fmt.Errorf("no value at %s%s: %s is %s, not an object", found, step, found, jsonKind(current))
+			}
+			value, ok := object[step.key]
+			if !ok {
+				return nil, fmt.Errorf("no value at %s%s: key %q not found in %s", found, step, step.key, found)
+			}
+			current = value
+		}
+		found += step.String()
+	}
+	return current, nil
+}
+
+// jsonKind describes the type of a raw JSON value for error messages.
+func jsonKind(value json.RawMessage) string {
+	trimmed := bytes.TrimSpace(value)
+	if len(trimmed) == 0 {
+		return "empty"
+	}
+	switch trimmed[0] {
+	case '{':
+		return "an object"
+	case '[':
+		return "an array"
+	case '"':
+		return "a string"
+	case 't', 'f':
+		return "a boolean"
+	case 'n':
+		return "null"
+	default:
+		return "a number"
+	}
+}
+
+// isPlainJSONKey reports whether a key can be written after a dot in a query.
+func isPlainJSONKey(key string) bool {
+	return key != "" && !strings.ContainsAny(key, ".[]\"' ")
+}
@@ -0,0 +1,92 @@
+package file
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/d-kuro/claude-code-mcp/internal/tools"
+)
+
+const readJSONFixture = `{
+  "name": "app",
+  "servers": [
+    {"host": "a.example.com", "ports": [80, 443]},
+    {"host": "b.example.com", "ports": [8080], "tls": {"enabled": true, "cert": "/etc/cert.pem"}}
+  ],
+  "routes": {"api.internal": {"timeout": 30}}
+}`
+
+func callReadJSON(t *testing.T, args map[string]any) (string, bool) {
+	t.Helper()
+	return callServerTool(t, CreateReadJSONTool(&tools.Context{Validator: &mockValidator{}}), args)
+}
+
+func TestReadJSON(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(filePath, []byte(readJSONFixture), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{"top-level key", "name", `"app"`},
+		{"nested object", "servers[1].tls", "{\n  \"enabled\": true,\n  \"cert\": \"/etc/cert.pem\"\n}"},
+		{"array element", "servers[0].ports[1]", "443"},
+		{"array", "servers[1].ports", "[\n  8080\n]"},
+		{"quoted key", `routes["api.internal"].timeout`, "30"},
+		{"leading dollar", "$.servers[0].host", `"a.example.com"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, isError := callReadJSON(t, map[string]any{"path": filePath, "query": tt.query})
+			if isError {
+				t.Fatalf("Expected success, got: %s", text)
+			}
+			if text != tt.want {
+				t.Errorf("Query %q returned:\n%s\nwant:\n%s", tt.query, text, tt.want)
+			}
+		})
+	}
+
+	t.Run("whole document", func(t *testing.T) {
+		text, isError := callReadJSON(t, map[string]any{"path": filePath, "query": ""})
+		if isError || !strings.HasPrefix(text, "{\n  \"name\": \"app\",") {
+			t.Errorf("Expected the whole document in file order, got: %s", text)
+		}
+	})
+
+	missing := []struct {
+		name    string
+		query   string
+		wantErr string
+	}{
+		{"missing key", "servers[0].tls", `no value at $.servers[0].tls: key "tls" not found in $.servers[0]`},
+		{"index out of range", "servers[5]", "index 5 is out of range, $.servers has 2 elements"},
+		{"not an array", "name[0]", "$.name is a string, not an array"},
+		{"not an object", "servers.host", "$.servers is an array, not an object"},
+	}
+	for _, tt := range missing {
+		t.Run(tt.name, func(t *testing.T) {
+			text, isError := callReadJSON(t, map[string]any{"path": filePath, "query": tt.query})
+			if !isError {
+				t.Fatalf("Expected an error for %q, got: %s", tt.query, text)
+			}
+			if !strings.Contains(text, tt.wantErr) {
+				t.Errorf("Expected error containing %q, got: %s", tt.wantErr, text)
+			}
+		})
+	}
+}
+
+func TestParseJSONQueryErrors(t *testing.T) {
+	for _, query := range []string{"a..b", "a[", "a[x]", "a[-1]", `a["b]`, "a.", "a]b"} {
+		if _, err := parseJSONQuery(query); err == nil {
+			t.Errorf("Expected an error for query %q", query)
+		}
+	}
+}
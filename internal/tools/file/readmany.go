@@ -0,0 +1,129 @@
+// Package file provides file operation tools using the MCP SDK patterns.
+package file
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/d-kuro/claude-code-mcp/internal/prompts"
+	"github.com/d-kuro/claude-code-mcp/internal/tools"
+)
+
+const (
+	// MaxReadManyPaths is the maximum number of files a ReadMany call may read
+	MaxReadManyPaths = 50
+	// readManyWorkers bounds the number of files read concurrently
+	readManyWorkers = 8
+)
+
+// ReadManyArgs represents the arguments for the ReadMany tool.
+type ReadManyArgs struct {
+	Paths []string `json:"paths"`
+	Limit *int     `json:"limit,omitempty"`
+}
+
+// readManyResult holds the outcome of reading one file in a ReadMany call.
+type readManyResult struct {
+	Path    string
+	Content string
+	Err     error
+}
+
+// CreateReadManyTool creates the ReadMany tool using MCP SDK patterns.
+func CreateReadManyTool(ctx *tools.Context) *tools.ServerTool {
+	handler := func(ctxReq context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[ReadManyArgs]) (*mcp.CallToolResultFor[any], error) {
+		args := params.Arguments
+
+		if len(args.Paths) == 0 {
+			return tools.EmptyFieldError("paths"), nil
+		}
+		if len(args.Paths) > MaxReadManyPaths {
+			return tools.InvalidFieldError("paths", fmt.Sprintf("must contain at most %d entries", MaxReadManyPaths)), nil
+		}
+		if args.Limit != nil && (*args.Limit < 1 || *args.Limit > MaxReadLines) {
+			return tools.InvalidFieldError("limit", fmt.Sprintf("must be between 1 and %d", MaxReadLines)), nil
+		}
+
+		results := readManyFiles(ctxReq, ctx.Validator, args.Paths, args.Limit)
+		return tools.SuccessResponse(formatReadManyResults(results)), nil
+	}
+
+	tool := &mcp.Tool{
+		Name:        "ReadMany",
+		Description: prompts.ReadManyToolDoc,
+	}
+
+	return &tools.ServerTool{
+		Tool: tool,
+		RegisterFunc: func(server *mcp.Server) {
+			mcp.AddTool(server, tool, handler)
+		},
+	}
+}
+
+// readManyFiles validates and reads each path using a bounded pool of workers.
+// Results are returned in the order of paths; a failure only affects its own entry.
+func readManyFiles(ctx context.Context, validator tools.Validator, paths []string, limit *int) []readManyResult {
+	results := make([]readManyResult, len(paths))
+	jobs := make(chan int)
+
+	workers := min(readManyWorkers, len(paths))
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for range workers {
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = readOneFile(ctx, validator, paths[i], limit)
+			}
+		}()
+	}
+
+	for i := range paths {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
+
+// readOneFile validates a single path and reads it like the Read tool.
+func readOneFile(ctx context.Context, validator tools.Validator, path string, limit *int) readManyResult {
+	result := readManyResult{Path: path}
+
+	sanitizedPath, err := validator.SanitizePath(path)
+	if err != nil {
+		result.Err = fmt.Errorf("invalid file path: %w", err)
+		return result
+	}
+	result.Path = sanitizedPath
+
+	if err := validator.ValidatePath(sanitizedPath); err != nil {
+		result.Err = fmt.Errorf("path validation failed: %w", err)
+		return result
+	}
+
+	result.Content, result.Err = readFileContent(ctx, sanitizedPath, nil, limit, nil)
+	if result.Err == nil {
+		result.Content, result.Err = ensureValidUTF8(result.Content, false)
+	}
+	return result
+}
+
+// formatReadManyResults renders one section per file, in request order.
+func formatReadManyResults(results []readManyResult) string {
+	sections := make([]string, len(results))
+	for i, result := range results {
+		if result.Err != nil {
+			sections[i] = fmt.Sprintf("==> %s <==\nError: %s", result.Path, result.Err)
+		} else {
+			sections[i] = fmt.Sprintf("==> %s <==\n%s", result.Path, result.Content)
+		}
+	}
+	return strings.Join(sections, "\n\n")
+}
@@ -0,0 +1,83 @@
+package file
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadManyFiles(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first.txt")
+	second := filepath.Join(dir, "second.txt")
+	if err := os.WriteFile(first, []byte("alpha\nbeta\n"), 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	if err := os.WriteFile(second, []byte("gamma\n"), 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+
+	paths := []string{
+		first,
+		filepath.Join(dir, "invalid.txt"),
+		filepath.Join(dir, "forbidden", "secret.txt"),
+		filepath.Join(dir, "missing.txt"),
+		second,
+	}
+
+	results := readManyFiles(context.Background(), &mockValidator{}, paths, nil)
+	if len(results) != len(paths) {
+		t.Fatalf("Expected %d results, got %d", len(paths), len(results))
+	}
+
+	if results[0].Err != nil || !strings.Contains(results[0].Content, "alpha") {
+		t.Errorf("Expected first file content, got %+v", results[0])
+	}
+	if results[1].Err == nil || !strings.Contains(results[1].Err.Error(), "invalid file path") {
+		t.Errorf("Expected invalid path error, got %v", results[1].Err)
+	}
+	if results[2].Err == nil || !strings.Contains(results[2].Err.Error(), "path validation failed") {
+		t.Errorf("Expected validation error, got %v", results[2].Err)
+	}
+	if results[3].Err == nil || !strings.Contains(results[3].Err.Error(), "failed to open file") {
+		t.Errorf("Expected open error, got %v", results[3].Err)
+	}
+	if results[4].Err != nil || !strings.Contains(results[4].Content, "gamma") {
+		t.Errorf("Expected second file content, got %+v", results[4])
+	}
+
+	output := formatReadManyResults(results)
+	if strings.Index(output, "==> "+first+" <==") > strings.Index(output, "==> "+second+" <==") {
+		t.Errorf("Expected sections in request order, got:\n%s", output)
+	}
+	if strings.Count(output, "Error: ") != 3 {
+		t.Errorf("Expected three error sections, got:\n%s", output)
+	}
+}
+
+func TestReadManyFilesManyPaths(t *testing.T) {
+	dir := t.TempDir()
+	var paths []string
+	for i := range MaxReadManyPaths {
+		path := filepath.Join(dir, fmt.Sprintf("file%02d.txt", i))
+		if err := os.WriteFile(path, []byte(fmt.Sprintf("content %d\n", i)), 0644); err != nil {
+			t.Fatalf("Failed to create file: %v", err)
+		}
+		paths = append(paths, path)
+	}
+
+	limit := 1
+	results := readManyFiles(context.Background(), &mockValidator{}, paths, &limit)
+	for i, result := range results {
+		if result.Err != nil {
+			t.Errorf("Unexpected error for %s: %v", result.Path, result.Err)
+			continue
+		}
+		if !strings.Contains(result.Content, fmt.Sprintf("content %d", i)) {
+			t.Errorf("Result %d has content of another file: %q", i, result.Content)
+		}
+	}
+}
@@ -9,11 +9,25 @@ import (
 func CreateFileTools(ctx *tools.Context) []*tools.ServerTool {
 	return []*tools.ServerTool{
 		CreateReadTool(ctx),
+		CreateReadManyTool(ctx),
 		CreateWriteTool(ctx),
 		CreateEditTool(ctx),
 		CreateMultiEditTool(ctx),
 		CreateLSTool(ctx),
 		CreateGlobTool(ctx),
 		CreateGrepTool(ctx),
+		CreateWatchFileTool(ctx),
+		CreateStatTool(ctx),
+		CreateReadJSONTool(ctx),
+		CreateSymlinkTool(ctx),
+		CreateReadLinkTool(ctx),
+		CreateGitStatusTool(ctx),
+		CreateGitDiffTool(ctx),
+		CreateValidateFileTool(ctx),
+		CreateFormatFileTool(ctx),
+		CreateReplaceInFilesTool(ctx),
+		CreateApplyPatchTool(ctx),
+		CreateTempFileTool(ctx),
+		CreateTempDirTool(ctx),
 	}
 }
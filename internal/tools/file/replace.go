@@ -0,0 +1,254 @@
+// Package file provides file operation tools using the MCP SDK patterns.
+package file
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/d-kuro/claude-code-mcp/internal/prompts"
+	"github.com/d-kuro/claude-code-mcp/internal/tools"
+)
+
+// ReplaceInFilesArgs represents the arguments for the ReplaceInFiles tool.
+type ReplaceInFilesArgs struct {
+	Pattern     string  `json:"pattern"`
+	Replacement string  `json:"replacement"`
+	Path        *string `json:"path,omitempty"`
+	PathGlob    *string `json:"path_glob,omitempty"`
+	DryRun      *bool   `json:"dry_run,omitempty"`
+}
+
+// fileReplacement holds the planned change to a single file.
+type fileReplacement struct {
+	Path     string
+	Mode     os.FileMode
+	Original []byte
+	Modified []byte
+	Count    int
+}
+
+// CreateReplaceInFilesTool creates the ReplaceInFiles tool using MCP SDK patterns.
+func CreateReplaceInFilesTool(ctx *tools.Context) *tools.ServerTool {
+	handler := func(ctxReq context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[ReplaceInFilesArgs]) (*mcp.CallToolResultFor[any], error) {
+		args := params.Arguments
+
+		if args.Pattern == "" {
+			return tools.EmptyFieldError("pattern"), nil
+		}
+
+		regex, err := regexp.Compile(args.Pattern)
+		if err != nil {
+			return tools.InvalidFieldError("pattern", err.Error()), nil
+		}
+
+		searchPath := "."
+		if args.Path != nil && *args.Path != "" {
+			searchPath = *args.Path
+		}
+		if !filepath.IsAbs(searchPath) {
+			cwd, err := os.Getwd()
+			if err != nil {
+				return tools.ErrorResponsef("Failed to get current working directory: %v", err), nil
+			}
+			searchPath = filepath.Join(cwd, searchPath)
+		}
+
+		sanitizedPath, err := ctx.Validator.SanitizePath(searchPath)
+		if err != nil {
+			return tools.ValidationErrorResult("Invalid search path", err), nil
+		}
+
+		if err := ctx.Validator.ValidatePath(sanitizedPath); err != nil {
+			return tools.ValidationErrorResult("Path validation failed", err), nil
+		}
+
+		if err := checkSearchRoot(sanitizedPath, ctx.AllowRootSearch); err != nil {
+			return tools.ErrorResponse(err.Error()), nil
+		}
+
+		opts := grepOptions{
+			Include:  args.PathGlob,
+			Ignore:   ctx.Ignore.Snapshot(),
+			MaxDepth: ctx.MaxSearchDepth,
+			Logger:   ctx.Logger,
+			CleanEnv: ctx.CleanEnv,
+		}
+
+		paths, _, _, err := findMatchingFiles(ctxReq, sanitizedPath, args.Pattern, opts)
+		if err != nil {
+			return tools.ErrorResponse(err.Error()), nil
+		}
+
+		for _, path := range paths {
+			if err := ctx.Validator.ValidateWritePath(path); err != nil {
+				return tools.ValidationErrorResult(fmt.Sprintf("Path validation failed for %s", path), err), nil
+			}
+		}
+
+		changes, err := planReplacements(paths, regex, args.Replacement)
+		if err != nil {
+			return tools.ErrorResponse(err.Error()), nil
+		}
+
+		if args.DryRun != nil && *args.DryRun {
+			return tools.SuccessResponse(formatReplacementDiffs(changes)), nil
+		}
+
+		if err := applyReplacements(changes, newWriteOptions(ctx)); err != nil {
+			return tools.ErrorResponse(err.Error()), nil
+		}
+
+		return tools.SuccessResponse(formatReplacementSummary(changes)), nil
+	}
+
+	tool := &mcp.Tool{
+		Name:        "ReplaceInFiles",
+		Description: prompts.ReplaceInFilesToolDoc,
+	}
+
+	return &tools.ServerTool{
+		Tool: tool,
+		RegisterFunc: func(server *mcp.Server) {
+			mcp.AddTool(server, tool, handler)
+		},
+	}
+}
+
+// planReplacements computes the new content of every file that the regex
+// matches, sorted by path. Files without a match are left out.
+func planReplacements(paths []string, regex *regexp.Regexp, replacement string) ([]fileReplacement, error) {
+	var changes []fileReplacement
+
+	for _, path := range paths {
+		stat, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to stat %s: %w", path, err)
+		}
+
+		original, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+
+		count := len(regex.FindAllIndex(original, -1))
+		if count == 0 {
+			continue
+		}
+
+		modified := regex.ReplaceAll(original, []byte(replacement))
+		if string(modified) == string(original) {
+			continue
+		}
+
+		changes = append(changes, fileReplacement{
+			Path:     path,
+			Mode:     stat.Mode().Perm(),
+			Original: original,
+			Modified: modified,
+			Count:    count,
+		})
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+
+	return changes, nil
+}
+
+// applyReplacements writes every planned change. If a write fails, the files
+// already changed are restored to their original content.
+func applyReplacements(changes []fileReplacement, opts writeOptions) error {
+	for i, change := range changes {
+		if err := replaceFileContent(change.Path, change.Original, change.Modified, change.Mode, opts); err != nil {
+			reverted := 0
+			for _, done := range changes[:i] {
+				if replaceFileContent(done.Path, done.Modified, done.Original, done.Mode, writeOptions{}) == nil {
+					reverted++
+				}
+			}
+			return fmt.Errorf("failed to update %s: %w (reverted %d of %d file(s) already changed)", change.Path, err, reverted, i)
+		}
+	}
+	return nil
+}
+
+// formatReplacementSummary lists the files changed and the replacements made in each.
+func formatReplacementSummary(changes []fileReplacement) string {
+	if len(changes) == 0 {
+		return "No matches found; no files were changed"
+	}
+
+	var output strings.Builder
+	fmt.Fprintf(&output, "Replaced %d occurrence(s) in %d file(s):", totalReplacements(changes), len(changes))
+	for _, change := range changes {
+		fmt.Fprintf(&output, "\n%s: %d", change.Path, change.Count)
+	}
+	return output.String()
+}
+
+// formatReplacementDiffs shows the changes a replacement would make without writing them.
+func formatReplacementDiffs(changes []fileReplacement) string {
+	if len(changes) == 0 {
+		return "Dry run: no matches found"
+	}
+
+	var output strings.Builder
+	fmt.Fprintf(&output, "Dry run: %d occurrence(s) in %d file(s) would be replaced (no files were changed)", totalReplacements(changes), len(changes))
+	for _, change := range changes {
+		fmt.Fprintf(&output, "\n\n--- %s\n+++ %s", change.Path, change.Path)
+		writeLineDiff(&output, string(change.Original), string(change.Modified))
+	}
+	return output.String()
+}
+
+// writeLineDiff writes the changed lines between two versions of a file.
+// When the line count is unchanged, each changed line is shown separately;
+// otherwise the differing region between the common prefix and suffix is shown.
+func writeLineDiff(output *strings.Builder, original, modified string) {
+	oldLines := strings.Split(original, "\n")
+	newLines := strings.Split(modified, "\n")
+
+	if len(oldLines) == len(newLines) {
+		for i := range oldLines {
+			if oldLines[i] != newLines[i] {
+				fmt.Fprintf(output, "\n@@ line %d @@\n-%s\n+%s", i+1, oldLines[i], newLines[i])
+			}
+		}
+		return
+	}
+
+	prefix := 0
+	for prefix < len(oldLines) && prefix < len(newLines) && oldLines[prefix] == newLines[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(oldLines)-prefix && suffix < len(newLines)-prefix &&
+		oldLines[len(oldLines)-1-suffix] == newLines[len(newLines)-1-suffix] {
+		suffix++
+	}
+
+	fmt.Fprintf(output, "\n@@ line %d @@", prefix+1)
+	for _, line := range oldLines[prefix : len(oldLines)-suffix] {
+		output.WriteString("\n-" + line)
+	}
+	for _, line := range newLines[prefix : len(newLines)-suffix] {
+		output.WriteString("\n+" + line)
+	}
+}
+
+// totalReplacements returns the number of replacements across all files.
+func totalReplacements(changes []fileReplacement) int {
+	total := 0
+	for _, change := range changes {
+		total += change.Count
+	}
+	return total
+}
@@ -0,0 +1,188 @@
+package file
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/d-kuro/claude-code-mcp/internal/tools"
+)
+
+func setupRenameProject(t *testing.T) (string, map[string]string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"main.go":      "package main\n\nfunc oldName() {}\n\nfunc main() {\n\toldName()\n}\n",
+		"pkg/util.go":  "package pkg\n\n// oldName is called from main\nvar x = oldName2\n",
+		"notes.txt":    "oldName should not change here\n",
+		"pkg/other.go": "package pkg\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create file: %v", err)
+		}
+	}
+	return dir, files
+}
+
+func TestReplaceInFilesDryRun(t *testing.T) {
+	dir, files := setupRenameProject(t)
+	ctx := &tools.Context{Validator: &mockValidator{}}
+
+	text, isError := callServerTool(t, CreateReplaceInFilesTool(ctx), map[string]any{
+		"pattern":     `\boldName\b`,
+		"replacement": "newName",
+		"path":        dir,
+		"path_glob":   "*.go",
+		"dry_run":     true,
+	})
+	if isError {
+		t.Fatalf("Expected success, got error: %s", text)
+	}
+
+	mainPath := filepath.Join(dir, "main.go")
+	utilPath := filepath.Join(dir, "pkg/util.go")
+	for _, want := range []string{
+		"Dry run: 3 occurrence(s) in 2 file(s) would be replaced",
+		"--- " + mainPath + "\n+++ " + mainPath,
+		"@@ line 3 @@\n-func oldName() {}\n+func newName() {}",
+		"@@ line 6 @@\n-\toldName()\n+\tnewName()",
+		"@@ line 3 @@\n-// oldName is called from main\n+// newName is called from main",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected dry run output to contain %q, got:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, "oldName2") || strings.Contains(text, "notes.txt") {
+		t.Errorf("Dry run reported unexpected changes:\n%s", text)
+	}
+
+	for name, content := range files {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", name, err)
+		}
+		if string(data) != content {
+			t.Errorf("Dry run modified %s", name)
+		}
+	}
+	if _, err := os.Stat(utilPath + ".backup"); !os.IsNotExist(err) {
+		t.Errorf("Dry run left a backup file")
+	}
+}
+
+func TestReplaceInFilesApply(t *testing.T) {
+	dir, files := setupRenameProject(t)
+	ctx := &tools.Context{Validator: &mockValidator{}}
+
+	text, isError := callServerTool(t, CreateReplaceInFilesTool(ctx), map[string]any{
+		"pattern":     `\boldName\b`,
+		"replacement": "newName",
+		"path":        dir,
+		"path_glob":   "*.go",
+	})
+	if isError {
+		t.Fatalf("Expected success, got error: %s", text)
+	}
+
+	expectedSummary := "Replaced 3 occurrence(s) in 2 file(s):\n" +
+		filepath.Join(dir, "main.go") + ": 2\n" +
+		filepath.Join(dir, "pkg/util.go") + ": 1"
+	if text != expectedSummary {
+		t.Errorf("Expected summary:\n%s\ngot:\n%s", expectedSummary, text)
+	}
+
+	expected := map[string]string{
+		"main.go":      "package main\n\nfunc newName() {}\n\nfunc main() {\n\tnewName()\n}\n",
+		"pkg/util.go":  "package pkg\n\n// newName is called from main\nvar x = oldName2\n",
+		"notes.txt":    files["notes.txt"],
+		"pkg/other.go": files["pkg/other.go"],
+	}
+	for name, want := range expected {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", name, err)
+		}
+		if string(data) != want {
+			t.Errorf("Unexpected content of %s:\n%s", name, data)
+		}
+	}
+
+	// Capture groups can be used in the replacement
+	text, isError = callServerTool(t, CreateReplaceInFilesTool(ctx), map[string]any{
+		"pattern":     `func (\w+)\(\)`,
+		"replacement": "func ${1}Renamed()",
+		"path":        dir,
+		"path_glob":   "main.go",
+	})
+	if isError {
+		t.Fatalf("Expected success, got error: %s", text)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "main.go"))
+	if err != nil {
+		t.Fatalf("Failed to read main.go: %v", err)
+	}
+	if !strings.Contains(string(data), "func newNameRenamed() {}") || !strings.Contains(string(data), "func mainRenamed() {") {
+		t.Errorf("Capture group replacement not applied:\n%s", data)
+	}
+}
+
+func TestReplaceInFilesErrors(t *testing.T) {
+	dir, _ := setupRenameProject(t)
+	ctx := &tools.Context{Validator: &mockValidator{}}
+
+	tests := []struct {
+		name    string
+		args    map[string]any
+		wantErr string
+	}{
+		{"empty pattern", map[string]any{"pattern": "", "replacement": "x", "path": dir}, "pattern"},
+		{"invalid pattern", map[string]any{"pattern": "(", "replacement": "x", "path": dir}, "pattern"},
+		{"forbidden search path", map[string]any{"pattern": "x", "replacement": "y", "path": filepath.Join(dir, "forbidden")}, "Path validation failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, isError := callServerTool(t, CreateReplaceInFilesTool(ctx), tt.args)
+			if !isError {
+				t.Fatalf("Expected error, got: %s", text)
+			}
+			if !strings.Contains(text, tt.wantErr) {
+				t.Errorf("Expected error containing %q, got: %s", tt.wantErr, text)
+			}
+		})
+	}
+
+	t.Run("forbidden file leaves tree unchanged", func(t *testing.T) {
+		forbidden := filepath.Join(dir, "forbidden", "x.go")
+		if err := os.MkdirAll(filepath.Dir(forbidden), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(forbidden, []byte("oldName\n"), 0644); err != nil {
+			t.Fatalf("Failed to create file: %v", err)
+		}
+
+		text, isError := callServerTool(t, CreateReplaceInFilesTool(ctx), map[string]any{
+			"pattern":     "oldName",
+			"replacement": "newName",
+			"path":        dir,
+		})
+		if !isError || !strings.Contains(text, "Path validation failed") {
+			t.Fatalf("Expected path validation error, got: %s", text)
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir, "main.go"))
+		if err != nil {
+			t.Fatalf("Failed to read main.go: %v", err)
+		}
+		if !strings.Contains(string(data), "oldName") {
+			t.Errorf("Expected no files to change when one is not writable")
+		}
+	})
+}
@@ -0,0 +1,83 @@
+// Package file provides retries for writes that fail with transient errors.
+package file
+
+import (
+	"errors"
+	"os"
+	"syscall"
+	"time"
+
+	"github.com/d-kuro/claude-code-mcp/internal/tools"
+)
+
+// DefaultWriteRetries is how many times Write and the edit tools retry a
+// write that fails with a transient filesystem error.
+const DefaultWriteRetries = 2
+
+// writeRetryBackoff is the delay before the first retry. It doubles after
+// each attempt.
+const writeRetryBackoff = 20 * time.Millisecond
+
+// writeOptions selects how the editing tools write files.
+type writeOptions struct {
+	// backup selects the ".backup" file strategy instead of in-memory rollback.
+	backup bool
+	// retries is how many times a write failing with a transient error is retried.
+	retries int
+	// sleep waits between write attempts. Nil uses time.Sleep.
+	sleep func(time.Duration)
+	// writeTemp writes the temporary file of an atomic replace. Nil writes
+	// the data as is.
+	writeTemp func(f *os.File, data []byte) error
+}
+
+// newWriteOptions returns the write options configured in ctx. A zero retry
+// count selects DefaultWriteRetries and a negative one disables retries.
+func newWriteOptions(ctx *tools.Context) writeOptions {
+	retries := ctx.WriteRetries
+	switch {
+	case retries == 0:
+		retries = DefaultWriteRetries
+	case retries < 0:
+		retries = 0
+	}
+	return writeOptions{backup: ctx.BackupFiles, retries: retries}
+}
+
+// isTransientFSError reports whether err is a filesystem error that may go
+// away when the operation is repeated, such as an interrupted system call or
+// a stale NFS handle. Permission and not-found errors are never transient.
+func isTransientFSError(err error) bool {
+	return errors.Is(err, syscall.EINTR) ||
+		errors.Is(err, syscall.EAGAIN) ||
+		errors.Is(err, syscall.ESTALE)
+}
+
+// retryTransient calls write until it succeeds, fails with an error that is
+// not transient, or has been retried opts.retries times, and returns its last
+// error.
+func retryTransient(opts writeOptions, write func() error) error {
+	sleep := opts.sleep
+	if sleep == nil {
+		sleep = time.Sleep
+	}
+
+	backoff := writeRetryBackoff
+	for attempt := 0; ; attempt++ {
+		err := write()
+		if err == nil || attempt >= opts.retries || !isTransientFSError(err) {
+			return err
+		}
+		sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// writeTempContent writes data to the temporary file used by replaceFileAtomic.
+func (opts writeOptions) writeTempContent(f *os.File, data []byte) error {
+	if opts.writeTemp != nil {
+		return opts.writeTemp(f, data)
+	}
+	_, err := f.Write(data)
+	return err
+}
@@ -0,0 +1,94 @@
+package file
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/d-kuro/claude-code-mcp/internal/tools"
+)
+
+func TestEditRetriesTransientWriteErrors(t *testing.T) {
+	// failingWriter fails the first failures writes with err, then writes normally.
+	failingWriter := func(failures int, err error) (func(*os.File, []byte) error, *int) {
+		calls := 0
+		return func(f *os.File, data []byte) error {
+			calls++
+			if calls <= failures {
+				return &os.PathError{Op: "write", Path: f.Name(), Err: err}
+			}
+			_, writeErr := f.Write(data)
+			return writeErr
+		}, &calls
+	}
+
+	tests := []struct {
+		name      string
+		failures  int
+		err       error
+		retries   int
+		wantErr   bool
+		wantCalls int
+	}{
+		{name: "stale handle then success", failures: 1, err: syscall.ESTALE, retries: 2, wantCalls: 2},
+		{name: "interrupted twice then success", failures: 2, err: syscall.EINTR, retries: 2, wantCalls: 3},
+		{name: "retries exhausted", failures: 3, err: syscall.EAGAIN, retries: 2, wantErr: true, wantCalls: 3},
+		{name: "retries disabled", failures: 1, err: syscall.ESTALE, retries: 0, wantErr: true, wantCalls: 1},
+		{name: "permission error not retried", failures: 1, err: syscall.EACCES, retries: 2, wantErr: true, wantCalls: 1},
+		{name: "not found error not retried", failures: 1, err: syscall.ENOENT, retries: 2, wantErr: true, wantCalls: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.txt")
+			if err := os.WriteFile(path, []byte("key = old\n"), 0644); err != nil {
+				t.Fatalf("Failed to create file: %v", err)
+			}
+
+			writeTemp, calls := failingWriter(tt.failures, tt.err)
+			opts := writeOptions{retries: tt.retries, sleep: func(time.Duration) {}, writeTemp: writeTemp}
+			_, err := editFileContent(path, "old", "new", nil, nil, opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("editFileContent() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, tt.err) {
+				t.Errorf("Expected error wrapping %v, got %v", tt.err, err)
+			}
+			if *calls != tt.wantCalls {
+				t.Errorf("Expected %d write attempts, got %d", tt.wantCalls, *calls)
+			}
+
+			want := "key = new\n"
+			if tt.wantErr {
+				want = "key = old\n"
+			}
+			content, readErr := os.ReadFile(path)
+			if readErr != nil {
+				t.Fatalf("Failed to read file: %v", readErr)
+			}
+			if string(content) != want {
+				t.Errorf("Expected content %q, got %q", want, content)
+			}
+		})
+	}
+}
+
+func TestNewWriteOptionsRetries(t *testing.T) {
+	tests := []struct {
+		configured int
+		want       int
+	}{
+		{configured: 0, want: DefaultWriteRetries},
+		{configured: 5, want: 5},
+		{configured: -1, want: 0},
+	}
+	for _, tt := range tests {
+		ctx := &tools.Context{WriteRetries: tt.configured}
+		if got := newWriteOptions(ctx).retries; got != tt.want {
+			t.Errorf("newWriteOptions(WriteRetries=%d).retries = %d, want %d", tt.configured, got, tt.want)
+		}
+	}
+}
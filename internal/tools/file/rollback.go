@@ -0,0 +1,132 @@
+// Package file provides the write strategies used by the edit tools.
+package file
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// fileOwner is the owner of a file and the number of hard links to it.
+type fileOwner struct {
+	uid, gid int
+	links    uint64
+}
+
+// replaceFileContent replaces the content of filePath with modified.
+// When opts.backup is set, the original content is first written to a ".backup"
+// sibling that is restored if the write fails. Otherwise the original content
+// is kept in memory and the new content is written atomically through a
+// temporary file and rename, so the file is never left partially written.
+// Attempts failing with a transient error are retried up to opts.retries times.
+func replaceFileContent(filePath string, original, modified []byte, mode os.FileMode, opts writeOptions) error {
+	return retryTransient(opts, func() error {
+		if opts.backup {
+			return replaceFileWithBackup(filePath, original, modified, mode)
+		}
+		return replaceFileAtomic(filePath, original, modified, mode, opts)
+	})
+}
+
+// replaceFileWithBackup writes modified to filePath using a ".backup" file for rollback.
+func replaceFileWithBackup(filePath string, original, modified []byte, mode os.FileMode) error {
+	backupPath := filePath + ".backup"
+	if err := os.WriteFile(backupPath, original, mode); err != nil {
+		return fmt.Errorf("failed to create backup file: %w", err)
+	}
+
+	if err := os.WriteFile(filePath, modified, mode); err != nil {
+		if restoreErr := os.Rename(backupPath, filePath); restoreErr != nil {
+			return fmt.Errorf("failed to write file and failed to restore backup: write error: %w, restore error: %v", err, restoreErr)
+		}
+		return fmt.Errorf("failed to write file (backup restored): %w", err)
+	}
+
+	_ = os.Remove(backupPath)
+	return nil
+}
+
+// replaceFileAtomic writes modified to a temporary file in the same directory
+// and renames it over filePath, so the file is never left partially written.
+// A symbolic link is kept and the file it points to is replaced. Files with
+// several hard links, or whose owner cannot be given to the temporary file,
+// are rewritten in place instead, since a rename would detach or re-own them.
+// Extended attributes are not carried over by the rename.
+func replaceFileAtomic(filePath string, original, modified []byte, mode os.FileMode, opts writeOptions) error {
+	if resolved, err := filepath.EvalSymlinks(filePath); err == nil {
+		filePath = resolved
+	}
+
+	// Renaming would replace a file the caller cannot write, so check access first
+	target, err := os.OpenFile(filePath, os.O_WRONLY, 0)
+	if err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	info, err := target.Stat()
+	_ = target.Close()
+	if err != nil {
+		return fmt.Errorf("failed to stat file: %w", err)
+	}
+	owner, known := ownerOf(info)
+	if known && owner.links > 1 {
+		return replaceFileInPlace(filePath, original, modified, mode)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(filePath), "."+filepath.Base(filePath)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	tmpPath := tmp.Name()
+
+	// Nothing has touched filePath before the rename, so a failure only
+	// needs the temporary file removed
+	discard := func() {
+		_ = tmp.Close()
+		_ = os.Remove(tmpPath)
+	}
+	fail := func(err error) error {
+		discard()
+		return fmt.Errorf("failed to write file (original content preserved): %w", err)
+	}
+
+	if known {
+		if tmpInfo, err := tmp.Stat(); err == nil {
+			if tmpOwner, ok := ownerOf(tmpInfo); ok && (tmpOwner.uid != owner.uid || tmpOwner.gid != owner.gid) {
+				if err := tmp.Chown(owner.uid, owner.gid); err != nil {
+					discard()
+					return replaceFileInPlace(filePath, original, modified, mode)
+				}
+			}
+		}
+	}
+
+	if err := tmp.Chmod(mode); err != nil {
+		return fail(err)
+	}
+	if err := opts.writeTempContent(tmp, modified); err != nil {
+		return fail(err)
+	}
+	if err := tmp.Sync(); err != nil {
+		return fail(err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fail(err)
+	}
+	if err := os.Rename(tmpPath, filePath); err != nil {
+		return fail(err)
+	}
+
+	return nil
+}
+
+// replaceFileInPlace overwrites filePath with modified, keeping its inode,
+// and writes original back if the write fails partway.
+func replaceFileInPlace(filePath string, original, modified []byte, mode os.FileMode) error {
+	if err := os.WriteFile(filePath, modified, mode); err != nil {
+		if restoreErr := os.WriteFile(filePath, original, mode); restoreErr != nil {
+			return fmt.Errorf("failed to write file and failed to restore original content: write error: %w, restore error: %v", err, restoreErr)
+		}
+		return fmt.Errorf("failed to write file (original content restored): %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package file
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReplaceFileContentFailedWriteLeavesNoBackup(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.txt")
+	original := "key = old\n"
+	if err := os.WriteFile(path, []byte(original), 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+
+	opts := writeOptions{writeTemp: func(f *os.File, data []byte) error {
+		// Simulate the process dying halfway through the write
+		_, _ = f.Write(data[:len(data)/2])
+		return errors.New("write interrupted")
+	}}
+
+	t.Run("Edit", func(t *testing.T) {
+		_, err := editFileContent(path, "old", "new", nil, nil, opts)
+		if err == nil || !strings.Contains(err.Error(), "write interrupted") {
+			t.Fatalf("Expected interrupted write error, got %v", err)
+		}
+	})
+
+	t.Run("MultiEdit", func(t *testing.T) {
+		_, err := performMultiEdit(path, []MultiEditOperation{{OldString: "old", NewString: "new"}}, opts)
+		if err == nil || !strings.Contains(err.Error(), "write interrupted") {
+			t.Fatalf("Expected interrupted write error, got %v", err)
+		}
+	})
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read file: %v", err)
+	}
+	if string(content) != original {
+		t.Errorf("Expected original content to be preserved, got %q", content)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("Failed to read directory: %v", err)
+	}
+	if len(entries) != 1 {
+		var names []string
+		for _, entry := range entries {
+			names = append(names, entry.Name())
+		}
+		t.Errorf("Expected no .backup or temporary files to remain, got %v", names)
+	}
+}
+
+func TestReplaceFileContentPreservesMode(t *testing.T) {
+	for _, useBackup := range []bool{false, true} {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "script.sh")
+		if err := os.WriteFile(path, []byte("echo old\n"), 0750); err != nil {
+			t.Fatalf("Failed to create file: %v", err)
+		}
+		if err := os.Chmod(path, 0750); err != nil {
+			t.Fatalf("Failed to chmod file: %v", err)
+		}
+
+		if _, err := editFileContent(path, "old", "new", nil, nil, writeOptions{backup: useBackup}); err != nil {
+			t.Fatalf("editFileContent(useBackup=%v) error = %v", useBackup, err)
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatalf("Failed to stat file: %v", err)
+		}
+		if info.Mode().Perm() != 0750 {
+			t.Errorf("useBackup=%v: expected mode 0750, got %#o", useBackup, info.Mode().Perm())
+		}
+		if _, err := os.Stat(path + ".backup"); !os.IsNotExist(err) {
+			t.Errorf("useBackup=%v: expected .backup file to be removed", useBackup)
+		}
+	}
+}
+
+func TestReplaceFileAtomicFailureKeepsConcurrentEdit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.txt")
+	if err := os.WriteFile(path, []byte("key = old\n"), 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+
+	opts := writeOptions{writeTemp: func(f *os.File, data []byte) error {
+		// Another process edits the file while the temporary file is written
+		if err := os.WriteFile(path, []byte("key = theirs\n"), 0644); err != nil {
+			t.Errorf("Failed to edit file: %v", err)
+		}
+		return errors.New("disk full")
+	}}
+
+	if err := replaceFileAtomic(path, []byte("key = old\n"), []byte("key = new\n"), 0644, opts); err == nil {
+		t.Fatal("Expected the write to fail")
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read file: %v", err)
+	}
+	if string(content) != "key = theirs\n" {
+		t.Errorf("Expected the concurrent edit to be kept, got %q", content)
+	}
+}
+
+func TestReplaceFileAtomicKeepsLinks(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.txt")
+	if err := os.WriteFile(path, []byte("key = old\n"), 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	symlink := filepath.Join(dir, "symlink.txt")
+	if err := os.Symlink(path, symlink); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+	hardlink := filepath.Join(dir, "hardlink.txt")
+	if err := os.Link(path, hardlink); err != nil {
+		t.Skipf("Hard links not supported: %v", err)
+	}
+
+	if _, err := editFileContent(symlink, "old", "new", nil, nil, writeOptions{}); err != nil {
+		t.Fatalf("editFileContent() error = %v", err)
+	}
+
+	info, err := os.Lstat(symlink)
+	if err != nil {
+		t.Fatalf("Failed to stat symlink: %v", err)
+	}
+	if info.Mode()&os.ModeSymlink == 0 {
+		t.Error("Expected the symlink to be kept")
+	}
+	for _, name := range []string{path, symlink, hardlink} {
+		content, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", name, err)
+		}
+		if string(content) != "key = new\n" {
+			t.Errorf("Expected %s to have the new content, got %q", name, content)
+		}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("Failed to read directory: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Errorf("Expected no temporary files to remain, got %d entries", len(entries))
+	}
+}
@@ -0,0 +1,95 @@
+// Package file provides file operation tools using the MCP SDK patterns.
+package file
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/d-kuro/claude-code-mcp/internal/prompts"
+	"github.com/d-kuro/claude-code-mcp/internal/tools"
+)
+
+// StatArgs represents the arguments for the Stat tool.
+type StatArgs struct {
+	Path string `json:"path"`
+}
+
+// FileStat is the metadata returned by the Stat tool.
+type FileStat struct {
+	Path          string `json:"path"`
+	Size          int64  `json:"size"`
+	Mode          string `json:"mode"`
+	ModTime       string `json:"mtime"`
+	IsDir         bool   `json:"is_dir"`
+	SymlinkTarget string `json:"symlink_target,omitempty"`
+}
+
+// CreateStatTool creates the Stat tool using MCP SDK patterns.
+func CreateStatTool(ctx *tools.Context) *tools.ServerTool {
+	handler := func(ctxReq context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[StatArgs]) (*mcp.CallToolResultFor[any], error) {
+		args := params.Arguments
+
+		sanitizedPath, err := ctx.Validator.SanitizePath(args.Path)
+		if err != nil {
+			return tools.InvalidPathError(err), nil
+		}
+
+		if err := ctx.Validator.ValidatePath(sanitizedPath); err != nil {
+			return tools.PathValidationError(err), nil
+		}
+
+		info, err := statPath(sanitizedPath)
+		if err != nil {
+			return tools.ErrorResponse(err.Error()), nil
+		}
+
+		return tools.JSONResponse(info), nil
+	}
+
+	tool := &mcp.Tool{
+		Name:        "Stat",
+		Description: prompts.StatToolDoc,
+	}
+
+	return &tools.ServerTool{
+		Tool: tool,
+		RegisterFunc: func(server *mcp.Server) {
+			mcp.AddTool(server, tool, handler)
+		},
+	}
+}
+
+// statPath collects metadata for a path. Symbolic links are followed, with the
+// link destination reported in SymlinkTarget; a dangling link falls back to
+// the metadata of the link itself.
+func statPath(path string) (*FileStat, error) {
+	linkInfo, err := os.Lstat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat path: %w", err)
+	}
+
+	info := linkInfo
+	var target string
+	if linkInfo.Mode()&os.ModeSymlink != 0 {
+		target, err = os.Readlink(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read symlink: %w", err)
+		}
+		if targetInfo, err := os.Stat(path); err == nil {
+			info = targetInfo
+		}
+	}
+
+	return &FileStat{
+		Path:          path,
+		Size:          info.Size(),
+		Mode:          info.Mode().String(),
+		ModTime:       info.ModTime().Format(time.RFC3339),
+		IsDir:         info.IsDir(),
+		SymlinkTarget: target,
+	}, nil
+}
@@ -0,0 +1,64 @@
+package file
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStatPath(t *testing.T) {
+	dir := t.TempDir()
+	filePath := filepath.Join(dir, "data.txt")
+	if err := os.WriteFile(filePath, []byte("hello"), 0640); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	if err := os.Chmod(filePath, 0640); err != nil {
+		t.Fatalf("Failed to chmod file: %v", err)
+	}
+	linkPath := filepath.Join(dir, "link.txt")
+	if err := os.Symlink(filePath, linkPath); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+
+	t.Run("file", func(t *testing.T) {
+		info, err := statPath(filePath)
+		if err != nil {
+			t.Fatalf("statPath() error = %v", err)
+		}
+		if info.IsDir || info.Size != 5 || info.Mode != "-rw-r-----" || info.SymlinkTarget != "" {
+			t.Errorf("Unexpected file metadata: %+v", info)
+		}
+		if info.ModTime == "" {
+			t.Errorf("Expected modification time to be set")
+		}
+	})
+
+	t.Run("directory", func(t *testing.T) {
+		info, err := statPath(dir)
+		if err != nil {
+			t.Fatalf("statPath() error = %v", err)
+		}
+		if !info.IsDir || info.Mode[0] != 'd' {
+			t.Errorf("Expected directory metadata, got %+v", info)
+		}
+	})
+
+	t.Run("symlink", func(t *testing.T) {
+		info, err := statPath(linkPath)
+		if err != nil {
+			t.Fatalf("statPath() error = %v", err)
+		}
+		if info.SymlinkTarget != filePath {
+			t.Errorf("Expected symlink target %s, got %q", filePath, info.SymlinkTarget)
+		}
+		if info.Size != 5 || info.IsDir {
+			t.Errorf("Expected metadata of the link target, got %+v", info)
+		}
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		if _, err := statPath(filepath.Join(dir, "missing")); err == nil {
+			t.Errorf("Expected error for missing path")
+		}
+	})
+}
@@ -0,0 +1,137 @@
+// Package file provides the tools that create and read symbolic links.
+package file
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/d-kuro/claude-code-mcp/internal/prompts"
+	"github.com/d-kuro/claude-code-mcp/internal/tools"
+)
+
+// SymlinkArgs represents the arguments for the Symlink tool.
+type SymlinkArgs struct {
+	// Target is the path the link points to. A relative target is stored as
+	// is and resolved from the directory of the link.
+	Target   string `json:"target"`
+	LinkPath string `json:"link_path"`
+}
+
+// ReadLinkArgs represents the arguments for the ReadLink tool.
+type ReadLinkArgs struct {
+	Path string `json:"path"`
+}
+
+// CreateSymlinkTool creates the Symlink tool using MCP SDK patterns.
+func CreateSymlinkTool(ctx *tools.Context) *tools.ServerTool {
+	handler := func(ctxReq context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[SymlinkArgs]) (*mcp.CallToolResultFor[any], error) {
+		args := params.Arguments
+
+		if args.Target == "" {
+			return tools.EmptyFieldError("target"), nil
+		}
+
+		linkPath, err := ctx.Validator.SanitizePath(args.LinkPath)
+		if err != nil {
+			return tools.InvalidPathError(err), nil
+		}
+
+		if err := ctx.Validator.ValidateWritePath(linkPath); err != nil {
+			return tools.PathValidationError(err), nil
+		}
+
+		// The link must not give access to a path the tools could not reach
+		// themselves. It sits in a writable directory, where files may be
+		// written through it, so its destination must be writable too
+		targetPath, err := ctx.Validator.SanitizePath(resolveLinkTarget(linkPath, args.Target))
+		if err != nil {
+			return tools.ValidationErrorResult("Invalid target", err), nil
+		}
+
+		if err := ctx.Validator.ValidateWritePath(targetPath); err != nil {
+			return tools.ValidationErrorResult("Invalid target", err), nil
+		}
+
+		if _, err := os.Lstat(linkPath); err == nil {
+			return tools.ErrorResponsef("%s already exists", linkPath), nil
+		}
+
+		if err := os.Symlink(args.Target, linkPath); err != nil {
+			return tools.ErrorResponsef("failed to create symlink: %v", err), nil
+		}
+
+		return tools.SuccessResponsef("Created symlink %s -> %s", linkPath, args.Target), nil
+	}
+
+	tool := &mcp.Tool{
+		Name:        "Symlink",
+		Description: prompts.SymlinkToolDoc,
+	}
+
+	return &tools.ServerTool{
+		Tool: tool,
+		RegisterFunc: func(server *mcp.Server) {
+			mcp.AddTool(server, tool, handler)
+		},
+	}
+}
+
+// CreateReadLinkTool creates the ReadLink tool using MCP SDK patterns.
+func CreateReadLinkTool(ctx *tools.Context) *tools.ServerTool {
+	handler := func(ctxReq context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[ReadLinkArgs]) (*mcp.CallToolResultFor[any], error) {
+		args := params.Arguments
+
+		sanitizedPath, err := ctx.Validator.SanitizePath(args.Path)
+		if err != nil {
+			return tools.InvalidPathError(err), nil
+		}
+
+		if err := ctx.Validator.ValidatePath(sanitizedPath); err != nil {
+			return tools.PathValidationError(err), nil
+		}
+
+		info, err := os.Lstat(sanitizedPath)
+		if err != nil {
+			return tools.ErrorResponsef("failed to stat path: %v", err), nil
+		}
+		if info.Mode()&os.ModeSymlink == 0 {
+			return tools.ErrorResponsef("%s is not a symbolic link", sanitizedPath), nil
+		}
+
+		target, err := os.Readlink(sanitizedPath)
+		if err != nil {
+			return tools.ErrorResponsef("failed to read symlink: %v", err), nil
+		}
+
+		return tools.SuccessResponse(target), nil
+	}
+
+	tool := &mcp.Tool{
+		Name:        "ReadLink",
+		Description: prompts.ReadLinkToolDoc,
+	}
+
+	return &tools.ServerTool{
+		Tool: tool,
+		RegisterFunc: func(server *mcp.Server) {
+			mcp.AddTool(server, tool, handler)
+		},
+	}
+}
+
+// resolveLinkTarget returns the absolute path a link at linkPath to target
+// points to. A relative target is resolved from the link's directory, after
+// following symlinks in that directory as the kernel does.
+func resolveLinkTarget(linkPath, target string) string {
+	if filepath.IsAbs(target) {
+		return target
+	}
+	dir := filepath.Dir(linkPath)
+	if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+		dir = resolved
+	}
+	return filepath.Join(dir, target)
+}
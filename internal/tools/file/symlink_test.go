@@ -0,0 +1,133 @@
+package file
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/d-kuro/claude-code-mcp/internal/security"
+	"github.com/d-kuro/claude-code-mcp/internal/tools"
+)
+
+func TestSymlinkAndReadLink(t *testing.T) {
+	allowed := t.TempDir()
+	outside := t.TempDir()
+	if err := os.WriteFile(filepath.Join(allowed, "real.txt"), []byte("content\n"), 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(outside, "secret.txt"), []byte("secret\n"), 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+
+	ctx := &tools.Context{Validator: security.NewDefaultValidator().WithAllowedPaths([]string{allowed})}
+	symlink := func(target, linkPath string) (string, bool) {
+		t.Helper()
+		return callServerTool(t, CreateSymlinkTool(ctx), map[string]any{"target": target, "link_path": linkPath})
+	}
+	readLink := func(path string) (string, bool) {
+		t.Helper()
+		return callServerTool(t, CreateReadLinkTool(ctx), map[string]any{"path": path})
+	}
+
+	t.Run("relative target within allowed paths", func(t *testing.T) {
+		link := filepath.Join(allowed, "link.txt")
+		if output, isError := symlink("real.txt", link); isError {
+			t.Fatalf("Symlink failed: %s", output)
+		}
+		content, err := os.ReadFile(link)
+		if err != nil || string(content) != "content\n" {
+			t.Errorf("Expected the link to resolve to real.txt, got %q (err %v)", content, err)
+		}
+
+		output, isError := readLink(link)
+		if isError || output != "real.txt" {
+			t.Errorf("Expected ReadLink to return the stored target, got %q (error %v)", output, isError)
+		}
+
+		if output, isError := symlink("real.txt", link); !isError || !strings.Contains(output, "already exists") {
+			t.Errorf("Expected an existing link path to be refused, got: %s", output)
+		}
+	})
+
+	t.Run("absolute target within allowed paths", func(t *testing.T) {
+		target := filepath.Join(allowed, "real.txt")
+		link := filepath.Join(allowed, "absolute.txt")
+		if output, isError := symlink(target, link); isError {
+			t.Fatalf("Symlink failed: %s", output)
+		}
+		if output, _ := readLink(link); output != target {
+			t.Errorf("Expected %s, got %s", target, output)
+		}
+	})
+
+	escapes := []struct {
+		name   string
+		target string
+	}{
+		{"absolute", filepath.Join(outside, "secret.txt")},
+		{"relative", filepath.Join("..", filepath.Base(outside), "secret.txt")},
+		{"blocked", "/etc/passwd"},
+	}
+	for _, tt := range escapes {
+		t.Run("rejects "+tt.name+" target outside allowed paths", func(t *testing.T) {
+			link := filepath.Join(allowed, "escape-"+tt.name)
+			output, isError := symlink(tt.target, link)
+			if !isError || !strings.Contains(output, "Invalid target") {
+				t.Errorf("Expected the target to be rejected, got: %s", output)
+			}
+			if _, err := os.Lstat(link); !os.IsNotExist(err) {
+				t.Errorf("Expected no link to be created, got err %v", err)
+			}
+		})
+	}
+
+	t.Run("rejects target through a link that escapes", func(t *testing.T) {
+		// A link created outside the tools leads out of the allowed paths
+		if err := os.Symlink(outside, filepath.Join(allowed, "out")); err != nil {
+			t.Fatalf("Failed to create symlink: %v", err)
+		}
+		output, isError := symlink("out/secret.txt", filepath.Join(allowed, "chained"))
+		if !isError || !strings.Contains(output, "Invalid target") {
+			t.Errorf("Expected the target to be rejected, got: %s", output)
+		}
+	})
+
+	t.Run("ReadLink on a regular file", func(t *testing.T) {
+		output, isError := readLink(filepath.Join(allowed, "real.txt"))
+		if !isError || !strings.Contains(output, "not a symbolic link") {
+			t.Errorf("Expected a not a symbolic link error, got: %s", output)
+		}
+	})
+}
+
+func TestSymlinkCannotOpenReadOnlyDirectoryForWrites(t *testing.T) {
+	project := t.TempDir()
+	src := filepath.Join(project, "src")
+	out := filepath.Join(project, "out")
+	for _, dir := range []string{src, out} {
+		if err := os.Mkdir(dir, 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+	}
+
+	validator := security.NewDefaultValidator().WithAllowedPaths([]string{project}).WithWritablePaths([]string{out})
+	ctx := &tools.Context{Validator: validator}
+
+	output, isError := callServerTool(t, CreateSymlinkTool(ctx), map[string]any{"target": src, "link_path": filepath.Join(out, "src")})
+	if !isError || !strings.Contains(output, "Invalid target") {
+		t.Errorf("Expected a read-only target to be rejected, got: %s", output)
+	}
+
+	// A link created outside the tools still does not make the directory writable
+	if err := os.Symlink(src, filepath.Join(out, "linked")); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+	output, isError = callServerTool(t, CreateWriteTool(ctx), map[string]any{"file_path": filepath.Join(out, "linked", "new.go"), "content": "package main\n"})
+	if !isError || !strings.Contains(output, "not writable") {
+		t.Errorf("Expected a write through the link to be rejected, got: %s", output)
+	}
+	if _, err := os.Stat(filepath.Join(src, "new.go")); !os.IsNotExist(err) {
+		t.Errorf("Expected no file in the read-only directory, got err %v", err)
+	}
+}
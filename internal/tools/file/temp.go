@@ -0,0 +1,96 @@
+// Package file provides the TempFile and TempDir tools.
+package file
+
+import (
+	"context"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/d-kuro/claude-code-mcp/internal/prompts"
+	"github.com/d-kuro/claude-code-mcp/internal/tools"
+)
+
+// TempFileArgs represents the arguments for the TempFile tool.
+type TempFileArgs struct {
+	Prefix string `json:"prefix,omitempty"`
+	Suffix string `json:"suffix,omitempty"`
+}
+
+// TempDirArgs represents the arguments for the TempDir tool.
+type TempDirArgs struct {
+	Prefix string `json:"prefix,omitempty"`
+}
+
+// CreateTempFileTool creates the TempFile tool using MCP SDK patterns.
+func CreateTempFileTool(ctx *tools.Context) *tools.ServerTool {
+	workspace := tempWorkspace(ctx)
+
+	handler := func(ctxReq context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[TempFileArgs]) (*mcp.CallToolResultFor[any], error) {
+		args := params.Arguments
+
+		if err := ctx.Validator.ValidateWritePath(workspace.Root()); err != nil {
+			return tools.PathValidationError(err), nil
+		}
+
+		path, err := workspace.CreateFile(args.Prefix, args.Suffix)
+		if err != nil {
+			return tools.ErrorResponse(err.Error()), nil
+		}
+
+		return tools.SuccessResponse(path), nil
+	}
+
+	tool := &mcp.Tool{
+		Name:        "TempFile",
+		Description: prompts.TempFileToolDoc,
+	}
+
+	return &tools.ServerTool{
+		Tool: tool,
+		RegisterFunc: func(server *mcp.Server) {
+			mcp.AddTool(server, tool, handler)
+		},
+	}
+}
+
+// CreateTempDirTool creates the TempDir tool using MCP SDK patterns.
+func CreateTempDirTool(ctx *tools.Context) *tools.ServerTool {
+	workspace := tempWorkspace(ctx)
+
+	handler := func(ctxReq context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[TempDirArgs]) (*mcp.CallToolResultFor[any], error) {
+		args := params.Arguments
+
+		if err := ctx.Validator.ValidateWritePath(workspace.Root()); err != nil {
+			return tools.PathValidationError(err), nil
+		}
+
+		path, err := workspace.CreateDir(args.Prefix)
+		if err != nil {
+			return tools.ErrorResponse(err.Error()), nil
+		}
+
+		return tools.SuccessResponse(path), nil
+	}
+
+	tool := &mcp.Tool{
+		Name:        "TempDir",
+		Description: prompts.TempDirToolDoc,
+	}
+
+	return &tools.ServerTool{
+		Tool: tool,
+		RegisterFunc: func(server *mcp.Server) {
+			mcp.AddTool(server, tool, handler)
+		},
+	}
+}
+
+// tempWorkspace returns the configured temp workspace, or one under the
+// system temp directory when none is set. The fallback is stored in ctx so
+// TempFile and TempDir share it.
+func tempWorkspace(ctx *tools.Context) *tools.TempWorkspace {
+	if ctx.TempWorkspace == nil {
+		ctx.TempWorkspace = tools.NewTempWorkspace("")
+	}
+	return ctx.TempWorkspace
+}
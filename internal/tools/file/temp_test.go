@@ -0,0 +1,73 @@
+package file
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/d-kuro/claude-code-mcp/internal/tools"
+)
+
+func TestTempFileTool(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "scratch")
+	workspace := tools.NewTempWorkspace(root)
+	ctx := (&tools.Context{Validator: &mockValidator{}}).WithTempWorkspace(workspace)
+
+	t.Run("file", func(t *testing.T) {
+		path, isError := callServerTool(t, CreateTempFileTool(ctx), map[string]any{"prefix": "report-", "suffix": ".json"})
+		if isError {
+			t.Fatalf("TempFile failed: %s", path)
+		}
+		if filepath.Dir(path) != root {
+			t.Errorf("Expected the file under %s, got %s", root, path)
+		}
+		name := filepath.Base(path)
+		if !strings.HasPrefix(name, "report-") || !strings.HasSuffix(name, ".json") {
+			t.Errorf("Expected the prefix and suffix in the file name, got %s", name)
+		}
+		if info, err := os.Stat(path); err != nil || !info.Mode().IsRegular() {
+			t.Errorf("Expected a regular file at %s, got %v", path, err)
+		}
+	})
+
+	t.Run("directory", func(t *testing.T) {
+		path, isError := callServerTool(t, CreateTempDirTool(ctx), map[string]any{"prefix": "build-"})
+		if isError {
+			t.Fatalf("TempDir failed: %s", path)
+		}
+		if filepath.Dir(path) != root {
+			t.Errorf("Expected the directory under %s, got %s", root, path)
+		}
+		if info, err := os.Stat(path); err != nil || !info.IsDir() {
+			t.Errorf("Expected a directory at %s, got %v", path, err)
+		}
+	})
+
+	t.Run("path separator in prefix", func(t *testing.T) {
+		text, isError := callServerTool(t, CreateTempFileTool(ctx), map[string]any{"prefix": "../escape-"})
+		if !isError || !strings.Contains(text, "must not contain path separators") {
+			t.Errorf("Expected a prefix with a path separator to be rejected, got %q", text)
+		}
+	})
+
+	t.Run("root not allowed", func(t *testing.T) {
+		forbidden := (&tools.Context{Validator: &mockValidator{}}).WithTempWorkspace(tools.NewTempWorkspace(filepath.Join(t.TempDir(), "forbidden")))
+		text, isError := callServerTool(t, CreateTempFileTool(forbidden), map[string]any{})
+		if !isError || !strings.Contains(text, "forbidden path") {
+			t.Errorf("Expected a temp root rejected by the validator to fail, got %q", text)
+		}
+	})
+
+	if got := len(workspace.Paths()); got != 2 {
+		t.Fatalf("Expected 2 tracked paths, got %d", got)
+	}
+	removed, err := workspace.Cleanup()
+	if err != nil || removed != 2 {
+		t.Fatalf("Cleanup() = %d, %v; want 2, nil", removed, err)
+	}
+	entries, err := os.ReadDir(root)
+	if err != nil || len(entries) != 0 {
+		t.Errorf("Expected an empty temp root after cleanup, got %v, %v", entries, err)
+	}
+}
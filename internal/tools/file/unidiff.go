@@ -0,0 +1,319 @@
+// Package file provides parsing and application of unified diffs.
+package file
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// devNull is the path used in diff headers for a file that does not exist.
+const devNull = "/dev/null"
+
+// filePatch holds the hunks of a unified diff that apply to one file.
+type filePatch struct {
+	OldPath string
+	NewPath string
+	Hunks   []hunk
+}
+
+// hunk is a single "@@ -a,b +c,d @@" section of a unified diff.
+type hunk struct {
+	OldStart int
+	OldLines int
+	NewStart int
+	NewLines int
+	Lines    []hunkLine
+	// OldNoNewline and NewNoNewline are set by "\ No newline at end of file"
+	// markers, meaning the hunk ends at the end of a file without a final newline.
+	OldNoNewline bool
+	NewNoNewline bool
+}
+
+// hunkLine is a context (' '), removed ('-') or added ('+') line of a hunk.
+type hunkLine struct {
+	Kind byte
+	Text string
+}
+
+// header returns the hunk range line, used in error messages.
+func (h hunk) header() string {
+	return fmt.Sprintf("@@ -%d,%d +%d,%d @@", h.OldStart, h.OldLines, h.NewStart, h.NewLines)
+}
+
+// oldLines returns the lines the hunk expects to find in the original file.
+func (h hunk) oldLines() []string {
+	var lines []string
+	for _, line := range h.Lines {
+		if line.Kind != '+' {
+			lines = append(lines, line.Text)
+		}
+	}
+	return lines
+}
+
+// newLines returns the lines the hunk leaves in the patched file.
+func (h hunk) newLines() []string {
+	var lines []string
+	for _, line := range h.Lines {
+		if line.Kind != '-' {
+			lines = append(lines, line.Text)
+		}
+	}
+	return lines
+}
+
+// parsePatch parses a unified diff into per-file patches. Lines outside of
+// file sections, such as "diff --git" and "index" lines, are ignored. When
+// every path carries a git-style "a/" or "b/" prefix, the prefix is removed.
+func parsePatch(patch string) ([]filePatch, error) {
+	lines := strings.Split(strings.ReplaceAll(patch, "\r\n", "\n"), "\n")
+
+	var patches []filePatch
+	for i := 0; i < len(lines); i++ {
+		if !strings.HasPrefix(lines[i], "--- ") {
+			continue
+		}
+		if i+1 >= len(lines) || !strings.HasPrefix(lines[i+1], "+++ ") {
+			return nil, fmt.Errorf("line %d: expected a \"+++\" header after %q", i+2, lines[i])
+		}
+
+		fp := filePatch{
+			OldPath: headerPath(lines[i][len("--- "):]),
+			NewPath: headerPath(lines[i+1][len("+++ "):]),
+		}
+		i += 2
+
+		for i < len(lines) && strings.HasPrefix(lines[i], "@@ ") {
+			h, next, err := parseHunk(lines, i)
+			if err != nil {
+				return nil, err
+			}
+			fp.Hunks = append(fp.Hunks, h)
+			i = next
+		}
+		i--
+
+		if len(fp.Hunks) == 0 {
+			return nil, fmt.Errorf("no hunks found for %s", fp.displayPath())
+		}
+		patches = append(patches, fp)
+	}
+
+	if len(patches) == 0 {
+		return nil, fmt.Errorf("no file changes found; expected unified diff headers (--- and +++)")
+	}
+
+	stripGitPrefixes(patches)
+	return patches, nil
+}
+
+// parseHunk parses the hunk starting at lines[start] and returns it with the
+// index of the first line after it.
+func parseHunk(lines []string, start int) (hunk, int, error) {
+	var h hunk
+	if err := parseHunkHeader(lines[start], &h); err != nil {
+		return h, 0, fmt.Errorf("line %d: %w", start+1, err)
+	}
+
+	oldSeen, newSeen := 0, 0
+	i := start + 1
+	for ; i < len(lines) && (oldSeen < h.OldLines || newSeen < h.NewLines); i++ {
+		line := lines[i]
+		if line == "" {
+			// Editors often strip the single space of empty context lines
+			line = " "
+		}
+
+		switch line[0] {
+		case ' ':
+			oldSeen++
+			newSeen++
+		case '-':
+			oldSeen++
+		case '+':
+			newSeen++
+		case '\\':
+			markNoNewline(&h)
+			continue
+		default:
+			return h, 0, fmt.Errorf("line %d: unexpected line in hunk %s: %q", i+1, h.header(), line)
+		}
+		h.Lines = append(h.Lines, hunkLine{Kind: line[0], Text: line[1:]})
+	}
+
+	if oldSeen != h.OldLines || newSeen != h.NewLines {
+		return h, 0, fmt.Errorf("hunk %s is truncated: found %d old and %d new lines", h.header(), oldSeen, newSeen)
+	}
+
+	if i < len(lines) && strings.HasPrefix(lines[i], `\`) {
+		markNoNewline(&h)
+		i++
+	}
+
+	return h, i, nil
+}
+
+// parseHunkHeader parses a "@@ -a,b +c,d @@" line. Omitted counts default to 1.
+func parseHunkHeader(line string, h *hunk) error {
+	fields := strings.Fields(line)
+	if len(fields) < 4 || fields[3] != "@@" || !strings.HasPrefix(fields[1], "-") || !strings.HasPrefix(fields[2], "+") {
+		return fmt.Errorf("invalid hunk header %q", line)
+	}
+
+	var err error
+	if h.OldStart, h.OldLines, err = parseRange(fields[1][1:]); err != nil {
+		return fmt.Errorf("invalid hunk header %q: %w", line, err)
+	}
+	if h.NewStart, h.NewLines, err = parseRange(fields[2][1:]); err != nil {
+		return fmt.Errorf("invalid hunk header %q: %w", line, err)
+	}
+	return nil
+}
+
+// parseRange parses a "start,count" or "start" hunk range.
+func parseRange(s string) (int, int, error) {
+	startText, countText, hasCount := strings.Cut(s, ",")
+	start, err := strconv.Atoi(startText)
+	if err != nil || start < 0 {
+		return 0, 0, fmt.Errorf("invalid range %q", s)
+	}
+	if !hasCount {
+		return start, 1, nil
+	}
+	count, err := strconv.Atoi(countText)
+	if err != nil || count < 0 {
+		return 0, 0, fmt.Errorf("invalid range %q", s)
+	}
+	return start, count, nil
+}
+
+// markNoNewline records a "\ No newline at end of file" marker for the last
+// line parsed in the hunk.
+func markNoNewline(h *hunk) {
+	if len(h.Lines) == 0 {
+		return
+	}
+	switch h.Lines[len(h.Lines)-1].Kind {
+	case '-':
+		h.OldNoNewline = true
+	case '+':
+		h.NewNoNewline = true
+	default:
+		h.OldNoNewline = true
+		h.NewNoNewline = true
+	}
+}
+
+// headerPath extracts the path from a "---" or "+++" header, dropping the
+// timestamp that diff(1) appends after a tab.
+func headerPath(header string) string {
+	path, _, _ := strings.Cut(header, "\t")
+	return strings.TrimSpace(path)
+}
+
+// stripGitPrefixes removes the "a/" and "b/" prefixes of git diffs when
+// every path in the patch carries them.
+func stripGitPrefixes(patches []filePatch) {
+	for _, fp := range patches {
+		if (fp.OldPath != devNull && !strings.HasPrefix(fp.OldPath, "a/")) ||
+			(fp.NewPath != devNull && !strings.HasPrefix(fp.NewPath, "b/")) {
+			return
+		}
+	}
+	for i := range patches {
+		if patches[i].OldPath != devNull {
+			patches[i].OldPath = patches[i].OldPath[2:]
+		}
+		if patches[i].NewPath != devNull {
+			patches[i].NewPath = patches[i].NewPath[2:]
+		}
+	}
+}
+
+// displayPath returns the path the patch applies to.
+func (fp filePatch) displayPath() string {
+	if fp.NewPath == devNull {
+		return fp.OldPath
+	}
+	return fp.NewPath
+}
+
+// applyHunks applies the hunks to content in order. Each hunk is matched at
+// the line given in its header or, if the file has shifted, at the nearest
+// position after the previous hunk where all of its context and removed lines
+// match exactly.
+func applyHunks(content string, hunks []hunk) (string, error) {
+	lines := strings.Split(content, "\n")
+	hasNewline := strings.HasSuffix(content, "\n")
+	if hasNewline || content == "" {
+		lines = lines[:len(lines)-1]
+	}
+	endsWithNewline := hasNewline || content == ""
+
+	var result []string
+	pos := 0
+	for i, h := range hunks {
+		old := h.oldLines()
+		// A hunk without old lines inserts after line OldStart
+		expected := h.OldStart - 1
+		if len(old) == 0 {
+			expected = h.OldStart
+		}
+		at := findHunk(lines, old, pos, expected)
+		if at < 0 {
+			return "", fmt.Errorf("hunk %d (%s) does not apply: context mismatch near line %d", i+1, h.header(), max(h.OldStart, 1))
+		}
+		if h.OldNoNewline && (at+len(old) != len(lines) || hasNewline) {
+			return "", fmt.Errorf("hunk %d (%s) does not apply: expected the end of a file without a final newline", i+1, h.header())
+		}
+
+		result = append(result, lines[pos:at]...)
+		result = append(result, h.newLines()...)
+		pos = at + len(old)
+
+		if at+len(old) == len(lines) && (h.OldNoNewline || h.NewNoNewline) {
+			endsWithNewline = !h.NewNoNewline
+		}
+	}
+	result = append(result, lines[pos:]...)
+
+	if len(result) == 0 {
+		return "", nil
+	}
+	patched := strings.Join(result, "\n")
+	if endsWithNewline {
+		patched += "\n"
+	}
+	return patched, nil
+}
+
+// findHunk returns the index at which old matches lines, searching from the
+// expected index outward but never before from. It returns -1 if there is no match.
+func findHunk(lines, old []string, from, expected int) int {
+	if expected < from {
+		expected = from
+	}
+	for offset := 0; ; offset++ {
+		after, before := expected+offset, expected-offset
+		if after+len(old) > len(lines) && before < from {
+			return -1
+		}
+		if after+len(old) <= len(lines) && linesMatch(lines[after:after+len(old)], old) {
+			return after
+		}
+		if offset > 0 && before >= from && before+len(old) <= len(lines) && linesMatch(lines[before:before+len(old)], old) {
+			return before
+		}
+	}
+}
+
+// linesMatch reports whether two line slices are equal.
+func linesMatch(a, b []string) bool {
+	for i := range b {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -2,11 +2,160 @@
 package file
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/d-kuro/claude-code-mcp/internal/ignore"
+	"github.com/d-kuro/claude-code-mcp/internal/tools"
 )
 
+// maxListedOccurrences caps how many line numbers occurrenceLines reports.
+const maxListedOccurrences = 10
+
 // FileMatchInfo represents a file with its modification time for sorting.
 type FileMatchInfo struct {
 	Path    string
 	ModTime time.Time
 }
+
+// sortByModTime orders matches from the most recently modified, breaking
+// ties by path so that files with the same modification time are always
+// listed in the same order.
+func sortByModTime(matches []FileMatchInfo) {
+	sort.Slice(matches, func(i, j int) bool {
+		if !matches[i].ModTime.Equal(matches[j].ModTime) {
+			return matches[i].ModTime.After(matches[j].ModTime)
+		}
+		return matches[i].Path < matches[j].Path
+	})
+}
+
+// checkSearchRoot refuses a search rooted at a filesystem root ("/" or a
+// volume root such as "C:\\") unless allowRoot is set, since it would scan
+// the entire filesystem. A path that is a symbolic link to a root, such as
+// /tmp/r -> /, is refused too.
+func checkSearchRoot(searchPath string, allowRoot bool) error {
+	if allowRoot {
+		return nil
+	}
+
+	cleanPath := filepath.Clean(searchPath)
+	if filepath.Dir(cleanPath) == cleanPath {
+		return fmt.Errorf("refusing to search filesystem root %s", cleanPath)
+	}
+	if resolved, err := filepath.EvalSymlinks(cleanPath); err == nil && filepath.Dir(resolved) == resolved {
+		return fmt.Errorf("refusing to search filesystem root %s, which %s links to", resolved, cleanPath)
+	}
+	return nil
+}
+
+// skippedDirectories returns the directory names a Glob, Grep or LS call
+// skips: the configured default ignores, or none when the call sets
+// default_ignores to false. Setting it to true skips
+// ignore.DefaultDirectories when no default ignores are configured.
+func skippedDirectories(ctx *tools.Context, enabled *bool) []string {
+	if enabled == nil {
+		return ctx.DefaultIgnores
+	}
+	if !*enabled {
+		return nil
+	}
+	if len(ctx.DefaultIgnores) > 0 {
+		return ctx.DefaultIgnores
+	}
+	return ignore.DefaultDirectories
+}
+
+// isSkippedDirectory reports whether a directory name matches one of the
+// skipped directory names or patterns.
+func isSkippedDirectory(name string, skipDirs []string) bool {
+	for _, pattern := range skipDirs {
+		if matched, err := filepath.Match(pattern, name); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveSearchRoot returns the directory a Glob or Grep search starts from.
+// A missing path selects the working directory and a relative one is joined
+// to it before validation, so "../x" is checked as the directory it names.
+// The root must pass the same checks as a file read, including following
+// symlinks into blocked directories, and must not be a filesystem root
+// unless root searches are allowed. It returns an error result when the
+// root is rejected, before any search command is started.
+func resolveSearchRoot(ctx *tools.Context, path *string) (string, *mcp.CallToolResultFor[any]) {
+	searchPath := "."
+	if path != nil && *path != "" {
+		searchPath = *path
+	}
+
+	if !filepath.IsAbs(searchPath) {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return "", tools.ErrorResponsef("Failed to get current working directory: %v", err)
+		}
+		searchPath = filepath.Join(cwd, searchPath)
+	}
+
+	sanitizedPath, err := ctx.Validator.SanitizePath(searchPath)
+	if err != nil {
+		return "", tools.ValidationErrorResult("Invalid search path", err)
+	}
+
+	if err := ctx.Validator.ValidatePath(sanitizedPath); err != nil {
+		return "", tools.ValidationErrorResult("Path validation failed", err)
+	}
+
+	if err := checkSearchRoot(sanitizedPath, ctx.AllowRootSearch); err != nil {
+		return "", tools.ErrorResponse(err.Error())
+	}
+
+	return sanitizedPath, nil
+}
+
+// occurrenceLines returns the 1-based line numbers on which the
+// non-overlapping occurrences of substr start, as counted by strings.Count.
+func occurrenceLines(content, substr string) []int {
+	if substr == "" {
+		return nil
+	}
+
+	var lines []int
+	line, offset := 1, 0
+	for {
+		idx := strings.Index(content[offset:], substr)
+		if idx < 0 {
+			return lines
+		}
+		line += strings.Count(content[offset:offset+idx], "\n")
+		lines = append(lines, line)
+		line += strings.Count(substr, "\n")
+		offset += idx + len(substr)
+	}
+}
+
+// ambiguousMatchError reports that old_string occurs more than once in
+// content, listing the lines of the occurrences so that the caller can add
+// context to pick one.
+func ambiguousMatchError(content, oldString string, count int) error {
+	lines := occurrenceLines(content, oldString)
+	listed := make([]string, 0, min(len(lines), maxListedOccurrences))
+	for _, line := range lines[:min(len(lines), maxListedOccurrences)] {
+		listed = append(listed, strconv.Itoa(line))
+	}
+	if len(lines) > maxListedOccurrences {
+		listed = append(listed, "...")
+	}
+	if len(listed) == 0 {
+		return fmt.Errorf("old_string appears %d times in file - use replace_all=true or provide more context to make it unique", count)
+	}
+	return fmt.Errorf("old_string appears %d times in file (at lines %s) - use replace_all=true or provide more context to make it unique", count, strings.Join(listed, ", "))
+}
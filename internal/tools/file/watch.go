@@ -0,0 +1,247 @@
+// Package file provides file operation tools using the MCP SDK patterns.
+package file
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/d-kuro/claude-code-mcp/internal/prompts"
+	"github.com/d-kuro/claude-code-mcp/internal/tools"
+)
+
+const (
+	// DefaultWatchTimeout is the default time to wait for new lines
+	DefaultWatchTimeout = 5 * time.Second
+	// MaxWatchTimeout is the maximum time a watch may wait
+	MaxWatchTimeout = 60 * time.Second
+	// DefaultWatchMaxLines is the default number of lines to collect
+	DefaultWatchMaxLines = 100
+	// MaxWatchLines is the maximum number of lines a watch may collect
+	MaxWatchLines = 2000
+	// MaxWatchLineBytes is the longest line a watch holds; longer lines are
+	// reported in pieces of this size
+	MaxWatchLineBytes = DefaultBufferSize
+	// watchPollInterval is how often the watched file is checked for changes
+	watchPollInterval = 50 * time.Millisecond
+)
+
+// WatchArgs represents the arguments for the WatchFile tool.
+type WatchArgs struct {
+	Path      string `json:"path"`
+	TimeoutMs int    `json:"timeout_ms,omitempty"`
+	MaxLines  int    `json:"max_lines,omitempty"`
+}
+
+// watchResult holds the lines collected while watching a file.
+type watchResult struct {
+	Lines    []string
+	Reset    bool
+	TimedOut bool
+}
+
+// CreateWatchFileTool creates the WatchFile tool using MCP SDK patterns.
+func CreateWatchFileTool(ctx *tools.Context) *tools.ServerTool {
+	handler := func(ctxReq context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[WatchArgs]) (*mcp.CallToolResultFor[any], error) {
+		args := params.Arguments
+
+		sanitizedPath, err := ctx.Validator.SanitizePath(args.Path)
+		if err != nil {
+			return tools.InvalidPathError(err), nil
+		}
+
+		if err := ctx.Validator.ValidatePath(sanitizedPath); err != nil {
+			return tools.PathValidationError(err), nil
+		}
+
+		timeout := DefaultWatchTimeout
+		if args.TimeoutMs < 0 {
+			return tools.InvalidFieldError("timeout_ms", "must not be negative"), nil
+		}
+		if args.TimeoutMs > 0 {
+			timeout = time.Duration(args.TimeoutMs) * time.Millisecond
+		}
+		if timeout > MaxWatchTimeout {
+			return tools.InvalidFieldError("timeout_ms", fmt.Sprintf("must be at most %d", MaxWatchTimeout.Milliseconds())), nil
+		}
+
+		maxLines := DefaultWatchMaxLines
+		if args.MaxLines < 0 || args.MaxLines > MaxWatchLines {
+			return tools.InvalidFieldError("max_lines", fmt.Sprintf("must be between 1 and %d", MaxWatchLines)), nil
+		}
+		if args.MaxLines > 0 {
+			maxLines = args.MaxLines
+		}
+
+		result, err := watchFile(ctxReq, sanitizedPath, timeout, maxLines)
+		if err != nil {
+			return tools.ErrorResponse(err.Error()), nil
+		}
+
+		return tools.SuccessResponse(formatWatchResult(result, timeout)), nil
+	}
+
+	tool := &mcp.Tool{
+		Name:        "WatchFile",
+		Description: prompts.WatchFileToolDoc,
+	}
+
+	return &tools.ServerTool{
+		Tool: tool,
+		RegisterFunc: func(server *mcp.Server) {
+			mcp.AddTool(server, tool, handler)
+		},
+	}
+}
+
+// watchFile collects lines appended to a file after the call starts until
+// maxLines have arrived or the timeout elapses. If the file is truncated or
+// replaced (log rotation), reading restarts from the beginning of the new content.
+func watchFile(ctx context.Context, filePath string, timeout time.Duration, maxLines int) (watchResult, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return watchResult{}, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	stat, err := file.Stat()
+	if err != nil {
+		return watchResult{}, fmt.Errorf("failed to get file info: %w", err)
+	}
+
+	if stat.IsDir() {
+		return watchResult{}, fmt.Errorf("path is a directory, not a file")
+	}
+
+	// Only lines appended after the watch starts are reported
+	offset := stat.Size()
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return watchResult{}, fmt.Errorf("failed to seek file: %w", err)
+	}
+
+	var result watchResult
+	var pending []byte
+	buf := make([]byte, DefaultBufferSize)
+
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	ticker := time.NewTicker(watchPollInterval)
+	defer ticker.Stop()
+
+	for {
+		// Drain everything currently available
+		for len(result.Lines) < maxLines {
+			n, err := file.Read(buf)
+			if n > 0 {
+				offset += int64(n)
+				pending = append(pending, buf[:n]...)
+				for len(result.Lines) < maxLines {
+					line, rest, ok := nextWatchLine(pending)
+					if !ok {
+						break
+					}
+					result.Lines = append(result.Lines, line)
+					pending = rest
+				}
+			}
+			if err == io.EOF || n == 0 {
+				break
+			}
+			if err != nil {
+				return result, fmt.Errorf("error reading file: %w", err)
+			}
+		}
+
+		if len(result.Lines) >= maxLines {
+			return result, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return result, ctx.Err()
+		case <-deadline.C:
+			if len(pending) > 0 {
+				result.Lines = append(result.Lines, string(pending))
+			}
+			result.TimedOut = true
+			return result, nil
+		case <-ticker.C:
+		}
+
+		// Detect truncation or rotation before the next read
+		current, err := os.Stat(filePath)
+		if err != nil {
+			// The file may be briefly missing during rotation
+			continue
+		}
+
+		if !os.SameFile(stat, current) {
+			rotated, err := os.Open(filePath)
+			if err != nil {
+				continue
+			}
+			_ = file.Close()
+			file = rotated
+			stat = current
+			offset = 0
+			pending = nil
+			result.Reset = true
+			continue
+		}
+
+		if current.Size() < offset {
+			if _, err := file.Seek(0, io.SeekStart); err != nil {
+				return result, fmt.Errorf("failed to seek file: %w", err)
+			}
+			offset = 0
+			pending = nil
+			result.Reset = true
+		}
+	}
+}
+
+// nextWatchLine splits the first complete line off pending. A line longer
+// than MaxWatchLineBytes is split at that size, on a rune boundary, so that
+// a file written without newlines cannot grow pending without bound.
+func nextWatchLine(pending []byte) (string, []byte, bool) {
+	if idx := bytes.IndexByte(pending, '\n'); idx >= 0 {
+		return strings.TrimSuffix(string(pending[:idx]), "\r"), pending[idx+1:], true
+	}
+	if len(pending) <= MaxWatchLineBytes {
+		return "", pending, false
+	}
+	cut := MaxWatchLineBytes
+	for cut > 0 && !utf8.RuneStart(pending[cut]) {
+		cut--
+	}
+	if cut == 0 {
+		cut = MaxWatchLineBytes
+	}
+	return string(pending[:cut]), pending[cut:], true
+}
+
+// formatWatchResult renders the collected lines for the tool response.
+func formatWatchResult(result watchResult, timeout time.Duration) string {
+	var builder strings.Builder
+
+	if len(result.Lines) == 0 {
+		builder.WriteString(fmt.Sprintf("No new lines within %v", timeout))
+	} else {
+		builder.WriteString(strings.Join(result.Lines, "\n"))
+	}
+
+	if result.Reset {
+		builder.WriteString("\n(file was truncated or rotated; reading resumed from the start)")
+	}
+
+	return builder.String()
+}
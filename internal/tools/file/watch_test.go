@@ -0,0 +1,188 @@
+package file
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/d-kuro/claude-code-mcp/internal/tools"
+)
+
+// appendToFile appends content to the file at path.
+func appendToFile(t *testing.T, path, content string) {
+	t.Helper()
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Errorf("Failed to open file for append: %v", err)
+		return
+	}
+	defer func() { _ = f.Close() }()
+
+	if _, err := f.WriteString(content); err != nil {
+		t.Errorf("Failed to append to file: %v", err)
+	}
+}
+
+func TestWatchFileCapturesAppendedLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	if err := os.WriteFile(path, []byte("existing line\n"), 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		appendToFile(t, path, "first\nsecond\n")
+		time.Sleep(100 * time.Millisecond)
+		appendToFile(t, path, "third\nfourth\n")
+	}()
+
+	start := time.Now()
+	result, err := watchFile(context.Background(), path, 5*time.Second, 3)
+	if err != nil {
+		t.Fatalf("watchFile failed: %v", err)
+	}
+
+	expected := []string{"first", "second", "third"}
+	if strings.Join(result.Lines, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected lines %v, got %v", expected, result.Lines)
+	}
+	if result.TimedOut {
+		t.Errorf("Expected watch to finish on max lines, not timeout")
+	}
+	if elapsed := time.Since(start); elapsed >= 5*time.Second {
+		t.Errorf("Expected watch to return before the timeout, took %v", elapsed)
+	}
+}
+
+func TestWatchFileTimeout(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	if err := os.WriteFile(path, []byte("existing line\n"), 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		appendToFile(t, path, "complete\npartial")
+	}()
+
+	result, err := watchFile(context.Background(), path, 300*time.Millisecond, 10)
+	if err != nil {
+		t.Fatalf("watchFile failed: %v", err)
+	}
+
+	if !result.TimedOut {
+		t.Errorf("Expected watch to time out")
+	}
+	if strings.Join(result.Lines, ",") != "complete,partial" {
+		t.Errorf("Expected complete and partial lines, got %v", result.Lines)
+	}
+}
+
+func TestWatchFileTruncation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	if err := os.WriteFile(path, []byte(strings.Repeat("old line\n", 10)), 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		if err := os.WriteFile(path, []byte("after truncate\n"), 0644); err != nil {
+			t.Errorf("Failed to truncate file: %v", err)
+		}
+	}()
+
+	result, err := watchFile(context.Background(), path, 2*time.Second, 1)
+	if err != nil {
+		t.Fatalf("watchFile failed: %v", err)
+	}
+
+	if !result.Reset {
+		t.Errorf("Expected truncation to be detected")
+	}
+	if len(result.Lines) != 1 || result.Lines[0] != "after truncate" {
+		t.Errorf("Expected line written after truncation, got %v", result.Lines)
+	}
+}
+
+func TestWatchFileRotation(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	if err := os.WriteFile(path, []byte("old\n"), 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		if err := os.Rename(path, filepath.Join(dir, "app.log.1")); err != nil {
+			t.Errorf("Failed to rotate file: %v", err)
+			return
+		}
+		if err := os.WriteFile(path, []byte("rotated\n"), 0644); err != nil {
+			t.Errorf("Failed to create rotated file: %v", err)
+		}
+	}()
+
+	result, err := watchFile(context.Background(), path, 2*time.Second, 1)
+	if err != nil {
+		t.Fatalf("watchFile failed: %v", err)
+	}
+
+	if !result.Reset {
+		t.Errorf("Expected rotation to be detected")
+	}
+	if len(result.Lines) != 1 || result.Lines[0] != "rotated" {
+		t.Errorf("Expected line from the new file, got %v", result.Lines)
+	}
+}
+
+func TestWatchFileErrors(t *testing.T) {
+	if _, err := watchFile(context.Background(), filepath.Join(t.TempDir(), "missing.log"), time.Second, 1); err == nil {
+		t.Errorf("Expected error for missing file")
+	}
+
+	if _, err := watchFile(context.Background(), t.TempDir(), time.Second, 1); err == nil {
+		t.Errorf("Expected error for directory")
+	}
+}
+
+func TestWatchFileSplitsLongLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	if err := os.WriteFile(path, nil, 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		appendToFile(t, path, strings.Repeat("x", MaxWatchLineBytes+10))
+	}()
+
+	result, err := watchFile(context.Background(), path, 300*time.Millisecond, 10)
+	if err != nil {
+		t.Fatalf("watchFile failed: %v", err)
+	}
+
+	if len(result.Lines) != 2 || len(result.Lines[0]) != MaxWatchLineBytes || len(result.Lines[1]) != 10 {
+		lengths := make([]int, len(result.Lines))
+		for i, line := range result.Lines {
+			lengths[i] = len(line)
+		}
+		t.Errorf("Expected a full piece and the 10 byte remainder, got lines of %v bytes", lengths)
+	}
+}
+
+func TestWatchFileToolRejectsLongTimeout(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	if err := os.WriteFile(path, nil, 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+
+	ctx := &tools.Context{Validator: &mockValidator{}}
+	output, isError := callServerTool(t, CreateWatchFileTool(ctx), map[string]any{"path": path, "timeout_ms": 61000})
+	if !isError || !strings.Contains(output, "timeout_ms") {
+		t.Errorf("Expected a timeout_ms validation error, got: %s", output)
+	}
+}
@@ -17,6 +17,9 @@ import (
 type WriteArgs struct {
 	FilePath string `json:"file_path"`
 	Content  string `json:"content"`
+	// SkipIfUnchanged leaves a file that already holds Content untouched,
+	// so a retried Write does not bump its modification time.
+	SkipIfUnchanged *bool `json:"skip_if_unchanged,omitempty"`
 }
 
 // CreateWriteTool creates the Write tool using MCP SDK patterns.
@@ -26,20 +29,20 @@ func CreateWriteTool(ctx *tools.Context) *tools.ServerTool {
 
 		sanitizedPath, err := ctx.Validator.SanitizePath(args.FilePath)
 		if err != nil {
-			return &mcp.CallToolResultFor[any]{
-				Content: []mcp.Content{&mcp.TextContent{Text: "Error: Invalid file path: " + err.Error()}},
-				IsError: true,
-			}, nil
+			return tools.ValidationErrorResult("Invalid file path", err), nil
 		}
 
-		if err := ctx.Validator.ValidatePath(sanitizedPath); err != nil {
+		if err := ctx.Validator.ValidateWritePath(sanitizedPath); err != nil {
+			return tools.ValidationErrorResult("Path validation failed", err), nil
+		}
+
+		if args.SkipIfUnchanged != nil && *args.SkipIfUnchanged && fileHasContent(sanitizedPath, args.Content) {
 			return &mcp.CallToolResultFor[any]{
-				Content: []mcp.Content{&mcp.TextContent{Text: "Error: Path validation failed: " + err.Error()}},
-				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("No change: %s already has this content (%d bytes)", sanitizedPath, len(args.Content))}},
 			}, nil
 		}
 
-		bytesWritten, err := writeFileContent(sanitizedPath, args.Content)
+		bytesWritten, err := writeFileContent(sanitizedPath, args.Content, ctx.NewFileMode(), ctx.NewDirMode(), newWriteOptions(ctx))
 		if err != nil {
 			return &mcp.CallToolResultFor[any]{
 				Content: []mcp.Content{&mcp.TextContent{Text: "Error: " + err.Error()}},
@@ -66,13 +69,38 @@ func CreateWriteTool(ctx *tools.Context) *tools.ServerTool {
 }
 
 // writeFileContent writes content to a file, creating directories as needed.
-func writeFileContent(filePath, content string) (int, error) {
+// New files and directories are created with fileMode and dirMode (before the umask);
+// existing files keep their permissions. Attempts failing with a transient
+// error are retried up to opts.retries times.
+func writeFileContent(filePath, content string, fileMode, dirMode os.FileMode, opts writeOptions) (int, error) {
 	dir := filepath.Dir(filePath)
-	if err := os.MkdirAll(dir, 0755); err != nil {
+	if err := os.MkdirAll(dir, dirMode); err != nil {
 		return 0, fmt.Errorf("failed to create directory: %w", err)
 	}
 
-	file, err := os.Create(filePath)
+	var bytesWritten int
+	err := retryTransient(opts, func() error {
+		var err error
+		bytesWritten, err = writeFileOnce(filePath, content, fileMode)
+		return err
+	})
+	return bytesWritten, err
+}
+
+// fileHasContent reports whether filePath is a regular file holding exactly
+// content. A missing or unreadable file reports false.
+func fileHasContent(filePath, content string) bool {
+	info, err := os.Stat(filePath)
+	if err != nil || !info.Mode().IsRegular() || info.Size() != int64(len(content)) {
+		return false
+	}
+	existing, err := os.ReadFile(filePath)
+	return err == nil && string(existing) == content
+}
+
+// writeFileOnce truncates filePath and writes content to it in one attempt.
+func writeFileOnce(filePath, content string, fileMode os.FileMode) (int, error) {
+	file, err := os.OpenFile(filePath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, fileMode)
 	if err != nil {
 		return 0, fmt.Errorf("failed to create file: %w", err)
 	}
@@ -0,0 +1,98 @@
+package file
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/d-kuro/claude-code-mcp/internal/tools"
+)
+
+func TestWriteFileContentModes(t *testing.T) {
+	ctx := (&tools.Context{}).WithDefaultFileMode(0640).WithDefaultDirMode(0750)
+	path := filepath.Join(t.TempDir(), "nested", "new.txt")
+
+	if _, err := writeFileContent(path, "hello\n", ctx.NewFileMode(), ctx.NewDirMode(), writeOptions{}); err != nil {
+		t.Fatalf("writeFileContent() error = %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Failed to stat file: %v", err)
+	}
+	if got := info.Mode().Perm(); got != 0640 {
+		t.Errorf("Expected file mode 0640, got %#o", got)
+	}
+
+	dirInfo, err := os.Stat(filepath.Dir(path))
+	if err != nil {
+		t.Fatalf("Failed to stat directory: %v", err)
+	}
+	if got := dirInfo.Mode().Perm(); got != 0750 {
+		t.Errorf("Expected directory mode 0750, got %#o", got)
+	}
+}
+
+func TestContextDefaultModes(t *testing.T) {
+	ctx := &tools.Context{}
+	if got := ctx.NewFileMode(); got != tools.DefaultFileMode {
+		t.Errorf("Expected default file mode %#o, got %#o", tools.DefaultFileMode, got)
+	}
+	if got := ctx.NewDirMode(); got != tools.DefaultDirMode {
+		t.Errorf("Expected default directory mode %#o, got %#o", tools.DefaultDirMode, got)
+	}
+}
+
+func TestWriteSkipIfUnchanged(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.txt")
+	if err := os.WriteFile(path, []byte("same\n"), 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	old := time.Now().Add(-time.Hour).Truncate(time.Second)
+	if err := os.Chtimes(path, old, old); err != nil {
+		t.Fatalf("Failed to set file times: %v", err)
+	}
+
+	write := func(content string) string {
+		t.Helper()
+		args := map[string]any{"file_path": path, "content": content, "skip_if_unchanged": true}
+		output, isError := callServerTool(t, CreateWriteTool(&tools.Context{Validator: &mockValidator{}}), args)
+		if isError {
+			t.Fatalf("Write failed: %s", output)
+		}
+		return output
+	}
+
+	if output := write("same\n"); !strings.HasPrefix(output, "No change:") {
+		t.Errorf("Expected identical content to be skipped, got: %s", output)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Failed to stat file: %v", err)
+	}
+	if !info.ModTime().Equal(old) {
+		t.Errorf("Expected modification time %v to be kept, got %v", old, info.ModTime())
+	}
+
+	if output := write("changed\n"); !strings.Contains(output, "written successfully") {
+		t.Errorf("Expected changed content to be written, got: %s", output)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read file: %v", err)
+	}
+	if string(content) != "changed\n" {
+		t.Errorf("Expected new content, got %q", content)
+	}
+
+	created := filepath.Join(filepath.Dir(path), "new.txt")
+	args := map[string]any{"file_path": created, "content": "", "skip_if_unchanged": true}
+	if output, isError := callServerTool(t, CreateWriteTool(&tools.Context{Validator: &mockValidator{}}), args); isError || !strings.Contains(output, "written successfully") {
+		t.Errorf("Expected a missing file to be created, got: %s", output)
+	}
+	if _, err := os.Stat(created); err != nil {
+		t.Errorf("Expected file to be created: %v", err)
+	}
+}
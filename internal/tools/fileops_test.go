@@ -717,6 +717,10 @@ func (m *mockValidator) ValidatePath(path string) error {
 	return m.validateError
 }
 
+func (m *mockValidator) ValidateWritePath(path string) error {
+	return m.ValidatePath(path)
+}
+
 func (m *mockValidator) ValidateContent(content []byte) error {
 	return nil
 }
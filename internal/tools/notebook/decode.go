@@ -0,0 +1,109 @@
+// Package notebook provides bounded parsing of Jupyter notebook files.
+package notebook
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// DefaultMaxNotebookSize is the largest notebook file, in bytes, that
+// NotebookRead and NotebookEdit load when no other limit is configured.
+const DefaultMaxNotebookSize = 100 * 1024 * 1024
+
+// checkNotebookSize rejects a notebook file of size bytes that is larger than
+// maxSize. A zero maxSize selects DefaultMaxNotebookSize.
+func checkNotebookSize(size, maxSize int64) error {
+	if maxSize <= 0 {
+		maxSize = DefaultMaxNotebookSize
+	}
+	if size > maxSize {
+		return fmt.Errorf("notebook file is %d bytes, which exceeds the maximum notebook size of %d bytes", size, maxSize)
+	}
+	return nil
+}
+
+// decodeNotebook parses a notebook from r one cell at a time instead of
+// loading the whole document first. When omitOutputs is set, the outputs of
+// each cell are dropped as soon as the cell is decoded, so large outputs such
+// as base64 images are never held for more than one cell, and only their
+// count is kept for display.
+func decodeNotebook(r io.Reader, omitOutputs bool) (*JupyterNotebook, error) {
+	decoder := json.NewDecoder(r)
+	if err := expectDelim(decoder, '{'); err != nil {
+		return nil, err
+	}
+
+	notebook := &JupyterNotebook{}
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		var field any
+		switch token {
+		case "cells":
+			if err := decodeCells(decoder, notebook, omitOutputs); err != nil {
+				return nil, err
+			}
+			continue
+		case "metadata":
+			field = &notebook.Metadata
+		case "nbformat":
+			field = &notebook.NBFormat
+		case "nbformat_minor":
+			field = &notebook.NBFormatMinor
+		default:
+			field = &json.RawMessage{}
+		}
+		if err := decoder.Decode(field); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := expectDelim(decoder, '}'); err != nil {
+		return nil, err
+	}
+	return notebook, nil
+}
+
+// decodeCells decodes the cells array into notebook.
+func decodeCells(decoder *json.Decoder, notebook *JupyterNotebook, omitOutputs bool) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	if token == nil {
+		return nil
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '[' {
+		return fmt.Errorf("cells must be an array")
+	}
+
+	for decoder.More() {
+		var cell JupyterCell
+		if err := decoder.Decode(&cell); err != nil {
+			return err
+		}
+		if omitOutputs && len(cell.Outputs) > 0 {
+			cell.omittedOutputs = len(cell.Outputs)
+			cell.Outputs = nil
+		}
+		notebook.Cells = append(notebook.Cells, cell)
+	}
+
+	return expectDelim(decoder, ']')
+}
+
+// expectDelim reads the next token and checks that it is delim.
+func expectDelim(decoder *json.Decoder, delim json.Delim) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	if token != delim {
+		return fmt.Errorf("expected %q, got %v", delim, token)
+	}
+	return nil
+}
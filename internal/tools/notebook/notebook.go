@@ -21,6 +21,16 @@ import (
 // NotebookReadArgs represents the arguments for the NotebookRead tool.
 type NotebookReadArgs struct {
 	NotebookPath string `json:"notebook_path"`
+	OmitOutputs  *bool  `json:"omit_outputs,omitempty"`
+}
+
+// readOptions controls how readNotebookContent loads a notebook.
+type readOptions struct {
+	// MaxSize is the largest notebook file accepted, in bytes. Zero selects
+	// DefaultMaxNotebookSize.
+	MaxSize int64
+	// OmitOutputs leaves cell outputs out of the result, showing only their count.
+	OmitOutputs bool
 }
 
 // NotebookEditArgs represents the arguments for the NotebookEdit tool.
@@ -48,6 +58,48 @@ type JupyterCell struct {
 	Metadata       map[string]interface{} `json:"metadata,omitempty"`
 	Outputs        []interface{}          `json:"outputs,omitempty"`
 	ExecutionCount *int                   `json:"execution_count,omitempty"`
+
+	// omittedOutputs counts the outputs dropped by decodeNotebook.
+	omittedOutputs int
+}
+
+// validCellTypes lists the cell types accepted by nbformat v4.
+var validCellTypes = map[string]bool{
+	"code":     true,
+	"markdown": true,
+	"raw":      true,
+}
+
+// MarshalJSON encodes the cell, always emitting the fields required by nbformat:
+// metadata for every cell, plus outputs and execution_count for code cells.
+func (c JupyterCell) MarshalJSON() ([]byte, error) {
+	metadata := c.Metadata
+	if metadata == nil {
+		metadata = map[string]interface{}{}
+	}
+
+	if c.CellType != "code" {
+		return json.Marshal(struct {
+			ID       string                 `json:"id,omitempty"`
+			CellType string                 `json:"cell_type"`
+			Source   interface{}            `json:"source"`
+			Metadata map[string]interface{} `json:"metadata"`
+		}{c.ID, c.CellType, c.Source, metadata})
+	}
+
+	outputs := c.Outputs
+	if outputs == nil {
+		outputs = []interface{}{}
+	}
+
+	return json.Marshal(struct {
+		ID             string                 `json:"id,omitempty"`
+		CellType       string                 `json:"cell_type"`
+		Source         interface{}            `json:"source"`
+		Metadata       map[string]interface{} `json:"metadata"`
+		Outputs        []interface{}          `json:"outputs"`
+		ExecutionCount *int                   `json:"execution_count"`
+	}{c.ID, c.CellType, c.Source, metadata, outputs, c.ExecutionCount})
 }
 
 // CreateNotebookReadTool creates the NotebookRead tool using MCP SDK patterns.
@@ -57,17 +109,11 @@ func CreateNotebookReadTool(ctx *tools.Context) *tools.ServerTool {
 
 		sanitizedPath, err := ctx.Validator.SanitizePath(args.NotebookPath)
 		if err != nil {
-			return &mcp.CallToolResultFor[any]{
-				Content: []mcp.Content{&mcp.TextContent{Text: "Error: Invalid notebook path: " + err.Error()}},
-				IsError: true,
-			}, nil
+			return tools.ValidationErrorResult("Invalid notebook path", err), nil
 		}
 
 		if err := ctx.Validator.ValidatePath(sanitizedPath); err != nil {
-			return &mcp.CallToolResultFor[any]{
-				Content: []mcp.Content{&mcp.TextContent{Text: "Error: Path validation failed: " + err.Error()}},
-				IsError: true,
-			}, nil
+			return tools.ValidationErrorResult("Path validation failed", err), nil
 		}
 
 		// Validate .ipynb extension
@@ -78,7 +124,11 @@ func CreateNotebookReadTool(ctx *tools.Context) *tools.ServerTool {
 			}, nil
 		}
 
-		content, err := readNotebookContent(sanitizedPath, nil)
+		opts := readOptions{
+			MaxSize:     ctx.MaxNotebookSize,
+			OmitOutputs: args.OmitOutputs != nil && *args.OmitOutputs,
+		}
+		content, err := readNotebookContent(sanitizedPath, nil, opts)
 		if err != nil {
 			return &mcp.CallToolResultFor[any]{
 				Content: []mcp.Content{&mcp.TextContent{Text: "Error: " + err.Error()}},
@@ -139,17 +189,11 @@ func CreateNotebookEditTool(ctx *tools.Context) *tools.ServerTool {
 
 		sanitizedPath, err := ctx.Validator.SanitizePath(args.NotebookPath)
 		if err != nil {
-			return &mcp.CallToolResultFor[any]{
-				Content: []mcp.Content{&mcp.TextContent{Text: "Error: Invalid notebook path: " + err.Error()}},
-				IsError: true,
-			}, nil
+			return tools.ValidationErrorResult("Invalid notebook path", err), nil
 		}
 
-		if err := ctx.Validator.ValidatePath(sanitizedPath); err != nil {
-			return &mcp.CallToolResultFor[any]{
-				Content: []mcp.Content{&mcp.TextContent{Text: "Error: Path validation failed: " + err.Error()}},
-				IsError: true,
-			}, nil
+		if err := ctx.Validator.ValidateWritePath(sanitizedPath); err != nil {
+			return tools.ValidationErrorResult("Path validation failed", err), nil
 		}
 
 		// Validate .ipynb extension
@@ -203,7 +247,7 @@ func CreateNotebookEditTool(ctx *tools.Context) *tools.ServerTool {
 			}, nil
 		}
 
-		result, err := editNotebookContent(sanitizedPath, args.CellID, args.NewSource, args.CellType, editMode)
+		result, err := editNotebookContent(sanitizedPath, args.CellID, args.NewSource, args.CellType, editMode, ctx.MaxNotebookSize)
 		if err != nil {
 			return &mcp.CallToolResultFor[any]{
 				Content: []mcp.Content{&mcp.TextContent{Text: "Error: " + err.Error()}},
@@ -258,7 +302,8 @@ func CreateNotebookEditTool(ctx *tools.Context) *tools.ServerTool {
 }
 
 // readNotebookContent reads and formats the content of a Jupyter notebook.
-func readNotebookContent(notebookPath string, cellID *string) (string, error) {
+// The notebook is parsed one cell at a time rather than loaded whole.
+func readNotebookContent(notebookPath string, cellID *string, opts readOptions) (string, error) {
 	// Check if file exists
 	stat, err := os.Stat(notebookPath)
 	if err != nil {
@@ -269,15 +314,22 @@ func readNotebookContent(notebookPath string, cellID *string) (string, error) {
 		return "", fmt.Errorf("path is a directory, not a file")
 	}
 
+	if err := checkNotebookSize(stat.Size(), opts.MaxSize); err != nil {
+		return "", err
+	}
+
 	// Read the notebook file
-	data, err := os.ReadFile(notebookPath)
+	file, err := os.Open(notebookPath)
 	if err != nil {
 		return "", fmt.Errorf("failed to read notebook file: %w", err)
 	}
+	defer func() {
+		_ = file.Close()
+	}()
 
 	// Parse JSON
-	var notebook JupyterNotebook
-	if err := json.Unmarshal(data, &notebook); err != nil {
+	notebook, err := decodeNotebook(file, opts.OmitOutputs)
+	if err != nil {
 		return "", fmt.Errorf("failed to parse notebook JSON: %w", err)
 	}
 
@@ -343,7 +395,9 @@ func formatNotebookCell(cell JupyterCell, index int) string {
 	}
 
 	// Outputs (for code cells)
-	if cell.CellType == "code" && len(cell.Outputs) > 0 {
+	if cell.omittedOutputs > 0 {
+		output.WriteString(fmt.Sprintf("\nOutputs: %d omitted\n", cell.omittedOutputs))
+	} else if cell.CellType == "code" && len(cell.Outputs) > 0 {
 		output.WriteString("\nOutputs:\n")
 		for i, outputData := range cell.Outputs {
 			output.WriteString(fmt.Sprintf("  Output %d: %s\n", i+1, formatOutputData(outputData)))
@@ -413,7 +467,8 @@ func formatOutputData(output interface{}) string {
 }
 
 // editNotebookContent edits a notebook cell based on the specified operation.
-func editNotebookContent(notebookPath string, cellID *string, newSource string, cellType *string, editMode string) (string, error) {
+// Notebooks larger than maxSize bytes are rejected; zero selects DefaultMaxNotebookSize.
+func editNotebookContent(notebookPath string, cellID *string, newSource string, cellType *string, editMode string, maxSize int64) (string, error) {
 	// Check if file exists
 	stat, err := os.Stat(notebookPath)
 	if err != nil {
@@ -424,6 +479,10 @@ func editNotebookContent(notebookPath string, cellID *string, newSource string,
 		return "", fmt.Errorf("path is a directory, not a file")
 	}
 
+	if err := checkNotebookSize(stat.Size(), maxSize); err != nil {
+		return "", err
+	}
+
 	// Read the notebook file
 	data, err := os.ReadFile(notebookPath)
 	if err != nil {
@@ -482,12 +541,70 @@ func editNotebookContent(notebookPath string, cellID *string, newSource string,
 		return "", fmt.Errorf("failed to write modified notebook: %w", err)
 	}
 
+	// Re-parse the written file to make sure it is still a loadable notebook
+	if err := validateNotebookFile(notebookPath); err != nil {
+		if restoreErr := os.Rename(backupPath, notebookPath); restoreErr != nil {
+			return "", fmt.Errorf("modified notebook is invalid and failed to restore backup: validation error: %w, restore error: %v", err, restoreErr)
+		}
+		return "", fmt.Errorf("modified notebook is invalid (backup restored): %w", err)
+	}
+
 	// Clean up backup on success
 	_ = os.Remove(backupPath)
 
 	return result, nil
 }
 
+// validateNotebookFile re-reads a notebook file and checks that it satisfies
+// the nbformat v4 structure: a supported format version, valid cell types,
+// and the fields each cell type requires.
+func validateNotebookFile(notebookPath string) error {
+	data, err := os.ReadFile(notebookPath)
+	if err != nil {
+		return fmt.Errorf("failed to read notebook file: %w", err)
+	}
+
+	var raw struct {
+		NBFormat int                          `json:"nbformat"`
+		Cells    []map[string]json.RawMessage `json:"cells"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("failed to parse notebook JSON: %w", err)
+	}
+
+	if raw.NBFormat < 4 {
+		return fmt.Errorf("unsupported nbformat version %d (must be 4 or later)", raw.NBFormat)
+	}
+
+	if raw.Cells == nil {
+		return fmt.Errorf("notebook is missing the cells field")
+	}
+
+	for i, cell := range raw.Cells {
+		var cellType string
+		if err := json.Unmarshal(cell["cell_type"], &cellType); err != nil {
+			return fmt.Errorf("cell %d: missing or invalid cell_type", i)
+		}
+
+		if !validCellTypes[cellType] {
+			return fmt.Errorf("cell %d: invalid cell_type '%s'", i, cellType)
+		}
+
+		required := []string{"source", "metadata"}
+		if cellType == "code" {
+			required = append(required, "outputs", "execution_count")
+		}
+
+		for _, field := range required {
+			if _, exists := cell[field]; !exists {
+				return fmt.Errorf("cell %d: %s cell is missing required field '%s'", i, cellType, field)
+			}
+		}
+	}
+
+	return nil
+}
+
 // replaceNotebookCell replaces the content of an existing cell.
 func replaceNotebookCell(notebook *JupyterNotebook, cellID *string, newSource string, cellType *string) (string, bool, error) {
 	if cellID == nil || *cellID == "" {
@@ -51,7 +51,7 @@ func TestReadNotebookContent(t *testing.T) {
 	// Test reading entire notebook
 	notebookPath := createTestNotebook(t)
 
-	content, err := readNotebookContent(notebookPath, nil)
+	content, err := readNotebookContent(notebookPath, nil, readOptions{})
 	if err != nil {
 		t.Fatalf("Failed to read notebook content: %v", err)
 	}
@@ -65,7 +65,7 @@ func TestReadNotebookContent(t *testing.T) {
 
 	// Test reading specific cell
 	cellID := "markdown-cell-1"
-	content, err = readNotebookContent(notebookPath, &cellID)
+	content, err = readNotebookContent(notebookPath, &cellID, readOptions{})
 	if err != nil {
 		t.Fatalf("Failed to read specific cell: %v", err)
 	}
@@ -79,7 +79,7 @@ func TestReadNotebookContent(t *testing.T) {
 
 	// Test nonexistent cell
 	nonexistentID := "nonexistent"
-	_, err = readNotebookContent(notebookPath, &nonexistentID)
+	_, err = readNotebookContent(notebookPath, &nonexistentID, readOptions{})
 	if err == nil {
 		t.Errorf("Expected error when reading nonexistent cell")
 	}
@@ -91,7 +91,7 @@ func TestEditNotebookContent(t *testing.T) {
 	cellID := "markdown-cell-1"
 	newSource := "# Updated Notebook\n\nThis has been updated."
 
-	result, err := editNotebookContent(notebookPath, &cellID, newSource, nil, "replace")
+	result, err := editNotebookContent(notebookPath, &cellID, newSource, nil, "replace", 0)
 	if err != nil {
 		t.Fatalf("Failed to edit notebook: %v", err)
 	}
@@ -135,7 +135,7 @@ func TestNotebookEditInsert(t *testing.T) {
 	newSource := "x = 42\nprint(x)"
 	cellType := "code"
 
-	result, err := editNotebookContent(notebookPath, &cellID, newSource, &cellType, "insert")
+	result, err := editNotebookContent(notebookPath, &cellID, newSource, &cellType, "insert", 0)
 	if err != nil {
 		t.Fatalf("Failed to insert cell: %v", err)
 	}
@@ -174,7 +174,7 @@ func TestNotebookEditDelete(t *testing.T) {
 	notebookPath := createTestNotebook(t)
 	cellID := "code-cell-1"
 
-	result, err := editNotebookContent(notebookPath, &cellID, "", nil, "delete")
+	result, err := editNotebookContent(notebookPath, &cellID, "", nil, "delete", 0)
 	if err != nil {
 		t.Fatalf("Failed to delete cell: %v", err)
 	}
@@ -208,22 +208,211 @@ func TestNotebookEditErrors(t *testing.T) {
 	notebookPath := createTestNotebook(t)
 
 	// Test missing cell_id for replace mode
-	_, err := editNotebookContent(notebookPath, nil, "test", nil, "replace")
+	_, err := editNotebookContent(notebookPath, nil, "test", nil, "replace", 0)
 	if err == nil {
 		t.Errorf("Expected error for missing cell_id in replace mode")
 	}
 
 	// Test nonexistent cell
 	nonexistentID := "nonexistent"
-	_, err = editNotebookContent(notebookPath, &nonexistentID, "test", nil, "replace")
+	_, err = editNotebookContent(notebookPath, &nonexistentID, "test", nil, "replace", 0)
 	if err == nil {
 		t.Errorf("Expected error for nonexistent cell")
 	}
 
 	// Test invalid edit_mode
 	cellID := "markdown-cell-1"
-	_, err = editNotebookContent(notebookPath, &cellID, "test", nil, "invalid")
+	_, err = editNotebookContent(notebookPath, &cellID, "test", nil, "invalid", 0)
 	if err == nil {
 		t.Errorf("Expected error for invalid edit_mode")
 	}
 }
+
+func TestNotebookEditInvalidResultRollback(t *testing.T) {
+	notebook := JupyterNotebook{
+		NBFormat:      3,
+		NBFormatMinor: 0,
+		Metadata:      map[string]interface{}{},
+		Cells: []JupyterCell{
+			{
+				ID:       "markdown-cell-1",
+				CellType: "markdown",
+				Source:   []string{"# Legacy Notebook"},
+				Metadata: map[string]interface{}{},
+			},
+		},
+	}
+
+	notebookPath := filepath.Join(t.TempDir(), "legacy.ipynb")
+	original, err := json.MarshalIndent(notebook, "", "  ")
+	if err != nil {
+		t.Fatalf("Failed to marshal test notebook: %v", err)
+	}
+	if err := os.WriteFile(notebookPath, original, 0644); err != nil {
+		t.Fatalf("Failed to write test notebook: %v", err)
+	}
+
+	cellID := "markdown-cell-1"
+	_, err = editNotebookContent(notebookPath, &cellID, "# Updated", nil, "replace", 0)
+	if err == nil {
+		t.Fatal("Expected validation error for notebook with nbformat < 4")
+	}
+
+	if !strings.Contains(err.Error(), "backup restored") {
+		t.Errorf("Expected error to mention backup restore, got: %v", err)
+	}
+
+	data, err := os.ReadFile(notebookPath)
+	if err != nil {
+		t.Fatalf("Failed to read notebook after rollback: %v", err)
+	}
+
+	if string(data) != string(original) {
+		t.Errorf("Expected notebook to be restored to its original content")
+	}
+
+	if _, err := os.Stat(notebookPath + ".backup"); !os.IsNotExist(err) {
+		t.Errorf("Expected backup file to be consumed by restore")
+	}
+}
+
+func TestValidateNotebookFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr bool
+	}{
+		{
+			name:    "valid notebook",
+			content: `{"nbformat": 4, "nbformat_minor": 5, "metadata": {}, "cells": [{"cell_type": "code", "source": [], "metadata": {}, "outputs": [], "execution_count": null}]}`,
+			wantErr: false,
+		},
+		{
+			name:    "old nbformat",
+			content: `{"nbformat": 3, "nbformat_minor": 0, "metadata": {}, "cells": []}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid cell type",
+			content: `{"nbformat": 4, "nbformat_minor": 5, "metadata": {}, "cells": [{"cell_type": "heading", "source": [], "metadata": {}}]}`,
+			wantErr: true,
+		},
+		{
+			name:    "code cell missing outputs",
+			content: `{"nbformat": 4, "nbformat_minor": 5, "metadata": {}, "cells": [{"cell_type": "code", "source": [], "metadata": {}, "execution_count": null}]}`,
+			wantErr: true,
+		},
+		{
+			name:    "missing cells",
+			content: `{"nbformat": 4, "nbformat_minor": 5, "metadata": {}}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notebookPath := filepath.Join(t.TempDir(), "test.ipynb")
+			if err := os.WriteFile(notebookPath, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to write test notebook: %v", err)
+			}
+
+			err := validateNotebookFile(notebookPath)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateNotebookFile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestReadNotebookLargeOutputs(t *testing.T) {
+	image := strings.Repeat("iVBORw0KGgo", 200*1024)
+	notebook := JupyterNotebook{
+		NBFormat:      4,
+		NBFormatMinor: 5,
+		Metadata:      map[string]interface{}{"kernelspec": map[string]interface{}{"name": "python3"}},
+		Cells: []JupyterCell{
+			{
+				ID:       "plot",
+				CellType: "code",
+				Source:   []string{"plt.show()"},
+				Metadata: map[string]interface{}{},
+				Outputs: []interface{}{
+					map[string]interface{}{
+						"output_type": "display_data",
+						"data":        map[string]interface{}{"image/png": image, "text/plain": "<Figure>"},
+						"metadata":    map[string]interface{}{},
+					},
+					map[string]interface{}{"output_type": "stream", "name": "stdout", "text": "done\n"},
+				},
+			},
+			{
+				ID:       "notes",
+				CellType: "markdown",
+				Source:   "# Results",
+				Metadata: map[string]interface{}{},
+			},
+		},
+	}
+
+	notebookPath := filepath.Join(t.TempDir(), "plots.ipynb")
+	data, err := json.Marshal(notebook)
+	if err != nil {
+		t.Fatalf("Failed to marshal test notebook: %v", err)
+	}
+	if err := os.WriteFile(notebookPath, data, 0644); err != nil {
+		t.Fatalf("Failed to write test notebook: %v", err)
+	}
+
+	full, err := readNotebookContent(notebookPath, nil, readOptions{})
+	if err != nil {
+		t.Fatalf("readNotebookContent() error = %v", err)
+	}
+	if !strings.Contains(full, "display_data: <Figure>") || !strings.Contains(full, "Format: v4.5") {
+		t.Errorf("Expected outputs and format in full read, got:\n%s", full)
+	}
+
+	omitted, err := readNotebookContent(notebookPath, nil, readOptions{OmitOutputs: true})
+	if err != nil {
+		t.Fatalf("readNotebookContent(omit outputs) error = %v", err)
+	}
+	if !strings.Contains(omitted, "Outputs: 2 omitted") {
+		t.Errorf("Expected omitted output count, got:\n%s", omitted)
+	}
+	if strings.Contains(omitted, "<Figure>") || strings.Contains(omitted, "done") {
+		t.Errorf("Expected outputs to be left out, got:\n%s", omitted)
+	}
+	if !strings.Contains(omitted, "plt.show()") || !strings.Contains(omitted, "# Results") || !strings.Contains(omitted, "Total cells: 2") {
+		t.Errorf("Expected cell sources with outputs omitted, got:\n%s", omitted)
+	}
+	if len(omitted) > 1024 {
+		t.Errorf("Expected a small response with outputs omitted, got %d bytes", len(omitted))
+	}
+
+	maxSize := int64(len(data) - 1)
+	if _, err := readNotebookContent(notebookPath, nil, readOptions{MaxSize: maxSize, OmitOutputs: true}); err == nil || !strings.Contains(err.Error(), "exceeds the maximum notebook size") {
+		t.Errorf("Expected oversized notebook to be rejected on read, got %v", err)
+	}
+	cellID := "notes"
+	if _, err := editNotebookContent(notebookPath, &cellID, "# Summary", nil, "replace", maxSize); err == nil || !strings.Contains(err.Error(), "exceeds the maximum notebook size") {
+		t.Errorf("Expected oversized notebook to be rejected on edit, got %v", err)
+	}
+	if _, err := readNotebookContent(notebookPath, nil, readOptions{MaxSize: int64(len(data))}); err != nil {
+		t.Errorf("Expected notebook at the size limit to be read, got %v", err)
+	}
+}
+
+func TestDecodeNotebookErrors(t *testing.T) {
+	for _, input := range []string{`[]`, `{"cells": {}}`, `{"cells": [`, `not json`} {
+		if _, err := decodeNotebook(strings.NewReader(input), false); err == nil {
+			t.Errorf("decodeNotebook(%q) expected error", input)
+		}
+	}
+
+	notebook, err := decodeNotebook(strings.NewReader(`{"cells": null, "nbformat": 4, "extra": {"a": [1]}}`), false)
+	if err != nil {
+		t.Fatalf("decodeNotebook() error = %v", err)
+	}
+	if notebook.NBFormat != 4 || len(notebook.Cells) != 0 {
+		t.Errorf("Unexpected notebook %+v", notebook)
+	}
+}
@@ -7,21 +7,41 @@ import (
 	"sort"
 	"sync"
 
+	"github.com/modelcontextprotocol/go-sdk/jsonschema"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
 // Registry manages the collection of available tools.
 type Registry struct {
-	mu    sync.RWMutex
-	tools map[string]Tool
-	ctx   *Context
+	mu         sync.RWMutex
+	tools      map[string]Tool
+	categories map[string]string
+	ctx        *Context
+
+	statsMu sync.Mutex
+	stats   map[string]ToolStats
+}
+
+// ToolStats counts the calls of a tool and how many of them failed.
+type ToolStats struct {
+	Invocations int64 `json:"invocations"`
+	Errors      int64 `json:"errors"`
+}
+
+// RegistryStats counts tool calls in total and per tool.
+type RegistryStats struct {
+	Invocations int64                `json:"invocations"`
+	Errors      int64                `json:"errors"`
+	Tools       map[string]ToolStats `json:"tools"`
 }
 
 // NewRegistry creates a new tool registry with the given context.
 func NewRegistry(ctx *Context) *Registry {
 	return &Registry{
-		tools: make(map[string]Tool),
-		ctx:   ctx,
+		tools:      make(map[string]Tool),
+		categories: make(map[string]string),
+		ctx:        ctx,
+		stats:      make(map[string]ToolStats),
 	}
 }
 
@@ -121,8 +141,35 @@ func (r *Registry) Clear() {
 	r.tools = make(map[string]Tool)
 }
 
+// RecordInvocation counts a call of the named tool, and whether it failed.
+func (r *Registry) RecordInvocation(toolName string, failed bool) {
+	r.statsMu.Lock()
+	defer r.statsMu.Unlock()
+
+	stats := r.stats[toolName]
+	stats.Invocations++
+	if failed {
+		stats.Errors++
+	}
+	r.stats[toolName] = stats
+}
+
+// Stats returns a snapshot of the recorded tool calls.
+func (r *Registry) Stats() RegistryStats {
+	r.statsMu.Lock()
+	defer r.statsMu.Unlock()
+
+	stats := RegistryStats{Tools: make(map[string]ToolStats, len(r.stats))}
+	for name, toolStats := range r.stats {
+		stats.Tools[name] = toolStats
+		stats.Invocations += toolStats.Invocations
+		stats.Errors += toolStats.Errors
+	}
+	return stats
+}
+
 // GetToolsByCategory returns tools filtered by category.
-// Categories: file, system, web, notebook, todo, workflow
+// Categories: file, system, web, notebook, todo, custom
 func (r *Registry) GetToolsByCategory(category string) []Tool {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
@@ -138,12 +185,32 @@ func (r *Registry) GetToolsByCategory(category string) []Tool {
 	return categoryTools
 }
 
+// ToolCategory returns the category of the named tool, or "unknown".
+func (r *Registry) ToolCategory(toolName string) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.getToolCategory(toolName)
+}
+
+// SetToolCategory assigns a category to a tool that is not one of the built-in tools.
+func (r *Registry) SetToolCategory(toolName, category string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.categories[toolName] = category
+}
+
 // getToolCategory determines the category of a tool based on its name.
 func (r *Registry) getToolCategory(toolName string) string {
+	if category, ok := r.categories[toolName]; ok {
+		return category
+	}
+
 	switch toolName {
-	case "Read", "Write", "Edit", "MultiEdit", "LS", "Glob", "Grep":
+	case "Read", "ReadMany", "Write", "Edit", "MultiEdit", "LS", "Glob", "Grep", "WatchFile", "Stat", "ReadJSON", "Symlink", "ReadLink", "GitStatus", "GitDiff", "ValidateFile", "FormatFile", "ReplaceInFiles", "ApplyPatch", "TempFile", "TempDir":
 		return "file"
-	case "Bash":
+	case "Bash", "BashHistory", "BashEnv", "BashJobs", "ListExecutions", "CancelExecution":
 		return "system"
 	case "WebFetch", "WebSearch":
 		return "web"
@@ -158,7 +225,7 @@ func (r *Registry) getToolCategory(toolName string) string {
 
 // GetCategories returns all available tool categories.
 func (r *Registry) GetCategories() []string {
-	return []string{"file", "system", "web", "notebook", "todo"}
+	return []string{"file", "system", "web", "notebook", "todo", "custom"}
 }
 
 // Validate checks if all registered tools are properly configured.
@@ -220,6 +287,7 @@ type ToolBuilder[T any] struct {
 	name        string
 	description string
 	category    string
+	inputSchema *jsonschema.Schema
 	handler     func(context.Context, *mcp.ServerSession, *mcp.CallToolParamsFor[T]) (*mcp.CallToolResultFor[any], error)
 	ctx         *Context
 }
@@ -240,6 +308,12 @@ func (b *ToolBuilder[T]) WithCategory(category string) *ToolBuilder[T] {
 	return b
 }
 
+// WithInputSchema sets an explicit input schema instead of inferring one from T.
+func (b *ToolBuilder[T]) WithInputSchema(schema *jsonschema.Schema) *ToolBuilder[T] {
+	b.inputSchema = schema
+	return b
+}
+
 // WithHandler sets the tool handler function with proper MCP SDK typing.
 func (b *ToolBuilder[T]) WithHandler(handler func(context.Context, *mcp.ServerSession, *mcp.CallToolParamsFor[T]) (*mcp.CallToolResultFor[any], error)) *ToolBuilder[T] {
 	b.handler = handler
@@ -255,6 +329,7 @@ func (b *ToolBuilder[T]) Build() *ServerTool {
 	tool := &mcp.Tool{
 		Name:        b.name,
 		Description: b.description,
+		InputSchema: b.inputSchema,
 	}
 
 	return &ServerTool{
@@ -0,0 +1,39 @@
+// Package tools provides per-invocation request ids for tool calls.
+package tools
+
+import "context"
+
+// RequestIDMetaKey is the result meta key holding the request id of the call.
+const RequestIDMetaKey = "request_id"
+
+// requestIDKey is the context key for the request id of a tool call.
+type requestIDKey struct{}
+
+// requestIDLogger is implemented by loggers that can attach a request id to
+// their records.
+type requestIDLogger interface {
+	WithRequestID(requestID string) Logger
+}
+
+// WithRequestID returns a context carrying the request id of a tool call.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestIDFromContext returns the request id of the tool call, or an empty
+// string if the context has none.
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// RequestLogger returns the tool logger for a call, with the tool name and,
+// when the logger supports it, the request id of the call attached.
+func (c *Context) RequestLogger(ctxReq context.Context, toolName string) Logger {
+	logger := c.Logger.WithTool(toolName)
+	requestID := RequestIDFromContext(ctxReq)
+	if rl, ok := logger.(requestIDLogger); ok && requestID != "" {
+		return rl.WithRequestID(requestID)
+	}
+	return logger
+}
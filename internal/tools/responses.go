@@ -60,17 +60,17 @@ func ResponseWithMeta(text string, meta map[string]any) *mcp.CallToolResultFor[a
 
 // InvalidPathError creates an error response for invalid file paths.
 func InvalidPathError(err error) *mcp.CallToolResultFor[any] {
-	return ErrorResponsef("Invalid file path: %v", err)
+	return ValidationErrorResult("Invalid file path", err)
 }
 
 // PathValidationError creates an error response for path validation failures.
 func PathValidationError(err error) *mcp.CallToolResultFor[any] {
-	return ErrorResponsef("Path validation failed: %v", err)
+	return ValidationErrorResult("Path validation failed", err)
 }
 
 // CommandValidationError creates an error response for command validation failures.
 func CommandValidationError(err error) *mcp.CallToolResultFor[any] {
-	return ErrorResponsef("Command validation failed: %v", err)
+	return ValidationErrorResult("Command validation failed", err)
 }
 
 // FileOperationError creates an error response for file operation failures.
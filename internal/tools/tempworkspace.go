@@ -0,0 +1,125 @@
+// Package tools provides the workspace that TempFile and TempDir create files in.
+package tools
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// DefaultTempDirName is the directory under the system temp directory that
+// holds the temp workspace when no root is configured.
+const DefaultTempDirName = "claude-code-mcp"
+
+// tempRootMode keeps the temp workspace root private to the server user.
+const tempRootMode os.FileMode = 0700
+
+// TempWorkspace creates scratch files and directories under a root directory
+// and remembers them so they can be removed when the server stops.
+type TempWorkspace struct {
+	root string
+
+	mu      sync.Mutex
+	created []string
+}
+
+// NewTempWorkspace creates a temp workspace rooted at root. An empty root
+// selects DefaultTempDirName under the system temp directory. The root is
+// created on first use.
+func NewTempWorkspace(root string) *TempWorkspace {
+	if root == "" {
+		root = filepath.Join(os.TempDir(), DefaultTempDirName)
+	}
+	return &TempWorkspace{root: filepath.Clean(root)}
+}
+
+// Root returns the directory that holds the workspace entries.
+func (w *TempWorkspace) Root() string {
+	return w.root
+}
+
+// CreateFile creates an empty file whose name is prefix, a random string and
+// suffix, and returns its path.
+func (w *TempWorkspace) CreateFile(prefix, suffix string) (string, error) {
+	if err := checkTempPattern(prefix + suffix); err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(w.root, tempRootMode); err != nil {
+		return "", fmt.Errorf("failed to create temp root: %w", err)
+	}
+
+	file, err := os.CreateTemp(w.root, prefix+"*"+suffix)
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp file: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return "", fmt.Errorf("failed to create temp file: %w", err)
+	}
+
+	w.track(file.Name())
+	return file.Name(), nil
+}
+
+// CreateDir creates an empty directory whose name is prefix followed by a
+// random string, and returns its path.
+func (w *TempWorkspace) CreateDir(prefix string) (string, error) {
+	if err := checkTempPattern(prefix); err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(w.root, tempRootMode); err != nil {
+		return "", fmt.Errorf("failed to create temp root: %w", err)
+	}
+
+	dir, err := os.MkdirTemp(w.root, prefix+"*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp directory: %w", err)
+	}
+
+	w.track(dir)
+	return dir, nil
+}
+
+// Paths returns the files and directories created so far that have not been
+// cleaned up.
+func (w *TempWorkspace) Paths() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]string(nil), w.created...)
+}
+
+// Cleanup removes every file and directory created by the workspace, with
+// their content, and returns how many were removed. The root itself is kept
+// because it may be shared with other processes.
+func (w *TempWorkspace) Cleanup() (int, error) {
+	w.mu.Lock()
+	created := w.created
+	w.created = nil
+	w.mu.Unlock()
+
+	var errs []error
+	for _, path := range created {
+		if err := os.RemoveAll(path); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return len(created) - len(errs), errors.Join(errs...)
+}
+
+// track records a created path for Cleanup.
+func (w *TempWorkspace) track(path string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.created = append(w.created, path)
+}
+
+// checkTempPattern rejects name parts that would place an entry outside the
+// workspace root.
+func checkTempPattern(pattern string) error {
+	if strings.ContainsAny(pattern, `/\`) {
+		return errors.New("prefix and suffix must not contain path separators")
+	}
+	return nil
+}
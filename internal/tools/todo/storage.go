@@ -1,53 +1,88 @@
-// Package todo provides in-memory storage for session-based todos.
+// Package todo provides state-store-backed storage for session-based todos.
 package todo
 
 import (
-	"github.com/d-kuro/claude-code-mcp/internal/collections"
+	"encoding/json"
+	"fmt"
+
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/d-kuro/claude-code-mcp/internal/collections"
+	"github.com/d-kuro/claude-code-mcp/internal/storage"
+	"github.com/d-kuro/claude-code-mcp/internal/tools"
 )
 
-// SessionStorage manages todo items for sessions using a generic SyncMap.
+// SessionStorage manages todo items for sessions. The todos are kept in a
+// storage.StateStore, and the sessions that have todos are tracked in memory.
 type SessionStorage struct {
-	todos *collections.SyncMap[*mcp.ServerSession, []TodoItem]
+	store    storage.StateStore
+	sessions *collections.SyncMap[*mcp.ServerSession, string]
 }
 
-// NewSessionStorage creates a new session storage.
+// NewSessionStorage creates a new session storage kept in memory.
 func NewSessionStorage() *SessionStorage {
+	return NewSessionStorageWithStore(storage.NewMemoryStateStore())
+}
+
+// NewSessionStorageWithStore creates a session storage kept in store.
+func NewSessionStorageWithStore(store storage.StateStore) *SessionStorage {
 	return &SessionStorage{
-		todos: collections.NewSyncMap[*mcp.ServerSession, []TodoItem](),
+		store:    store,
+		sessions: collections.NewSyncMap[*mcp.ServerSession, string](),
 	}
 }
 
+// sessionKey returns the state key for the todos of a session. Sessions
+// without an ID, such as stdio sessions, are identified by their address.
+func sessionKey(session *mcp.ServerSession) string {
+	if session == nil {
+		return "todos/default"
+	}
+	if id := session.ID(); id != "" {
+		return "todos/" + id
+	}
+	return fmt.Sprintf("todos/%p", session)
+}
+
 // GetSessionTodos retrieves todos for the given session.
 func (s *SessionStorage) GetSessionTodos(session *mcp.ServerSession) []TodoItem {
-	todos, exists := s.todos.Get(session)
-	if !exists {
+	data, err := s.store.Get(sessionKey(session))
+	if err != nil {
 		return []TodoItem{}
 	}
 
-	// Return a copy to prevent external modification
-	result := make([]TodoItem, len(todos))
-	copy(result, todos)
-	return result
+	var todos []TodoItem
+	if err := json.Unmarshal(data, &todos); err != nil || todos == nil {
+		return []TodoItem{}
+	}
+	return todos
 }
 
 // SetSessionTodos updates todos for the given session.
-func (s *SessionStorage) SetSessionTodos(session *mcp.ServerSession, todos []TodoItem) {
-	// Store a copy to prevent external modification
-	todosCopy := make([]TodoItem, len(todos))
-	copy(todosCopy, todos)
-	s.todos.Set(session, todosCopy)
+func (s *SessionStorage) SetSessionTodos(session *mcp.ServerSession, todos []TodoItem) error {
+	data, err := json.Marshal(todos)
+	if err != nil {
+		return fmt.Errorf("failed to encode todos: %w", err)
+	}
+
+	key := sessionKey(session)
+	if err := s.store.Set(key, data, 0); err != nil {
+		return err
+	}
+	s.sessions.Set(session, key)
+	return nil
 }
 
 // ClearSessionTodos removes all todos for the given session.
 func (s *SessionStorage) ClearSessionTodos(session *mcp.ServerSession) {
-	s.todos.Delete(session)
+	_ = s.store.Delete(sessionKey(session))
+	s.sessions.Delete(session)
 }
 
 // GetAllSessions returns all sessions that have todos.
 func (s *SessionStorage) GetAllSessions() []*mcp.ServerSession {
 	var sessions []*mcp.ServerSession
-	s.todos.Range(func(session *mcp.ServerSession, _ []TodoItem) bool {
+	s.sessions.Range(func(session *mcp.ServerSession, _ string) bool {
 		sessions = append(sessions, session)
 		return true
 	})
@@ -56,22 +91,32 @@ func (s *SessionStorage) GetAllSessions() []*mcp.ServerSession {
 
 // GetSessionCount returns the number of sessions with todos.
 func (s *SessionStorage) GetSessionCount() int {
-	return s.todos.Len()
+	return s.sessions.Len()
 }
 
 // GetTotalTodoCount returns the total number of todos across all sessions.
 func (s *SessionStorage) GetTotalTodoCount() int {
 	total := 0
-	s.todos.Range(func(_ *mcp.ServerSession, todos []TodoItem) bool {
-		total += len(todos)
-		return true
-	})
+	for _, session := range s.GetAllSessions() {
+		total += len(s.GetSessionTodos(session))
+	}
 	return total
 }
 
 // ClearAll removes all todos from all sessions.
 func (s *SessionStorage) ClearAll() {
-	s.todos.Clear()
+	for _, session := range s.GetAllSessions() {
+		s.ClearSessionTodos(session)
+	}
+}
+
+// sessionStorageFor returns the storage used by the todo tools: the global
+// storage, or one kept in the context's state store when it has one.
+func sessionStorageFor(ctx *tools.Context) *SessionStorage {
+	if ctx.StateStore == nil {
+		return globalStorage
+	}
+	return NewSessionStorageWithStore(ctx.StateStore)
 }
 
 // Global storage instance for backward compatibility
@@ -82,8 +127,8 @@ func GetSessionTodos(session *mcp.ServerSession) []TodoItem {
 	return globalStorage.GetSessionTodos(session)
 }
 
-func SetSessionTodos(session *mcp.ServerSession, todos []TodoItem) {
-	globalStorage.SetSessionTodos(session, todos)
+func SetSessionTodos(session *mcp.ServerSession, todos []TodoItem) error {
+	return globalStorage.SetSessionTodos(session, todos)
 }
 
 func ClearSessionTodos(session *mcp.ServerSession) {
@@ -45,11 +45,13 @@ type TodoWriteArgs struct {
 
 // CreateTodoReadTool creates the TodoRead tool using MCP SDK patterns.
 func CreateTodoReadTool(ctx *tools.Context) *tools.ServerTool {
+	todoStorage := sessionStorageFor(ctx)
+
 	handler := func(ctxReq context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[map[string]any]) (*mcp.CallToolResultFor[any], error) {
 		// No permission check needed for reading todos
 		// No arguments needed for TodoRead
 
-		todos := GetSessionTodos(session)
+		todos := todoStorage.GetSessionTodos(session)
 
 		if len(todos) == 0 {
 			return &mcp.CallToolResultFor[any]{
@@ -99,6 +101,8 @@ func CreateTodoReadTool(ctx *tools.Context) *tools.ServerTool {
 
 // CreateTodoWriteTool creates the TodoWrite tool using MCP SDK patterns.
 func CreateTodoWriteTool(ctx *tools.Context) *tools.ServerTool {
+	todoStorage := sessionStorageFor(ctx)
+
 	typedHandler := func(ctxReq context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[TodoWriteArgs]) (*mcp.CallToolResultFor[any], error) {
 		args := params.Arguments
 
@@ -170,7 +174,9 @@ func CreateTodoWriteTool(ctx *tools.Context) *tools.ServerTool {
 		}
 
 		// Update session todos
-		SetSessionTodos(session, args.Todos)
+		if err := todoStorage.SetSessionTodos(session, args.Todos); err != nil {
+			return tools.ErrorResponsef("Failed to save todos: %v", err), nil
+		}
 
 		// Count by status
 		statusCounts := make(map[TodoStatus]int)
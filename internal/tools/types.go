@@ -2,7 +2,14 @@
 package tools
 
 import (
+	"net/url"
+	"os"
+	"time"
+
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/d-kuro/claude-code-mcp/internal/ignore"
+	"github.com/d-kuro/claude-code-mcp/internal/storage"
 )
 
 // ServerTool represents a tool with its registration function for MCP server.
@@ -33,6 +40,294 @@ type Tool interface {
 type Context struct {
 	Logger    Logger
 	Validator Validator
+
+	// Ignore excludes paths from Glob, Grep, and LS results. Nil disables filtering.
+	Ignore *ignore.Matcher
+	// DefaultIgnores names directories that Glob, Grep, and LS skip at any
+	// depth unless a call turns them off. Nil disables them.
+	DefaultIgnores []string
+
+	// FileMode and DirMode are the permissions for files and directories
+	// created by the tools, before the umask. Zero selects the defaults.
+	FileMode os.FileMode
+	DirMode  os.FileMode
+
+	// BackupFiles makes Edit and MultiEdit write a ".backup" file before
+	// modifying a file. When false, the original content is kept in memory
+	// and the new content is written atomically via a temporary file.
+	BackupFiles bool
+
+	// WriteRetries is how many times Write and the edit tools retry a write
+	// that fails with a transient filesystem error. Zero selects the default
+	// and a negative value disables retries.
+	WriteRetries int
+
+	// MaxSearchDepth limits the directory depth of Glob and Grep searches,
+	// where 1 covers only the entries directly in the search path. Zero means unbounded.
+	MaxSearchDepth int
+
+	// AllowRootSearch permits Glob and Grep searches rooted at a filesystem
+	// root such as "/" or "C:\".
+	AllowRootSearch bool
+
+	// CleanEnv starts commands run by the tools with only the variables in
+	// CleanEnvVars instead of the full server process environment.
+	CleanEnv bool
+	// AllowedEnvVars, when non-empty, lists the only process environment
+	// variables passed to Bash sessions, in place of CleanEnvVars.
+	AllowedEnvVars []string
+	// DefaultSessionEnv holds variables set in every new Bash session, on
+	// top of the process environment it starts from.
+	DefaultSessionEnv map[string]string
+	// CommandRules checks the complete command lines run by Bash and custom
+	// tools, on top of the Validator. When nil, they are checked against
+	// the bash package's default dangerous patterns.
+	CommandRules CommandRules
+	// NonInteractiveEnv holds variables such as TERM=dumb and NO_COLOR=1 set
+	// for commands run by Bash and custom tools, so that they do not emit
+	// terminal escape sequences. Nil leaves the environment unchanged.
+	NonInteractiveEnv map[string]string
+	// StripANSI removes ANSI escape sequences left in the output of Bash and
+	// custom tool commands.
+	StripANSI bool
+	// ProgressInterval is how often Bash sends progress notifications while a
+	// command runs. Zero disables them.
+	ProgressInterval time.Duration
+	// HistorySize is the number of commands kept in each Bash session history.
+	// Zero keeps the default and a negative value disables the history.
+	HistorySize int
+	// MaxSessions limits the number of live Bash sessions, and
+	// MaxConcurrentCommands the number of Bash commands running at once.
+	// A command over the limit waits up to CommandQueueTimeout for a slot.
+	// Zero means no limit.
+	MaxSessions           int
+	MaxConcurrentCommands int
+	CommandQueueTimeout   time.Duration
+	// AllowedContentTypes, when non-empty, limits the media types WebFetch
+	// returns; BlockedContentTypes are always rejected. Entries may use a
+	// wildcard subtype such as "image/*".
+	AllowedContentTypes []string
+	BlockedContentTypes []string
+	// Proxy routes WebFetch and WebSearch requests through an HTTP proxy.
+	// When nil, HTTP_PROXY, HTTPS_PROXY and NO_PROXY are honored.
+	Proxy *url.URL
+	// WebCacheDir, when set, is the directory where WebFetch caches results
+	// for 15 minutes. WebCacheMaxSize caps its size in bytes; zero uses the
+	// web package default.
+	WebCacheDir     string
+	WebCacheMaxSize int64
+	// MaxRedirects caps the redirects WebFetch follows; zero uses the web
+	// package default.
+	MaxRedirects int
+	// StateStore keeps tool state such as todo lists. When nil, the tools
+	// keep their state in memory.
+	StateStore storage.StateStore
+	// ChunkedReadThreshold is the file size in bytes above which Read reads
+	// files in chunks with bounded memory. Zero uses the file package default.
+	ChunkedReadThreshold int64
+
+	// LSTimeout bounds the ls command run by LS, and LSMaxEntries caps the
+	// entries listed per directory. Zero selects the defaults.
+	LSTimeout    time.Duration
+	LSMaxEntries int
+
+	// MaxNotebookSize is the largest notebook file in bytes that NotebookRead
+	// and NotebookEdit load. Zero selects the default.
+	MaxNotebookSize int64
+	// TempWorkspace is where TempFile and TempDir create scratch entries.
+	// When nil, those tools use a workspace under the system temp directory.
+	TempWorkspace *TempWorkspace
+}
+
+const (
+	// DefaultFileMode is the permission used for newly created files.
+	DefaultFileMode os.FileMode = 0666
+	// DefaultDirMode is the permission used for newly created directories.
+	DefaultDirMode os.FileMode = 0755
+)
+
+// WithDefaultFileMode sets the permission for files created by the tools.
+func (c *Context) WithDefaultFileMode(mode os.FileMode) *Context {
+	c.FileMode = mode
+	return c
+}
+
+// WithDefaultDirMode sets the permission for directories created by the tools.
+func (c *Context) WithDefaultDirMode(mode os.FileMode) *Context {
+	c.DirMode = mode
+	return c
+}
+
+// WithBackupFiles enables or disables the ".backup" file strategy for edits.
+func (c *Context) WithBackupFiles(enabled bool) *Context {
+	c.BackupFiles = enabled
+	return c
+}
+
+// WithWriteRetries sets how many times writes failing with a transient error are retried.
+func (c *Context) WithWriteRetries(retries int) *Context {
+	c.WriteRetries = retries
+	return c
+}
+
+// WithMaxSearchDepth limits the directory depth of Glob and Grep searches.
+func (c *Context) WithMaxSearchDepth(depth int) *Context {
+	c.MaxSearchDepth = depth
+	return c
+}
+
+// WithRootSearch allows or refuses Glob and Grep searches rooted at a filesystem root.
+func (c *Context) WithRootSearch(allowed bool) *Context {
+	c.AllowRootSearch = allowed
+	return c
+}
+
+// WithCleanEnv selects a minimal allow-listed environment for commands run by the tools.
+func (c *Context) WithCleanEnv(clean bool) *Context {
+	c.CleanEnv = clean
+	return c
+}
+
+// WithAllowedEnvVars limits the process environment variables passed to Bash sessions.
+func (c *Context) WithAllowedEnvVars(names []string) *Context {
+	c.AllowedEnvVars = names
+	return c
+}
+
+// WithCommandRules sets the rules that Bash and custom tool commands are checked against.
+func (c *Context) WithCommandRules(rules CommandRules) *Context {
+	c.CommandRules = rules
+	return c
+}
+
+// WithDefaultSessionEnv seeds every new Bash session with the given variables.
+func (c *Context) WithDefaultSessionEnv(env map[string]string) *Context {
+	c.DefaultSessionEnv = env
+	return c
+}
+
+// WithNonInteractiveEnv sets variables that keep commands from producing terminal output.
+func (c *Context) WithNonInteractiveEnv(env map[string]string) *Context {
+	c.NonInteractiveEnv = env
+	return c
+}
+
+// WithStripANSI removes ANSI escape sequences from command output.
+func (c *Context) WithStripANSI(strip bool) *Context {
+	c.StripANSI = strip
+	return c
+}
+
+// WithProgressInterval enables periodic progress notifications for long-running commands.
+func (c *Context) WithProgressInterval(interval time.Duration) *Context {
+	c.ProgressInterval = interval
+	return c
+}
+
+// WithHistorySize sets the number of commands kept in each Bash session history.
+func (c *Context) WithHistorySize(size int) *Context {
+	c.HistorySize = size
+	return c
+}
+
+// WithBashLimits bounds the number of Bash sessions and concurrent commands.
+func (c *Context) WithBashLimits(maxSessions, maxCommands int, queueTimeout time.Duration) *Context {
+	c.MaxSessions = maxSessions
+	c.MaxConcurrentCommands = maxCommands
+	c.CommandQueueTimeout = queueTimeout
+	return c
+}
+
+// WithAllowedContentTypes limits WebFetch to content of the given media types.
+func (c *Context) WithAllowedContentTypes(contentTypes []string) *Context {
+	c.AllowedContentTypes = contentTypes
+	return c
+}
+
+// WithBlockedContentTypes rejects WebFetch content of the given media types.
+func (c *Context) WithBlockedContentTypes(contentTypes []string) *Context {
+	c.BlockedContentTypes = contentTypes
+	return c
+}
+
+// WithProxy routes web tool requests through the given HTTP proxy.
+func (c *Context) WithProxy(proxy *url.URL) *Context {
+	c.Proxy = proxy
+	return c
+}
+
+// WithWebCache enables the WebFetch cache in dir, limited to maxSize bytes.
+func (c *Context) WithWebCache(dir string, maxSize int64) *Context {
+	c.WebCacheDir = dir
+	c.WebCacheMaxSize = maxSize
+	return c
+}
+
+// WithMaxRedirects caps the redirects WebFetch follows.
+func (c *Context) WithMaxRedirects(maxRedirects int) *Context {
+	c.MaxRedirects = maxRedirects
+	return c
+}
+
+// WithStateStore keeps tool state such as todo lists in the given store.
+func (c *Context) WithStateStore(store storage.StateStore) *Context {
+	c.StateStore = store
+	return c
+}
+
+// WithChunkedReadThreshold sets the file size above which Read reads in chunks.
+func (c *Context) WithChunkedReadThreshold(threshold int64) *Context {
+	c.ChunkedReadThreshold = threshold
+	return c
+}
+
+// WithLSLimits sets the ls timeout and the number of entries LS lists per directory.
+func (c *Context) WithLSLimits(timeout time.Duration, maxEntries int) *Context {
+	c.LSTimeout = timeout
+	c.LSMaxEntries = maxEntries
+	return c
+}
+
+// WithMaxNotebookSize sets the largest notebook file the notebook tools load.
+func (c *Context) WithMaxNotebookSize(size int64) *Context {
+	c.MaxNotebookSize = size
+	return c
+}
+
+// WithTempWorkspace sets the workspace used by TempFile and TempDir.
+func (c *Context) WithTempWorkspace(workspace *TempWorkspace) *Context {
+	c.TempWorkspace = workspace
+	return c
+}
+
+// NewFileMode returns the permission to use when creating a file.
+func (c *Context) NewFileMode() os.FileMode {
+	if c.FileMode == 0 {
+		return DefaultFileMode
+	}
+	return c.FileMode
+}
+
+// NewDirMode returns the permission to use when creating a directory.
+func (c *Context) NewDirMode() os.FileMode {
+	if c.DirMode == 0 {
+		return DefaultDirMode
+	}
+	return c.DirMode
+}
+
+// WithIgnoreFile sets the project ignore file consulted by the file tools,
+// overriding the default .mcpignore in the working directory.
+func (c *Context) WithIgnoreFile(path string) *Context {
+	c.Ignore = ignore.New(path)
+	return c
+}
+
+// WithDefaultIgnores sets the directories that Glob, Grep, and LS skip by
+// default, such as ignore.DefaultDirectories. Names may be glob patterns.
+func (c *Context) WithDefaultIgnores(names []string) *Context {
+	c.DefaultIgnores = names
+	return c
 }
 
 // Logger defines the logging interface for tools.
@@ -48,11 +343,18 @@ type Logger interface {
 // Validator defines the security validation interface.
 type Validator interface {
 	ValidatePath(path string) error
+	ValidateWritePath(path string) error
 	ValidateCommand(cmd string, args []string) error
 	ValidateURL(url string) error
 	SanitizePath(path string) (string, error)
 }
 
+// CommandRules checks a shell command line as a whole, for example against
+// dangerous command patterns.
+type CommandRules interface {
+	ValidateCommand(command string) error
+}
+
 // BaseTool provides common functionality for all tools.
 type BaseTool struct {
 	name        string
@@ -0,0 +1,90 @@
+// Package web provides the cache of WebFetch results.
+package web
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/d-kuro/geminiwebtools/pkg/types"
+
+	"github.com/d-kuro/claude-code-mcp/internal/storage"
+)
+
+const (
+	// fetchCacheTTL is how long a cached WebFetch result stays fresh.
+	fetchCacheTTL = 15 * time.Minute
+	// DefaultFetchCacheMaxSize is the default size cap of the WebFetch cache directory.
+	DefaultFetchCacheMaxSize int64 = 50 << 20
+)
+
+// fetchCacheEntry is the stored form of a cached WebFetch result.
+type fetchCacheEntry struct {
+	URL       string                `json:"url"`
+	FetchedAt time.Time             `json:"fetched_at"`
+	Result    *types.WebFetchResult `json:"result"`
+}
+
+// fetchCache stores WebFetch results in a state store. Entries expire after
+// fetchCacheTTL. It is safe for concurrent use.
+type fetchCache struct {
+	store storage.StateStore
+	ttl   time.Duration
+	now   func() time.Time
+}
+
+// newFetchCache returns a cache kept in files in dir, so it survives restarts
+// and can be shared between servers, or nil when dir is empty. The oldest
+// entries are removed when the directory grows past maxSize; zero uses
+// DefaultFetchCacheMaxSize.
+func newFetchCache(dir string, maxSize int64) *fetchCache {
+	if dir == "" {
+		return nil
+	}
+	if maxSize <= 0 {
+		maxSize = DefaultFetchCacheMaxSize
+	}
+	return newFetchCacheWithStore(storage.NewFileStateStore(dir).WithMaxSize(maxSize))
+}
+
+// newFetchCacheWithStore returns a cache kept in store.
+func newFetchCacheWithStore(store storage.StateStore) *fetchCache {
+	return &fetchCache{store: store, ttl: fetchCacheTTL, now: time.Now}
+}
+
+// cacheKey returns the state key for a fetch. The prompt is part of the key
+// because the result is the page processed with that prompt.
+func cacheKey(url, prompt string) string {
+	sum := sha256.Sum256([]byte(url + "\n" + prompt))
+	return "webfetch/" + hex.EncodeToString(sum[:])
+}
+
+// get returns the cached result for the fetch, removing the entry if it has expired.
+func (c *fetchCache) get(url, prompt string) (*types.WebFetchResult, bool) {
+	key := cacheKey(url, prompt)
+	data, err := c.store.Get(key)
+	if err != nil {
+		return nil, false
+	}
+
+	var entry fetchCacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil || entry.Result == nil || entry.URL != url {
+		_ = c.store.Delete(key)
+		return nil, false
+	}
+	if c.now().Sub(entry.FetchedAt) >= c.ttl {
+		_ = c.store.Delete(key)
+		return nil, false
+	}
+	return entry.Result, true
+}
+
+// put stores the result of a fetch.
+func (c *fetchCache) put(url, prompt string, result *types.WebFetchResult) error {
+	data, err := json.Marshal(fetchCacheEntry{URL: url, FetchedAt: c.now(), Result: result})
+	if err != nil {
+		return err
+	}
+	return c.store.Set(cacheKey(url, prompt), data, c.ttl)
+}
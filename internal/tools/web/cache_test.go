@@ -0,0 +1,110 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	geministorage "github.com/d-kuro/geminiwebtools/pkg/storage"
+	"github.com/d-kuro/geminiwebtools/pkg/types"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/d-kuro/claude-code-mcp/internal/storage"
+)
+
+func callCachedWebFetch(t *testing.T, session *mcp.ClientSession, args map[string]any) *mcp.CallToolResult {
+	t.Helper()
+
+	result, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: "WebFetch", Arguments: args})
+	if err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("Expected success, got: %v", result.Content)
+	}
+	return result
+}
+
+func TestWebFetchCache(t *testing.T) {
+	client := &fakeWebClient{fetchResult: &types.WebFetchResult{Content: "page summary"}}
+	opts := stubWebClient(t, func(geministorage.CredentialStore) (webClient, error) {
+		return client, nil
+	})
+
+	ctx := createTestContext().WithWebCache(t.TempDir(), 0)
+	session := connectWebTools(t, createWebFetchTool(ctx, opts))
+	args := map[string]any{"url": "https://example.com", "prompt": "Summarize"}
+
+	first := callCachedWebFetch(t, session, args)
+	if first.Meta["cached"] != nil {
+		t.Errorf("Expected the first fetch not to be cached, got meta: %v", first.Meta)
+	}
+
+	second := callCachedWebFetch(t, session, args)
+	if second.Meta["cached"] != true {
+		t.Errorf("Expected the second fetch to be served from the cache, got meta: %v", second.Meta)
+	}
+	if text := second.Content[0].(*mcp.TextContent).Text; text != "page summary" {
+		t.Errorf("Expected the cached content, got: %s", text)
+	}
+	if got := client.fetches.Load(); got != 1 {
+		t.Errorf("Expected 1 fetch, got %d", got)
+	}
+
+	// A different prompt is a different entry
+	callCachedWebFetch(t, session, map[string]any{"url": "https://example.com", "prompt": "List the links"})
+	if got := client.fetches.Load(); got != 2 {
+		t.Errorf("Expected a new fetch for a different prompt, got %d fetches", got)
+	}
+
+	args["no_cache"] = true
+	bypassed := callCachedWebFetch(t, session, args)
+	if bypassed.Meta["cached"] != nil {
+		t.Errorf("Expected no_cache to bypass the cache, got meta: %v", bypassed.Meta)
+	}
+	if got := client.fetches.Load(); got != 3 {
+		t.Errorf("Expected no_cache to fetch again, got %d fetches", got)
+	}
+}
+
+func TestFetchCacheExpiry(t *testing.T) {
+	store := storage.NewMemoryStateStore()
+	cache := newFetchCacheWithStore(store)
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+
+	if err := cache.put("https://example.com", "Summarize", &types.WebFetchResult{Content: "old"}); err != nil {
+		t.Fatalf("put failed: %v", err)
+	}
+
+	now = now.Add(fetchCacheTTL - time.Second)
+	if _, ok := cache.get("https://example.com", "Summarize"); !ok {
+		t.Fatal("Expected a hit before the TTL")
+	}
+
+	now = now.Add(2 * time.Second)
+	if _, ok := cache.get("https://example.com", "Summarize"); ok {
+		t.Fatal("Expected a miss after the TTL")
+	}
+	if _, err := store.Get(cacheKey("https://example.com", "Summarize")); !errors.Is(err, storage.ErrStateNotFound) {
+		t.Errorf("Expected the expired entry to be removed, got: %v", err)
+	}
+}
+
+func TestFetchCacheDirectory(t *testing.T) {
+	dir := t.TempDir()
+	if err := newFetchCache(dir, 0).put("https://example.com", "Summarize", &types.WebFetchResult{Content: "kept"}); err != nil {
+		t.Fatalf("put failed: %v", err)
+	}
+
+	// A new cache on the same directory, as after a restart, sees the entry
+	result, ok := newFetchCache(dir, 0).get("https://example.com", "Summarize")
+	if !ok || result.Content != "kept" {
+		t.Errorf("Expected the entry to survive in the cache directory, got: %v", result)
+	}
+
+	if newFetchCache("", 0) != nil {
+		t.Error("Expected no cache without a directory")
+	}
+}
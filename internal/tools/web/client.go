@@ -0,0 +1,70 @@
+// Package web provides the clients shared by WebFetch and WebSearch.
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+
+	"github.com/d-kuro/geminiwebtools/pkg/storage"
+)
+
+// clientCache creates the web client on first use and shares it between
+// calls, so credentials are loaded once and connections are reused. It is
+// safe for concurrent use.
+type clientCache struct {
+	// newClient creates the client. Nil uses newWebClient.
+	newClient func(storage.CredentialStore) (webClient, error)
+
+	mu     sync.Mutex
+	client webClient
+}
+
+// sharedClient is the client cache used by WebFetch and WebSearch.
+var sharedClient = &clientCache{}
+
+// get returns the cached client, creating it if needed. A failed creation is
+// not cached, so the next call tries again.
+func (c *clientCache) get() (webClient, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.client != nil {
+		return c.client, nil
+	}
+
+	credStore, err := createGeminiCredentialStore()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create credential store: %w", err)
+	}
+
+	newClient := c.newClient
+	if newClient == nil {
+		newClient = newWebClient
+	}
+	client, err := newClient(credStore)
+	if err != nil {
+		return nil, err
+	}
+	c.client = client
+	return client, nil
+}
+
+// proxyClients holds one HTTP client per proxy setting so that connection
+// pools survive between calls.
+var proxyClients sync.Map
+
+// proxyHTTPClient returns the shared HTTP client for the proxy, creating it
+// on first use.
+func proxyHTTPClient(proxy *url.URL) *http.Client {
+	key := ""
+	if proxy != nil {
+		key = proxy.String()
+	}
+	if client, ok := proxyClients.Load(key); ok {
+		return client.(*http.Client)
+	}
+	client, _ := proxyClients.LoadOrStore(key, newProxyHTTPClient(proxy))
+	return client.(*http.Client)
+}
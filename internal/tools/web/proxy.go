@@ -0,0 +1,36 @@
+// Package web provides proxy support for the web tools.
+package web
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"golang.org/x/oauth2"
+)
+
+// proxyFunc selects the proxy for outbound requests: the explicit proxy when
+// one is configured, otherwise HTTP_PROXY, HTTPS_PROXY and NO_PROXY from the
+// environment.
+func proxyFunc(proxy *url.URL) func(*http.Request) (*url.URL, error) {
+	if proxy != nil {
+		return http.ProxyURL(proxy)
+	}
+	return http.ProxyFromEnvironment
+}
+
+// newProxyHTTPClient creates an HTTP client that sends requests through the
+// proxy chosen by proxyFunc.
+func newProxyHTTPClient(proxy *url.URL) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = proxyFunc(proxy)
+	return &http.Client{Transport: transport}
+}
+
+// withProxy returns a context that makes the authenticated Gemini API client
+// of geminiwebtools, which is built on oauth2, send its requests through the
+// proxy. The proxy only changes how requests are routed; the target URL is
+// still checked by the validator before anything is fetched.
+func withProxy(ctx context.Context, proxy *url.URL) context.Context {
+	return context.WithValue(ctx, oauth2.HTTPClient, proxyHTTPClient(proxy))
+}
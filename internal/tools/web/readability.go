@@ -0,0 +1,390 @@
+// Package web provides extraction of the main content of fetched HTML.
+package web
+
+import (
+	"fmt"
+	"mime"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Extraction modes of the WebFetch tool.
+const (
+	// ExtractModeFull returns the fetched page as is.
+	ExtractModeFull = "full"
+	// ExtractModeReadability keeps only the main content of an HTML page,
+	// dropping navigation, ads and other boilerplate, and returns it as
+	// markdown.
+	ExtractModeReadability = "readability"
+)
+
+// boilerplateElements never hold the main content of a page.
+var boilerplateElements = map[atom.Atom]bool{
+	atom.Script:   true,
+	atom.Style:    true,
+	atom.Noscript: true,
+	atom.Template: true,
+	atom.Nav:      true,
+	atom.Header:   true,
+	atom.Footer:   true,
+	atom.Aside:    true,
+	atom.Form:     true,
+	atom.Iframe:   true,
+	atom.Svg:      true,
+	atom.Button:   true,
+	atom.Select:   true,
+}
+
+// boilerplateRoles are ARIA roles of page furniture.
+var boilerplateRoles = map[string]bool{
+	"navigation":    true,
+	"banner":        true,
+	"contentinfo":   true,
+	"complementary": true,
+	"search":        true,
+	"dialog":        true,
+}
+
+// boilerplatePattern matches class names and ids commonly used for page
+// furniture rather than content.
+var boilerplatePattern = regexp.MustCompile(`(?i)(^|[-_\s])(nav|navbar|menu|sidebar|footer|header|masthead|ads?|advert\w*|sponsor\w*|banner|promo\w*|cookie\w*|consent|share|social|related|recommend\w*|breadcrumbs?|popup|modal|newsletter|subscribe|comments?)($|[-_\s])`)
+
+// extractMainContent returns the main content of an HTML document as
+// markdown. It prefers an <article> or <main> element and otherwise picks
+// the element whose paragraphs hold the most text, after removing
+// boilerplate such as navigation, headers, footers, sidebars and ads.
+func extractMainContent(document string) (string, error) {
+	root, err := html.Parse(strings.NewReader(document))
+	if err != nil {
+		return "", fmt.Errorf("failed to parse HTML: %w", err)
+	}
+
+	title := strings.TrimSpace(textContent(findFirst(root, atom.Title)))
+	removeBoilerplate(root)
+
+	container := findMainContent(root)
+	if container == nil {
+		return "", fmt.Errorf("no main content found")
+	}
+
+	var md markdownWriter
+	md.render(container)
+	content := md.String()
+	if title != "" && findFirst(container, atom.H1) == nil {
+		content = "# " + title + "\n\n" + content
+	}
+	return content, nil
+}
+
+// readableContent returns the main content of fetched content that is an
+// HTML page, detected from its content type or, when none is reported, from
+// its start. It reports false for other content, such as the text already
+// processed by the Gemini API, which is then returned as is.
+func readableContent(content, contentType string) (string, bool) {
+	if !isHTMLContent(content, contentType) {
+		return "", false
+	}
+	extracted, err := extractMainContent(content)
+	if err != nil || strings.TrimSpace(extracted) == "" {
+		return "", false
+	}
+	return extracted, true
+}
+
+// isHTMLContent reports whether fetched content is an HTML page.
+func isHTMLContent(content, contentType string) bool {
+	if contentType != "" {
+		mediaType, _, err := mime.ParseMediaType(contentType)
+		if err != nil {
+			mediaType = strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+		}
+		if mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+			return false
+		}
+	}
+	start := strings.ToLower(strings.TrimSpace(content))
+	return strings.HasPrefix(start, "<!doctype html") || strings.HasPrefix(start, "<html") || strings.Contains(start, "<body")
+}
+
+// removeBoilerplate detaches the elements that are not part of the content.
+func removeBoilerplate(n *html.Node) {
+	for child := n.FirstChild; child != nil; {
+		next := child.NextSibling
+		if child.Type == html.CommentNode || (child.Type == html.ElementNode && isBoilerplate(child)) {
+			n.RemoveChild(child)
+		} else {
+			removeBoilerplate(child)
+		}
+		child = next
+	}
+}
+
+// isBoilerplate reports whether an element is page furniture.
+func isBoilerplate(n *html.Node) bool {
+	if boilerplateElements[n.DataAtom] {
+		return true
+	}
+	if boilerplateRoles[attr(n, "role")] || attr(n, "aria-hidden") == "true" || hasAttr(n, "hidden") {
+		return true
+	}
+	// The elements that usually wrap the content are kept whatever their class
+	if n.DataAtom == atom.Body || n.DataAtom == atom.Article || n.DataAtom == atom.Main {
+		return false
+	}
+	return boilerplatePattern.MatchString(attr(n, "class")) || boilerplatePattern.MatchString(attr(n, "id"))
+}
+
+// findMainContent returns the element holding the main content: the
+// <article> or <main> element with the most text, or else the parent of
+// the paragraphs with the most text, or else the body.
+func findMainContent(root *html.Node) *html.Node {
+	var best *html.Node
+	bestLength := 0
+	walk(root, func(n *html.Node) {
+		if n.DataAtom == atom.Article || n.DataAtom == atom.Main || attr(n, "role") == "main" {
+			if length := len(strings.TrimSpace(textContent(n))); length > bestLength {
+				best, bestLength = n, length
+			}
+		}
+	})
+	if best != nil {
+		return best
+	}
+
+	scores := make(map[*html.Node]int)
+	walk(root, func(n *html.Node) {
+		if n.DataAtom != atom.P || n.Parent == nil {
+			return
+		}
+		text := strings.TrimSpace(textContent(n))
+		if len(text) < 25 {
+			return
+		}
+		// Longer paragraphs with more sentences are more likely content
+		score := 1 + min(len(text)/100, 3) + strings.Count(text, ",")
+		scores[n.Parent] += score
+		if grandparent := n.Parent.Parent; grandparent != nil {
+			scores[grandparent] += score / 2
+		}
+	})
+	bestScore := 0
+	for n, score := range scores {
+		if score > bestScore {
+			best, bestScore = n, score
+		}
+	}
+	if best != nil {
+		return best
+	}
+
+	return findFirst(root, atom.Body)
+}
+
+// markdownWriter renders HTML elements as markdown.
+type markdownWriter struct {
+	sb strings.Builder
+	// listDepth is the nesting level of the list being rendered.
+	listDepth int
+}
+
+// String returns the rendered markdown with blank lines collapsed.
+func (w *markdownWriter) String() string {
+	lines := strings.Split(w.sb.String(), "\n")
+	var out []string
+	blank := true
+	for _, line := range lines {
+		line = strings.TrimRight(line, " \t")
+		if line == "" {
+			if !blank {
+				out = append(out, "")
+			}
+			blank = true
+			continue
+		}
+		out = append(out, line)
+		blank = false
+	}
+	return strings.TrimSpace(strings.Join(out, "\n")) + "\n"
+}
+
+// atLineStart reports whether the next text starts a line, or follows a list
+// marker, so that its leading space is dropped.
+func (w *markdownWriter) atLineStart() bool {
+	written := w.sb.String()
+	return written == "" || strings.HasSuffix(written, "\n") || strings.HasSuffix(written, "- ") || strings.HasSuffix(written, "> ") || strings.HasSuffix(written, "1. ")
+}
+
+// block writes a block element, surrounded by blank lines.
+func (w *markdownWriter) block(prefix string, n *html.Node) {
+	w.sb.WriteString("\n\n" + prefix)
+	w.children(n)
+	w.sb.WriteString("\n\n")
+}
+
+func (w *markdownWriter) children(n *html.Node) {
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		w.render(child)
+	}
+}
+
+func (w *markdownWriter) render(n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		text := collapseSpace(n.Data)
+		if w.atLineStart() {
+			text = strings.TrimLeft(text, " ")
+		}
+		w.sb.WriteString(text)
+		return
+	case html.ElementNode:
+	default:
+		w.children(n)
+		return
+	}
+
+	switch n.DataAtom {
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		level := int(n.Data[1] - '0')
+		w.sb.WriteString("\n\n" + strings.Repeat("#", level) + " " + strings.TrimSpace(collapseSpace(textContent(n))) + "\n\n")
+	case atom.P, atom.Div, atom.Section, atom.Article, atom.Main, atom.Figure, atom.Table, atom.Tr:
+		w.block("", n)
+	case atom.Blockquote:
+		w.block("> ", n)
+	case atom.Pre:
+		w.sb.WriteString("\n\n```\n" + strings.Trim(textContent(n), "\n") + "\n```\n\n")
+	case atom.Code:
+		w.sb.WriteString("`" + textContent(n) + "`")
+	case atom.Br:
+		w.sb.WriteString("\n")
+	case atom.Hr:
+		w.sb.WriteString("\n\n---\n\n")
+	case atom.Ul, atom.Ol:
+		// Nested lists continue their parent list without blank lines
+		if w.listDepth == 0 {
+			w.sb.WriteString("\n\n")
+		}
+		w.listDepth++
+		w.children(n)
+		w.listDepth--
+		if w.listDepth == 0 {
+			w.sb.WriteString("\n\n")
+		}
+	case atom.Li:
+		marker := "- "
+		if n.Parent != nil && n.Parent.DataAtom == atom.Ol {
+			marker = "1. "
+		}
+		w.sb.WriteString("\n" + strings.Repeat("  ", max(w.listDepth-1, 0)) + marker)
+		w.children(n)
+	case atom.Strong, atom.B:
+		w.inline("**", n)
+	case atom.Em, atom.I:
+		w.inline("*", n)
+	case atom.A:
+		text := strings.TrimSpace(collapseSpace(textContent(n)))
+		if href := attr(n, "href"); href != "" && text != "" && !strings.HasPrefix(href, "#") && !strings.HasPrefix(href, "javascript:") {
+			w.sb.WriteString("[" + text + "](" + href + ")")
+		} else {
+			w.sb.WriteString(text)
+		}
+	case atom.Img:
+		if alt := attr(n, "alt"); alt != "" {
+			w.sb.WriteString("![" + alt + "](" + attr(n, "src") + ")")
+		}
+	case atom.Td, atom.Th:
+		w.children(n)
+		w.sb.WriteString(" ")
+	default:
+		w.children(n)
+	}
+}
+
+// inline writes an inline element wrapped in a markdown marker.
+func (w *markdownWriter) inline(marker string, n *html.Node) {
+	text := strings.TrimSpace(collapseSpace(textContent(n)))
+	if text != "" {
+		w.sb.WriteString(marker + text + marker)
+	}
+}
+
+// walk calls fn for every element under n, in document order.
+func walk(n *html.Node, fn func(*html.Node)) {
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type == html.ElementNode {
+			fn(child)
+		}
+		walk(child, fn)
+	}
+}
+
+// findFirst returns the first element under n with the given tag, or nil.
+func findFirst(n *html.Node, tag atom.Atom) *html.Node {
+	var found *html.Node
+	walk(n, func(child *html.Node) {
+		if found == nil && child.DataAtom == tag {
+			found = child
+		}
+	})
+	return found
+}
+
+// textContent returns the text under n.
+func textContent(n *html.Node) string {
+	if n == nil {
+		return ""
+	}
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	var sb strings.Builder
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		sb.WriteString(textContent(child))
+	}
+	return sb.String()
+}
+
+// attr returns the value of an attribute of n, or "".
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// hasAttr reports whether n has an attribute, whatever its value.
+func hasAttr(n *html.Node, key string) bool {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// collapseSpace replaces runs of whitespace with a single space, keeping a
+// leading or trailing space as the separator between inline elements.
+func collapseSpace(s string) string {
+	words := strings.Join(strings.FieldsFunc(s, isSpace), " ")
+	if words == "" {
+		if s != "" {
+			return " "
+		}
+		return ""
+	}
+	if isSpace(rune(s[0])) {
+		words = " " + words
+	}
+	if isSpace(rune(s[len(s)-1])) {
+		words += " "
+	}
+	return words
+}
+
+func isSpace(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\n' || r == '\r' || r == '\f'
+}
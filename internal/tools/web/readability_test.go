@@ -0,0 +1,113 @@
+package web
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/d-kuro/geminiwebtools/pkg/types"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const articlePage = `<!DOCTYPE html>
+<html>
+<head>
+  <title>Release notes</title>
+  <style>body { color: red; }</style>
+  <script>trackVisitor();</script>
+</head>
+<body>
+  <header class="site-header"><a href="/">Example Corp</a> <a href="/pricing">Pricing</a></header>
+  <nav><ul><li><a href="/docs">Docs</a></li><li><a href="/blog">Blog</a></li></ul></nav>
+  <div class="cookie-banner">We use cookies to improve your experience.</div>
+  <div class="content-wrapper">
+    <aside class="sidebar"><h3>Popular posts</h3><p>Ten tips for faster builds, and more reading.</p></aside>
+    <article>
+      <h1>Version 2.0 released</h1>
+      <p>Version 2.0 brings a new query planner, which makes large joins up to three times faster.</p>
+      <p>The <code>--legacy</code> flag restores the old planner, see the <a href="https://example.com/docs/planner">planner guide</a>.</p>
+      <ul><li>Faster joins</li><li>Smaller indexes</li></ul>
+      <div class="ad-slot">Buy our premium plan today!</div>
+    </article>
+  </div>
+  <footer><p>Copyright 2026 Example Corp. All rights reserved.</p></footer>
+</body>
+</html>`
+
+func TestWebFetchReadabilityMode(t *testing.T) {
+	fetchResult := &types.WebFetchResult{Content: articlePage}
+	fetchResult.Metadata.ContentType = "text/html; charset=utf-8"
+	client := &fakeWebClient{fetchResult: fetchResult}
+
+	t.Run("readability", func(t *testing.T) {
+		result := callWebFetchWithArgs(t, createTestContext(), client, map[string]any{
+			"url":          "https://example.com/blog/v2",
+			"prompt":       "Summarize the release",
+			"extract_mode": "readability",
+		})
+		if result.IsError {
+			t.Fatalf("Expected success, got: %s", result.Content[0].(*mcp.TextContent).Text)
+		}
+
+		text := result.Content[0].(*mcp.TextContent).Text
+		for _, want := range []string{
+			"# Version 2.0 released",
+			"Version 2.0 brings a new query planner",
+			"`--legacy`",
+			"[planner guide](https://example.com/docs/planner)",
+			"- Faster joins",
+		} {
+			if !strings.Contains(text, want) {
+				t.Errorf("Expected article content %q, got:\n%s", want, text)
+			}
+		}
+		for _, boilerplate := range []string{
+			"Pricing", "Docs", "cookies", "Popular posts", "premium plan", "Copyright", "trackVisitor", "color: red", "<",
+		} {
+			if strings.Contains(text, boilerplate) {
+				t.Errorf("Expected boilerplate %q to be dropped, got:\n%s", boilerplate, text)
+			}
+		}
+		if result.Meta["extract_mode"] != ExtractModeReadability {
+			t.Errorf("Expected extract_mode metadata, got %v", result.Meta)
+		}
+	})
+
+	t.Run("full", func(t *testing.T) {
+		result := callWebFetch(t, createTestContext(), client, "https://example.com/blog/v2")
+		if text := result.Content[0].(*mcp.TextContent).Text; text != articlePage {
+			t.Errorf("Expected the page unchanged in full mode, got:\n%s", text)
+		}
+	})
+
+	t.Run("invalid mode", func(t *testing.T) {
+		result := callWebFetchWithArgs(t, createTestContext(), client, map[string]any{
+			"url":          "https://example.com/blog/v2",
+			"prompt":       "Summarize the release",
+			"extract_mode": "summary",
+		})
+		if !result.IsError {
+			t.Fatal("Expected an error for an unknown extract mode")
+		}
+	})
+}
+
+func TestExtractMainContentWithoutArticle(t *testing.T) {
+	page := `<html><body>
+<div id="menu"><p>Home, About, Contact, and a long list of other links here</p></div>
+<div class="post">
+  <p>The first paragraph of the story is long enough to count, with a few clauses, commas and details.</p>
+  <p>The second paragraph continues the story, adding more detail, more commas, and more sentences.</p>
+</div>
+</body></html>`
+
+	content, err := extractMainContent(page)
+	if err != nil {
+		t.Fatalf("extractMainContent failed: %v", err)
+	}
+	if !strings.Contains(content, "The first paragraph") || !strings.Contains(content, "The second paragraph") {
+		t.Errorf("Expected the story paragraphs, got:\n%s", content)
+	}
+	if strings.Contains(content, "Contact") {
+		t.Errorf("Expected the menu to be dropped, got:\n%s", content)
+	}
+}
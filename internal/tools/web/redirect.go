@@ -0,0 +1,77 @@
+// Package web provides redirect checking for the WebFetch tool.
+package web
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// DefaultMaxRedirects is the number of redirects WebFetch follows when no
+// limit is configured.
+const DefaultMaxRedirects = 5
+
+// redirectCheckTimeout bounds the requests made to follow redirects.
+const redirectCheckTimeout = 10 * time.Second
+
+// errRedirectRejected marks a redirect that WebFetch refuses to follow.
+var errRedirectRejected = errors.New("redirect rejected")
+
+// errRedirectProbeFailed marks a HEAD request that got no response, such as
+// a refused connection or a timeout, so the redirects could not be followed.
+var errRedirectProbeFailed = errors.New("redirect probe failed")
+
+// resolveRedirects follows the redirects of targetURL with HEAD requests and
+// returns the final URL. Every redirect target is checked with validate, so a
+// public URL cannot redirect to a local service, a URL seen twice is reported
+// as a loop, and at most maxRedirects are followed. Errors caused by a
+// rejected redirect wrap errRedirectRejected, and errors of a request that
+// got no response wrap errRedirectProbeFailed.
+func resolveRedirects(parent context.Context, client *http.Client, targetURL string, maxRedirects int, validate func(string) error) (string, error) {
+	if maxRedirects <= 0 {
+		maxRedirects = DefaultMaxRedirects
+	}
+
+	redirectClient := *client
+	redirectClient.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		next := req.URL.String()
+		for _, previous := range via {
+			if previous.URL.String() == next {
+				return fmt.Errorf("%w: redirect loop detected at %s", errRedirectRejected, next)
+			}
+		}
+		if len(via) > maxRedirects {
+			return fmt.Errorf("%w: stopped after %d redirects", errRedirectRejected, maxRedirects)
+		}
+		if err := validate(next); err != nil {
+			return fmt.Errorf("%w: redirect to %s is not allowed: %v", errRedirectRejected, next, err)
+		}
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(parent, redirectCheckTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, targetURL, nil)
+	if err != nil {
+		return targetURL, err
+	}
+
+	resp, err := redirectClient.Do(req)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) && errors.Is(urlErr.Err, errRedirectRejected) {
+			return targetURL, urlErr.Err
+		}
+		if parent.Err() != nil {
+			return targetURL, err
+		}
+		return targetURL, fmt.Errorf("%w: %v", errRedirectProbeFailed, err)
+	}
+	_ = resp.Body.Close()
+
+	return resp.Request.URL.String(), nil
+}
@@ -0,0 +1,259 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/d-kuro/geminiwebtools/pkg/storage"
+	"github.com/d-kuro/geminiwebtools/pkg/types"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/d-kuro/claude-code-mcp/internal/security"
+)
+
+// rejectHost returns a URL check that rejects the given host name.
+func rejectHost(host string) func(string) error {
+	return func(rawURL string) error {
+		parsed, err := url.Parse(rawURL)
+		if err != nil {
+			return err
+		}
+		if parsed.Hostname() == host {
+			return fmt.Errorf("access to %s is not allowed", host)
+		}
+		return nil
+	}
+}
+
+// newRedirectServer serves redirects from /hop/N to /hop/N+1 up to /hop/last,
+// /loop/a and /loop/b redirecting to each other, /local redirecting to
+// localhost and /metadata redirecting to the cloud metadata address.
+func newRedirectServer(t *testing.T, last int) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/hop/{n}", func(w http.ResponseWriter, r *http.Request) {
+		var n int
+		_, _ = fmt.Sscan(r.PathValue("n"), &n)
+		if n >= last {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		http.Redirect(w, r, fmt.Sprintf("/hop/%d", n+1), http.StatusFound)
+	})
+	mux.HandleFunc("/loop/a", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/loop/b", http.StatusFound)
+	})
+	mux.HandleFunc("/loop/b", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/loop/a", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/local", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://localhost:8080/admin", http.StatusFound)
+	})
+	mux.HandleFunc("/metadata", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://169.254.169.254/latest/meta-data/", http.StatusFound)
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestResolveRedirects(t *testing.T) {
+	server := newRedirectServer(t, 3)
+	allowAll := func(string) error { return nil }
+
+	t.Run("follows redirects", func(t *testing.T) {
+		final, err := resolveRedirects(context.Background(), server.Client(), server.URL+"/hop/0", 0, allowAll)
+		if err != nil {
+			t.Fatalf("Expected redirects to be followed, got: %v", err)
+		}
+		if final != server.URL+"/hop/3" {
+			t.Errorf("Expected the final URL %s/hop/3, got %s", server.URL, final)
+		}
+	})
+
+	t.Run("loop", func(t *testing.T) {
+		_, err := resolveRedirects(context.Background(), server.Client(), server.URL+"/loop/a", 0, allowAll)
+		if !errors.Is(err, errRedirectRejected) || !strings.Contains(err.Error(), "redirect loop detected at "+server.URL+"/loop/a") {
+			t.Errorf("Expected a redirect loop error, got: %v", err)
+		}
+	})
+
+	t.Run("too many redirects", func(t *testing.T) {
+		_, err := resolveRedirects(context.Background(), server.Client(), server.URL+"/hop/0", 2, allowAll)
+		if !errors.Is(err, errRedirectRejected) || !strings.Contains(err.Error(), "stopped after 2 redirects") {
+			t.Errorf("Expected the redirect limit to be enforced, got: %v", err)
+		}
+	})
+
+	t.Run("blocked target", func(t *testing.T) {
+		_, err := resolveRedirects(context.Background(), server.Client(), server.URL+"/local", 0, rejectHost("localhost"))
+		if !errors.Is(err, errRedirectRejected) || !strings.Contains(err.Error(), "redirect to http://localhost:8080/admin is not allowed") {
+			t.Errorf("Expected the localhost redirect to be rejected, got: %v", err)
+		}
+	})
+
+	t.Run("unreachable", func(t *testing.T) {
+		closed := httptest.NewServer(http.NotFoundHandler())
+		closed.Close()
+		_, err := resolveRedirects(context.Background(), server.Client(), closed.URL, 0, allowAll)
+		if !errors.Is(err, errRedirectProbeFailed) || errors.Is(err, errRedirectRejected) {
+			t.Errorf("Expected a failed probe, got: %v", err)
+		}
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := resolveRedirects(ctx, server.Client(), server.URL+"/hop/0", 0, allowAll)
+		if err == nil || errors.Is(err, errRedirectProbeFailed) {
+			t.Errorf("Expected a canceled request not to count as a failed probe, got: %v", err)
+		}
+	})
+}
+
+// serverOnlyValidator allows the URLs of a test server and checks all other
+// URLs with the default security validator.
+type serverOnlyValidator struct {
+	mockValidator
+	serverURL string
+}
+
+func (v *serverOnlyValidator) ValidateURL(rawURL string) error {
+	if strings.HasPrefix(rawURL, v.serverURL+"/") {
+		return nil
+	}
+	return security.NewDefaultValidator().ValidateURL(rawURL)
+}
+
+func TestWebFetchRejectsRedirectToMetadataAddress(t *testing.T) {
+	server := newRedirectServer(t, 0)
+
+	client := &fakeWebClient{fetchResult: &types.WebFetchResult{Content: "credentials"}}
+	opts := stubWebClient(t, func(storage.CredentialStore) (webClient, error) {
+		return client, nil
+	})
+	opts.checkRedirects = resolveRedirects
+
+	ctx := createTestContext()
+	ctx.Validator = &serverOnlyValidator{serverURL: server.URL}
+	clientSession := connectWebTools(t, createWebFetchTool(ctx, opts))
+
+	result, err := clientSession.CallTool(context.Background(), &mcp.CallToolParams{
+		Name:      "WebFetch",
+		Arguments: map[string]any{"url": server.URL + "/metadata", "prompt": "Describe it"},
+	})
+	if err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+	text := result.Content[0].(*mcp.TextContent).Text
+	if !result.IsError || !strings.Contains(text, "redirect to http://169.254.169.254/latest/meta-data/ is not allowed") || !strings.Contains(text, "private address access denied") {
+		t.Errorf("Expected the redirect to the metadata address to be rejected, got: %s", text)
+	}
+	if client.fetched.Load() {
+		t.Error("Expected nothing to be fetched after a rejected redirect")
+	}
+}
+
+func TestWebFetchFallsBackWhenRedirectProbeFails(t *testing.T) {
+	server := newRedirectServer(t, 0)
+	unreachable := server.URL + "/hop/0"
+	server.Close()
+
+	client := &fakeWebClient{fetchResult: &types.WebFetchResult{Content: "page"}}
+	opts := stubWebClient(t, func(storage.CredentialStore) (webClient, error) {
+		return client, nil
+	})
+	opts.checkRedirects = resolveRedirects
+
+	ctx := createTestContext()
+	ctx.Validator = &serverOnlyValidator{serverURL: server.URL}
+	clientSession := connectWebTools(t, createWebFetchTool(ctx, opts))
+
+	result, err := clientSession.CallTool(context.Background(), &mcp.CallToolParams{
+		Name:      "WebFetch",
+		Arguments: map[string]any{"url": unreachable, "prompt": "Describe it"},
+	})
+	if err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+	if result.IsError {
+		t.Errorf("Expected the original URL to be fetched when the probe fails, got: %s", result.Content[0].(*mcp.TextContent).Text)
+	}
+	if !client.fetched.Load() {
+		t.Error("Expected the original URL to be fetched when the probe fails")
+	}
+}
+
+func TestWebFetchRejectsUncheckedRedirects(t *testing.T) {
+	client := &fakeWebClient{fetchResult: &types.WebFetchResult{Content: "page"}}
+	opts := stubWebClient(t, func(storage.CredentialStore) (webClient, error) {
+		return client, nil
+	})
+	opts.checkRedirects = func(context.Context, *http.Client, string, int, func(string) error) (string, error) {
+		return "", errors.New("malformed response")
+	}
+
+	clientSession := connectWebTools(t, createWebFetchTool(createTestContext(), opts))
+
+	result, err := clientSession.CallTool(context.Background(), &mcp.CallToolParams{
+		Name:      "WebFetch",
+		Arguments: map[string]any{"url": "https://example.com/page", "prompt": "Describe it"},
+	})
+	if err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+	if text := result.Content[0].(*mcp.TextContent).Text; !result.IsError || !strings.Contains(text, "could not check the redirects of https://example.com/page") {
+		t.Errorf("Expected a fetch whose redirects cannot be checked to fail, got: %s", text)
+	}
+	if client.fetched.Load() {
+		t.Error("Expected nothing to be fetched when the redirects could not be checked")
+	}
+}
+
+// localhostRejectingValidator rejects URLs whose host is localhost.
+type localhostRejectingValidator struct {
+	mockValidator
+}
+
+func (v *localhostRejectingValidator) ValidateURL(rawURL string) error {
+	return rejectHost("localhost")(rawURL)
+}
+
+func TestWebFetchRejectsRedirectToLocalhost(t *testing.T) {
+	server := newRedirectServer(t, 0)
+
+	client := &fakeWebClient{fetchResult: &types.WebFetchResult{Content: "admin page"}}
+	opts := stubWebClient(t, func(storage.CredentialStore) (webClient, error) {
+		return client, nil
+	})
+	opts.checkRedirects = resolveRedirects
+
+	ctx := createTestContext()
+	ctx.Validator = &localhostRejectingValidator{}
+	clientSession := connectWebTools(t, createWebFetchTool(ctx, opts))
+
+	result, err := clientSession.CallTool(context.Background(), &mcp.CallToolParams{
+		Name:      "WebFetch",
+		Arguments: map[string]any{"url": server.URL + "/local", "prompt": "Describe it"},
+	})
+	if err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+	if !result.IsError {
+		t.Fatal("Expected the redirect to localhost to be rejected")
+	}
+	if text := result.Content[0].(*mcp.TextContent).Text; !strings.Contains(text, "redirect to http://localhost:8080/admin is not allowed") {
+		t.Errorf("Unexpected error message: %s", text)
+	}
+	if client.fetched.Load() {
+		t.Error("Expected nothing to be fetched after a rejected redirect")
+	}
+}
@@ -3,8 +3,12 @@ package web
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"mime"
+	"net/http"
 	"net/url"
+	"path"
 	"strings"
 
 	"github.com/d-kuro/geminiwebtools"
@@ -17,10 +21,18 @@ import (
 	"github.com/d-kuro/claude-code-mcp/internal/tools/auth"
 )
 
+// Output formats supported by the WebSearch tool.
+const (
+	OutputFormatText = "text"
+	OutputFormatJSON = "json"
+)
+
 // WebFetchArgs represents the arguments for the WebFetch tool.
 type WebFetchArgs struct {
-	URL    string `json:"url"`
-	Prompt string `json:"prompt"`
+	URL         string  `json:"url"`
+	Prompt      string  `json:"prompt"`
+	NoCache     *bool   `json:"no_cache,omitempty"`
+	ExtractMode *string `json:"extract_mode,omitempty"`
 }
 
 // WebSearchArgs represents the arguments for the WebSearch tool.
@@ -28,19 +40,75 @@ type WebSearchArgs struct {
 	Query          string   `json:"query"`
 	AllowedDomains []string `json:"allowed_domains,omitempty"`
 	BlockedDomains []string `json:"blocked_domains,omitempty"`
+	OutputFormat   *string  `json:"output_format,omitempty"`
+}
+
+// SearchResultItem is a single search result returned when output_format is
+// "json". Snippet is empty when the search backend returns no excerpt.
+type SearchResultItem struct {
+	Title   string `json:"title"`
+	URL     string `json:"url"`
+	Snippet string `json:"snippet"`
+}
+
+// webClient is the part of the geminiwebtools client used by the web tools.
+type webClient interface {
+	Fetch(ctx context.Context, prompt string) (*types.WebFetchResult, error)
+	Search(ctx context.Context, query string) (*types.WebSearchResult, error)
+}
+
+// newWebClient creates the client used by WebFetch and WebSearch.
+func newWebClient(credStore storage.CredentialStore) (webClient, error) {
+	return geminiwebtools.NewClient(
+		geminiwebtools.WithCredentialStore(credStore),
+	)
+}
+
+// redirectChecker resolves the redirects of a WebFetch target, as
+// resolveRedirects does.
+type redirectChecker func(ctx context.Context, client *http.Client, targetURL string, maxRedirects int, validate func(string) error) (string, error)
+
+// webToolOptions holds what WebFetch and WebSearch depend on besides the
+// tools context. The zero value uses sharedClient and resolveRedirects.
+type webToolOptions struct {
+	// clients provides the geminiwebtools client.
+	clients *clientCache
+	// checkRedirects resolves the redirects of a WebFetch target.
+	checkRedirects redirectChecker
+}
+
+// withDefaults returns opts with the unset dependencies filled in.
+func (opts webToolOptions) withDefaults() webToolOptions {
+	if opts.clients == nil {
+		opts.clients = sharedClient
+	}
+	if opts.checkRedirects == nil {
+		opts.checkRedirects = resolveRedirects
+	}
+	return opts
 }
 
 // CreateWebFetchTool creates the WebFetch tool using geminiwebtools library.
 func CreateWebFetchTool(ctx *tools.Context) *tools.ServerTool {
+	return createWebFetchTool(ctx, webToolOptions{})
+}
+
+// createWebFetchTool creates the WebFetch tool with the given dependencies.
+func createWebFetchTool(ctx *tools.Context, opts webToolOptions) *tools.ServerTool {
+	opts = opts.withDefaults()
+	cache := newFetchCache(ctx.WebCacheDir, ctx.WebCacheMaxSize)
+	if cache == nil && ctx.StateStore != nil {
+		cache = newFetchCacheWithStore(ctx.StateStore)
+	}
+
+	fetchClient := proxyHTTPClient(ctx.Proxy)
+
 	handler := func(ctxReq context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[WebFetchArgs]) (*mcp.CallToolResultFor[any], error) {
 		args := params.Arguments
 
 		// Validate URL
 		if err := ctx.Validator.ValidateURL(args.URL); err != nil {
-			return &mcp.CallToolResultFor[any]{
-				Content: []mcp.Content{&mcp.TextContent{Text: "Error: Invalid URL: " + err.Error()}},
-				IsError: true,
-			}, nil
+			return tools.ValidationErrorResult("Invalid URL", err), nil
 		}
 
 		// Validate prompt
@@ -51,32 +119,69 @@ func CreateWebFetchTool(ctx *tools.Context) *tools.ServerTool {
 			}, nil
 		}
 
-		// Create geminiwebtools client with MCP credential sharing
-		credStore, err := createGeminiCredentialStore()
-		if err != nil {
-			ctx.Logger.WithTool("WebFetch").Error("Failed to create credential store", "error", err)
-			return createErrorResponse("Failed to initialize credential store: " + err.Error()), nil
+		if mode := extractMode(args); mode != ExtractModeFull && mode != ExtractModeReadability {
+			return tools.InvalidFieldError("extract_mode", fmt.Sprintf("must be %q or %q", ExtractModeFull, ExtractModeReadability)), nil
+		}
+
+		useCache := cache != nil && (args.NoCache == nil || !*args.NoCache)
+		if useCache {
+			if result, ok := cache.get(args.URL, args.Prompt); ok {
+				if err := checkContentType(result.Metadata.ContentType, ctx.AllowedContentTypes, ctx.BlockedContentTypes); err != nil {
+					return createErrorResponse("Error: " + err.Error()), nil
+				}
+				response := convertWebFetchResult(result, args)
+				response.Meta["cached"] = true
+				return response, nil
+			}
 		}
 
-		client, err := geminiwebtools.NewClient(
-			geminiwebtools.WithCredentialStore(credStore),
-		)
+		// Check where the URL redirects to, so that the fetched page is one the validator allows
+		targetURL, err := opts.checkRedirects(ctxReq, fetchClient, args.URL, ctx.MaxRedirects, ctx.Validator.ValidateURL)
+		if errors.Is(err, errRedirectRejected) {
+			ctx.RequestLogger(ctxReq, "WebFetch").Warn("Rejected redirect", "error", err, "url", args.URL)
+			return createErrorResponse("Error: " + err.Error()), nil
+		}
+		if errors.Is(err, errRedirectProbeFailed) {
+			// The site cannot be reached from this host, so fall back to the
+			// original URL, which the validator already allowed
+			ctx.RequestLogger(ctxReq, "WebFetch").Warn("Could not probe redirects, fetching the original URL", "error", err, "url", args.URL)
+			targetURL = args.URL
+		} else if err != nil {
+			// Without the final URL, the page that would be fetched cannot be validated
+			ctx.RequestLogger(ctxReq, "WebFetch").Warn("Could not check redirects", "error", err, "url", args.URL)
+			return createErrorResponse("Error: could not check the redirects of " + args.URL + ": " + err.Error()), nil
+		}
+
+		// Reuse the geminiwebtools client, which shares credentials with the MCP server
+		client, err := opts.clients.get()
 		if err != nil {
-			ctx.Logger.WithTool("WebFetch").Error("Failed to create geminiwebtools client", "error", err)
+			ctx.RequestLogger(ctxReq, "WebFetch").Error("Failed to create geminiwebtools client", "error", err)
 			return createErrorResponse("Failed to initialize web fetch client: " + err.Error()), nil
 		}
 
 		// Construct prompt that includes the URL and user's processing instructions
 		// This matches the gemini-cli interface expectation
-		fetchPrompt := fmt.Sprintf("%s\n\nPlease process the content from: %s", args.Prompt, args.URL)
+		fetchPrompt := fmt.Sprintf("%s\n\nPlease process the content from: %s", args.Prompt, targetURL)
 
 		// Perform the fetch
-		result, err := client.Fetch(ctxReq, fetchPrompt)
+		result, err := client.Fetch(withProxy(ctxReq, ctx.Proxy), fetchPrompt)
 		if err != nil {
-			ctx.Logger.WithTool("WebFetch").Error("Web fetch failed", "error", err, "url", args.URL)
+			ctx.RequestLogger(ctxReq, "WebFetch").Error("Web fetch failed", "error", err, "url", args.URL)
+			return createErrorResponse("Error: " + err.Error()), nil
+		}
+
+		if err := checkContentType(result.Metadata.ContentType, ctx.AllowedContentTypes, ctx.BlockedContentTypes); err != nil {
+			ctx.RequestLogger(ctxReq, "WebFetch").Warn("Rejected fetched content", "error", err, "url", args.URL)
 			return createErrorResponse("Error: " + err.Error()), nil
 		}
 
+		// A bypassed lookup still refreshes the cached entry
+		if cache != nil {
+			if err := cache.put(args.URL, args.Prompt, result); err != nil {
+				ctx.RequestLogger(ctxReq, "WebFetch").Warn("Failed to cache fetched content", "error", err, "url", args.URL)
+			}
+		}
+
 		// Convert result to MCP response format
 		return convertWebFetchResult(result, args), nil
 	}
@@ -96,6 +201,12 @@ func CreateWebFetchTool(ctx *tools.Context) *tools.ServerTool {
 
 // CreateWebSearchTool creates the WebSearch tool using geminiwebtools library.
 func CreateWebSearchTool(ctx *tools.Context) *tools.ServerTool {
+	return createWebSearchTool(ctx, webToolOptions{})
+}
+
+// createWebSearchTool creates the WebSearch tool with the given dependencies.
+func createWebSearchTool(ctx *tools.Context, opts webToolOptions) *tools.ServerTool {
+	opts = opts.withDefaults()
 	handler := func(ctxReq context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[WebSearchArgs]) (*mcp.CallToolResultFor[any], error) {
 		args := params.Arguments
 
@@ -114,31 +225,37 @@ func CreateWebSearchTool(ctx *tools.Context) *tools.ServerTool {
 			}, nil
 		}
 
-		// Create geminiwebtools client with MCP credential sharing
-		credStore, err := createGeminiCredentialStore()
-		if err != nil {
-			ctx.Logger.WithTool("WebSearch").Error("Failed to create credential store", "error", err)
-			return createErrorResponse("Failed to initialize credential store: " + err.Error()), nil
+		outputFormat := OutputFormatText
+		if args.OutputFormat != nil && *args.OutputFormat != "" {
+			outputFormat = *args.OutputFormat
+		}
+		if outputFormat != OutputFormatText && outputFormat != OutputFormatJSON {
+			return tools.InvalidFieldError("output_format", fmt.Sprintf("must be %q or %q", OutputFormatText, OutputFormatJSON)), nil
 		}
 
-		client, err := geminiwebtools.NewClient(
-			geminiwebtools.WithCredentialStore(credStore),
-		)
+		// Reuse the geminiwebtools client, which shares credentials with the MCP server
+		client, err := opts.clients.get()
 		if err != nil {
-			ctx.Logger.WithTool("WebSearch").Error("Failed to create geminiwebtools client", "error", err)
+			ctx.RequestLogger(ctxReq, "WebSearch").Error("Failed to create geminiwebtools client", "error", err)
 			return createErrorResponse("Failed to initialize web search client: " + err.Error()), nil
 		}
 
 		// Perform the search
-		result, err := client.Search(ctxReq, args.Query)
+		result, err := client.Search(withProxy(ctxReq, ctx.Proxy), args.Query)
 		if err != nil {
-			ctx.Logger.WithTool("WebSearch").Error("Web search failed", "error", err, "query", args.Query)
+			ctx.RequestLogger(ctxReq, "WebSearch").Error("Web search failed", "error", err, "query", args.Query)
 			return createErrorResponse("Error: " + err.Error()), nil
 		}
 
 		// Apply domain filtering as post-processing
 		filteredResult := applyDomainFiltering(result, args.AllowedDomains, args.BlockedDomains)
 
+		if outputFormat == OutputFormatJSON {
+			response := tools.JSONResponse(searchResultItems(filteredResult))
+			response.Meta = buildWebSearchMetadata(filteredResult, args)
+			return response, nil
+		}
+
 		// Convert result to MCP response format
 		return convertWebSearchResult(filteredResult, args), nil
 	}
@@ -166,10 +283,51 @@ func createErrorResponse(message string) *mcp.CallToolResultFor[any] {
 	}
 }
 
+// checkContentType rejects a fetched content type that matches the blocked
+// list or, when an allowed list is given, does not match it. Entries are media
+// types such as "text/html" and may use a wildcard subtype ("image/*").
+// Parameters like charset are ignored, and an unreported content type is allowed.
+func checkContentType(contentType string, allowed, blocked []string) error {
+	if contentType == "" {
+		return nil
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+	}
+
+	if matchesContentType(mediaType, blocked) {
+		return fmt.Errorf("content type %s is blocked", mediaType)
+	}
+
+	if len(allowed) > 0 && !matchesContentType(mediaType, allowed) {
+		return fmt.Errorf("content type %s is not allowed (allowed: %s)", mediaType, strings.Join(allowed, ", "))
+	}
+
+	return nil
+}
+
+// matchesContentType reports whether mediaType matches any of the patterns.
+func matchesContentType(mediaType string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(strings.ToLower(strings.TrimSpace(pattern)), mediaType); matched {
+			return true
+		}
+	}
+	return false
+}
+
 // convertWebFetchResult converts geminiwebtools WebFetchResult to MCP response format.
 func convertWebFetchResult(result *types.WebFetchResult, args WebFetchArgs) *mcp.CallToolResultFor[any] {
 	metadata := buildWebFetchMetadata(result, args)
 	content := selectContent(result.DisplayText, result.Content, "No content received")
+	if extractMode(args) == ExtractModeReadability {
+		if extracted, ok := readableContent(content, result.Metadata.ContentType); ok {
+			content = extracted
+			metadata["extract_mode"] = ExtractModeReadability
+		}
+	}
 
 	return &mcp.CallToolResultFor[any]{
 		Content: []mcp.Content{&mcp.TextContent{Text: content}},
@@ -177,6 +335,14 @@ func convertWebFetchResult(result *types.WebFetchResult, args WebFetchArgs) *mcp
 	}
 }
 
+// extractMode returns the extraction mode requested for a fetch.
+func extractMode(args WebFetchArgs) string {
+	if args.ExtractMode == nil || *args.ExtractMode == "" {
+		return ExtractModeFull
+	}
+	return *args.ExtractMode
+}
+
 // buildWebFetchMetadata builds metadata for web fetch results.
 func buildWebFetchMetadata(result *types.WebFetchResult, args WebFetchArgs) map[string]any {
 	metadata := map[string]any{
@@ -210,6 +376,21 @@ func convertWebSearchResult(result *types.WebSearchResult, args WebSearchArgs) *
 	}
 }
 
+// searchResultItems lists the sources of a search result as structured items.
+func searchResultItems(result *types.WebSearchResult) []SearchResultItem {
+	items := make([]SearchResultItem, 0, len(result.Sources))
+	for _, source := range result.Sources {
+		if source.Web.URI == "" {
+			continue
+		}
+		items = append(items, SearchResultItem{
+			Title: source.Web.Title,
+			URL:   source.Web.URI,
+		})
+	}
+	return items
+}
+
 // buildWebSearchMetadata builds metadata for web search results.
 func buildWebSearchMetadata(result *types.WebSearchResult, args WebSearchArgs) map[string]any {
 	metadata := map[string]any{
@@ -2,8 +2,22 @@
 package web
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 
+	"github.com/d-kuro/geminiwebtools/pkg/storage"
+	"github.com/d-kuro/geminiwebtools/pkg/types"
+	"golang.org/x/oauth2"
+
+	"github.com/d-kuro/claude-code-mcp/internal/security"
 	"github.com/d-kuro/claude-code-mcp/internal/tools"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
@@ -12,6 +26,7 @@ import (
 type mockValidator struct{}
 
 func (m *mockValidator) ValidatePath(path string) error                  { return nil }
+func (m *mockValidator) ValidateWritePath(path string) error             { return nil }
 func (m *mockValidator) ValidateCommand(cmd string, args []string) error { return nil }
 func (m *mockValidator) ValidateURL(url string) error                    { return nil }
 func (m *mockValidator) SanitizePath(path string) (string, error)        { return path, nil }
@@ -191,3 +206,346 @@ func TestWebSearchArgsValidation(t *testing.T) {
 		t.Error("BlockedDomains should have values")
 	}
 }
+
+// fakeWebClient returns fixed results instead of contacting the network.
+type fakeWebClient struct {
+	fetchResult  *types.WebFetchResult
+	searchResult *types.WebSearchResult
+	fetched      atomic.Bool
+	fetches      atomic.Int32
+}
+
+func (f *fakeWebClient) Fetch(ctx context.Context, prompt string) (*types.WebFetchResult, error) {
+	f.fetched.Store(true)
+	f.fetches.Add(1)
+	return f.fetchResult, nil
+}
+
+func (f *fakeWebClient) Search(ctx context.Context, query string) (*types.WebSearchResult, error) {
+	if f.searchResult != nil {
+		return f.searchResult, nil
+	}
+	return &types.WebSearchResult{}, nil
+}
+
+func callFakeWebFetch(t *testing.T, ctx *tools.Context, contentType string) *mcp.CallToolResult {
+	t.Helper()
+
+	result := &types.WebFetchResult{Content: "fetched content"}
+	result.Metadata.ContentType = contentType
+	return callWebFetch(t, ctx, &fakeWebClient{fetchResult: result}, "https://example.com/picture")
+}
+
+func callWebFetch(t *testing.T, ctx *tools.Context, client webClient, targetURL string) *mcp.CallToolResult {
+	t.Helper()
+
+	return callWebFetchWithArgs(t, ctx, client, map[string]any{"url": targetURL, "prompt": "Describe it"})
+}
+
+func callWebFetchWithArgs(t *testing.T, ctx *tools.Context, client webClient, args map[string]any) *mcp.CallToolResult {
+	t.Helper()
+
+	opts := stubWebClient(t, func(storage.CredentialStore) (webClient, error) {
+		return client, nil
+	})
+
+	clientSession := connectWebTools(t, createWebFetchTool(ctx, opts))
+	result, err := clientSession.CallTool(context.Background(), &mcp.CallToolParams{
+		Name:      "WebFetch",
+		Arguments: args,
+	})
+	if err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+	return result
+}
+
+// stubWebClient returns web tool options that create clients with factory
+// in a cache of their own and skip redirect checks.
+func stubWebClient(t *testing.T, factory func(storage.CredentialStore) (webClient, error)) webToolOptions {
+	t.Helper()
+
+	t.Setenv("HOME", t.TempDir())
+	return webToolOptions{
+		clients: &clientCache{newClient: factory},
+		checkRedirects: func(ctx context.Context, client *http.Client, targetURL string, maxRedirects int, validate func(string) error) (string, error) {
+			return targetURL, nil
+		},
+	}
+}
+
+// connectWebTools registers the tools on a test server and returns a
+// connected client session.
+func connectWebTools(t *testing.T, serverTools ...*tools.ServerTool) *mcp.ClientSession {
+	t.Helper()
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "test-server", Version: "v0.0.1"}, nil)
+	for _, tool := range serverTools {
+		tool.RegisterFunc(server)
+	}
+
+	reqCtx := context.Background()
+	clientTransport, serverTransport := mcp.NewInMemoryTransports()
+	serverSession, err := server.Connect(reqCtx, serverTransport)
+	if err != nil {
+		t.Fatalf("Failed to connect server: %v", err)
+	}
+	t.Cleanup(func() { _ = serverSession.Close() })
+
+	mcpClient := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "v0.0.1"}, nil)
+	clientSession, err := mcpClient.Connect(reqCtx, clientTransport)
+	if err != nil {
+		t.Fatalf("Failed to connect client: %v", err)
+	}
+	t.Cleanup(func() { _ = clientSession.Close() })
+
+	return clientSession
+}
+
+func TestWebFetchRejectsDisallowedContentType(t *testing.T) {
+	ctx := createTestContext().WithAllowedContentTypes([]string{"text/html", "text/plain", "application/json"})
+
+	result := callFakeWebFetch(t, ctx, "image/png")
+	if !result.IsError {
+		t.Fatal("Expected an error for an image content type")
+	}
+	text := result.Content[0].(*mcp.TextContent).Text
+	if !strings.Contains(text, "content type image/png is not allowed") {
+		t.Errorf("Unexpected error message: %s", text)
+	}
+
+	result = callFakeWebFetch(t, ctx, "text/html; charset=utf-8")
+	if result.IsError {
+		t.Fatalf("Expected HTML to be allowed, got: %s", result.Content[0].(*mcp.TextContent).Text)
+	}
+	if text := result.Content[0].(*mcp.TextContent).Text; text != "fetched content" {
+		t.Errorf("Expected fetched content, got %q", text)
+	}
+}
+
+func TestCheckContentType(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		allowed     []string
+		blocked     []string
+		wantErr     string
+	}{
+		{"no lists", "application/pdf", nil, nil, ""},
+		{"unknown content type", "", []string{"text/html"}, nil, ""},
+		{"allowed with parameters", "Text/HTML; charset=utf-8", []string{"text/html"}, nil, ""},
+		{"not in allowed list", "application/pdf", []string{"text/html"}, nil, "not allowed"},
+		{"wildcard allowed", "text/markdown", []string{"text/*"}, nil, ""},
+		{"blocked", "image/png", nil, []string{"image/*"}, "is blocked"},
+		{"blocked wins over allowed", "image/png", []string{"image/*"}, []string{"image/png"}, "is blocked"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkContentType(tt.contentType, tt.allowed, tt.blocked)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestWithProxyRoutesRequestsThroughProxy(t *testing.T) {
+	var proxiedURL, proxiedAuth string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxiedURL = r.URL.String()
+		proxiedAuth = r.Header.Get("Authorization")
+		_, _ = w.Write([]byte("via proxy"))
+	}))
+	defer proxy.Close()
+
+	proxyURL, err := url.Parse(proxy.URL)
+	if err != nil {
+		t.Fatalf("Failed to parse proxy URL: %v", err)
+	}
+
+	// geminiwebtools builds its API client with oauth2, which picks up the
+	// HTTP client from the context.
+	ctx := withProxy(context.Background(), proxyURL)
+	client := oauth2.NewClient(ctx, oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "test-token"}))
+
+	resp, err := client.Get("http://api.example.invalid/v1/generate")
+	if err != nil {
+		t.Fatalf("Request through proxy failed: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if proxiedURL != "http://api.example.invalid/v1/generate" {
+		t.Errorf("Expected proxy to receive the target URL, got %q", proxiedURL)
+	}
+	if proxiedAuth != "Bearer test-token" {
+		t.Errorf("Expected proxied request to carry the token, got %q", proxiedAuth)
+	}
+}
+
+func TestWebFetchWithProxyStillValidatesTarget(t *testing.T) {
+	proxyURL, err := url.Parse("http://127.0.0.1:3128")
+	if err != nil {
+		t.Fatalf("Failed to parse proxy URL: %v", err)
+	}
+	ctx := createTestContext().WithProxy(proxyURL)
+	ctx.Validator = security.NewDefaultValidator()
+
+	client := &fakeWebClient{fetchResult: &types.WebFetchResult{Content: "internal"}}
+	result := callWebFetch(t, ctx, client, "http://localhost:8080/admin")
+	if !result.IsError {
+		t.Fatal("Expected a localhost target to be rejected")
+	}
+	if text := result.Content[0].(*mcp.TextContent).Text; !strings.Contains(text, "Invalid URL") {
+		t.Errorf("Unexpected error message: %s", text)
+	}
+	if client.fetched.Load() {
+		t.Error("Expected the target to be rejected before fetching")
+	}
+}
+
+func TestWebClientIsReusedAcrossCalls(t *testing.T) {
+	var constructions atomic.Int32
+	opts := stubWebClient(t, func(storage.CredentialStore) (webClient, error) {
+		constructions.Add(1)
+		return &fakeWebClient{fetchResult: &types.WebFetchResult{Content: "ok"}}, nil
+	})
+
+	ctx := createTestContext()
+	session := connectWebTools(t, createWebFetchTool(ctx, opts), createWebSearchTool(ctx, opts))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			result, err := session.CallTool(context.Background(), &mcp.CallToolParams{
+				Name:      "WebFetch",
+				Arguments: map[string]any{"url": "https://example.com", "prompt": "Summarize"},
+			})
+			if err != nil || result.IsError {
+				t.Errorf("WebFetch failed: %v %v", err, result)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			result, err := session.CallTool(context.Background(), &mcp.CallToolParams{
+				Name:      "WebSearch",
+				Arguments: map[string]any{"query": "golang"},
+			})
+			if err != nil || result.IsError {
+				t.Errorf("WebSearch failed: %v %v", err, result)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := constructions.Load(); got != 1 {
+		t.Errorf("Expected the web client to be constructed once, got %d constructions", got)
+	}
+}
+
+func TestWebClientCreationFailureIsRetried(t *testing.T) {
+	var constructions atomic.Int32
+	opts := stubWebClient(t, func(storage.CredentialStore) (webClient, error) {
+		if constructions.Add(1) == 1 {
+			return nil, errors.New("not ready")
+		}
+		return &fakeWebClient{}, nil
+	})
+
+	if _, err := opts.clients.get(); err == nil {
+		t.Fatal("Expected the first creation to fail")
+	}
+	first, err := opts.clients.get()
+	if err != nil {
+		t.Fatalf("Expected the second creation to succeed, got: %v", err)
+	}
+	second, err := opts.clients.get()
+	if err != nil || second != first {
+		t.Errorf("Expected the client to be cached after a successful creation")
+	}
+	if got := constructions.Load(); got != 2 {
+		t.Errorf("Expected 2 constructions, got %d", got)
+	}
+}
+
+func TestProxyHTTPClientIsShared(t *testing.T) {
+	proxy, _ := url.Parse("http://proxy.example.com:3128")
+	if proxyHTTPClient(proxy) != proxyHTTPClient(proxy) {
+		t.Error("Expected the same HTTP client for the same proxy")
+	}
+	if proxyHTTPClient(proxy) == proxyHTTPClient(nil) {
+		t.Error("Expected different HTTP clients for different proxies")
+	}
+}
+
+func TestWebSearchJSONOutput(t *testing.T) {
+	searchResult := &types.WebSearchResult{DisplayText: "Go is a programming language."}
+	for _, source := range []struct{ title, uri string }{
+		{"The Go Programming Language", "https://go.dev/"},
+		{"Go - Wikipedia", "https://en.wikipedia.org/wiki/Go_(programming_language)"},
+		{"Go blog", "https://go.dev/blog/"},
+	} {
+		var chunk types.GroundingChunk
+		chunk.Web.Title, chunk.Web.URI = source.title, source.uri
+		searchResult.Sources = append(searchResult.Sources, chunk)
+	}
+
+	opts := stubWebClient(t, func(storage.CredentialStore) (webClient, error) {
+		return &fakeWebClient{searchResult: searchResult}, nil
+	})
+	clientSession := connectWebTools(t, createWebSearchTool(createTestContext(), opts))
+
+	result, err := clientSession.CallTool(context.Background(), &mcp.CallToolParams{
+		Name: "WebSearch",
+		Arguments: map[string]any{
+			"query":           "golang",
+			"blocked_domains": []string{"wikipedia.org"},
+			"output_format":   "json",
+		},
+	})
+	if err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+	text := result.Content[0].(*mcp.TextContent).Text
+	if result.IsError {
+		t.Fatalf("Expected success, got: %s", text)
+	}
+
+	var items []map[string]any
+	if err := json.Unmarshal([]byte(text), &items); err != nil {
+		t.Fatalf("Expected a JSON array, got %q: %v", text, err)
+	}
+	expected := []map[string]any{
+		{"title": "The Go Programming Language", "url": "https://go.dev/", "snippet": ""},
+		{"title": "Go blog", "url": "https://go.dev/blog/", "snippet": ""},
+	}
+	if len(items) != len(expected) {
+		t.Fatalf("Expected %d results after domain filtering, got: %s", len(expected), text)
+	}
+	for i, want := range expected {
+		for key, value := range want {
+			if items[i][key] != value {
+				t.Errorf("Result %d: expected %s %q, got %v", i, key, value, items[i][key])
+			}
+		}
+	}
+
+	invalid, err := clientSession.CallTool(context.Background(), &mcp.CallToolParams{
+		Name:      "WebSearch",
+		Arguments: map[string]any{"query": "golang", "output_format": "xml"},
+	})
+	if err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+	if !invalid.IsError {
+		t.Error("Expected an unknown output format to be rejected")
+	}
+}